@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/browse"
+)
+
+var browseCmd = &cobra.Command{
+	Use:   "browse [path]",
+	Short: "Explore the extracted API in the terminal",
+	Long: `Open an interactive terminal browser over the API extracted from the Go sources
+in path. It needs nothing but an ANSI terminal, so it works over SSH.
+
+Endpoints are listed by tag on the left; type / to filter them with a fuzzy
+search over methods, paths, summaries and handler names. The right pane shows
+the selected endpoint's parameters, request body, responses and documentation.
+Schemas mentioned there are numbered: press the number to open one, and
+backspace to return. o opens the source of what the pane shows in $VISUAL or
+$EDITOR at the right line.
+
+The analysis is cached, so browsing an unchanged tree again starts instantly.`,
+	Example: `  api-doc-gen-go browse ./...
+  EDITOR=nano api-doc-gen-go browse ./services/billing -r`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		doc, err := analyze(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		dir, _ := analyzer.SplitPattern(args[0])
+		root, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		title := doc.Service
+		if title == "" {
+			title = doc.Module
+		}
+		if title == "" {
+			title = filepath.Base(root)
+		}
+		var opts browse.Options
+		opts.Root = root
+		opts.Editor, _ = cmd.Flags().GetString("editor")
+		return browse.Run(browse.New(doc, title), opts)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(browseCmd)
+
+	addAnalysisFlags(browseCmd)
+	browseCmd.Flags().String("editor", "", "Command opening source files (default: $VISUAL or $EDITOR)")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/compat"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <ts-output.json> [path]",
+	Short: "Compare the output of the TypeScript Go parser with this component's",
+	Long: `Compare the JSON output of the TypeScript GoDocParser for a service with the
+analysis of its Go sources under path (./... by default), shaped as by
+parse --compat ts-godoc, and report their semantic differences per endpoint
+and schema: endpoints found by only one of them, and differences of path,
+summary, description, parameters, request body and responses. A path
+ending in .json is read as saved output of parse --compat ts-godoc instead.
+
+The TS output may be one ParseResponse, an array of them, one per parsed
+file, or the ast of a ParseResponse. Endpoints are matched by method and
+path, whatever their path parameters are named, and then by ID, since
+GoDocParser guesses the paths of handlers whose doc comments name no
+route. Texts are compared with their white space collapsed.
+
+The command fails when the outputs differ, so that a service whose
+outputs agree can move to this component.`,
+	Example: `  api-doc-gen-go compare ts-output.json ./services/users/...
+  api-doc-gen-go compare ts-output.json go-output.json --format json -o diff.json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown report format %q (want text or json)", format)
+		}
+		ts, err := readParseResponse(args[0])
+		if err != nil {
+			return err
+		}
+		path := "./..."
+		if len(args) == 2 {
+			path = args[1]
+		}
+		var goOut *compat.ParseResponse
+		if strings.HasSuffix(path, ".json") {
+			if goOut, err = readParseResponse(path); err != nil {
+				return err
+			}
+		} else {
+			start := time.Now()
+			doc, err := analyze(cmd, path)
+			if err != nil {
+				return err
+			}
+			goOut = compat.GoDoc(doc, compat.Options{Source: path, Elapsed: time.Since(start)})
+		}
+
+		report := compat.Compare(ts, goOut)
+		output, _ := cmd.Flags().GetString("output")
+		err = writeOutput(output, func(w io.Writer) error {
+			if format == "json" {
+				return encode(w, report, format)
+			}
+			return report.WriteText(w)
+		})
+		if err != nil {
+			return err
+		}
+		if report.Differs() {
+			return fmt.Errorf("the TS and Go outputs differ")
+		}
+		return nil
+	},
+}
+
+// readParseResponse reads the parse output in the named JSON file.
+func readParseResponse(name string) (*compat.ParseResponse, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := compat.ReadParseResponse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return resp, nil
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	addAnalysisFlags(compareCmd)
+	addRevisionFlag(compareCmd)
+	compareCmd.Flags().StringP("format", "f", "text", "Report format (text, json)")
+	compareCmd.Flags().StringP("output", "o", "", "Output file for the report")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/bundle"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/config"
+)
+
+// version is the version of api-doc-gen-go recorded in debug bundles.
+const version = "1.0.0"
+
+// panics collects the panics recovered during the run, for --debug-bundle.
+var panics struct {
+	sync.Mutex
+	list []*analyzer.Panic
+}
+
+func recordPanic(p *analyzer.Panic) {
+	panics.Lock()
+	defer panics.Unlock()
+	panics.list = append(panics.list, p)
+}
+
+// debugBundle returns the file named by --debug-bundle, if any.
+func debugBundle(cmd *cobra.Command) string {
+	name, _ := cmd.Flags().GetString("debug-bundle")
+	return name
+}
+
+// execute runs the command line. A panic that escapes the recovery of the
+// analysis, which isolates packages from each other, fails the run with
+// an internal error after printing its stack trace.
+func execute() (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		stack := string(debug.Stack())
+		recordPanic(&analyzer.Panic{Stage: "command", Value: fmt.Sprint(v), Stack: stack})
+		fmt.Fprint(os.Stderr, stack)
+		err = fmt.Errorf("internal error: %v", v)
+		if debugBundle(rootCmd) == "" {
+			err = fmt.Errorf("%w (rerun with --debug-bundle to report it)", err)
+		}
+	}()
+	return rootCmd.Execute()
+}
+
+// writeDebugBundle writes the bundle asked for by --debug-bundle,
+// recording runErr, the error the run failed with.
+func writeDebugBundle(runErr error) error {
+	name := debugBundle(rootCmd)
+	if name == "" {
+		return nil
+	}
+	b := &bundle.Bundle{Version: version, Args: os.Args[1:]}
+	b.Redact, _ = rootCmd.PersistentFlags().GetBool("redact-bundle")
+	if runErr != nil {
+		b.Error = runErr.Error()
+	}
+	b.ConfigName, _ = rootCmd.PersistentFlags().GetString("config")
+	if b.ConfigName == "" {
+		b.ConfigName = config.DefaultFile
+	}
+	if data, err := os.ReadFile(b.ConfigName); err == nil {
+		b.Config = data
+	} else {
+		// There is no configuration file, or reading it failed the run.
+		b.ConfigName = ""
+	}
+	panics.Lock()
+	b.Panics = panics.list
+	panics.Unlock()
+	if err := b.Write(name); err != nil {
+		return fmt.Errorf("--debug-bundle: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "debug bundle written to %s (%d internal errors)\n", name, len(b.Panics))
+	return nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("debug-bundle", "", "Write a zip archive reproducing internal errors of the run, for bug reports")
+	rootCmd.PersistentFlags().Bool("redact-bundle", false, "Replace the text of comments and string literals in the sources captured by --debug-bundle")
+}
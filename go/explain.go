@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [path]",
+	Short: "Explain why a route was or was not extracted",
+	Long: `Trace how the analysis of the Go sources in path treats a route or a handler:
+which framework extractor matched the registration, the routers the route
+is reached through and where they are mounted or passed, how its path is
+composed from prefixes and what the constants in it evaluate to, the
+handler and methods it resolves to, and whether it became an endpoint or
+why not.
+
+--route matches routes by method and path in any framework's syntax;
+parameter names do not matter and the method may be left out. Routes whose
+path differs only by a prefix, or that serve another method, are traced as
+near matches when nothing matches exactly. --func matches the routes served
+by a handler and, when there are none, traces every use of the handler.
+When nothing is extracted, files left out by --include, --exclude, test
+files and skipped directories that mention the route or handler are listed.
+
+path defaults to ./... .`,
+	Example: `  api-doc-gen-go explain --route "GET /users/{id}"
+  api-doc-gen-go explain ./cmd/api/... --func handlers.GetUser
+  api-doc-gen-go explain --route /orders -f json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "./..."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		route, _ := cmd.Flags().GetString("route")
+		fn, _ := cmd.Flags().GetString("func")
+		var q analyzer.Query
+		switch {
+		case (route == "") == (fn == ""):
+			return fmt.Errorf("give one of --route and --func")
+		case route != "":
+			var err error
+			if q, err = analyzer.ParseRoute(route); err != nil {
+				return err
+			}
+		default:
+			q.Func = fn
+		}
+		opts, err := analysisOptions(cmd)
+		if err != nil {
+			return err
+		}
+		opts, done, err := revisionOptions(cmd, path, opts)
+		if err != nil {
+			return err
+		}
+		defer done()
+		ex, err := analyzer.Explain(path, opts, q)
+		if err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		return writeOutput(output, func(w io.Writer) error {
+			if format != "text" {
+				return encode(w, ex, format)
+			}
+			return writeExplanation(w, ex)
+		})
+	},
+}
+
+// writeExplanation prints the traces of an explanation, one step per
+// line with the source position it refers to.
+func writeExplanation(w io.Writer, ex *analyzer.Explanation) error {
+	if len(ex.Traces) == 0 {
+		if _, err := fmt.Fprintf(w, "nothing in the analyzed sources matches %s\n", ex.Query); err != nil {
+			return err
+		}
+	}
+	for i, t := range ex.Traces {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s (%s)\n", t.Subject, t.Pos)
+		for _, s := range t.Steps {
+			if err := writeStep(w, "  ", s); err != nil {
+				return err
+			}
+		}
+	}
+	if len(ex.Notes) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "notes:")
+		for _, s := range ex.Notes {
+			if err := writeStep(w, "  ", s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeStep(w io.Writer, indent string, s *analyzer.Step) error {
+	line := fmt.Sprintf("%s%-10s %s", indent, s.Stage, s.Message)
+	if s.Pos != nil {
+		line += fmt.Sprintf(" (%s)", s.Pos)
+	}
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	addAnalysisFlags(explainCmd)
+	addRevisionFlag(explainCmd)
+	explainCmd.Flags().String("route", "", `Route to explain, e.g. "GET /users/{id}"`)
+	explainCmd.Flags().String("func", "", "Handler to explain, e.g. handlers.GetUser")
+	explainCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	explainCmd.Flags().StringP("format", "f", "text", "Output format (text, json, yaml)")
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/fuzzgen"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/gateway"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/glossary"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/modelgen"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/openapi"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/sunset"
+)
+
+var genCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "Generate artifacts from the extracted API",
+	Long: `Generate configuration and code from the endpoints, schemas and middleware
+extracted from Go source code.`,
+}
+
+var genGatewayCmd = &cobra.Command{
+	Use:   "gateway [path]",
+	Short: "Generate API gateway configuration",
+	Long: `Generate API gateway configuration for the endpoints extracted from the Go
+sources in path.
+
+Route templates are converted to the gateway's path syntax, rate limits are
+taken from detected rate limiting middleware, and authentication plugin stubs
+are generated for the security schemes each endpoint requires. The upstream
+service is named after the binary that serves the API unless --service is
+given. Placeholder values in plugin stubs are marked TODO.`,
+	Example: `  api-doc-gen-go gen gateway ./... --target kong -o kong.yaml
+  api-doc-gen-go gen gateway ./cmd/api -r --target envoy --upstream http://api:9000
+  api-doc-gen-go gen gateway . -r --target krakend -o krakend.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, _ := cmd.Flags().GetString("target")
+		known := false
+		for _, t := range gateway.Targets {
+			known = known || t == target
+		}
+		if !known {
+			return fmt.Errorf("unknown gateway target %q (want one of %s)", target, strings.Join(gateway.Targets, ", "))
+		}
+		var opts gateway.Options
+		opts.Service, _ = cmd.Flags().GetString("service")
+		opts.Upstream, _ = cmd.Flags().GetString("upstream")
+		opts.Port, _ = cmd.Flags().GetInt("port")
+
+		doc, err := analyze(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		out, err := gateway.Generate(doc, target, opts)
+		if err != nil {
+			return err
+		}
+		output, _ := cmd.Flags().GetString("output")
+		return writeOutput(output, func(w io.Writer) error {
+			_, err := w.Write(out)
+			return err
+		})
+	},
+}
+
+var genFuzzCmd = &cobra.Command{
+	Use:   "fuzz [path]",
+	Short: "Generate Go fuzz tests for every endpoint",
+	Long: `Generate a _test.go file with one native Go fuzz test per endpoint extracted
+from the Go sources in path.
+
+Each test fuzzes the endpoint's path, query, header and cookie parameters and
+the scalar fields of its JSON request body, constrained by the extracted
+schemas, and seeds the corpus from documented examples. Requests are served
+in-process through net/http/httptest by the application's router; a test fails
+when a handler panics or responds with a 5xx status.
+
+The router is the function with no parameters that returns the router on
+which the most endpoints are registered, e.g. NewRouter, and the tests are
+written next to it as apidoc_fuzz_test.go. --router names another such
+function, or gives any Go expression of type http.Handler together with
+--package; the file is then written to stdout unless --output is set.`,
+	Example: `  api-doc-gen-go gen fuzz ./...
+  api-doc-gen-go gen fuzz ./... --header "Authorization: Bearer test"
+  api-doc-gen-go gen fuzz . -r --router 'server.New(server.Config{})' --package server_test -o server/fuzz_test.go`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var opts fuzzgen.Options
+		opts.Router, _ = cmd.Flags().GetString("router")
+		opts.Package, _ = cmd.Flags().GetString("package")
+		output, _ := cmd.Flags().GetString("output")
+		headers, _ := cmd.Flags().GetStringArray("header")
+		opts.Headers = map[string]string{}
+		for _, h := range headers {
+			name, value, ok := strings.Cut(h, ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid header %q (want \"Name: value\")", h)
+			}
+			opts.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+
+		doc, err := analyze(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		router := findRouter(doc, opts.Router)
+		switch {
+		case router != nil:
+			opts.Router = router.Func + "()"
+			if opts.Package == "" {
+				opts.Package = router.Package
+			}
+			if output == "" {
+				root, _ := analyzer.SplitPattern(args[0])
+				output = filepath.Join(root, router.Dir, "apidoc_fuzz_test.go")
+			}
+		case opts.Router == "":
+			return fmt.Errorf("no function returning the router was found in %s; use --router and --package", args[0])
+		case opts.Package == "":
+			return fmt.Errorf("--package is required when --router is an expression")
+		}
+		out, err := fuzzgen.Generate(doc, opts)
+		if err != nil {
+			return err
+		}
+		return writeOutput(output, func(w io.Writer) error {
+			_, err := w.Write(out)
+			return err
+		})
+	},
+}
+
+var genModelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "Generate Go model types from JSON Schema or OpenAPI",
+	Long: `Generate Go types for the schemas of an OpenAPI 3 or Swagger 2 document, a
+JSON Schema document, or the output of parse.
+
+Objects become structs with json tags. Optional properties are omitempty and
+held through pointers unless they are slices, maps or interfaces; required
+nullable properties are pointers without omitempty. Enums become a defined
+type with a const block and a String method, and oneOf schemas become sealed
+interfaces with an UnmarshalXxx function choosing the variant. Descriptions
+become doc comments, and constraints are kept as struct tags, so that parsing
+the generated package yields equivalent schemas.`,
+	Example: `  api-doc-gen-go gen models --from spec.yaml --package vendorapi -o vendorapi/models.go
+  api-doc-gen-go gen models --from order.schema.json --package orders`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, _ := cmd.Flags().GetString("from")
+		var opts modelgen.Options
+		opts.Package, _ = cmd.Flags().GetString("package")
+		opts.Source = filepath.Base(from)
+		data, err := os.ReadFile(from)
+		if err != nil {
+			return err
+		}
+		schemas, err := openapi.ReadSchemas(data, from)
+		if err != nil {
+			return err
+		}
+		out, err := modelgen.Generate(schemas, opts)
+		if err != nil {
+			return err
+		}
+		output, _ := cmd.Flags().GetString("output")
+		return writeOutput(output, func(w io.Writer) error {
+			_, err := w.Write(out)
+			return err
+		})
+	},
+}
+
+var genGlossaryCmd = &cobra.Command{
+	Use:   "glossary [path]",
+	Short: "Generate a Markdown glossary page",
+	Long: `Generate a Markdown page listing the terms of the glossary with their
+definitions, the terms they replace, and links to the schemas and endpoints of
+the Go sources in path whose documentation uses them. Terms to avoid are
+listed in a table at the end.
+
+Links point at the source files, relative to the output file.`,
+	Example: `  api-doc-gen-go gen glossary ./... --glossary glossary.yaml -o docs/GLOSSARY.md`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := loadGlossary(cmd)
+		if err != nil {
+			return err
+		}
+		if g == nil {
+			return fmt.Errorf("no glossary given; use --glossary or the glossary key of the configuration file")
+		}
+		analysis, err := analysisOptions(cmd)
+		if err != nil {
+			return err
+		}
+		prog, err := analyzer.Load(args[0], analysis)
+		if err != nil {
+			return err
+		}
+		_, texts := analyzer.AnalyzeTexts(prog)
+		var opts glossary.PageOptions
+		output, _ := cmd.Flags().GetString("output")
+		if output != "" && output != "-" {
+			abs, err := filepath.Abs(output)
+			if err != nil {
+				return err
+			}
+			if rel, err := filepath.Rel(filepath.Dir(abs), prog.Root); err == nil && rel != "." {
+				opts.SourceBase = filepath.ToSlash(rel)
+			}
+		}
+		page := g.Page(texts, opts)
+		return writeOutput(output, func(w io.Writer) error {
+			_, err := w.Write(page)
+			return err
+		})
+	},
+}
+
+var genSunsetCmd = &cobra.Command{
+	Use:   "sunset [path]",
+	Short: "Generate middleware announcing deprecated endpoints",
+	Long: `Generate a Go package whose Middleware function sets the Deprecation header,
+and the RFC 8594 Sunset header when a sunset date is documented, on the
+responses of the deprecated endpoints of the Go sources in path, and of no
+other endpoints. Wrap the router with it so that clients get the same
+schedule as the documentation:
+
+  http.ListenAndServe(":8080", sunset.Middleware(router))
+
+--link adds a Link header with the "sunset" relation pointing to the
+deprecation policy. Regenerate the package when deprecations change.`,
+	Example: `  api-doc-gen-go gen sunset ./... --package sunset -o internal/sunset/sunset.go
+  api-doc-gen-go gen sunset ./... --link https://example.com/docs/deprecations`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var opts sunset.Options
+		opts.Package, _ = cmd.Flags().GetString("package")
+		opts.Link, _ = cmd.Flags().GetString("link")
+		doc, err := analyze(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		out, err := sunset.Middleware(doc, opts)
+		if err != nil {
+			return err
+		}
+		output, _ := cmd.Flags().GetString("output")
+		return writeOutput(output, func(w io.Writer) error {
+			_, err := w.Write(out)
+			return err
+		})
+	},
+}
+
+// findRouter returns the detected router function named name, qualified by
+// its package name or not, or the router serving the most endpoints when
+// name is empty.
+func findRouter(doc *model.Document, name string) *model.Router {
+	for _, r := range doc.Routers {
+		if name == "" || name == r.Func || name == r.Package+"."+r.Func {
+			return r
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(genCmd)
+	genCmd.AddCommand(genGatewayCmd)
+	genCmd.AddCommand(genFuzzCmd)
+	genCmd.AddCommand(genModelsCmd)
+	genCmd.AddCommand(genGlossaryCmd)
+	genCmd.AddCommand(genSunsetCmd)
+
+	addAnalysisFlags(genGatewayCmd)
+	genGatewayCmd.Flags().StringP("target", "t", "", fmt.Sprintf("Gateway to configure (%s)", strings.Join(gateway.Targets, ", ")))
+	genGatewayCmd.Flags().String("service", "", "Upstream service name (default: the binary serving the API)")
+	genGatewayCmd.Flags().String("upstream", "", "Upstream base URL (default: http://<service>:8080)")
+	genGatewayCmd.Flags().Int("port", 8000, "Port the gateway listens on")
+	genGatewayCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	_ = genGatewayCmd.MarkFlagRequired("target")
+
+	addAnalysisFlags(genFuzzCmd)
+	addExampleFlags(genFuzzCmd)
+	genFuzzCmd.Flags().String("router", "", "Router function, or Go expression of type http.Handler (default: detected)")
+	genFuzzCmd.Flags().String("package", "", "Package of the generated file (default: the router's package)")
+	genFuzzCmd.Flags().StringArray("header", nil, "Header set on every request, as \"Name: value\" (repeatable)")
+	genFuzzCmd.Flags().StringP("output", "o", "", "Output file (default: apidoc_fuzz_test.go next to the router)")
+
+	genModelsCmd.Flags().String("from", "", "OpenAPI, Swagger or JSON Schema document (YAML or JSON)")
+	genModelsCmd.Flags().String("package", "", "Package of the generated file")
+	genModelsCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	_ = genModelsCmd.MarkFlagRequired("from")
+	_ = genModelsCmd.MarkFlagRequired("package")
+
+	addAnalysisFlags(genGlossaryCmd)
+	genGlossaryCmd.Flags().String("glossary", "", "Glossary file (default: the glossary key of the configuration file)")
+	genGlossaryCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+
+	addAnalysisFlags(genSunsetCmd)
+	genSunsetCmd.Flags().String("package", "sunset", "Package of the generated file")
+	genSunsetCmd.Flags().String("link", "", "URL of the deprecation policy, sent in a Link header")
+	genSunsetCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+}
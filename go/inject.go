@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/inject"
+)
+
+var injectCmd = &cobra.Command{
+	Use:   "inject file...",
+	Short: "Update generated API sections of Markdown files",
+	Long: `Replace the content of the marked regions of Markdown files with fragments
+rendered from the API document of the Go sources in --source, keeping the
+hand-written text around them. A region is delimited by markers on lines of
+their own:
+
+  <!-- apidoc:begin endpoints tag=users -->
+  <!-- apidoc:end -->
+
+The fragments are:
+
+  endpoints  a table of endpoints; tag=, path= (a prefix) and method=
+             select which
+  schemas    the field tables of the schemas listed by name=User,Order,
+             or of every schema
+  config     the configuration reference of the schema named by schema=,
+             one row per setting under its dotted key
+  middleware the middleware catalog with the effects of each middleware,
+             or the ordered chain of the endpoint named by
+             route="GET /users/{id}"
+
+Markers inside fenced code blocks are ignored. With --check no file is
+written, and the command fails when a region is stale, so CI can require
+the injected sections to be up to date.`,
+	Example: `  api-doc-gen-go inject README.md docs/*.md
+  api-doc-gen-go inject docs/*.md --source ./cmd/api/... --check`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := expandFiles(args)
+		if err != nil {
+			return err
+		}
+		source, _ := cmd.Flags().GetString("source")
+		doc, err := analyze(cmd, source)
+		if err != nil {
+			return err
+		}
+		check, _ := cmd.Flags().GetBool("check")
+		stale := 0
+		for _, name := range files {
+			src, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			out, regions, err := inject.Apply(src, doc)
+			if err != nil {
+				return fmt.Errorf("%s:%w", name, err)
+			}
+			if check {
+				for _, r := range regions {
+					fmt.Fprintf(os.Stderr, "%s:%d: %s is stale\n", name, r.Line, r)
+				}
+				stale += len(regions)
+				continue
+			}
+			if bytes.Equal(out, src) {
+				continue
+			}
+			info, err := os.Stat(name)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(name, out, info.Mode().Perm()); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "updated %d regions of %s\n", len(regions), name)
+		}
+		if stale > 0 {
+			return fmt.Errorf("%d stale regions; run api-doc-gen-go inject to update them", stale)
+		}
+		return nil
+	},
+}
+
+// expandFiles expands the glob patterns among names, for shells that pass
+// them through. Names without matches are kept, so that reading them
+// reports the missing file.
+func expandFiles(names []string) ([]string, error) {
+	var files []string
+	for _, name := range names {
+		if !strings.ContainsAny(name, "*?[") {
+			files = append(files, name)
+			continue
+		}
+		matches, err := filepath.Glob(name)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", name)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func init() {
+	rootCmd.AddCommand(injectCmd)
+
+	addAnalysisFlags(injectCmd)
+	addExampleFlags(injectCmd)
+	addRevisionFlag(injectCmd)
+	injectCmd.Flags().StringP("source", "s", "./...", "Go sources to analyze")
+	injectCmd.Flags().Bool("check", false, "Write nothing and fail when a region is stale")
+}
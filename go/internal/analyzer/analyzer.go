@@ -0,0 +1,391 @@
+// Package analyzer extracts HTTP endpoints, data models and security
+// schemes from Go source code.
+//
+// Analysis is purely syntactic: packages are parsed with go/parser and
+// never type-checked, so the analyzer works on code whose dependencies are
+// not downloaded and on code that does not compile. Router registrations are
+// recognized for net/http, gorilla/mux, chi, gin and echo, and handler doc
+// comments follow the conventions of the TypeScript GoDocParser.
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Analyze loads the packages under root and extracts their API document.
+func Analyze(root string, opts Options) (*model.Document, error) {
+	prog, err := Load(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyzeProgram(prog), nil
+}
+
+// AnalyzeProgram extracts the API document from already loaded packages.
+func AnalyzeProgram(prog *Program) *model.Document {
+	doc, _ := finish([]*Partial{newAnalyzer(prog).collect()})
+	return doc
+}
+
+type analyzer struct {
+	prog *Program
+	doc  *model.Document
+
+	// Declarations indexed by "importPath.Name"; methods are indexed by
+	// "importPath.Recv.Name".
+	types  map[string]*typeDecl
+	funcs  map[string]*funcDecl
+	consts map[string]*constDecl
+	vars   map[string]*varDecl
+	// methods indexes methods by bare name for method values whose
+	// receiver type cannot be determined syntactically.
+	methods map[string][]*funcDecl
+	// enums lists the typed constants of each named type.
+	enums map[string][]*constDecl
+
+	// schemaNames maps a type key to its component schema name.
+	schemaNames map[string]string
+
+	routes  *routeGraph
+	helpers map[*funcDecl]*helperFacts
+	// part collects what the program contributes to the document.
+	part *Partial
+	// imports caches the import paths each package reaches, and byPath
+	// indexes the packages by import path.
+	imports map[*Package]map[string]bool
+	byPath  map[string][]*Package
+}
+
+type typeDecl struct {
+	Name string
+	File *File
+	Spec *ast.TypeSpec
+	Doc  *ast.CommentGroup
+}
+
+type funcDecl struct {
+	Name string
+	Recv string
+	File *File
+	Decl *ast.FuncDecl
+}
+
+// id returns the key of f in the analyzer's index of functions.
+func (f *funcDecl) id() string {
+	if f.Recv != "" {
+		return f.File.Pkg.ImportPath + "." + f.Recv + "." + f.Name
+	}
+	return f.File.Pkg.ImportPath + "." + f.Name
+}
+
+// Key returns the handler name shown in the output, e.g. "handlers.GetUser"
+// or "handlers.UserHandler.Get".
+func (f *funcDecl) Key() string {
+	if f.Recv != "" {
+		return f.File.Pkg.Name + "." + f.Recv + "." + f.Name
+	}
+	return f.File.Pkg.Name + "." + f.Name
+}
+
+type constDecl struct {
+	Name  string
+	Type  string
+	Value any
+	Doc   string
+	File  *File
+	Pos   token.Pos
+	// Comments is the comment group Doc was taken from.
+	Comments *ast.CommentGroup
+}
+
+type varDecl struct {
+	Name  string
+	File  *File
+	Type  ast.Expr
+	Value ast.Expr
+}
+
+func newAnalyzer(prog *Program) *analyzer {
+	a := &analyzer{
+		prog: prog,
+		doc: &model.Document{
+			Module:          prog.Module,
+			Schemas:         map[string]*model.Schema{},
+			SecuritySchemes: map[string]*model.SecurityScheme{},
+			Middleware:      map[string]*model.Middleware{},
+		},
+		types:       map[string]*typeDecl{},
+		funcs:       map[string]*funcDecl{},
+		consts:      map[string]*constDecl{},
+		vars:        map[string]*varDecl{},
+		methods:     map[string][]*funcDecl{},
+		enums:       map[string][]*constDecl{},
+		schemaNames: map[string]string{},
+		helpers:     map[*funcDecl]*helperFacts{},
+	}
+	a.doc.Diagnostics = append(a.doc.Diagnostics, prog.Diagnostics...)
+	return a
+}
+
+// collect analyzes the program into a partial document, which finish
+// completes.
+func (a *analyzer) collect() *Partial {
+	a.part = &Partial{
+		Version:  partialVersion,
+		Shard:    a.prog.Shard,
+		Plan:     a.prog.Plan,
+		Module:   a.prog.Module,
+		RootName: a.rootName(),
+		Reached:  map[string][]string{},
+		Naming:   a.prog.naming,
+	}
+	a.index()
+	a.collectConsts()
+	a.collectPackages()
+	a.collectSchemas()
+	a.collectEndpoints()
+	for _, pkg := range a.prog.Packages {
+		if pkg.Name == "main" {
+			a.part.Services = append(a.part.Services, &PartialService{
+				ImportPath: pkg.ImportPath,
+				Name:       a.binaryName(pkg),
+				Registers:  a.routes.registersIn(pkg),
+			})
+		}
+	}
+	a.part.Packages = a.doc.Packages
+	a.part.Schemas = a.doc.Schemas
+	a.part.Diagnostics = a.doc.Diagnostics
+	return a.part
+}
+
+func (a *analyzer) index() {
+	for _, pkg := range a.prog.Packages {
+		a.guard("index", pkg, token.NoPos, func() { a.indexPackage(pkg) })
+	}
+}
+
+func (a *analyzer) indexPackage(pkg *Package) {
+	for _, f := range pkg.Files {
+		for _, decl := range f.AST.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				fd := &funcDecl{Name: d.Name.Name, File: f, Decl: d}
+				if d.Recv != nil && len(d.Recv.List) > 0 {
+					fd.Recv = recvTypeName(d.Recv.List[0].Type)
+					a.funcs[pkg.ImportPath+"."+fd.Recv+"."+fd.Name] = fd
+					a.methods[fd.Name] = append(a.methods[fd.Name], fd)
+				} else {
+					a.funcs[pkg.ImportPath+"."+fd.Name] = fd
+				}
+			case *ast.GenDecl:
+				a.indexGenDecl(f, d)
+			}
+		}
+	}
+}
+
+func (a *analyzer) indexGenDecl(f *File, d *ast.GenDecl) {
+	switch d.Tok {
+	case token.TYPE:
+		for _, spec := range d.Specs {
+			ts := spec.(*ast.TypeSpec)
+			doc := ts.Doc
+			if doc == nil && len(d.Specs) == 1 {
+				doc = d.Doc
+			}
+			a.types[f.Pkg.ImportPath+"."+ts.Name.Name] = &typeDecl{Name: ts.Name.Name, File: f, Spec: ts, Doc: doc}
+		}
+	case token.VAR:
+		for _, spec := range d.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				v := &varDecl{Name: name.Name, File: f, Type: vs.Type}
+				if i < len(vs.Values) {
+					v.Value = vs.Values[i]
+				}
+				a.vars[f.Pkg.ImportPath+"."+name.Name] = v
+			}
+		}
+	}
+}
+
+func recvTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return recvTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return recvTypeName(t.X)
+	case *ast.IndexListExpr:
+		return recvTypeName(t.X)
+	}
+	return ""
+}
+
+func (a *analyzer) collectPackages() {
+	for _, pkg := range a.prog.Packages {
+		p := &model.Package{Name: pkg.Name, ImportPath: pkg.ImportPath, Dir: pkg.Dir}
+		for _, f := range pkg.Files {
+			p.Files = append(p.Files, f.Name)
+			if f.AST.Doc != nil && p.Description == "" {
+				p.Description = strings.TrimSpace(f.AST.Doc.Text())
+			}
+		}
+		p.Assets = a.assets(pkg)
+		p.Limitations = a.limitations(pkg)
+		p.GoMinVersion, p.GoRequirements = a.goRequirements(pkg)
+		a.checkGoDirective(p)
+		a.doc.Packages = append(a.doc.Packages, p)
+	}
+}
+
+// qualify resolves an identifier or package-qualified selector to the
+// import path it refers to and the bare name.
+func (a *analyzer) qualify(f *File, expr ast.Expr) (string, string, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return f.Pkg.ImportPath, e.Name, true
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			if p, ok := f.ImportPath(x.Name); ok {
+				return p, e.Sel.Name, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// lookupType returns the declaration of a named type used in f.
+func (a *analyzer) lookupType(f *File, expr ast.Expr) *typeDecl {
+	p, name, ok := a.qualify(f, expr)
+	if !ok {
+		return nil
+	}
+	return a.types[p+"."+name]
+}
+
+// lookupFunc resolves a function or method value to its declaration.
+// Method values on receivers whose type is unknown are matched by name
+// when the name is unique among the packages f can reach through its
+// imports, the only ones that can declare the receiver's type.
+func (a *analyzer) lookupFunc(f *File, expr ast.Expr) *funcDecl {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return a.funcs[f.Pkg.ImportPath+"."+e.Name]
+	case *ast.SelectorExpr:
+		if p, name, ok := a.qualify(f, e); ok {
+			if fd := a.funcs[p+"."+name]; fd != nil {
+				return fd
+			}
+		}
+		var candidates []*funcDecl
+		for _, c := range a.methods[e.Sel.Name] {
+			if a.reaches(f.Pkg, c.File.Pkg) {
+				candidates = append(candidates, c)
+			}
+		}
+		if len(candidates) == 1 {
+			return candidates[0]
+		}
+		// Prefer a method declared in the same package.
+		var local *funcDecl
+		for _, c := range candidates {
+			if c.File.Pkg == f.Pkg {
+				if local != nil {
+					return nil
+				}
+				local = c
+			}
+		}
+		return local
+	case *ast.ParenExpr:
+		return a.lookupFunc(f, e.X)
+	}
+	return nil
+}
+
+// reaches reports whether from is to or imports it, directly or through
+// other packages of the program.
+func (a *analyzer) reaches(from, to *Package) bool {
+	if from == to {
+		return true
+	}
+	if a.imports == nil {
+		a.imports = map[*Package]map[string]bool{}
+		a.byPath = map[string][]*Package{}
+		for _, pkg := range a.prog.Packages {
+			a.byPath[pkg.ImportPath] = append(a.byPath[pkg.ImportPath], pkg)
+		}
+	}
+	closure := a.imports[from]
+	if closure == nil {
+		closure = map[string]bool{}
+		queue := []*Package{from}
+		for len(queue) > 0 {
+			pkg := queue[0]
+			queue = queue[1:]
+			for _, f := range pkg.Files {
+				for _, p := range f.imports {
+					if !closure[p] {
+						closure[p] = true
+						queue = append(queue, a.byPath[p]...)
+					}
+				}
+			}
+		}
+		a.imports[from] = closure
+	}
+	return closure[to.ImportPath]
+}
+
+func (a *analyzer) diag(code, severity string, pos token.Pos, format string) {
+	a.doc.Diagnostics = append(a.doc.Diagnostics, &model.Diagnostic{
+		Code:     code,
+		Severity: severity,
+		Message:  format,
+		Pos:      a.prog.Position(pos),
+	})
+}
+
+// binaryName returns the name go build gives the binary of a main
+// package.
+func (a *analyzer) binaryName(pkg *Package) string {
+	if pkg.Dir != "." {
+		return path.Base(pkg.Dir)
+	}
+	// A binary built at the module root is named after the module, and
+	// any other after its directory.
+	if a.prog.ModuleDir != a.prog.Root {
+		return filepath.Base(a.prog.Root)
+	}
+	return a.rootName()
+}
+
+func (a *analyzer) rootName() string {
+	if a.prog.Module != "" {
+		base := path.Base(a.prog.Module)
+		if isVersionSuffix(base) {
+			base = path.Base(path.Dir(a.prog.Module))
+		}
+		return base
+	}
+	return path.Base(strings.ReplaceAll(a.prog.Root, "\\", "/"))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
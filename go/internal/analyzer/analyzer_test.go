@@ -0,0 +1,1276 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+}
+
+func endpoint(t *testing.T, doc *model.Document, key string) *model.Endpoint {
+	t.Helper()
+	for _, ep := range doc.Endpoints {
+		if ep.Key() == key {
+			return ep
+		}
+	}
+	var keys []string
+	for _, ep := range doc.Endpoints {
+		keys = append(keys, ep.Key())
+	}
+	require.Failf(t, "endpoint not found", "%s not in %v", key, keys)
+	return nil
+}
+
+func TestAnalyzeChi(t *testing.T) {
+	doc, err := Analyze("testdata/chiapp", Options{Recursive: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com/chiapp", doc.Module)
+	assert.Equal(t, "usersvc", doc.Service)
+	require.Len(t, doc.Endpoints, 4)
+
+	list := endpoint(t, doc, "GET /api/v1/users")
+	assert.Equal(t, "GET_ListUsers", list.ID)
+	assert.Equal(t, "chi", list.Framework)
+	assert.Equal(t, []string{"users"}, list.Tags)
+	assert.Equal(t, []string{"httprate.LimitByIP", "handlers.RequireAuth"}, list.Middleware)
+	assert.Equal(t, &model.RateLimit{Requests: 100, Period: model.PeriodMinute, Source: "httprate.LimitByIP"}, list.RateLimit)
+	assert.Equal(t, []string{"bearerAuth"}, list.Security)
+	require.Len(t, list.Parameters, 1)
+	assert.Equal(t, "limit", list.Parameters[0].Name)
+	assert.Equal(t, "query", list.Parameters[0].In)
+	assert.Equal(t, "integer", list.Parameters[0].Schema.Type)
+	assert.Equal(t, "Maximum number of users to return (optional)", list.Parameters[0].Description)
+
+	create := endpoint(t, doc, "POST /api/v1/users")
+	require.NotNil(t, create.RequestBody)
+	assert.Equal(t, "User", create.RequestBody.Schema.RefName())
+	require.Len(t, create.Responses, 2)
+	assert.Equal(t, "201", create.Responses[0].StatusCode)
+	assert.Equal(t, "The created user", create.Responses[0].Description)
+	assert.Equal(t, "400", create.Responses[1].StatusCode)
+
+	get := endpoint(t, doc, "GET /api/v1/users/{id}")
+	assert.True(t, get.Deprecated)
+	require.Len(t, get.Parameters, 1)
+	assert.Equal(t, "path", get.Parameters[0].In)
+	assert.True(t, get.Parameters[0].Required)
+
+	health := endpoint(t, doc, "GET /healthz")
+	assert.Empty(t, health.Security)
+	assert.Nil(t, health.RateLimit)
+	assert.Equal(t, "204", health.Responses[0].StatusCode)
+
+	user := doc.Schema("User")
+	require.NotNil(t, user)
+	assert.Equal(t, []string{"id", "name", "email"}, user.PropertyOrder)
+	assert.Equal(t, []string{"id", "name"}, user.Required)
+
+	require.Contains(t, doc.SecuritySchemes, "bearerAuth")
+	assert.Equal(t, "handlers.RequireAuth", doc.SecuritySchemes["bearerAuth"].Middleware)
+}
+
+func TestAnalyzeGin(t *testing.T) {
+	doc, err := Analyze("testdata/ginapp", Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ginapp", doc.Service)
+	get := endpoint(t, doc, "GET /v1/orders/{id}")
+	assert.Equal(t, "gin", get.Framework)
+	assert.Equal(t, []string{"apiKeyAuth"}, get.Security)
+	assert.Equal(t, "Order", get.Responses[0].Schema.RefName())
+
+	del := endpoint(t, doc, "DELETE /v1/orders/{id}")
+	assert.Equal(t, "204", del.Responses[0].StatusCode)
+
+	endpoint(t, doc, "GET /v1/orders/files/{path}")
+
+	require.Contains(t, doc.SecuritySchemes, "apiKeyAuth")
+	assert.Equal(t, "X-Api-Key", doc.SecuritySchemes["apiKeyAuth"].Name)
+}
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		in, want string
+		patterns map[string]string
+	}{
+		{"/users/:id", "/users/{id}", map[string]string{}},
+		{"/files/*path", "/files/{path}", map[string]string{}},
+		{"/users/{id:[0-9]+}", "/users/{id}", map[string]string{"id": "[0-9]+"}},
+		{"/static/{path...}", "/static/{path}", map[string]string{}},
+		{"/", "/", map[string]string{}},
+	}
+	for _, tt := range tests {
+		got, patterns := normalizePath(tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+		assert.Equal(t, tt.patterns, patterns, tt.in)
+	}
+}
+
+func TestPerDuration(t *testing.T) {
+	assert.Equal(t, &model.RateLimit{Requests: 5, Period: model.PeriodSecond}, perDuration(5, 1e9))
+	assert.Equal(t, &model.RateLimit{Requests: 100, Period: model.PeriodMinute}, perDuration(100, 60e9))
+	assert.Equal(t, &model.RateLimit{Requests: 20, Period: model.PeriodMinute}, perDuration(10, 30e9))
+	assert.Nil(t, perDuration(0, 1e9))
+}
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644))
+	}
+	write("main.go", "package main\n")
+	first, err := Fingerprint(dir+"/...", Options{})
+	require.NoError(t, err)
+
+	write("main_test.go", "package main\n")
+	write("testdata/x.go", "package x\n")
+	same, err := Fingerprint(dir, Options{Recursive: true})
+	require.NoError(t, err)
+	assert.Equal(t, first, same)
+
+	write("api/api.go", "package api\n")
+	changed, err := Fingerprint(dir+"/...", Options{})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+	shallow, err := Fingerprint(dir, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, first, shallow)
+}
+
+func TestParseShard(t *testing.T) {
+	s, err := ParseShard("2/3")
+	require.NoError(t, err)
+	assert.Equal(t, Shard{Index: 2, Count: 3}, s)
+	assert.Equal(t, "2/3", s.String())
+	for _, bad := range []string{"", "3", "0/2", "3/2", "a/b", "1/0"} {
+		_, err := ParseShard(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+// shop writes a module with three binaries sharing packages. The items
+// router is mounted by the admin binary only; the report binary imports
+// the items package without serving it.
+func shop(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/shop\n",
+		"models/models.go": `package models
+
+// Item is a product in stock.
+type Item struct {
+	ID    string ` + "`json:\"id\"`" + `
+	Count int    ` + "`json:\"count\"`" + `
+}
+
+// Order is a purchase of items.
+type Order struct {
+	ID    string ` + "`json:\"id\"`" + `
+	Items []Item ` + "`json:\"items\"`" + `
+}
+`,
+		"items/items.go": `package items
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"example.com/shop/models"
+)
+
+// Routes returns the item routes.
+func Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", List)
+	r.Get("/{id}", Get)
+	return r
+}
+
+// List returns the items in stock.
+func List(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode([]models.Item{})
+}
+
+// Get returns an item.
+func Get(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(models.Item{})
+}
+
+// Total counts the items in stock.
+func Total() int { return 0 }
+`,
+		"orders/orders.go": `package orders
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"example.com/shop/models"
+)
+
+// Order is a placed order.
+type Order struct {
+	ID    string       ` + "`json:\"id\"`" + `
+	Order models.Order ` + "`json:\"order\"`" + `
+}
+
+// Place places an order.
+func Place(w http.ResponseWriter, r *http.Request) {
+	var o Order
+	json.NewDecoder(r.Body).Decode(&o)
+	json.NewEncoder(w).Encode(o)
+}
+`,
+		"cmd/admin/main.go": `package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"example.com/shop/items"
+)
+
+func main() {
+	r := chi.NewRouter()
+	r.Mount("/admin/items", items.Routes())
+	http.ListenAndServe(":8080", r)
+}
+`,
+		"cmd/report/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/shop/items"
+)
+
+func main() {
+	fmt.Println(items.Total())
+}
+`,
+		"cmd/orders/main.go": `package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"example.com/shop/orders"
+)
+
+func main() {
+	r := chi.NewRouter()
+	r.Post("/orders", orders.Place)
+	http.ListenAndServe(":8081", r)
+}
+`,
+	}
+	writeFiles(t, dir, files)
+	return dir
+}
+
+func TestMergeShards(t *testing.T) {
+	roots := []string{"testdata/chiapp/...", "testdata/ginapp", shop(t) + "/..."}
+	for _, root := range roots {
+		whole, err := Analyze(root, Options{})
+		require.NoError(t, err)
+		want, err := json.Marshal(whole)
+		require.NoError(t, err)
+		for n := 1; n <= 4; n++ {
+			var parts []*Partial
+			for i := 1; i <= n; i++ {
+				part, err := AnalyzeShard(root, Options{Shard: Shard{Index: i, Count: n}})
+				require.NoError(t, err)
+				// Partials travel between processes as JSON.
+				data, err := json.Marshal(part)
+				require.NoError(t, err)
+				decoded := &Partial{}
+				require.NoError(t, json.Unmarshal(data, decoded))
+				parts = append(parts, decoded)
+			}
+			doc, err := Merge(parts)
+			require.NoError(t, err)
+			got, err := json.Marshal(doc)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got), "%s in %d shards", root, n)
+		}
+	}
+}
+
+func TestShardLocality(t *testing.T) {
+	root := shop(t) + "/..."
+	var loaded [][]string
+	for i := 1; i <= 2; i++ {
+		part, err := AnalyzeShard(root, Options{Shard: Shard{Index: i, Count: 2}})
+		require.NoError(t, err)
+		var paths []string
+		for _, pkg := range part.Packages {
+			paths = append(paths, pkg.ImportPath)
+		}
+		loaded = append(loaded, paths)
+	}
+	// The binaries sharing the items package share a shard.
+	assert.Equal(t, []string{"example.com/shop/cmd/admin", "example.com/shop/cmd/report", "example.com/shop/items", "example.com/shop/models"}, loaded[0])
+	assert.Equal(t, []string{"example.com/shop/cmd/orders", "example.com/shop/models", "example.com/shop/orders"}, loaded[1])
+}
+
+func TestMergeErrors(t *testing.T) {
+	root := shop(t) + "/..."
+	shard := func(i, n int) *Partial {
+		part, err := AnalyzeShard(root, Options{Shard: Shard{Index: i, Count: n}})
+		require.NoError(t, err)
+		return part
+	}
+	one, two := shard(1, 3), shard(2, 3)
+	_, err := Merge([]*Partial{one, two})
+	assert.EqualError(t, err, "missing shards 3/3")
+	_, err = Merge([]*Partial{one, two, two})
+	assert.EqualError(t, err, "shard 2/3 given twice")
+	_, err = Merge([]*Partial{one, shard(2, 2)})
+	assert.ErrorContains(t, err, "planned for different sources or options")
+	other := shard(3, 3)
+	other.Plan = "changed"
+	_, err = Merge([]*Partial{one, two, other})
+	assert.ErrorContains(t, err, "planned for different sources or options")
+	_, err = Merge(nil)
+	assert.Error(t, err)
+}
+
+func TestParseRoute(t *testing.T) {
+	q, err := ParseRoute("get /users/:id")
+	require.NoError(t, err)
+	assert.Equal(t, Query{Method: "GET", Path: "/users/:id"}, q)
+	q, err = ParseRoute("/users")
+	require.NoError(t, err)
+	assert.Equal(t, "/users", q.String())
+	_, err = ParseRoute("FETCH /users")
+	assert.Error(t, err)
+	_, err = ParseRoute("users")
+	assert.Error(t, err)
+}
+
+// stages returns the stages and messages of a trace's steps.
+func stages(tr *Trace) []string {
+	var out []string
+	for _, s := range tr.Steps {
+		out = append(out, s.Stage+": "+s.Message)
+	}
+	return out
+}
+
+func TestExplainRoute(t *testing.T) {
+	ex, err := Explain("testdata/chiapp/...", Options{}, Query{Method: "GET", Path: "/api/v1/users/{userID}"})
+	require.NoError(t, err)
+	require.Len(t, ex.Traces, 1)
+	tr := ex.Traces[0]
+	assert.True(t, tr.Extracted)
+	assert.Equal(t, "extracted as GET /api/v1/users/{id} (GET_GetUser)", tr.Outcome)
+	assert.Equal(t, `r.Get("/users/{id:[0-9]+}", handlers.GetUser) in main.main`, tr.Subject)
+	assert.Equal(t, []string{
+		"framework: matched by the chi extractor as a Get registration",
+		"router: registered on the router created by chi.NewRouter() in main.main",
+		`prefix: "/api/v1" from r.Route(apiPrefix, func(r chi.Router) { ...`,
+		`constant: the constant apiPrefix evaluates to "/api/v1"`,
+		`prefix: "/users/{id:[0-9]+}" from r.Get("/users/{id:[0-9]+}", handlers.GetUser)`,
+		`prefix: "/api/v1" + "/users/{id:[0-9]+}" composes to /api/v1/users/{id:[0-9]+}, normalized to /api/v1/users/{id}`,
+		"handler: the handler is handlers.GetUser",
+		"method: GET given at registration",
+		"middleware: applies httprate.LimitByIP, handlers.RequireAuth",
+		"result: extracted as GET /api/v1/users/{id} (GET_GetUser)",
+	}, stages(tr))
+	assert.Equal(t, "cmd/usersvc/main.go:13:7", tr.Steps[3].Pos.String())
+
+	// Without the prefix, and for another method, the route is a near
+	// match.
+	ex, err = Explain("testdata/chiapp/...", Options{}, Query{Method: "DELETE", Path: "/users/:id"})
+	require.NoError(t, err)
+	require.Len(t, ex.Traces, 1)
+	assert.Equal(t, []string{
+		"query: the path composes to /api/v1/users/{id}, which has a prefix the query /users/:id lacks",
+		"query: the route serves GET, not DELETE",
+	}, stages(ex.Traces[0])[:2])
+}
+
+func TestExplainMissing(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644))
+	}
+	write("main.go", `package main
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var base = os.Getenv("BASE")
+
+func main() {
+	r := chi.NewRouter()
+	r.Get(base+"/users", ListUsers)
+	srv := newServer()
+	srv.Register("/orders", ListOrders)
+	r.Get("/health", Health)
+	r.Get("/health", Health)
+	http.ListenAndServe(":8080", r)
+}
+
+func ListUsers(w http.ResponseWriter, r *http.Request)  {}
+func ListOrders(w http.ResponseWriter, r *http.Request) {}
+func Health(w http.ResponseWriter, r *http.Request)     {}
+`)
+	write("admin/admin.go", "package admin\n\n// Routes serves \"/reports\".\nfunc Routes() {}\n")
+
+	ex, err := Explain(dir, Options{}, Query{Func: "main.ListUsers"})
+	require.NoError(t, err)
+	require.Len(t, ex.Traces, 1)
+	assert.Equal(t, "not extracted: the route path is not a constant", ex.Traces[0].Outcome)
+	assert.Contains(t, stages(ex.Traces[0]), "constant: the package variable base cannot be evaluated: it is not a constant, a package variable with a constant initializer or a local variable assigned one")
+
+	ex, err = Explain(dir, Options{}, Query{Func: "main.ListOrders"})
+	require.NoError(t, err)
+	require.Len(t, ex.Traces, 1)
+	assert.Equal(t, "srv.Register(\"/orders\", ListOrders) in main.main", ex.Traces[0].Subject)
+	assert.Equal(t, "framework: Register is not a route registration method of any supported framework", stages(ex.Traces[0])[0])
+
+	ex, err = Explain(dir, Options{}, Query{Method: "GET", Path: "/v2/users"})
+	require.NoError(t, err)
+	require.Len(t, ex.Traces, 1)
+	assert.Equal(t, "r.Get(base+\"/users\", ListUsers) in main.main", ex.Traces[0].Subject)
+
+	ex, err = Explain(dir, Options{}, Query{Path: "/health"})
+	require.NoError(t, err)
+	require.Len(t, ex.Traces, 2)
+	assert.True(t, ex.Traces[0].Extracted)
+	assert.Equal(t, "not extracted: GET /health is already extracted from r.Get(\"/health\", Health) in main.main at main.go:17:2", ex.Traces[1].Outcome)
+
+	ex, err = Explain(dir, Options{}, Query{Path: "/reports"})
+	require.NoError(t, err)
+	assert.Empty(t, ex.Traces)
+	require.Len(t, ex.Notes, 1)
+	assert.Equal(t, "admin/admin.go mentions /reports but is not analyzed: it is below the root; analyze recursively with -r or "+dir+"/...", ex.Notes[0].Message)
+	assert.Equal(t, "admin/admin.go:3:19", ex.Notes[0].Pos.String())
+}
+
+func TestRecoverPanic(t *testing.T) {
+	panicHook = func(stage string, pkg *Package) {
+		if stage == "schemas" && pkg.Name == "handlers" {
+			panic("boom")
+		}
+	}
+	defer func() { panicHook = nil }()
+
+	var panics []*Panic
+	doc, err := Analyze("testdata/chiapp", Options{Recursive: true, Recovered: func(p *Panic) { panics = append(panics, p) }})
+	require.NoError(t, err)
+	assert.Len(t, doc.Endpoints, 4)
+
+	require.NotEmpty(t, panics)
+	p := panics[0]
+	assert.Equal(t, "example.com/chiapp/handlers", p.Package)
+	assert.Equal(t, "schemas", p.Stage)
+	assert.Equal(t, "boom", p.Value)
+	assert.Contains(t, p.Stack, "TestRecoverPanic")
+	assert.Equal(t, "handlers/users.go", p.Pos.File)
+	assert.Contains(t, p.Files, "handlers/users.go")
+
+	var internal []*model.Diagnostic
+	for _, d := range doc.Diagnostics {
+		if d.Code == "INTERNAL_ERROR" {
+			internal = append(internal, d)
+		}
+	}
+	require.Len(t, internal, len(panics))
+	assert.Equal(t, model.SeverityError, internal[0].Severity)
+	assert.Contains(t, internal[0].Message, "internal error in the schemas extractor analyzing package example.com/chiapp/handlers: boom")
+}
+
+func TestHTTPSemantics(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+const maxUpload = 1 << 20
+
+var published = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func main() {
+	http.HandleFunc("POST /payments", CreatePayment)
+	http.HandleFunc("GET /orders/{id}", GetOrder)
+	http.HandleFunc("PUT /orders/{id}", UpdateOrder)
+	http.HandleFunc("GET /files/{name}", Download)
+	http.HandleFunc("GET /report", Report)
+	http.HandleFunc("PUT /mirror/{id}", Mirror)
+	http.ListenAndServe(":8080", nil)
+}
+
+// CreatePayment charges a card.
+//
+// Header Parameters:
+//   - Idempotency-Key: Key of the payment attempt
+func CreatePayment(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Idempotency-Key")
+	r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
+	var p struct{ Amount int }
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil || key == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func GetOrder(w http.ResponseWriter, r *http.Request) {
+	etag := "\"v1\""
+	_ = r.Header.Get("If-Range")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Write([]byte("{}"))
+}
+
+func UpdateOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("If-Match") != "\"v1\"" {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func Report(w http.ResponseWriter, r *http.Request) {
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !published.After(since) {
+		return
+	}
+	w.Write([]byte("report"))
+}
+
+func Mirror(w http.ResponseWriter, r *http.Request) {
+	log.Printf("mirroring with If-Match %s", r.Header.Get("If-Match"))
+	match := r.Header.Get("If-None-Match")
+	if match == "" {
+		log.Print("unconditional mirror")
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func Download(w http.ResponseWriter, r *http.Request) {
+	f, _ := os.Open(r.PathValue("name"))
+	http.ServeContent(w, r, "", time.Time{}, f)
+}
+`), 0o644))
+	doc, err := Analyze(dir, Options{})
+	require.NoError(t, err)
+
+	codes := func(ep *model.Endpoint) []string {
+		var out []string
+		for _, r := range ep.Responses {
+			out = append(out, r.StatusCode)
+		}
+		return out
+	}
+
+	pay := endpoint(t, doc, "POST /payments")
+	assert.Equal(t, "Idempotency-Key", pay.IdempotencyKey)
+	assert.Equal(t, int64(1<<20), pay.MaxBodyBytes)
+	assert.Equal(t, []string{"201", "400", "413"}, codes(pay))
+	require.Len(t, pay.Parameters, 1)
+	assert.Equal(t, "header", pay.Parameters[0].In)
+	assert.Equal(t, "Key of the payment attempt", pay.Parameters[0].Description)
+
+	get := endpoint(t, doc, "GET /orders/{id}")
+	assert.Equal(t, []string{"If-None-Match"}, get.Conditional)
+	assert.Equal(t, []string{"200", "304"}, codes(get))
+	assert.Contains(t, get.Responses[0].Headers, "Etag")
+	assert.Contains(t, get.Responses[1].Headers, "Etag")
+	assert.Empty(t, get.IdempotencyKey)
+	assert.Zero(t, get.MaxBodyBytes)
+
+	put := endpoint(t, doc, "PUT /orders/{id}")
+	assert.Equal(t, []string{"If-Match"}, put.Conditional)
+	assert.Equal(t, []string{"204", "412"}, codes(put))
+
+	report := endpoint(t, doc, "GET /report")
+	assert.Equal(t, []string{"If-Modified-Since"}, report.Conditional)
+	assert.Equal(t, []string{"200", "304"}, codes(report))
+
+	// Reading a precondition header without comparing it honors nothing.
+	mirror := endpoint(t, doc, "PUT /mirror/{id}")
+	assert.Empty(t, mirror.Conditional)
+	assert.Equal(t, []string{"202"}, codes(mirror))
+
+	dl := endpoint(t, doc, "GET /files/{name}")
+	assert.True(t, dl.Ranges)
+	assert.Equal(t, []string{"If-Match", "If-Modified-Since", "If-None-Match", "If-Range", "If-Unmodified-Since"}, dl.Conditional)
+	assert.Equal(t, []string{"200", "206", "304", "412", "416"}, codes(dl))
+	assert.Contains(t, dl.Responses[0].Headers, "Accept-Ranges")
+	assert.Contains(t, dl.Responses[1].Headers, "Content-Range")
+}
+
+func TestLongRunning(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// State is the progress of an operation.
+type State string
+
+const (
+	Pending   State = "pending"
+	Running   State = "running"
+	Succeeded State = "succeeded"
+	Failed    State = "failed"
+)
+
+// Operation is an asynchronous job.
+type Operation struct {
+	ID     string `+"`json:\"id\"`"+`
+	Kind   string `+"`json:\"kind\"`"+`
+	Status State  `+"`json:\"status\"`"+`
+}
+
+var baseURL string
+
+func main() {
+	http.HandleFunc("POST /exports", StartExport)
+	http.HandleFunc("POST /imports", StartImport)
+	http.HandleFunc("GET /operations/{id}", GetOperation)
+	http.HandleFunc("GET /operations/latest", GetOperation)
+	http.HandleFunc("GET /jobs/{id}", GetOperation)
+	http.ListenAndServe(":8080", nil)
+}
+
+func StartExport(w http.ResponseWriter, r *http.Request) {
+	op := Operation{ID: "1"}
+	loc := "/operations/" + op.ID
+	w.Header().Set("Location", loc)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(op)
+}
+
+func StartImport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Location", fmt.Sprintf("%s/jobs/%d", baseURL, 42))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func GetOperation(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(Operation{})
+}
+`), 0o644))
+	doc, err := Analyze(dir, Options{})
+	require.NoError(t, err)
+
+	export := endpoint(t, doc, "POST /exports")
+	require.NotNil(t, export.LongRunning)
+	assert.Equal(t, &model.LongRunning{
+		Location:       "/operations/{id}",
+		StatusEndpoint: "GET /operations/{id}",
+		Schema:         model.RefTo("Operation"),
+		StateProperty:  "status",
+		States:         []string{"pending", "running", "succeeded", "failed"},
+	}, export.LongRunning)
+
+	imp := endpoint(t, doc, "POST /imports")
+	require.NotNil(t, imp.LongRunning)
+	assert.Equal(t, "GET /jobs/{id}", imp.LongRunning.StatusEndpoint)
+
+	assert.Nil(t, endpoint(t, doc, "GET /operations/{id}").LongRunning)
+
+	assert.Equal(t, "/{}/jobs/{}", formatTemplate("/%[1]s/jobs/%05d"))
+	assert.Equal(t, []string{"jobs", "{}"}, templateSegments("https://api.example.com/jobs/{}?wait=1"))
+	assert.Equal(t, []string{"operations", "{}"}, templateSegments("operations/{}"))
+}
+
+func TestSunset(t *testing.T) {
+	d := parseDoc("GetUser returns a user.\n\nDeprecated: use /v2/users.\nSunset: 2027-03-01. Migrate before.\n")
+	assert.Equal(t, "use /v2/users. Sunset: 2027-03-01. Migrate before.", d.Deprecated)
+	assert.Equal(t, "2027-03-01", d.Sunset)
+	assert.Empty(t, parseDoc("Deprecated: the sunset is not planned yet.\n").Sunset)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
+
+import "net/http"
+
+func main() {
+	http.HandleFunc("GET /users", ListUsers)
+	http.HandleFunc("GET /orders", ListOrders)
+	http.ListenAndServe(":8080", nil)
+}
+
+// ListUsers lists users.
+//
+// Deprecated: use /v2/users. Sunset: 2027-03-01.
+func ListUsers(w http.ResponseWriter, r *http.Request) {}
+
+// ListOrders lists orders.
+//
+// Deprecated: Sunset: next spring.
+func ListOrders(w http.ResponseWriter, r *http.Request) {}
+`), 0o644))
+	doc, err := Analyze(dir, Options{})
+	require.NoError(t, err)
+	users := endpoint(t, doc, "GET /users")
+	assert.True(t, users.Deprecated)
+	assert.Equal(t, "2027-03-01", users.Sunset)
+	orders := endpoint(t, doc, "GET /orders")
+	assert.True(t, orders.Deprecated)
+	assert.Empty(t, orders.Sunset)
+	require.Len(t, doc.Diagnostics, 1)
+	assert.Equal(t, "INVALID_SUNSET_DATE", doc.Diagnostics[0].Code)
+	assert.Equal(t, `sunset date "next spring" of GET /orders is not a date like 2027-03-01`, doc.Diagnostics[0].Message)
+}
+
+func namingProgram(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"main.go": `package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"example.com/shop/billing"
+	billingv2 "example.com/shop/v2/billing"
+	"example.com/shop/orders"
+	"example.com/shop/users"
+)
+
+func main() {
+	http.HandleFunc("GET /invoices", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(billing.Response{})
+	})
+	http.HandleFunc("GET /v2/invoices", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(billingv2.Response{})
+	})
+	http.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(users.Response{})
+	})
+	http.HandleFunc("GET /orders", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(orders.Order{})
+	})
+	http.ListenAndServe(":8080", nil)
+}
+`,
+		"billing/billing.go":    "package billing\n\ntype Response struct {\n\tTotal int `json:\"total\"`\n}\n",
+		"v2/billing/billing.go": "package billing\n\ntype Response struct {\n\tTotal int `json:\"total\"`\n}\n",
+		"users/users.go":        "package users\n\ntype Response struct {\n\tName string `json:\"name\"`\n}\n",
+		"orders/orders.go": `package orders
+
+// Order is a purchase order.
+//
+//apidoc:schema-name PurchaseOrder
+type Order struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+`,
+	}
+	writeFiles(t, dir, files)
+	return dir
+}
+
+func schemaNames(doc *model.Document) []string {
+	var names []string
+	for name := range doc.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSchemaNaming(t *testing.T) {
+	dir := namingProgram(t)
+	root := dir + "/..."
+
+	doc, err := Analyze(root, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PurchaseOrder", "billing.Response", "users.Response", "v2.billing.Response"}, schemaNames(doc))
+	assert.Equal(t, "Order is a purchase order.", doc.Schemas["PurchaseOrder"].Description)
+	assert.Equal(t, "#/components/schemas/PurchaseOrder", endpoint(t, doc, "GET /orders").Responses[0].Schema.Ref)
+	require.Len(t, doc.Diagnostics, 1)
+	d := doc.Diagnostics[0]
+	assert.Equal(t, "SCHEMA_NAME_COLLISION", d.Code)
+	assert.Equal(t, model.SeverityWarning, d.Severity)
+	assert.Equal(t, "schema name Response is wanted by example.com/shop/billing.Response, example.com/shop/users.Response, example.com/shop/v2/billing.Response; "+
+		"naming example.com/shop/billing.Response as billing.Response, example.com/shop/users.Response as users.Response, example.com/shop/v2/billing.Response as v2.billing.Response", d.Message)
+
+	doc, err = Analyze(root, Options{Naming: SchemaNaming{Strategy: NamingPackage}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PurchaseOrder", "shop.billing.Response", "users.Response", "v2.billing.Response"}, schemaNames(doc))
+
+	naming := SchemaNaming{Strategy: NamingModule, Rename: map[string]string{
+		"users.Response":                     "User",
+		"example.com/shop/orders.Order":      "Order",
+		"example.com/shop/accounts.Response": "Account",
+	}}
+	doc, err = Analyze(root, Options{Naming: naming})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Order", "User", "billing.Response", "v2.billing.Response"}, schemaNames(doc))
+	require.Len(t, doc.Diagnostics, 1)
+	assert.Equal(t, "UNUSED_SCHEMA_RENAME", doc.Diagnostics[0].Code)
+
+	// Moving a type to another file of its package keeps its name.
+	require.NoError(t, os.Rename(filepath.Join(dir, "users", "users.go"), filepath.Join(dir, "users", "types.go")))
+	doc, err = Analyze(root, Options{})
+	require.NoError(t, err)
+	assert.Contains(t, doc.Schemas, "users.Response")
+
+	// Shards carry the naming to the merge.
+	var parts []*Partial
+	for i := 1; i <= 2; i++ {
+		part, err := AnalyzeShard(root, Options{Shard: Shard{Index: i, Count: 2}, Naming: naming})
+		require.NoError(t, err)
+		parts = append(parts, part)
+	}
+	doc, err = Merge(parts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Order", "User", "billing.Response", "v2.billing.Response"}, schemaNames(doc))
+
+	assert.EqualError(t, SchemaNaming{Strategy: "long"}.Validate(), `unknown schema naming strategy "long" (want short, package-qualified or module-path)`)
+	assert.EqualError(t, SchemaNaming{Rename: map[string]string{"users.Response": "users/Response"}}.Validate(), `invalid schema name "users/Response" for users.Response (want letters, digits, '.', '-' and '_')`)
+}
+
+func TestPointerFields(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api.go": "package api\n\n" +
+			"// UserPatch updates the fields it sets.\n" +
+			"type UserPatch struct {\n" +
+			"\tName  *string `json:\"name\"`\n" +
+			"\tEmail *string `json:\"email\" validate:\"required\"`\n" +
+			"\tAge   *int    `json:\"age,omitempty\"`\n" +
+			"\tRole  string  `json:\"role\"`\n" +
+			"}\n",
+	})
+	doc, err := Analyze(dir, Options{})
+	require.NoError(t, err)
+	patch := doc.Schema("UserPatch")
+	require.NotNil(t, patch)
+	assert.Equal(t, []string{"email", "role"}, patch.Required)
+	assert.True(t, patch.Properties["name"].Nullable)
+	assert.True(t, patch.Properties["email"].Nullable)
+	assert.False(t, patch.Properties["age"].Nullable)
+}
+
+func TestLimitations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/native\n\ngo 1.22\n",
+		"main.go": `package main
+
+/*
+#include <stdint.h>
+typedef struct { int64_t id; } item;
+*/
+import "C"
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	_ "unsafe"
+)
+
+// Pages are the static pages.
+//
+//go:embed static/*.html "my docs/index.html"
+var pages embed.FS
+
+//go:embed VERSION
+var version string
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+type Item struct {
+	ID   int64  ` + "`json:\"id\"`" + `
+	Size C.int  ` + "`json:\"size\"`" + `
+}
+
+func main() {
+	http.HandleFunc("GET /items", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Item{})
+	})
+	http.ListenAndServe(":8080", nil)
+}
+`,
+		"sum/sum.go":       "package sum\n\n// Sum adds up xs.\nfunc Sum(xs []int64) int64\n\nfunc (v Vec) Norm() float64\n\ntype Vec []float64\n",
+		"sum/sum_amd64.s":  "TEXT ·Sum(SB),$0\n",
+		"sum/sum_arm64.s":  "TEXT ·Sum(SB),$0\n",
+		"ext/ext.go":       "package ext\n\nfunc hook()\n",
+		"native/native.go": "package native\n\nimport \"C\"\n",
+		"native/native.c":  "int answer(void) { return 42; }\n",
+		"native/native.h":  "int answer(void);\n",
+	}
+	writeFiles(t, dir, files)
+	doc, err := Analyze(dir+"/...", Options{})
+	require.NoError(t, err)
+
+	size := doc.Schemas["Item"].Properties["size"]
+	assert.Equal(t, &model.Schema{GoType: "C.int", Description: "Opaque C type C.int."}, size)
+	require.Len(t, doc.Diagnostics, 1)
+	assert.Equal(t, "CGO_OPAQUE_TYPE", doc.Diagnostics[0].Code)
+
+	limits := map[string][]string{}
+	for _, pkg := range doc.Packages {
+		for _, l := range pkg.Limitations {
+			limits[pkg.ImportPath] = append(limits[pkg.ImportPath], l.Construct+": "+l.Detail)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"example.com/native": {
+			"cgo: main.go uses cgo; the C declarations of its preamble are not analyzed and C types are documented as opaque values",
+			"linkname: nanotime is linked to runtime.nanotime with //go:linkname; the analysis does not follow the link",
+		},
+		"example.com/native/ext": {
+			"external-function: hook is declared without a body; its implementation is not analyzed",
+		},
+		"example.com/native/native": {
+			"cgo: native.go uses cgo; the C declarations of its preamble are not analyzed and C types are documented as opaque values",
+			"cgo: C sources native.c, native.h are built with cgo and not analyzed",
+		},
+		"example.com/native/sum": {
+			"assembly: assembly sources sum_amd64.s, sum_arm64.s are not analyzed",
+			"assembly: Sum is implemented in assembly; its body is not analyzed",
+			"assembly: Vec.Norm is implemented in assembly; its body is not analyzed",
+		},
+	}, limits)
+
+	main := doc.Packages[0]
+	require.Equal(t, "example.com/native", main.ImportPath)
+	require.Len(t, main.Assets, 2)
+	assert.Equal(t, &model.Asset{
+		Var: "pages", Type: "embed.FS", Patterns: []string{"static/*.html", "my docs/index.html"},
+		Description: "Pages are the static pages.", Source: &model.Position{File: "main.go", Line: 19, Column: 5},
+	}, main.Assets[0])
+	assert.Equal(t, []string{"VERSION"}, main.Assets[1].Patterns)
+	assert.Equal(t, "string", main.Assets[1].Type)
+
+	// Adding assembly changes the fingerprint, though no Go source does.
+	before, err := Fingerprint(dir+"/...", Options{})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ext", "ext_amd64.s"), []byte("TEXT ·hook(SB),$0\n"), 0o644))
+	after, err := Fingerprint(dir+"/...", Options{})
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+}
+
+func TestGoVersion(t *testing.T) {
+	assert.Equal(t, 21, stdlibMinor("slices"))
+	assert.Equal(t, 21, stdlibMinor("slices Contains"))
+	assert.Equal(t, 22, stdlibMinor("net/http Request.PathValue"))
+	assert.Equal(t, 0, stdlibMinor("fmt Println"))
+	minor, ok := goMinor("go1.21rc2")
+	assert.True(t, ok)
+	assert.Equal(t, 21, minor)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/lib\n\ngo 1.20.3\n",
+		"lib.go": `package lib
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Contains reports whether xs contains x.
+func Contains[T comparable](xs []T, x T) bool { return slices.Contains(xs, x) }
+
+func Split(s string) (string, string) {
+	a, b, _ := strings.Cut(s, ",")
+	return a, b
+}
+
+func ID(r *http.Request) string { return r.PathValue("id") }
+
+func Biggest(a, b int) int { return max(a, b) }
+
+func count() {
+	for i := range 10 {
+		_ = i
+	}
+}
+
+type Set[T any] map[T]struct{}
+
+func (s Set[T]) Reset() { clear(s) }
+`,
+		"seq.go": `//go:build go1.23
+
+package lib
+
+import "iter"
+
+func All() iter.Seq[int] {
+	return func(yield func(int) bool) {}
+}
+
+func sum() {
+	for range All() {
+	}
+}
+`,
+	}
+	writeFiles(t, dir, files)
+	doc, err := Analyze(dir, Options{})
+	require.NoError(t, err)
+	require.Len(t, doc.Packages, 1)
+	pkg := doc.Packages[0]
+	assert.Equal(t, "1.22", pkg.GoMinVersion)
+	var got []string
+	for _, r := range pkg.GoRequirements {
+		got = append(got, fmt.Sprintf("%s %s %s %s", r.Symbol, r.Version, r.Feature, r.Pos))
+	}
+	assert.Equal(t, []string{
+		" 1.22 range over int lib.go:22:17",
+		"Biggest 1.21 min and max builtins lib.go:19:37",
+		"Contains 1.21 slices.Contains lib.go:10:56",
+		"ID 1.22 net/http.Request.PathValue lib.go:17:44",
+		"Set 1.18 type parameters lib.go:27:9",
+		"Set 1.18 predeclared any lib.go:27:12",
+		"Set.Reset 1.21 clear builtin lib.go:29:27",
+		"Split 1.18 strings.Cut lib.go:13:13",
+	}, got)
+	require.Len(t, doc.Diagnostics, 1)
+	assert.Equal(t, "GO_VERSION_TOO_LOW", doc.Diagnostics[0].Code)
+	assert.Equal(t, "example.com/lib uses range over int, which needs Go 1.22, but go.mod declares go 1.20", doc.Diagnostics[0].Message)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/lib\n\ngo 1.23\n"), 0o644))
+	doc, err = Analyze(dir, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.23", doc.Packages[0].GoMinVersion)
+	assert.Empty(t, doc.Diagnostics)
+}
+
+func TestBuildConstraints(t *testing.T) {
+	otherOS, otherArch := "plan9", "s390x"
+	if build.Default.GOOS == otherOS {
+		otherOS = "windows"
+	}
+	if build.Default.GOARCH == otherArch {
+		otherArch = "wasm"
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/lib\n\ngo 1.20\n",
+		"lib.go": "package lib\n\nfunc Name() string { return \"lib\" }\n",
+		"gen.go": `//go:build ignore
+
+package main
+
+func main() { println(max(1, 2)) }
+`,
+		"lib_" + otherOS + ".go":   "package lib\n\nfunc count() {\n\tfor range 10 {\n\t}\n}\n",
+		"lib_" + otherArch + ".go": "package lib\n\nfunc count() {}\n",
+		"legacy.go":                "// +build " + otherOS + "\n\npackage lib\n\nfunc count() {}\n",
+		"next.go":                  "//go:build go1.99 && (unix || !unix)\n\npackage lib\n",
+		"_draft.go":                "package lib\n\nfunc count() {}\n",
+	})
+	doc, err := Analyze(dir, Options{})
+	require.NoError(t, err)
+	require.Len(t, doc.Packages, 1)
+	assert.Equal(t, "example.com/lib", doc.Packages[0].ImportPath)
+	assert.Equal(t, []string{"lib.go", "next.go"}, doc.Packages[0].Files)
+	assert.Equal(t, "1.20", doc.Packages[0].GoMinVersion)
+	assert.Empty(t, doc.Diagnostics)
+}
+
+func TestMiddlewareCatalog(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"chi/main.go": `package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type userKey struct{}
+
+func main() {
+	r := chi.NewRouter()
+	r.Use(SecureHeaders)
+	r.Group(func(r chi.Router) {
+		r.Use(RequireSession)
+		r.Get("/me", me)
+	})
+	r.Get("/health", health)
+	http.ListenAndServe(":8080", r)
+}
+
+// SecureHeaders sets the security headers of every response.
+func SecureHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("X-Request-Id", r.Header.Get("X-Request-Id"))
+		r.Header.Set("X-Forwarded-Proto", "https")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireSession rejects requests without a valid session cookie.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil {
+			http.Error(w, "no session", http.StatusUnauthorized)
+			return
+		}
+		if c.Value == "locked" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, c.Value)))
+	})
+}
+
+func me(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("me"))
+}
+
+func health(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+`,
+		"gin/main.go": `package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	r := gin.Default()
+	v1 := r.Group("/v1", Tenant())
+	v1.GET("/orders", listOrders)
+	r.Run(":8080")
+}
+
+// Tenant reads the tenant of the request from the X-Tenant header.
+func Tenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant := c.GetHeader("X-Tenant")
+		if tenant == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "missing tenant"})
+			return
+		}
+		c.Set("tenant", tenant)
+		c.Header("Vary", "X-Tenant")
+	}
+}
+
+func listOrders(c *gin.Context) {
+	c.JSON(http.StatusOK, []string{})
+}
+`,
+		"echo/main.go": `package main
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func main() {
+	e := echo.New()
+	admin := e.Group("/admin", AdminOnly)
+	admin.GET("/stats", stats)
+	e.Start(":8080")
+}
+
+// AdminOnly lets administrators through.
+func AdminOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Header.Get("X-Role") != "admin" {
+			return echo.ErrForbidden
+		}
+		return next(c)
+	}
+}
+
+func stats(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]int{})
+}
+`,
+	}
+	writeFiles(t, dir, files)
+	doc, err := Analyze(dir, Options{Recursive: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"SecureHeaders", "RequireSession"}, endpoint(t, doc, "GET /me").Middleware)
+	assert.Equal(t, []string{"SecureHeaders"}, endpoint(t, doc, "GET /health").Middleware)
+	assert.Equal(t, []string{"Tenant"}, endpoint(t, doc, "GET /v1/orders").Middleware)
+	assert.Equal(t, []string{"AdminOnly"}, endpoint(t, doc, "GET /admin/stats").Middleware)
+
+	require.Len(t, doc.Middleware, 4)
+	secure := doc.Middleware["SecureHeaders"]
+	assert.Equal(t, "net/http", secure.Kind)
+	assert.Equal(t, "main.SecureHeaders", secure.Func)
+	assert.Equal(t, "SecureHeaders sets the security headers of every response.", secure.Description)
+	assert.Equal(t, map[string]string{"X-Frame-Options": "DENY", "X-Request-Id": ""}, secure.Headers)
+	assert.Empty(t, secure.Statuses)
+	assert.Equal(t, &model.Position{File: "chi/main.go", Line: 24, Column: 1}, secure.Source)
+
+	session := doc.Middleware["RequireSession"]
+	assert.Equal(t, "net/http", session.Kind)
+	assert.Equal(t, []int{401, 403}, session.Statuses)
+	assert.Equal(t, []string{"userKey{}"}, session.ContextValues)
+	assert.Equal(t, "cookieAuth", session.Security)
+
+	tenant := doc.Middleware["Tenant"]
+	assert.Equal(t, "gin", tenant.Kind)
+	assert.Equal(t, []int{400}, tenant.Statuses)
+	assert.Equal(t, []string{"tenant"}, tenant.ContextValues)
+	assert.Equal(t, map[string]string{"Vary": "X-Tenant"}, tenant.Headers)
+
+	admin := doc.Middleware["AdminOnly"]
+	assert.Equal(t, "echo", admin.Kind)
+	assert.Equal(t, []int{403}, admin.Statuses)
+	assert.Empty(t, admin.Security)
+}
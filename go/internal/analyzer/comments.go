@@ -0,0 +1,285 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// docComment is a doc comment split into the sections recognized by the
+// TypeScript GoDocParser (src/parsers/languages/go-parser.ts), so that both
+// parsers agree on the comment conventions they document.
+type docComment struct {
+	Summary     string
+	Description string
+	Routes      []docRoute
+	Params      []docParam
+	Responses   []docResponse
+	RequestBody string
+	Examples    []string
+	Tags        []string
+	// Deprecated holds the text of a "Deprecated:" paragraph, if any.
+	Deprecated string
+	// Sunset holds the date given as "Sunset: 2027-03-01." in the
+	// Deprecated paragraph.
+	Sunset string
+	// Sections holds the raw lines of every section by name.
+	Sections map[string][]string
+}
+
+type docRoute struct {
+	Method string
+	Path   string
+}
+
+type docParam struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+}
+
+type docResponse struct {
+	Status      string
+	Text        string
+	Description string
+}
+
+// Section names, matching GoDocParser.getSectionName.
+const (
+	sectionDescription = "description"
+	sectionRoute       = "route"
+	sectionParameters  = "parameters"
+	sectionQuery       = "query_parameters"
+	sectionPath        = "path_parameters"
+	sectionHeaders     = "header_parameters"
+	sectionReturns     = "returns"
+	sectionExamples    = "examples"
+	sectionErrors      = "errors"
+	sectionRequestBody = "request_body"
+	sectionTags        = "tags"
+	sectionDeprecated  = "deprecated"
+)
+
+var sectionPrefixes = []struct {
+	prefix  string
+	section string
+}{
+	{"query parameters:", sectionQuery},
+	{"path parameters:", sectionPath},
+	{"header parameters:", sectionHeaders},
+	{"headers:", sectionHeaders},
+	{"request body:", sectionRequestBody},
+	{"http status codes:", sectionReturns},
+	{"routes:", sectionRoute},
+	{"route:", sectionRoute},
+	{"endpoints:", sectionRoute},
+	{"endpoint:", sectionRoute},
+	{"parameters:", sectionParameters},
+	{"params:", sectionParameters},
+	{"arguments:", sectionParameters},
+	{"args:", sectionParameters},
+	{"returns:", sectionReturns},
+	{"return:", sectionReturns},
+	{"responses:", sectionReturns},
+	{"response:", sectionReturns},
+	{"examples:", sectionExamples},
+	{"example:", sectionExamples},
+	{"usage:", sectionExamples},
+	{"errors:", sectionErrors},
+	{"error:", sectionErrors},
+	{"tags:", sectionTags},
+	{"tag:", sectionTags},
+}
+
+var (
+	routeLineRE    = regexp.MustCompile(`^([A-Za-z]+)\s+(/[^\s]*)`)
+	paramLineRE    = regexp.MustCompile(`^([\w.\-\[\]]+)\s*(?:\(([^)]*)\))?\s*[-:]\s*(.*)$`)
+	responseLineRE = regexp.MustCompile(`^(\d{3})\s*([^-:]*?)\s*[-:]\s*(.*)$`)
+	sunsetRE       = regexp.MustCompile(`(?i)\bsunset:\s*(.*?)\.?(?:\s*$|\.\s)`)
+)
+
+// sectionHeader reports the section a line starts, and any content that
+// follows the header on the same line.
+func sectionHeader(line string) (string, string, bool) {
+	lower := strings.ToLower(line)
+	for _, h := range sectionPrefixes {
+		if strings.HasPrefix(lower, h.prefix) {
+			return h.section, strings.TrimSpace(line[len(h.prefix):]), true
+		}
+	}
+	return "", "", false
+}
+
+// parseDoc splits comment text, as returned by ast.CommentGroup.Text, into
+// its sections.
+func parseDoc(text string) *docComment {
+	d := &docComment{Sections: map[string][]string{}}
+	if strings.TrimSpace(text) == "" {
+		return d
+	}
+
+	var paragraphs []string
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			paragraphs = append(paragraphs, strings.Join(para, " "))
+			para = nil
+		}
+	}
+
+	section := sectionDescription
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(raw)
+		// Indented lines belong to the current section, so an example
+		// containing "Response: {...}" does not start a new one.
+		indented := line != "" && raw[0] != line[0]
+		if name, rest, ok := sectionHeader(line); ok && !indented {
+			if section == sectionDescription {
+				flush()
+			}
+			section = name
+			if d.Sections[section] == nil {
+				d.Sections[section] = []string{}
+			}
+			if rest != "" {
+				d.Sections[section] = append(d.Sections[section], rest)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "Deprecated:") {
+			flush()
+			section = sectionDeprecated
+			d.Deprecated = strings.TrimSpace(strings.TrimPrefix(line, "Deprecated:"))
+			continue
+		}
+		if line == "" {
+			if section == sectionDescription {
+				flush()
+			} else if section == sectionDeprecated {
+				section = sectionDescription
+			}
+			continue
+		}
+		switch section {
+		case sectionDescription:
+			para = append(para, line)
+		case sectionDeprecated:
+			d.Deprecated = strings.TrimSpace(d.Deprecated + " " + line)
+		default:
+			d.Sections[section] = append(d.Sections[section], line)
+		}
+	}
+	flush()
+
+	if m := sunsetRE.FindStringSubmatch(d.Deprecated); m != nil {
+		d.Sunset = m[1]
+	}
+	d.Description = strings.Join(paragraphs, "\n\n")
+	if len(paragraphs) > 0 {
+		d.Summary = synopsis(paragraphs[0])
+	}
+	d.Routes = parseRoutes(d.Sections[sectionRoute])
+	d.Params = append(d.Params, parseParams(d.Sections[sectionParameters], "")...)
+	d.Params = append(d.Params, parseParams(d.Sections[sectionQuery], "query")...)
+	d.Params = append(d.Params, parseParams(d.Sections[sectionPath], "path")...)
+	d.Params = append(d.Params, parseParams(d.Sections[sectionHeaders], "header")...)
+	d.Responses = append(parseResponses(d.Sections[sectionReturns]), parseResponses(d.Sections[sectionErrors])...)
+	d.RequestBody = strings.Join(d.Sections[sectionRequestBody], " ")
+	d.Examples = d.Sections[sectionExamples]
+	for _, line := range d.Sections[sectionTags] {
+		for _, t := range strings.Split(trimBullet(line), ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				d.Tags = append(d.Tags, t)
+			}
+		}
+	}
+	return d
+}
+
+// synopsis returns the first sentence of a paragraph.
+func synopsis(p string) string {
+	for i := 0; i < len(p); i++ {
+		if p[i] == '.' && (i+1 == len(p) || p[i+1] == ' ') {
+			return p[:i+1]
+		}
+	}
+	return p
+}
+
+// trimBullet removes a leading "-", "*" or "•" list marker.
+func trimBullet(line string) string {
+	for _, b := range []string{"- ", "* ", "• "} {
+		if strings.HasPrefix(line, b) {
+			return strings.TrimSpace(line[len(b):])
+		}
+	}
+	return line
+}
+
+func parseRoutes(lines []string) []docRoute {
+	var routes []docRoute
+	for _, line := range lines {
+		m := routeLineRE.FindStringSubmatch(trimBullet(line))
+		if m == nil {
+			continue
+		}
+		method := strings.ToUpper(m[1])
+		if !isHTTPMethod(method) {
+			continue
+		}
+		routes = append(routes, docRoute{Method: method, Path: m[2]})
+	}
+	return routes
+}
+
+func parseParams(lines []string, in string) []docParam {
+	var params []docParam
+	for _, line := range lines {
+		m := paramLineRE.FindStringSubmatch(trimBullet(line))
+		if m == nil {
+			continue
+		}
+		p := docParam{Name: m[1], In: in, Description: strings.TrimSpace(m[3])}
+		lower := strings.ToLower(m[2] + " " + p.Description)
+		p.Required = !strings.Contains(lower, "optional")
+		switch {
+		case strings.Contains(lower, "query"):
+			if p.In == "" {
+				p.In = "query"
+			}
+		case strings.Contains(lower, "header"):
+			if p.In == "" {
+				p.In = "header"
+			}
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+func parseResponses(lines []string) []docResponse {
+	var responses []docResponse
+	for _, line := range lines {
+		m := responseLineRE.FindStringSubmatch(trimBullet(line))
+		if m == nil {
+			continue
+		}
+		responses = append(responses, docResponse{
+			Status:      m[1],
+			Text:        strings.TrimSpace(m[2]),
+			Description: strings.TrimSpace(m[3]),
+		})
+	}
+	return responses
+}
+
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE", "CONNECT"}
+
+func isHTTPMethod(m string) bool {
+	for _, h := range httpMethods {
+		if h == m {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,458 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// collectConsts evaluates package-level constants and groups typed
+// constants by their type so that enum schemas can be built from them.
+func (a *analyzer) collectConsts() {
+	for _, pkg := range a.prog.Packages {
+		for _, f := range pkg.Files {
+			for _, decl := range f.AST.Decls {
+				d, ok := decl.(*ast.GenDecl)
+				if !ok || d.Tok != token.CONST {
+					continue
+				}
+				a.guard("constants", pkg, d.Pos(), func() { a.collectConstDecl(f, d) })
+			}
+		}
+	}
+}
+
+func (a *analyzer) collectConstDecl(f *File, d *ast.GenDecl) {
+	// Constant specs without values repeat the previous type and
+	// expressions with the next iota.
+	var typ ast.Expr
+	var values []ast.Expr
+	for i, spec := range d.Specs {
+		vs := spec.(*ast.ValueSpec)
+		if len(vs.Values) > 0 {
+			typ, values = vs.Type, vs.Values
+		}
+		ev := &evaluator{a: a, file: f, iota: int64(i)}
+		for j, name := range vs.Names {
+			if name.Name == "_" || j >= len(values) {
+				continue
+			}
+			c := &constDecl{Name: name.Name, File: f, Pos: name.Pos()}
+			if v, ok := ev.eval(values[j]); ok {
+				c.Value = v
+			}
+			if id, ok := typ.(*ast.Ident); ok {
+				c.Type = id.Name
+			} else if typ == nil {
+				c.Type = conversionType(values[j])
+			}
+			if c.Comments = vs.Doc; c.Comments == nil {
+				c.Comments = vs.Comment
+			}
+			if c.Comments != nil {
+				c.Doc = strings.TrimSpace(c.Comments.Text())
+			}
+			a.consts[f.Pkg.ImportPath+"."+name.Name] = c
+			if c.Type != "" && c.Value != nil {
+				key := f.Pkg.ImportPath + "." + c.Type
+				a.enums[key] = append(a.enums[key], c)
+			}
+		}
+	}
+}
+
+// conversionType returns T for a constant written as T(value).
+func conversionType(expr ast.Expr) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return ""
+	}
+	if id, ok := call.Fun.(*ast.Ident); ok && !isBuiltinType(id.Name) {
+		return id.Name
+	}
+	return ""
+}
+
+// evaluator folds constant expressions: literals, package constants,
+// string concatenation, arithmetic, and a few pure standard library calls
+// commonly used to build route paths.
+type evaluator struct {
+	a    *analyzer
+	file *File
+	iota int64
+	// locals resolves identifiers assigned in the enclosing function.
+	locals map[string]ast.Expr
+	depth  int
+}
+
+func (ev *evaluator) eval(expr ast.Expr) (any, bool) {
+	if expr == nil || ev.depth > 32 {
+		return nil, false
+	}
+	ev.depth++
+	defer func() { ev.depth-- }()
+
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return evalLit(e)
+	case *ast.ParenExpr:
+		return ev.eval(e.X)
+	case *ast.Ident:
+		return ev.ident(e)
+	case *ast.SelectorExpr:
+		return ev.selector(e)
+	case *ast.UnaryExpr:
+		v, ok := ev.eval(e.X)
+		if !ok {
+			return nil, false
+		}
+		if n, isInt := v.(int64); isInt && e.Op == token.SUB {
+			return -n, true
+		}
+		if b, isBool := v.(bool); isBool && e.Op == token.NOT {
+			return !b, true
+		}
+		return v, e.Op == token.ADD
+	case *ast.BinaryExpr:
+		x, ok1 := ev.eval(e.X)
+		y, ok2 := ev.eval(e.Y)
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		return binary(e.Op, x, y)
+	case *ast.CallExpr:
+		return ev.call(e)
+	}
+	return nil, false
+}
+
+// String evaluates expr to a constant string.
+func (ev *evaluator) String(expr ast.Expr) (string, bool) {
+	v, ok := ev.eval(expr)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// Int evaluates expr to a constant integer.
+func (ev *evaluator) Int(expr ast.Expr) (int64, bool) {
+	v, ok := ev.eval(expr)
+	if !ok {
+		return 0, false
+	}
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func evalLit(e *ast.BasicLit) (any, bool) {
+	switch e.Kind {
+	case token.STRING, token.CHAR:
+		s, err := strconv.Unquote(e.Value)
+		if err != nil {
+			return nil, false
+		}
+		if e.Kind == token.CHAR {
+			return int64([]rune(s)[0]), true
+		}
+		return s, true
+	case token.INT:
+		n, err := strconv.ParseInt(strings.ReplaceAll(e.Value, "_", ""), 0, 64)
+		return n, err == nil
+	case token.FLOAT:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(e.Value, "_", ""), 64)
+		return f, err == nil
+	}
+	return nil, false
+}
+
+func (ev *evaluator) ident(e *ast.Ident) (any, bool) {
+	switch e.Name {
+	case "iota":
+		return ev.iota, true
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+	if ev.locals != nil {
+		if x, ok := ev.locals[e.Name]; ok {
+			return ev.eval(x)
+		}
+	}
+	return ev.lookup(ev.file.Pkg.ImportPath, e.Name)
+}
+
+func (ev *evaluator) lookup(importPath, name string) (any, bool) {
+	key := importPath + "." + name
+	if c := ev.a.consts[key]; c != nil && c.Value != nil {
+		return c.Value, true
+	}
+	if v := ev.a.vars[key]; v != nil && v.Value != nil {
+		sub := &evaluator{a: ev.a, file: v.File, depth: ev.depth}
+		return sub.eval(v.Value)
+	}
+	return nil, false
+}
+
+func (ev *evaluator) selector(e *ast.SelectorExpr) (any, bool) {
+	x, ok := e.X.(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	importPath, ok := ev.file.ImportPath(x.Name)
+	if !ok {
+		return nil, false
+	}
+	switch importPath {
+	case "net/http":
+		if code, ok := httpStatusCodes[e.Sel.Name]; ok {
+			return int64(code), true
+		}
+		if strings.HasPrefix(e.Sel.Name, "Method") {
+			return strings.ToUpper(strings.TrimPrefix(e.Sel.Name, "Method")), true
+		}
+	case "time":
+		if d, ok := durations[e.Sel.Name]; ok {
+			return d, true
+		}
+	}
+	return ev.lookup(importPath, e.Sel.Name)
+}
+
+var durations = map[string]int64{
+	"Nanosecond":  1,
+	"Microsecond": 1e3,
+	"Millisecond": 1e6,
+	"Second":      1e9,
+	"Minute":      60e9,
+	"Hour":        3600e9,
+}
+
+func (ev *evaluator) call(e *ast.CallExpr) (any, bool) {
+	// Conversions such as string(x), T(x) and time.Duration(x).
+	if len(e.Args) == 1 {
+		switch fun := e.Fun.(type) {
+		case *ast.Ident:
+			if isBuiltinType(fun.Name) || ev.a.lookupType(ev.file, fun) != nil {
+				return ev.eval(e.Args[0])
+			}
+		case *ast.SelectorExpr:
+			if p, _, ok := ev.a.qualify(ev.file, fun); ok && (p == "time" && fun.Sel.Name == "Duration" || ev.a.lookupType(ev.file, fun) != nil) {
+				return ev.eval(e.Args[0])
+			}
+		}
+	}
+	sel, ok := e.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+	p, name, ok := ev.a.qualify(ev.file, sel)
+	if !ok {
+		return nil, false
+	}
+	args := make([]any, 0, len(e.Args))
+	for _, arg := range e.Args {
+		v, ok := ev.eval(arg)
+		if !ok {
+			return nil, false
+		}
+		args = append(args, v)
+	}
+	switch p + "." + name {
+	case "fmt.Sprintf":
+		if len(args) == 0 {
+			return nil, false
+		}
+		format, ok := args[0].(string)
+		if !ok {
+			return nil, false
+		}
+		return fmt.Sprintf(format, args[1:]...), true
+	case "path.Join":
+		parts := make([]string, 0, len(args))
+		for _, arg := range args {
+			s, ok := arg.(string)
+			if !ok {
+				return nil, false
+			}
+			parts = append(parts, s)
+		}
+		return path.Join(parts...), true
+	case "strings.ToLower", "strings.ToUpper", "strings.TrimSuffix", "strings.TrimPrefix":
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, false
+		}
+		switch name {
+		case "ToLower":
+			return strings.ToLower(s), true
+		case "ToUpper":
+			return strings.ToUpper(s), true
+		}
+		if len(args) != 2 {
+			return nil, false
+		}
+		affix, _ := args[1].(string)
+		if name == "TrimSuffix" {
+			return strings.TrimSuffix(s, affix), true
+		}
+		return strings.TrimPrefix(s, affix), true
+	case "strconv.Itoa":
+		if n, ok := args[0].(int64); ok {
+			return strconv.FormatInt(n, 10), true
+		}
+	}
+	return nil, false
+}
+
+func binary(op token.Token, x, y any) (any, bool) {
+	switch xv := x.(type) {
+	case string:
+		yv, ok := y.(string)
+		if ok && op == token.ADD {
+			return xv + yv, true
+		}
+	case int64:
+		switch yv := y.(type) {
+		case int64:
+			switch op {
+			case token.ADD:
+				return xv + yv, true
+			case token.SUB:
+				return xv - yv, true
+			case token.MUL:
+				return xv * yv, true
+			case token.QUO:
+				if yv != 0 {
+					return xv / yv, true
+				}
+			case token.REM:
+				if yv != 0 {
+					return xv % yv, true
+				}
+			case token.SHL:
+				return xv << uint(yv), true
+			case token.SHR:
+				return xv >> uint(yv), true
+			case token.OR:
+				return xv | yv, true
+			case token.AND:
+				return xv & yv, true
+			}
+		case float64:
+			return binary(op, float64(xv), yv)
+		}
+	case float64:
+		var yf float64
+		switch yv := y.(type) {
+		case int64:
+			yf = float64(yv)
+		case float64:
+			yf = yv
+		default:
+			return nil, false
+		}
+		switch op {
+		case token.ADD:
+			return xv + yf, true
+		case token.SUB:
+			return xv - yf, true
+		case token.MUL:
+			return xv * yf, true
+		case token.QUO:
+			if yf != 0 {
+				return xv / yf, true
+			}
+		}
+	}
+	return nil, false
+}
+
+var builtinTypes = map[string]string{
+	"string": "string", "bool": "boolean", "byte": "integer", "rune": "integer",
+	"int": "integer", "int8": "integer", "int16": "integer", "int32": "integer", "int64": "integer",
+	"uint": "integer", "uint8": "integer", "uint16": "integer", "uint32": "integer", "uint64": "integer",
+	"uintptr": "integer", "float32": "number", "float64": "number",
+	"complex64": "string", "complex128": "string",
+}
+
+func isBuiltinType(name string) bool {
+	_, ok := builtinTypes[name]
+	return ok
+}
+
+// httpStatusCodes maps the net/http status constant names to their codes.
+var httpStatusCodes = map[string]int{
+	"StatusContinue":                      100,
+	"StatusSwitchingProtocols":            101,
+	"StatusProcessing":                    102,
+	"StatusEarlyHints":                    103,
+	"StatusOK":                            200,
+	"StatusCreated":                       201,
+	"StatusAccepted":                      202,
+	"StatusNonAuthoritativeInfo":          203,
+	"StatusNoContent":                     204,
+	"StatusResetContent":                  205,
+	"StatusPartialContent":                206,
+	"StatusMultiStatus":                   207,
+	"StatusAlreadyReported":               208,
+	"StatusIMUsed":                        226,
+	"StatusMultipleChoices":               300,
+	"StatusMovedPermanently":              301,
+	"StatusFound":                         302,
+	"StatusSeeOther":                      303,
+	"StatusNotModified":                   304,
+	"StatusUseProxy":                      305,
+	"StatusTemporaryRedirect":             307,
+	"StatusPermanentRedirect":             308,
+	"StatusBadRequest":                    400,
+	"StatusUnauthorized":                  401,
+	"StatusPaymentRequired":               402,
+	"StatusForbidden":                     403,
+	"StatusNotFound":                      404,
+	"StatusMethodNotAllowed":              405,
+	"StatusNotAcceptable":                 406,
+	"StatusProxyAuthRequired":             407,
+	"StatusRequestTimeout":                408,
+	"StatusConflict":                      409,
+	"StatusGone":                          410,
+	"StatusLengthRequired":                411,
+	"StatusPreconditionFailed":            412,
+	"StatusRequestEntityTooLarge":         413,
+	"StatusRequestURITooLong":             414,
+	"StatusUnsupportedMediaType":          415,
+	"StatusRequestedRangeNotSatisfiable":  416,
+	"StatusExpectationFailed":             417,
+	"StatusTeapot":                        418,
+	"StatusMisdirectedRequest":            421,
+	"StatusUnprocessableEntity":           422,
+	"StatusLocked":                        423,
+	"StatusFailedDependency":              424,
+	"StatusTooEarly":                      425,
+	"StatusUpgradeRequired":               426,
+	"StatusPreconditionRequired":          428,
+	"StatusTooManyRequests":               429,
+	"StatusRequestHeaderFieldsTooLarge":   431,
+	"StatusUnavailableForLegalReasons":    451,
+	"StatusInternalServerError":           500,
+	"StatusNotImplemented":                501,
+	"StatusBadGateway":                    502,
+	"StatusServiceUnavailable":            503,
+	"StatusGatewayTimeout":                504,
+	"StatusHTTPVersionNotSupported":       505,
+	"StatusVariantAlsoNegotiates":         506,
+	"StatusInsufficientStorage":           507,
+	"StatusLoopDetected":                  508,
+	"StatusNotExtended":                   510,
+	"StatusNetworkAuthenticationRequired": 511,
+}
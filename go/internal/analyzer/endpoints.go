@@ -0,0 +1,360 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// collectEndpoints builds an endpoint for every route registration and
+// every handler documented with a "Route:" section but registered in code
+// the analyzer could not follow.
+//
+// The endpoints are candidates: finish drops those whose method and path
+// an earlier candidate already claimed, and numbers repeated IDs.
+func (a *analyzer) collectEndpoints() {
+	a.routes = newRouteGraph(a)
+	a.routes.build()
+	a.routes.sortRegistrations()
+
+	registered := map[*funcDecl]bool{}
+	attached := map[string]bool{}
+	for _, reg := range a.routes.regs {
+		a.guard("endpoints", reg.file.Pkg, reg.pos, func() {
+			ref := a.resolveHandler(reg.file, reg.handler, reg.locals)
+			if ref.decl != nil && !registered[ref.decl] {
+				registered[ref.decl] = true
+				a.part.Registered = append(a.part.Registered, ref.decl.id())
+			}
+			facts := a.scanHandler(ref)
+			doc := parseDoc(handlerDoc(ref))
+			pos := a.prog.Fset.Position(reg.pos)
+			id := fmt.Sprintf("%s:%d", pos.Filename, pos.Offset)
+			for _, ctx := range a.routes.contexts(reg.router) {
+				mws := append(append(append([]*mwUse(nil), ctx.mws...), reg.mws...), ref.mws...)
+				p := joinPath(ctx.prefix, reg.path)
+				for i, method := range endpointMethods(reg.methods, facts, doc) {
+					c := &PartialEndpoint{
+						Rank:         fmt.Sprintf("0\x00%s\x00%010d\x00%s\x00%04d", pos.Filename, pos.Offset, strings.Join(ctx.rank, "\x01"), i),
+						Registration: id,
+						trace:        &candidateTrace{reg: reg, ref: ref, ctx: ctx, facts: facts, doc: doc},
+					}
+					if ctx.top != nil {
+						c.Root = a.routes.baseID(ctx.top)
+					}
+					a.candidate(c, func() *model.Endpoint {
+						return a.endpoint(method, p, reg, ref, facts, doc, mws)
+					})
+				}
+			}
+			if _, ok := a.part.Reached[id]; ok {
+				return
+			}
+			var bases []string
+			for _, b := range a.routes.ancestors(reg.router.base) {
+				bid := a.routes.baseID(b)
+				bases = append(bases, bid)
+				if len(b.sources) > 0 && !attached[bid] {
+					attached[bid] = true
+					a.part.Attached = append(a.part.Attached, bid)
+				}
+			}
+			a.part.Reached[id] = bases
+		})
+	}
+	a.collectRouters()
+
+	for _, key := range sortedKeys(a.funcs) {
+		fd := a.funcs[key]
+		if registered[fd] || fd.Decl.Doc == nil {
+			continue
+		}
+		doc := parseDoc(fd.Decl.Doc.Text())
+		if len(doc.Routes) == 0 {
+			continue
+		}
+		a.guard("endpoints", fd.File.Pkg, fd.Decl.Pos(), func() {
+			ref := &handlerRef{decl: fd, file: fd.File, ftype: fd.Decl.Type, body: fd.Decl.Body, name: fd.Key()}
+			facts := a.scanHandler(ref)
+			reg := &registration{file: fd.File, pos: fd.Decl.Pos(), fn: fd}
+			for i, r := range doc.Routes {
+				c := &PartialEndpoint{
+					Rank:  fmt.Sprintf("1\x00%s\x00%04d", key, i),
+					Func:  key,
+					trace: &candidateTrace{reg: reg, ref: ref, facts: facts, doc: doc},
+				}
+				a.candidate(c, func() *model.Endpoint {
+					return a.endpoint(r.Method, r.Path, reg, ref, facts, doc, nil)
+				})
+			}
+		})
+	}
+}
+
+// candidate records the endpoint built by build as c, along with the
+// security schemes building it detected and the middleware of its chain.
+func (a *analyzer) candidate(c *PartialEndpoint, build func() *model.Endpoint) {
+	schemes, middleware := a.doc.SecuritySchemes, a.doc.Middleware
+	a.doc.SecuritySchemes = map[string]*model.SecurityScheme{}
+	a.doc.Middleware = map[string]*model.Middleware{}
+	defer func() { a.doc.SecuritySchemes, a.doc.Middleware = schemes, middleware }()
+	c.Endpoint = build()
+	if len(a.doc.SecuritySchemes) > 0 {
+		c.SecuritySchemes = a.doc.SecuritySchemes
+	}
+	if len(a.doc.Middleware) > 0 {
+		c.Middleware = a.doc.Middleware
+	}
+	a.part.Endpoints = append(a.part.Endpoints, c)
+}
+
+// collectRouters records the functions without parameters that return a
+// router, along with the routers they return. finish keeps those through
+// which endpoints are reached.
+func (a *analyzer) collectRouters() {
+	for _, key := range sortedKeys(a.funcs) {
+		fd := a.funcs[key]
+		if fd.Recv != "" || fd.Decl.Type.Params.NumFields() > 0 || !a.returnsRouter(fd) {
+			continue
+		}
+		var bases []string
+		seen := map[*routerBase]bool{}
+		for _, b := range a.routes.returns[fd] {
+			if !seen[b] {
+				seen[b] = true
+				bases = append(bases, a.routes.baseID(b))
+			}
+		}
+		if len(bases) == 0 {
+			continue
+		}
+		pkg := fd.File.Pkg
+		a.part.Routers = append(a.part.Routers, &PartialRouter{
+			Func:  key,
+			Bases: bases,
+			Router: &model.Router{
+				Func:       fd.Name,
+				Package:    pkg.Name,
+				ImportPath: pkg.ImportPath,
+				Dir:        pkg.Dir,
+			},
+		})
+	}
+}
+
+func handlerDoc(ref *handlerRef) string {
+	if ref.decl == nil || ref.decl.Decl.Doc == nil {
+		return ""
+	}
+	return ref.decl.Decl.Doc.Text()
+}
+
+// endpointMethods decides the methods served by a registration: those
+// given at registration, those the handler checks r.Method against, those
+// its doc comment lists, or GET.
+func endpointMethods(registered []string, facts *handlerFacts, doc *docComment) []string {
+	if len(registered) > 0 {
+		return registered
+	}
+	if len(facts.methods) > 0 {
+		return facts.methods
+	}
+	var methods []string
+	for _, r := range doc.Routes {
+		if !containsString(methods, r.Method) {
+			methods = append(methods, r.Method)
+		}
+	}
+	if len(methods) > 0 {
+		return methods
+	}
+	return []string{"GET"}
+}
+
+func (a *analyzer) endpoint(method, rawPath string, reg *registration, ref *handlerRef, facts *handlerFacts, doc *docComment, mws []*mwUse) *model.Endpoint {
+	p, patterns := normalizePath(rawPath)
+	name := handlerFuncName(ref)
+	ep := &model.Endpoint{
+		ID:          method + "_" + name,
+		Method:      method,
+		Path:        p,
+		OperationID: lowerFirst(name),
+		Summary:     doc.Summary,
+		Description: doc.Description,
+		Tags:        doc.Tags,
+		Deprecated:  doc.Deprecated != "",
+		Sunset:      doc.Sunset,
+		Handler:     ref.name,
+		Framework:   reg.framework,
+		Source:      a.prog.Position(reg.pos),
+	}
+	if ep.Tags == nil {
+		ep.Tags = defaultTags(p)
+	}
+	if ep.Sunset != "" {
+		if _, err := time.Parse(model.DateLayout, ep.Sunset); err != nil {
+			a.diag("INVALID_SUNSET_DATE", model.SeverityWarning, reg.pos, fmt.Sprintf("sunset date %q of %s %s is not a date like 2027-03-01", ep.Sunset, method, p))
+			ep.Sunset = ""
+		}
+	}
+	ep.Parameters = endpointParams(p, patterns, facts, doc)
+	if facts.body != nil && method != "GET" && method != "HEAD" {
+		body := *facts.body
+		if doc.RequestBody != "" {
+			body.Description = doc.RequestBody
+		}
+		ep.RequestBody = &body
+	}
+	ep.Responses = endpointResponses(facts, doc)
+	ep.IdempotencyKey = facts.idempotencyKey
+	ep.Conditional = facts.conditional
+	ep.MaxBodyBytes = facts.maxBodyBytes
+	ep.Ranges = facts.ranges
+	if r := facts.responses[http.StatusAccepted]; r != nil && strings.Contains(r.location, "/") {
+		ep.LongRunning = &model.LongRunning{Location: r.location}
+	}
+	a.applyMiddleware(ep, mws)
+	if facts.basicAuth && !containsString(ep.Security, schemeBasic) {
+		ep.Security = append(ep.Security, schemeBasic)
+		if _, ok := a.doc.SecuritySchemes[schemeBasic]; !ok {
+			a.doc.SecuritySchemes[schemeBasic] = &model.SecurityScheme{
+				Type:        model.SecurityHTTP,
+				Scheme:      "basic",
+				Description: "Detected from r.BasicAuth in " + ref.name + ".",
+			}
+		}
+	}
+	return ep
+}
+
+// handlerFuncName names an endpoint after its handler function, falling
+// back to the method name of a method value or "Handler".
+func handlerFuncName(ref *handlerRef) string {
+	if ref.decl != nil {
+		return ref.decl.Name
+	}
+	name := ref.name
+	if i := strings.LastIndexAny(name, ".)"); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" || !isIdent(name) || name == "literal" {
+		return "Handler"
+	}
+	return name
+}
+
+func isIdent(s string) bool {
+	for i, r := range s {
+		if r != '_' && !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || i > 0 && '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// defaultTags tags an endpoint with its first static path segment after
+// "api" and version prefixes, e.g. "users" for /api/v1/users/{id}.
+func defaultTags(p string) []string {
+	for _, seg := range strings.Split(p, "/") {
+		if seg == "" || seg == "api" || isVersionSuffix(seg) || strings.HasPrefix(seg, "{") {
+			continue
+		}
+		return []string{seg}
+	}
+	return []string{}
+}
+
+// endpointParams merges path template parameters, parameters read by the
+// handler and those described in its doc comment.
+func endpointParams(p string, patterns map[string]string, facts *handlerFacts, doc *docComment) []*model.Parameter {
+	params := []*model.Parameter{}
+	find := func(name, in string) *model.Parameter {
+		for _, q := range params {
+			if q.Name == name && (in == "" || q.In == in) {
+				return q
+			}
+		}
+		return nil
+	}
+	for _, name := range model.PathParams(p) {
+		param := &model.Parameter{Name: name, In: "path", Required: true, Schema: &model.Schema{Type: "string"}}
+		if pat, ok := patterns[name]; ok {
+			param.Schema.Pattern = "^" + pat + "$"
+		}
+		params = append(params, param)
+	}
+	for _, fp := range facts.params {
+		if q := find(fp.Name, fp.In); q != nil {
+			if fp.Schema.Type != "string" || fp.Schema.Format != "" {
+				pattern := q.Schema.Pattern
+				q.Schema = fp.Schema
+				if pattern != "" && q.Schema.Type == "string" {
+					q.Schema.Pattern = pattern
+				}
+			}
+			continue
+		}
+		if fp.In == "path" {
+			// A path value the route template does not declare.
+			continue
+		}
+		cp := *fp
+		params = append(params, &cp)
+	}
+	for _, dp := range doc.Params {
+		q := find(dp.Name, dp.In)
+		if q == nil {
+			in := dp.In
+			if in == "" {
+				in = "query"
+			}
+			q = &model.Parameter{Name: dp.Name, In: in, Required: dp.Required, Schema: &model.Schema{Type: "string"}}
+			params = append(params, q)
+		} else if q.In != "path" && dp.In != "" {
+			q.Required = dp.Required
+		}
+		q.Description = dp.Description
+	}
+	return params
+}
+
+// endpointResponses merges the responses found in the handler body with
+// those documented under "Returns:", defaulting to a 200 response.
+func endpointResponses(facts *handlerFacts, doc *docComment) []*model.Response {
+	responses := facts.responseList()
+	for _, dr := range doc.Responses {
+		var r *model.Response
+		for _, have := range responses {
+			if have.StatusCode == dr.Status {
+				r = have
+				break
+			}
+		}
+		if r == nil {
+			r = &model.Response{StatusCode: dr.Status}
+			responses = append(responses, r)
+		}
+		switch {
+		case dr.Description != "":
+			r.Description = dr.Description
+		case dr.Text != "":
+			r.Description = dr.Text
+		}
+	}
+	if len(responses) == 0 {
+		responses = append(responses, &model.Response{StatusCode: "200", Description: "Successful response"})
+	}
+	sortResponses(responses)
+	return responses
+}
+
+func sortResponses(rs []*model.Response) {
+	for i := 1; i < len(rs); i++ {
+		for j := i; j > 0 && rs[j].StatusCode < rs[j-1].StatusCode; j-- {
+			rs[j], rs[j-1] = rs[j-1], rs[j]
+		}
+	}
+}
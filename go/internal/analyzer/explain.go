@@ -0,0 +1,794 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/printer"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Query selects the routes Explain traces.
+type Query struct {
+	// Method and Path select the routes of a method and path, written in
+	// any framework's syntax; an empty Method selects every method.
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// Func selects the routes served by a handler function, named like
+	// the handler of an endpoint ("handlers.GetUser" or
+	// "handlers.UserHandler.Get") or qualified by import path.
+	Func string `json:"func,omitempty"`
+}
+
+// ParseRoute parses a route query such as "GET /users/{id}" or
+// "/users/:id".
+func ParseRoute(route string) (Query, error) {
+	s := strings.TrimSpace(route)
+	var q Query
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		q.Method, s = strings.ToUpper(s[:i]), strings.TrimSpace(s[i+1:])
+		if !isHTTPMethod(q.Method) {
+			return Query{}, fmt.Errorf("invalid route %q: %s is not an HTTP method", route, q.Method)
+		}
+	}
+	if !strings.HasPrefix(s, "/") {
+		return Query{}, fmt.Errorf("invalid route %q (want [METHOD] /path)", route)
+	}
+	q.Path = s
+	return q, nil
+}
+
+func (q Query) String() string {
+	if q.Func != "" {
+		return q.Func
+	}
+	return strings.TrimSpace(q.Method + " " + q.Path)
+}
+
+// Explanation is the extraction trace of everything matching a query.
+type Explanation struct {
+	Query Query `json:"query"`
+	// Traces follow the registrations, documented routes and references
+	// to handlers matching the query through the analysis.
+	Traces []*Trace `json:"traces"`
+	// Notes are findings not tied to a trace, such as files the filters
+	// left out that may hold what the query looks for.
+	Notes []*Step `json:"notes,omitempty"`
+}
+
+// Trace follows one route registration, documented route or reference to
+// a handler through the analysis.
+type Trace struct {
+	// Subject is the traced source, such as the registration call.
+	Subject string          `json:"subject"`
+	Pos     *model.Position `json:"pos,omitempty"`
+	// Extracted reports whether the subject became an endpoint, and
+	// Outcome says what became of it.
+	Extracted bool    `json:"extracted"`
+	Outcome   string  `json:"outcome"`
+	Steps     []*Step `json:"steps"`
+}
+
+// Step is one decision of the analysis.
+type Step struct {
+	// Stage names the part of the analysis that decided: "framework",
+	// "router", "prefix", "constant", "handler", "method", "middleware",
+	// "filter", "query" or "result".
+	Stage   string          `json:"stage"`
+	Message string          `json:"message"`
+	Pos     *model.Position `json:"pos,omitempty"`
+}
+
+// candidateTrace records how an endpoint candidate was built.
+type candidateTrace struct {
+	reg   *registration
+	ref   *handlerRef
+	ctx   routeContext
+	facts *handlerFacts
+	doc   *docComment
+}
+
+// Explain analyzes the program under root and traces how the analysis
+// treated the routes and handlers matching q: the framework extractor that
+// matched the registration, the routers the route was reached through,
+// how its path was composed and evaluated, and why it was or was not
+// extracted. When nothing matches, it looks for the query in the files
+// the options leave out.
+func Explain(root string, opts Options, q Query) (*Explanation, error) {
+	prog, err := Load(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	a := newAnalyzer(prog)
+	part := a.collect()
+	doc, _ := finish([]*Partial{part})
+
+	x := &explainer{a: a, q: q, out: &Explanation{Query: q, Traces: []*Trace{}}, claimed: map[string]*PartialEndpoint{}}
+	if q.Path != "" {
+		x.shape = pathShape(q.Path)
+	}
+	kept := map[*model.Endpoint]bool{}
+	for _, ep := range doc.Endpoints {
+		kept[ep] = true
+	}
+	for _, c := range part.Endpoints {
+		if kept[c.Endpoint] {
+			x.claimed[c.Endpoint.Key()] = c
+		}
+	}
+	registered := map[string]bool{}
+	for _, id := range part.Registered {
+		registered[id] = true
+	}
+
+	var near []*Trace
+	exact := false
+	for _, c := range part.Endpoints {
+		notes, ok := x.match(c)
+		if !ok {
+			continue
+		}
+		t := x.candidate(c, kept[c.Endpoint], registered)
+		if len(notes) > 0 {
+			var steps []*Step
+			for _, n := range notes {
+				steps = append(steps, &Step{Stage: "query", Message: n})
+			}
+			t.Steps = append(steps, t.Steps...)
+			near = append(near, t)
+			continue
+		}
+		exact = exact || t.Extracted
+		x.out.Traces = append(x.out.Traces, t)
+	}
+	if !exact {
+		x.out.Traces = append(x.out.Traces, near...)
+		for _, reg := range a.routes.unresolved {
+			if x.matchUnresolved(reg) {
+				x.out.Traces = append(x.out.Traces, x.unresolved(reg))
+			}
+		}
+		if q.Func != "" {
+			x.references()
+		} else {
+			for _, d := range doc.Diagnostics {
+				if d.Code == "UNRESOLVED_ROUTE_PATH" && strings.Contains(d.Message, "group prefix") {
+					x.note("constant", d.Message+"; routes registered on that group are not extracted", d.Pos)
+				}
+			}
+		}
+		if err := x.filtered(root, opts); err != nil {
+			return nil, err
+		}
+	}
+	return x.out, nil
+}
+
+type explainer struct {
+	a     *analyzer
+	q     Query
+	shape string
+	out   *Explanation
+	// claimed maps endpoint keys to the candidates extracted for them.
+	claimed map[string]*PartialEndpoint
+}
+
+func (x *explainer) note(stage, msg string, pos *model.Position) {
+	x.out.Notes = append(x.out.Notes, &Step{Stage: stage, Message: msg, Pos: pos})
+}
+
+// pathShape normalizes p and blanks its parameter names, so that
+// "/users/:id" and "/users/{userID}" compare equal.
+func pathShape(p string) string {
+	p, _ = normalizePath(p)
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		if strings.HasPrefix(seg, "{") {
+			segs[i] = "{}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// hasSegSuffix reports whether path p ends with the path suffix on a
+// segment boundary. The root path is no suffix.
+func hasSegSuffix(p, suffix string) bool {
+	if suffix == "/" || suffix == "" || p == suffix {
+		return false
+	}
+	return strings.HasSuffix(p, suffix) && strings.HasPrefix(suffix, "/")
+}
+
+// match reports whether the candidate c matches the query. Near matches,
+// returned with notes on how they differ, are candidates whose path is a
+// suffix of the queried one or the other way round, or that serve another
+// method.
+func (x *explainer) match(c *PartialEndpoint) ([]string, bool) {
+	if x.q.Func != "" {
+		return nil, x.matchFunc(c.trace.ref)
+	}
+	ep := c.Endpoint
+	shape := pathShape(ep.Path)
+	var notes []string
+	switch {
+	case shape == x.shape:
+	case hasSegSuffix(shape, x.shape):
+		notes = append(notes, fmt.Sprintf("the path composes to %s, which has a prefix the query %s lacks", ep.Path, x.q.Path))
+	case c.trace.reg.path != "" && hasSegSuffix(x.shape, pathShape(c.trace.reg.path)):
+		notes = append(notes, fmt.Sprintf("the registered path %s composes to %s, not %s", c.trace.reg.path, ep.Path, x.q.Path))
+	default:
+		return nil, false
+	}
+	if x.q.Method != "" && ep.Method != x.q.Method {
+		notes = append(notes, fmt.Sprintf("the route serves %s, not %s", ep.Method, x.q.Method))
+	}
+	return notes, true
+}
+
+func (x *explainer) matchFunc(ref *handlerRef) bool {
+	if ref == nil {
+		return false
+	}
+	if ref.decl != nil {
+		return ref.decl.Key() == x.q.Func || ref.decl.id() == x.q.Func
+	}
+	return ref.name == x.q.Func
+}
+
+// matchUnresolved reports whether a registration whose path could not be
+// evaluated may be what the query looks for.
+func (x *explainer) matchUnresolved(reg *registration) bool {
+	if x.q.Func != "" {
+		return x.matchFunc(x.a.resolveHandler(reg.file, reg.handler, reg.locals))
+	}
+	if x.q.Method != "" && len(reg.methods) > 0 && !containsString(reg.methods, x.q.Method) {
+		return false
+	}
+	// The literal parts of the path must appear in the queried path.
+	ok := true
+	ast.Inspect(reg.pathExpr, func(n ast.Node) bool {
+		if lit, isLit := n.(*ast.BasicLit); isLit && lit.Kind == token.STRING {
+			if v, err := strconv.Unquote(lit.Value); err == nil && !strings.Contains(x.q.Path, strings.TrimSuffix(v, "/")) {
+				ok = false
+			}
+		}
+		return ok
+	})
+	return ok
+}
+
+// candidate traces an endpoint candidate.
+func (x *explainer) candidate(c *PartialEndpoint, kept bool, registered map[string]bool) *Trace {
+	ct := c.trace
+	reg, ep := ct.reg, c.Endpoint
+	t := &Trace{Pos: x.a.prog.Position(reg.pos)}
+	if reg.call != nil {
+		t.Subject = x.subject(reg.call, reg.fn)
+		x.registration(t, reg)
+		x.routers(t, reg, ct.ctx)
+		x.prefix(t, reg, ct.ctx, ep)
+	} else {
+		t.Subject = fmt.Sprintf("Route: %s %s in the doc comment of %s", ep.Method, ep.Path, reg.fn.Key())
+		t.step("framework", "no registration of "+reg.fn.Key()+" was found, so the route comes from the Route: section of its doc comment", x.a.prog.Position(reg.pos))
+	}
+	x.handler(t, reg, ct.ref)
+	x.method(t, reg, ct, ep.Method)
+	if len(ep.Middleware) > 0 {
+		t.step("middleware", "applies "+strings.Join(ep.Middleware, ", "), nil)
+	}
+
+	switch {
+	case kept:
+		t.Extracted = true
+		t.Outcome = fmt.Sprintf("extracted as %s %s (%s)", ep.Method, ep.Path, ep.ID)
+	case c.Func != "" && registered[c.Func]:
+		t.Outcome = "not extracted: the handler is registered in code, which takes precedence over its Route: section"
+	case x.claimed[ep.Key()] != nil:
+		by := x.claimed[ep.Key()].trace.reg
+		if by.call == nil {
+			t.Outcome = fmt.Sprintf("not extracted: %s %s is already extracted from the doc comment of %s", ep.Method, ep.Path, by.fn.Key())
+		} else {
+			t.Outcome = fmt.Sprintf("not extracted: %s %s is already extracted from %s at %s", ep.Method, ep.Path, x.subject(by.call, by.fn), x.a.prog.Position(by.pos))
+		}
+	default:
+		t.Outcome = "not extracted: the router it is registered on is attached to another router"
+	}
+	t.step("result", t.Outcome, nil)
+	return t
+}
+
+func (t *Trace) step(stage, msg string, pos *model.Position) {
+	t.Steps = append(t.Steps, &Step{Stage: stage, Message: msg, Pos: pos})
+}
+
+// subject renders a call on one line, followed by the function it is in.
+func (x *explainer) subject(call *ast.CallExpr, fn *funcDecl) string {
+	s := x.source(call)
+	if fn != nil {
+		s += " in " + fn.Key()
+	}
+	return s
+}
+
+// source renders node as written, cut at the first line break.
+func (x *explainer) source(node ast.Node) string {
+	var b strings.Builder
+	if err := printer.Fprint(&b, x.a.prog.Fset, node); err != nil {
+		return ""
+	}
+	s := b.String()
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " ..."
+	}
+	return s
+}
+
+func (x *explainer) registration(t *Trace, reg *registration) {
+	name := callName(reg.call)
+	msg := fmt.Sprintf("matched by the %s extractor as a %s registration", reg.framework, name)
+	if reg.router.base != nil && reg.router.base.adopted {
+		msg += "; the framework is guessed from the file's imports"
+	}
+	t.step("framework", msg, x.a.prog.Position(reg.pos))
+}
+
+// base describes the origin of a router.
+func (x *explainer) base(b *routerBase) (string, *model.Position) {
+	switch {
+	case b == x.a.routes.defaultBase:
+		return "http.DefaultServeMux, the router of net/http's package-level Handle and HandleFunc", nil
+	case b.param >= 0:
+		return fmt.Sprintf("parameter %s of %s", b.name, b.fn.Key()), x.a.prog.Position(b.fn.Decl.Pos())
+	case b.adopted:
+		return fmt.Sprintf("%s in %s, taken for a router because routes with constant paths are registered on it", b.name, b.fn.Key()), x.a.prog.Position(b.pos)
+	}
+	return fmt.Sprintf("the router created by %s() in %s", b.name, b.fn.Key()), x.a.prog.Position(b.pos)
+}
+
+// routers traces the router value flow from the registration out to the
+// root router of ctx.
+func (x *explainer) routers(t *Trace, reg *registration, ctx routeContext) {
+	child := reg.router.base
+	if child == nil {
+		return
+	}
+	desc, pos := x.base(child)
+	t.step("router", "registered on "+desc, pos)
+	for _, src := range ctx.srcs {
+		parent, _ := x.base(src.parent.base)
+		childDesc, _ := x.base(child)
+		if child.param >= 0 {
+			t.step("router", fmt.Sprintf("%s receives %s", childDesc, parent), x.a.prog.Position(src.pos))
+		} else {
+			t.step("router", fmt.Sprintf("%s is mounted on %s", childDesc, parent), x.a.prog.Position(src.pos))
+		}
+		child = src.parent.base
+	}
+	if child.param >= 0 && len(child.sources) == 0 {
+		desc, pos := x.base(child)
+		t.step("router", desc+" receives no router from any call the analysis followed, so paths start at its own prefixes", pos)
+	}
+}
+
+// prefix traces how the route's path was composed from the prefixes of
+// the routers it was reached through.
+func (x *explainer) prefix(t *Trace, reg *registration, ctx routeContext, ep *model.Endpoint) {
+	var segs []pathSeg
+	for i := len(ctx.srcs) - 1; i >= 0; i-- {
+		segs = append(segs, ctx.srcs[i].parent.segs...)
+	}
+	segs = append(segs, reg.router.segs...)
+	segs = append(segs, pathSeg{value: reg.path, expr: reg.pathExpr, call: reg.call, file: reg.file})
+	var parts []string
+	for _, seg := range segs {
+		parts = append(parts, fmt.Sprintf("%q", seg.value))
+		t.step("prefix", fmt.Sprintf("%q from %s", seg.value, x.source(seg.call)), x.a.prog.Position(seg.expr.Pos()))
+		x.constants(t, seg.file, reg.locals, seg.expr)
+	}
+	raw := joinPath(ctx.prefix, reg.path)
+	msg := fmt.Sprintf("%s composes to %s", strings.Join(parts, " + "), raw)
+	if raw != ep.Path {
+		msg += ", normalized to " + ep.Path
+	}
+	t.step("prefix", msg, nil)
+}
+
+// constants traces the named constants and variables a path expression
+// was evaluated from.
+func (x *explainer) constants(t *Trace, f *File, locals map[string]ast.Expr, expr ast.Expr) {
+	if expr == nil {
+		return
+	}
+	if _, lit := unparen(expr).(*ast.BasicLit); lit {
+		return
+	}
+	ev := &evaluator{a: x.a, file: f, locals: locals}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		var name, importPath string
+		switch e := n.(type) {
+		case *ast.SelectorExpr:
+			id, ok := e.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			p, ok := f.ImportPath(id.Name)
+			if !ok {
+				return true
+			}
+			name, importPath = e.Sel.Name, p
+		case *ast.Ident:
+			name, importPath = e.Name, f.Pkg.ImportPath
+		default:
+			return true
+		}
+		v, ok := ev.eval(n.(ast.Expr))
+		desc, pos := x.named(f, locals, n.(ast.Expr), name, importPath)
+		switch {
+		case desc == "":
+		case ok:
+			t.step("constant", fmt.Sprintf("%s evaluates to %s", desc, formatValue(v)), pos)
+		default:
+			t.step("constant", desc+" cannot be evaluated: it is not a constant, a package variable with a constant initializer or a local variable assigned one", pos)
+		}
+		return false
+	})
+}
+
+// named describes the declaration an identifier refers to.
+func (x *explainer) named(f *File, locals map[string]ast.Expr, expr ast.Expr, name, importPath string) (string, *model.Position) {
+	src := x.source(expr)
+	if id, ok := expr.(*ast.Ident); ok {
+		if v, ok := locals[id.Name]; ok {
+			return "the local variable " + src, x.a.prog.Position(v.Pos())
+		}
+	}
+	key := importPath + "." + name
+	if c := x.a.consts[key]; c != nil {
+		return "the constant " + src, x.a.prog.Position(c.Pos)
+	}
+	if v := x.a.vars[key]; v != nil {
+		pos := token.NoPos
+		if v.Value != nil {
+			pos = v.Value.Pos()
+		}
+		return "the package variable " + src, x.a.prog.Position(pos)
+	}
+	if _, isImport := f.ImportPath(name); isImport {
+		return "", nil
+	}
+	return src, nil
+}
+
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
+
+func (x *explainer) handler(t *Trace, reg *registration, ref *handlerRef) {
+	switch {
+	case ref.decl != nil && reg.handler != nil && x.source(reg.handler) != ref.decl.Key():
+		t.step("handler", fmt.Sprintf("%s resolves to %s", x.source(reg.handler), ref.decl.Key()), x.a.prog.Position(ref.decl.Decl.Pos()))
+	case ref.decl != nil:
+		t.step("handler", "the handler is "+ref.decl.Key(), x.a.prog.Position(ref.decl.Decl.Pos()))
+	case ref.body != nil:
+		t.step("handler", "a function literal", x.a.prog.Position(ref.ftype.Pos()))
+	default:
+		msg := fmt.Sprintf("%s cannot be followed to a function, so its parameters and responses are not scanned", x.source(reg.handler))
+		if sel, ok := unparen(reg.handler).(*ast.SelectorExpr); ok {
+			if p, ok := reg.file.ImportPath(exprKey(sel.X)); ok && !x.loaded(p) {
+				msg += "; package " + p + " is not analyzed"
+			}
+		}
+		t.step("handler", msg, x.a.prog.Position(reg.handler.Pos()))
+	}
+	for _, mw := range ref.mws {
+		t.step("handler", "wrapped in the middleware "+mw.Name, x.a.prog.Position(mw.Expr.Pos()))
+	}
+}
+
+// loaded reports whether the package with the import path is analyzed.
+func (x *explainer) loaded(importPath string) bool {
+	for _, pkg := range x.a.prog.Packages {
+		if pkg.ImportPath == importPath {
+			return true
+		}
+	}
+	return false
+}
+
+func (x *explainer) method(t *Trace, reg *registration, ct *candidateTrace, method string) {
+	switch {
+	case reg.call == nil:
+		t.step("method", method+" from the Route: section", nil)
+	case len(reg.methods) > 0:
+		t.step("method", method+" given at registration", nil)
+	case len(ct.facts.methods) > 0:
+		t.step("method", method+" from the handler's checks of r.Method", nil)
+	case len(ct.doc.Routes) > 0:
+		t.step("method", method+" from the Route: section of the handler's doc comment", nil)
+	default:
+		t.step("method", "no method is given at registration or checked by the handler, so GET is assumed", nil)
+	}
+}
+
+// unresolved traces a registration whose path could not be evaluated.
+func (x *explainer) unresolved(reg *registration) *Trace {
+	t := &Trace{Subject: x.subject(reg.call, reg.fn), Pos: x.a.prog.Position(reg.pos)}
+	x.registration(t, reg)
+	x.routers(t, reg, routeContext{})
+	t.step("constant", "cannot evaluate the route path "+x.source(reg.pathExpr), x.a.prog.Position(reg.pathExpr.Pos()))
+	x.constants(t, reg.file, reg.locals, reg.pathExpr)
+	x.handler(t, reg, x.a.resolveHandler(reg.file, reg.handler, reg.locals))
+	t.Outcome = "not extracted: the route path is not a constant"
+	t.step("result", t.Outcome, nil)
+	return t
+}
+
+// references traces the uses of the queried handler when no registration
+// of it was found.
+func (x *explainer) references() {
+	var decls []*funcDecl
+	for _, key := range sortedKeys(x.a.funcs) {
+		fd := x.a.funcs[key]
+		if fd.Key() == x.q.Func || key == x.q.Func {
+			decls = append(decls, fd)
+		}
+	}
+	if len(decls) == 0 {
+		x.note("handler", "no function "+x.q.Func+" is declared in the analyzed files", nil)
+		return
+	}
+	regs := map[*ast.CallExpr]bool{}
+	for _, reg := range append(append([]*registration(nil), x.a.routes.regs...), x.a.routes.unresolved...) {
+		regs[reg.call] = true
+	}
+	found := false
+	for _, pkg := range x.a.prog.Packages {
+		for _, f := range pkg.Files {
+			var stack []ast.Node
+			ast.Inspect(f.AST, func(n ast.Node) bool {
+				if n == nil {
+					stack = stack[:len(stack)-1]
+					return true
+				}
+				stack = append(stack, n)
+				expr, ok := n.(ast.Expr)
+				if !ok {
+					return true
+				}
+				switch expr.(type) {
+				case *ast.Ident, *ast.SelectorExpr:
+				default:
+					return true
+				}
+				fd := x.a.lookupFunc(f, expr)
+				if fd == nil || !containsFunc(decls, fd) || isDeclName(stack, expr) {
+					return true
+				}
+				found = true
+				if !inCall(stack, regs) {
+					x.out.Traces = append(x.out.Traces, x.reference(f, stack, expr, fd))
+				}
+				// The selector's identifiers are not references on their own.
+				stack = stack[:len(stack)-1]
+				return false
+			})
+		}
+	}
+	if !found {
+		fd := decls[0]
+		x.note("handler", fd.Key()+" is never referenced and its doc comment has no Route: section", x.a.prog.Position(fd.Decl.Pos()))
+	}
+}
+
+func containsFunc(fds []*funcDecl, fd *funcDecl) bool {
+	for _, d := range fds {
+		if d == fd {
+			return true
+		}
+	}
+	return false
+}
+
+// inCall reports whether the innermost node of stack is an argument of
+// one of calls, possibly wrapped in other calls, within one statement.
+func inCall(stack []ast.Node, calls map[*ast.CallExpr]bool) bool {
+	for i := len(stack) - 2; i >= 0; i-- {
+		if c, ok := stack[i].(*ast.CallExpr); ok && calls[c] {
+			return true
+		}
+		if _, ok := stack[i].(ast.Stmt); ok {
+			return false
+		}
+	}
+	return false
+}
+
+// isDeclName reports whether expr is the name of the function declaration
+// enclosing it.
+func isDeclName(stack []ast.Node, expr ast.Expr) bool {
+	if len(stack) < 2 {
+		return false
+	}
+	fd, ok := stack[len(stack)-2].(*ast.FuncDecl)
+	return ok && fd.Name == expr
+}
+
+// reference traces a use of a handler that the analysis did not take for
+// a route registration.
+func (x *explainer) reference(f *File, stack []ast.Node, expr ast.Expr, fd *funcDecl) *Trace {
+	pos := x.a.prog.Position(expr.Pos())
+	t := &Trace{Pos: pos, Outcome: "not extracted: not recognized as a route registration"}
+	var call *ast.CallExpr
+	for i := len(stack) - 2; i >= 0; i-- {
+		if c, ok := stack[i].(*ast.CallExpr); ok {
+			call = c
+			break
+		}
+		if _, ok := stack[i].(ast.Stmt); ok {
+			break
+		}
+	}
+	fn := x.enclosing(f, expr.Pos())
+	switch {
+	case call == nil:
+		t.Subject = x.source(expr)
+		if fn != nil {
+			t.Subject += " in " + fn.Key()
+		}
+		t.step("handler", fd.Key()+" is used as a value here, not passed to a route registration", pos)
+	case unparen(call.Fun) == expr:
+		t.Subject = x.subject(call, fn)
+		t.step("handler", fd.Key()+" is called here, not registered as a handler", pos)
+	default:
+		t.Subject = x.subject(call, fn)
+		name := callName(call)
+		switch fun := call.Fun.(type) {
+		case *ast.SelectorExpr:
+			if _, isPkg := f.ImportPath(exprKey(fun.X)); isPkg && exprKey(fun.X) != "" {
+				t.step("framework", fmt.Sprintf("%s is a package function, not a route registration of a supported framework", x.source(fun)), x.a.prog.Position(call.Pos()))
+			} else if routeMethods[name] == "" && name != "Handle" && name != "HandleFunc" && name != "Method" && name != "MethodFunc" && name != "Match" && name != "Any" && name != "Add" {
+				t.step("framework", fmt.Sprintf("%s is not a route registration method of any supported framework", name), x.a.prog.Position(call.Pos()))
+			} else {
+				t.step("framework", fmt.Sprintf("%s is not a router the analysis tracks: routers come from a framework's constructor, parameters of a router type, or variables routes with constant paths are registered on", x.source(fun.X)), x.a.prog.Position(fun.X.Pos()))
+			}
+		default:
+			t.step("framework", fmt.Sprintf("%s is passed to %s, which is not a route registration; handlers passed to other functions are not followed", fd.Key(), x.source(call.Fun)), x.a.prog.Position(call.Pos()))
+		}
+	}
+	t.step("result", t.Outcome, nil)
+	return t
+}
+
+// enclosing returns the function declaration of f containing pos.
+func (x *explainer) enclosing(f *File, pos token.Pos) *funcDecl {
+	for _, decl := range f.AST.Decls {
+		d, ok := decl.(*ast.FuncDecl)
+		if !ok || pos < d.Pos() || pos > d.End() {
+			continue
+		}
+		for _, fd := range x.a.funcs {
+			if fd.Decl == d {
+				return fd
+			}
+		}
+	}
+	return nil
+}
+
+// filtered notes the Go files under root that the options leave out and
+// that mention what the query looks for.
+func (x *explainer) filtered(root string, opts Options) error {
+	needle := x.needle()
+	if needle == "" {
+		return nil
+	}
+	root, recursive := SplitPattern(root)
+	opts.Recursive = opts.Recursive || recursive
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	fsys := opts.fs()
+	const limit = 20
+	n := 0
+	var walk func(dir, reason string) error
+	walk = func(dir, reason string) error {
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		rel := x.a.prog.rel(dir)
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() {
+				if strings.HasPrefix(name, ".") || name == "node_modules" {
+					continue
+				}
+				sub := reason
+				switch {
+				case sub != "":
+				case SkipDir(name):
+					sub = "it is in the skipped directory " + path.Join(rel, name)
+				case !opts.Recursive:
+					sub = "it is below the root; analyze recursively with -r or " + root + "/..."
+				}
+				if err := walk(filepath.Join(dir, name), sub); err != nil {
+					return err
+				}
+				continue
+			}
+			if !strings.HasSuffix(name, ".go") || n >= limit {
+				continue
+			}
+			why := reason
+			relFile := path.Join(rel, name)
+			switch {
+			case why != "":
+			case strings.HasSuffix(name, "_test.go"):
+				why = "it is a test file"
+			default:
+				why = unselectedBy(name, relFile, opts)
+			}
+			src, err := fsys.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return err
+			}
+			if why == "" && !buildable(name, src) {
+				why = "its build constraints exclude it on " + build.Default.GOOS + "/" + build.Default.GOARCH
+			}
+			if why == "" {
+				continue
+			}
+			if i := strings.Index(string(src), needle); i >= 0 {
+				n++
+				line := strings.Count(string(src[:i]), "\n") + 1
+				col := i - strings.LastIndexByte(string(src[:i]), '\n')
+				x.note("filter", fmt.Sprintf("%s mentions %s but is not analyzed: %s", relFile, needle, why), &model.Position{File: relFile, Line: line, Column: col})
+			}
+		}
+		return nil
+	}
+	return walk(abs, "")
+}
+
+// needle returns the text whose presence in a file suggests it holds what
+// the query looks for: the handler's name, or the longest static segment
+// of the queried path.
+func (x *explainer) needle() string {
+	if x.q.Func != "" {
+		return x.q.Func[strings.LastIndex(x.q.Func, ".")+1:]
+	}
+	best := ""
+	for _, seg := range strings.Split(x.shape, "/") {
+		if seg != "{}" && len(seg) > len(best) {
+			best = seg
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return "/" + best
+}
+
+// unselectedBy returns why the include and exclude patterns leave a file
+// out, or "" when they select it.
+func unselectedBy(base, rel string, opts Options) string {
+	for _, pat := range opts.Exclude {
+		if match(pat, base, rel) {
+			return "it is excluded by " + pat
+		}
+	}
+	if len(opts.Include) > 0 && !selected(base, rel, opts) {
+		return "it matches no include pattern"
+	}
+	return ""
+}
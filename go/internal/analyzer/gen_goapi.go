@@ -0,0 +1,97 @@
+//go:build ignore
+
+// gen_goapi writes goapi.txt, the Go version that introduced each package,
+// function, type, constant, variable and method of the standard library
+// since Go 1.16, from the api/go1.*.txt files of the Go distribution
+// running it.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// since is the oldest version listed; older APIs need no mention.
+const since = 16
+
+var (
+	fileRE   = regexp.MustCompile(`^go1\.(\d+)\.txt$`)
+	symbolRE = regexp.MustCompile(`^pkg ([^ ,]+), (?:func|type|const|var) ([A-Za-z0-9_]+)`)
+	methodRE = regexp.MustCompile(`^pkg ([^ ,]+), method \(\*?([A-Za-z0-9_]+)(?:\[[^\]]*\])?\) ([A-Za-z0-9_]+)`)
+	pkgRE    = regexp.MustCompile(`^pkg ([^ ,]+),`)
+)
+
+func main() {
+	dir := filepath.Join(runtime.GOROOT(), "api")
+	names, err := filepath.Glob(filepath.Join(dir, "go1*.txt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	versions := map[string]int{}
+	first := func(key string, minor int) {
+		if v, ok := versions[key]; !ok || minor < v {
+			versions[key] = minor
+		}
+	}
+	for _, name := range names {
+		minor := 0
+		if m := fileRE.FindStringSubmatch(filepath.Base(name)); m != nil {
+			minor, _ = strconv.Atoi(m[1])
+		} else if filepath.Base(name) != "go1.txt" {
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := sc.Text()
+			m := pkgRE.FindStringSubmatch(line)
+			// Platform-specific APIs, listed as "pkg syscall (linux-386)",
+			// are left out.
+			if m == nil || strings.HasPrefix(m[1], "syscall") || strings.Contains(line, " (") && strings.Index(line, " (") < strings.Index(line, ",") {
+				continue
+			}
+			first(m[1], minor)
+			if m := symbolRE.FindStringSubmatch(line); m != nil {
+				first(m[1]+" "+m[2], minor)
+			} else if m := methodRE.FindStringSubmatch(line); m != nil {
+				first(m[1]+" "+m[2]+"."+m[3], minor)
+			}
+		}
+		f.Close()
+		if err := sc.Err(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var lines []string
+	for key, minor := range versions {
+		if minor >= since {
+			lines = append(lines, fmt.Sprintf("1.%d %s", minor, key))
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		a, b := strings.SplitN(lines[i], " ", 2), strings.SplitN(lines[j], " ", 2)
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return a[0] < b[0]
+	})
+	out := "# Code generated by gen_goapi.go from the api files of " + runtime.Version() + ". DO NOT EDIT.\n" +
+		"# Each line gives the Go version that introduced a standard library package,\n" +
+		"# or one of its functions, types, constants, variables or methods.\n" +
+		strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile("goapi.txt", []byte(out), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
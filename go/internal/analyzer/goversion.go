@@ -0,0 +1,440 @@
+package analyzer
+
+//go:generate go run gen_goapi.go
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// goAPIText is the table of the Go releases that introduced the packages
+// and symbols of the standard library, written by gen_goapi.go.
+//
+//go:embed goapi.txt
+var goAPIText string
+
+var goAPI struct {
+	once sync.Once
+	// minor maps "slices" and "slices Contains" to 21.
+	minor map[string]int
+}
+
+// stdlibMinor returns the minor version of the Go release that introduced
+// a standard library package, given by import path, or symbol, given as
+// "net/http Request.PathValue", or 0 when it predates Go 1.16.
+func stdlibMinor(key string) int {
+	goAPI.once.Do(func() {
+		goAPI.minor = map[string]int{}
+		for _, line := range strings.Split(goAPIText, "\n") {
+			version, key, ok := strings.Cut(line, " ")
+			if !ok || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if minor, ok := goMinor(version); ok {
+				goAPI.minor[key] = minor
+			}
+		}
+	})
+	return goAPI.minor[key]
+}
+
+// goMinor parses a Go release such as "1.21" or "1.21.3" into its minor
+// version.
+func goMinor(version string) (int, bool) {
+	rest := strings.TrimPrefix(version, "go")
+	if !strings.HasPrefix(rest, "1.") {
+		return 0, false
+	}
+	rest = rest[len("1."):]
+	end := 0
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+	minor, err := strconv.Atoi(rest[:end])
+	return minor, err == nil
+}
+
+func goRelease(minor int) string {
+	return "1." + strconv.Itoa(minor)
+}
+
+// Language features and the minor versions of the Go releases that
+// introduced them.
+var goFeatures = map[string]int{
+	"type parameters":                       18,
+	"predeclared any":                       18,
+	"predeclared comparable":                18,
+	"min and max builtins":                  21,
+	"clear builtin":                         21,
+	"range over int":                        22,
+	"ServeMux method and wildcard patterns": 22,
+	"range over func":                       23,
+	"generic type aliases":                  24,
+}
+
+// versionScan collects the requirements of a package, keeping for each
+// symbol the features needing its newest release.
+type versionScan struct {
+	a     *analyzer
+	pkg   *Package
+	file  *File
+	guard int
+	// newest maps symbols to the newest release they need, and reqs to
+	// the requirements of that release.
+	newest map[string]int
+	reqs   map[string][]*model.GoRequirement
+}
+
+// goRequirements returns the oldest Go release that builds pkg, as
+// "1.21", and the features that need it, by symbol. Features used in
+// files built only by newer releases, such as those constrained by
+// //go:build go1.23, are left out: older releases build the package
+// without them.
+func (a *analyzer) goRequirements(pkg *Package) (string, []*model.GoRequirement) {
+	v := &versionScan{a: a, pkg: pkg, newest: map[string]int{}, reqs: map[string][]*model.GoRequirement{}}
+	for _, f := range pkg.Files {
+		v.file, v.guard = f, buildGuard(f.AST)
+		for _, imp := range f.AST.Imports {
+			if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+				v.require("", stdlibMinor(p), "package "+p, imp.Pos())
+			}
+		}
+		for _, decl := range f.AST.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				v.inspect(funcSymbol(d), d)
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					v.inspect(specSymbol(spec), spec)
+				}
+			}
+		}
+	}
+
+	newest := 0
+	if directive, ok := goMinor(a.prog.GoVersion); ok {
+		newest = directive
+	}
+	var reqs []*model.GoRequirement
+	for _, symbol := range sortedKeys(v.reqs) {
+		if v.newest[symbol] > newest {
+			newest = v.newest[symbol]
+		}
+		reqs = append(reqs, v.reqs[symbol]...)
+	}
+	if newest == 0 {
+		return "", reqs
+	}
+	return goRelease(newest), reqs
+}
+
+// checkGoDirective reports a package needing a newer release than the go
+// directive of its module declares.
+func (a *analyzer) checkGoDirective(pkg *model.Package) {
+	directive, ok := goMinor(a.prog.GoVersion)
+	if !ok {
+		return
+	}
+	for _, r := range pkg.GoRequirements {
+		if minor, _ := goMinor(r.Version); minor > directive && r.Version == pkg.GoMinVersion {
+			a.doc.Diagnostics = append(a.doc.Diagnostics, &model.Diagnostic{
+				Code:     "GO_VERSION_TOO_LOW",
+				Severity: model.SeverityWarning,
+				Message:  fmt.Sprintf("%s uses %s, which needs Go %s, but go.mod declares go %s", pkg.ImportPath, r.Feature, r.Version, a.prog.GoVersion),
+				Pos:      r.Pos,
+			})
+			return
+		}
+	}
+}
+
+func (v *versionScan) require(symbol string, minor int, feature string, pos token.Pos) {
+	if minor == 0 || minor <= v.guard || minor < v.newest[symbol] {
+		return
+	}
+	if minor > v.newest[symbol] {
+		v.newest[symbol] = minor
+		v.reqs[symbol] = nil
+	}
+	for _, r := range v.reqs[symbol] {
+		if r.Feature == feature {
+			return
+		}
+	}
+	v.reqs[symbol] = append(v.reqs[symbol], &model.GoRequirement{
+		Symbol:  symbol,
+		Version: goRelease(minor),
+		Feature: feature,
+		Pos:     v.a.prog.Position(pos),
+	})
+}
+
+func (v *versionScan) feature(symbol, feature string, pos token.Pos) {
+	v.require(symbol, goFeatures[feature], feature, pos)
+}
+
+// inspect records the features used by a declaration of symbol.
+func (v *versionScan) inspect(symbol string, node ast.Node) {
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncType:
+			if n.TypeParams != nil {
+				v.feature(symbol, "type parameters", n.TypeParams.Pos())
+			}
+		case *ast.TypeSpec:
+			if n.TypeParams != nil {
+				v.feature(symbol, "type parameters", n.TypeParams.Pos())
+				if n.Assign.IsValid() {
+					v.feature(symbol, "generic type aliases", n.Assign)
+				}
+			}
+		case *ast.Ident:
+			if (n.Name == "any" || n.Name == "comparable") && v.predeclared(n) {
+				v.feature(symbol, "predeclared "+n.Name, n.Pos())
+			}
+		case *ast.SelectorExpr:
+			v.selector(symbol, n)
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.CallExpr:
+			if id, ok := n.Fun.(*ast.Ident); ok && v.predeclared(id) {
+				switch id.Name {
+				case "min", "max":
+					v.feature(symbol, "min and max builtins", id.Pos())
+				case "clear":
+					v.feature(symbol, "clear builtin", id.Pos())
+				}
+			}
+			v.servePattern(symbol, n)
+		case *ast.RangeStmt:
+			v.rangeFeature(symbol, n)
+		}
+		return true
+	}
+	ast.Inspect(node, visit)
+}
+
+// predeclared reports whether id refers to a predeclared identifier
+// rather than a declaration of the file or package.
+func (v *versionScan) predeclared(id *ast.Ident) bool {
+	if id.Obj != nil {
+		return false
+	}
+	key := v.pkg.ImportPath + "." + id.Name
+	return v.a.funcs[key] == nil && v.a.types[key] == nil
+}
+
+// selector records the standard library function, type, constant,
+// variable or method a selector refers to. Methods are recognized on
+// parameters and variables declared with a standard library type.
+func (v *versionScan) selector(symbol string, sel *ast.SelectorExpr) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return
+	}
+	if x.Obj == nil {
+		if p, ok := v.file.ImportPath(x.Name); ok {
+			v.require(symbol, stdlibMinor(p+" "+sel.Sel.Name), p+"."+sel.Sel.Name, sel.Pos())
+		}
+		return
+	}
+	var typ ast.Expr
+	switch d := x.Obj.Decl.(type) {
+	case *ast.Field:
+		typ = d.Type
+	case *ast.ValueSpec:
+		typ = d.Type
+	}
+	if typ == nil {
+		return
+	}
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if idx, ok := typ.(*ast.IndexExpr); ok {
+		typ = idx.X
+	}
+	if t, ok := typ.(*ast.SelectorExpr); ok {
+		if p, name, ok := v.a.qualify(v.file, t); ok {
+			v.require(symbol, stdlibMinor(p+" "+name+"."+sel.Sel.Name), p+"."+name+"."+sel.Sel.Name, sel.Sel.Pos())
+		}
+	}
+}
+
+// servePattern records the ServeMux patterns of Go 1.22 in calls of
+// http.Handle and http.HandleFunc, or of the methods of a mux made by
+// http.NewServeMux, with a method or wildcards.
+func (v *versionScan) servePattern(symbol string, call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Handle" && sel.Sel.Name != "HandleFunc" || len(call.Args) != 2 {
+		return
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return
+	}
+	if p, ok := v.file.ImportPath(x.Name); !ok || x.Obj != nil || p != "net/http" {
+		if !v.isServeMux(x) {
+			return
+		}
+	}
+	pattern, _ := strconv.Unquote(lit.Value)
+	if strings.ContainsAny(pattern, " {") {
+		v.feature(symbol, "ServeMux method and wildcard patterns", lit.Pos())
+	}
+}
+
+func (v *versionScan) isServeMux(x *ast.Ident) bool {
+	if x.Obj == nil {
+		return false
+	}
+	var values []ast.Expr
+	switch d := x.Obj.Decl.(type) {
+	case *ast.AssignStmt:
+		values = d.Rhs
+	case *ast.ValueSpec:
+		values = d.Values
+	}
+	if len(values) != 1 {
+		return false
+	}
+	call, ok := values[0].(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	p, name, ok := v.a.qualify(v.file, call.Fun)
+	return ok && p == "net/http" && name == "NewServeMux"
+}
+
+// rangeFeature records ranging over an integer or a function. Without
+// types, integers are recognized as constants and calls of len and cap,
+// and functions as function literals, functions of the package and
+// calls of those returning a function or an iter.Seq.
+func (v *versionScan) rangeFeature(symbol string, r *ast.RangeStmt) {
+	switch x := r.X.(type) {
+	case *ast.BasicLit:
+		if x.Kind == token.INT {
+			v.feature(symbol, "range over int", x.Pos())
+		}
+	case *ast.FuncLit:
+		v.feature(symbol, "range over func", x.Pos())
+	case *ast.Ident:
+		if x.Obj == nil && v.a.funcs[v.pkg.ImportPath+"."+x.Name] != nil {
+			v.feature(symbol, "range over func", x.Pos())
+		}
+	case *ast.CallExpr:
+		if id, ok := x.Fun.(*ast.Ident); ok && (id.Name == "len" || id.Name == "cap") && v.predeclared(id) {
+			v.feature(symbol, "range over int", x.Pos())
+			return
+		}
+		if fd := v.a.lookupFunc(v.file, x.Fun); fd != nil && returnsFunc(fd) {
+			v.feature(symbol, "range over func", x.Pos())
+		}
+	}
+}
+
+// returnsFunc reports whether a function returns a function or an
+// iter.Seq.
+func returnsFunc(fd *funcDecl) bool {
+	results := fd.Decl.Type.Results
+	if results == nil || len(results.List) != 1 {
+		return false
+	}
+	switch t := results.List[0].Type.(type) {
+	case *ast.FuncType:
+		return true
+	case *ast.IndexExpr:
+		return strings.HasPrefix(exprString(t.X), "iter.Seq")
+	case *ast.IndexListExpr:
+		return strings.HasPrefix(exprString(t.X), "iter.Seq")
+	}
+	return false
+}
+
+// buildGuard returns the minor version of the oldest Go release the
+// //go:build constraint of a file allows, or 0.
+func buildGuard(f *ast.File) int {
+	for _, group := range f.Comments {
+		if group.Pos() > f.Package {
+			break
+		}
+		for _, c := range group.List {
+			if constraint.IsGoBuild(c.Text) {
+				if expr, err := constraint.Parse(c.Text); err == nil {
+					return guardMinor(expr)
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func guardMinor(expr constraint.Expr) int {
+	switch e := expr.(type) {
+	case *constraint.TagExpr:
+		if minor, ok := goMinor(e.Tag); ok && strings.HasPrefix(e.Tag, "go1.") {
+			return minor
+		}
+	case *constraint.AndExpr:
+		// Both operands hold, so the newer release is required.
+		x, y := guardMinor(e.X), guardMinor(e.Y)
+		if x > y {
+			return x
+		}
+		return y
+	case *constraint.OrExpr:
+		x, y := guardMinor(e.X), guardMinor(e.Y)
+		if x < y {
+			return x
+		}
+		return y
+	}
+	return 0
+}
+
+// funcSymbol names an exported function or method, or returns "".
+func funcSymbol(fd *ast.FuncDecl) string {
+	if !fd.Name.IsExported() {
+		return ""
+	}
+	if fd.Recv != nil && len(fd.Recv.List) > 0 {
+		recv := recvTypeName(fd.Recv.List[0].Type)
+		if !ast.IsExported(recv) {
+			return ""
+		}
+		return recv + "." + fd.Name.Name
+	}
+	return fd.Name.Name
+}
+
+// specSymbol names an exported type, constant or variable, or returns "".
+func specSymbol(spec ast.Spec) string {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		if s.Name.IsExported() {
+			return s.Name.Name
+		}
+	case *ast.ValueSpec:
+		var names []string
+		for _, n := range s.Names {
+			if n.IsExported() {
+				names = append(names, n.Name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
@@ -0,0 +1,1153 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// handlerRef is a resolved route handler.
+type handlerRef struct {
+	// decl carries the doc comment; it is nil for function literals.
+	decl  *funcDecl
+	file  *File
+	ftype *ast.FuncType
+	body  *ast.BlockStmt
+	name  string
+	// mws lists middleware found wrapping the handler expression.
+	mws []*mwUse
+}
+
+// resolveHandler follows a handler expression to the function that
+// implements it, unwrapping http.HandlerFunc conversions, middleware
+// wrappers and handler factories.
+func (a *analyzer) resolveHandler(f *File, expr ast.Expr, locals map[string]ast.Expr) *handlerRef {
+	return a.resolveHandlerDepth(f, expr, locals, 0)
+}
+
+func (a *analyzer) resolveHandlerDepth(f *File, expr ast.Expr, locals map[string]ast.Expr, depth int) *handlerRef {
+	expr = unparen(expr)
+	if depth > 8 {
+		return &handlerRef{file: f, name: exprString(expr)}
+	}
+	switch e := expr.(type) {
+	case *ast.FuncLit:
+		return &handlerRef{file: f, ftype: e.Type, body: e.Body, name: "func literal"}
+	case *ast.Ident:
+		if x, ok := locals[e.Name]; ok {
+			return a.resolveHandlerDepth(f, x, locals, depth+1)
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return a.resolveHandlerDepth(f, e.X, locals, depth+1)
+		}
+	case *ast.CallExpr:
+		return a.resolveHandlerCall(f, e, locals, depth)
+	}
+	if fd := a.lookupFunc(f, expr); fd != nil {
+		return &handlerRef{decl: fd, file: fd.File, ftype: fd.Decl.Type, body: fd.Decl.Body, name: fd.Key()}
+	}
+	return &handlerRef{file: f, name: exprString(expr)}
+}
+
+func (a *analyzer) resolveHandlerCall(f *File, call *ast.CallExpr, locals map[string]ast.Expr, depth int) *handlerRef {
+	if p, name, ok := a.qualify(f, call.Fun); ok && p == "net/http" && len(call.Args) == 1 {
+		switch name {
+		case "HandlerFunc":
+			return a.resolveHandlerDepth(f, call.Args[0], locals, depth+1)
+		}
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && len(call.Args) == 1 {
+		switch sel.Sel.Name {
+		case "Then", "ThenFunc", "HandlerFunc":
+			// alice-style chains: chain.Then(handler).
+			ref := a.resolveHandlerDepth(f, call.Args[0], locals, depth+1)
+			ref.mws = append([]*mwUse{{Name: mwName(sel.X), Expr: sel.X, File: f, locals: locals}}, ref.mws...)
+			return ref
+		}
+	}
+
+	fd := a.lookupFunc(f, call.Fun)
+	if fd != nil && fd.Decl.Body != nil {
+		if idx := handlerParamIndex(fd); idx >= 0 && idx < len(call.Args) {
+			ref := a.resolveHandlerDepth(f, call.Args[idx], locals, depth+1)
+			ref.mws = append([]*mwUse{{Name: fd.Key(), Expr: call.Fun, File: f, locals: locals}}, ref.mws...)
+			return ref
+		}
+		if lit := returnedFuncLit(fd.Decl.Body); lit != nil {
+			return &handlerRef{decl: fd, file: fd.File, ftype: lit.Type, body: lit.Body, name: fd.Key()}
+		}
+		return &handlerRef{decl: fd, file: fd.File, name: fd.Key()}
+	}
+	// An unknown function applied to a handler, or a curried middleware
+	// such as auth(cfg)(handler), wraps its last argument.
+	if len(call.Args) > 0 {
+		last := call.Args[len(call.Args)-1]
+		ref := a.resolveHandlerDepth(f, last, locals, depth+1)
+		if ref.body != nil || ref.decl != nil {
+			ref.mws = append([]*mwUse{{Name: mwName(call.Fun), Expr: call.Fun, File: f, locals: locals}}, ref.mws...)
+			return ref
+		}
+	}
+	return &handlerRef{file: f, name: exprString(call)}
+}
+
+// handlerParamIndex returns the index of the first parameter of fd whose
+// type is a handler, marking fd as a middleware, or -1.
+func handlerParamIndex(fd *funcDecl) int {
+	i := 0
+	for _, field := range fd.Decl.Type.Params.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		if isHandlerType(field.Type) {
+			return i
+		}
+		i += n
+	}
+	return -1
+}
+
+func isHandlerType(expr ast.Expr) bool {
+	switch exprString(expr) {
+	case "http.Handler", "http.HandlerFunc", "gin.HandlerFunc", "echo.HandlerFunc", "httprouter.Handle":
+		return true
+	}
+	return false
+}
+
+// returnedFuncLit returns the function literal returned by a handler
+// factory such as func GetUser(svc Service) http.HandlerFunc.
+func returnedFuncLit(body *ast.BlockStmt) *ast.FuncLit {
+	var lit *ast.FuncLit
+	for _, stmt := range body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		expr := ret.Results[0]
+		if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 1 {
+			// return http.HandlerFunc(func(w, r) {...})
+			expr = call.Args[0]
+		}
+		if l, ok := expr.(*ast.FuncLit); ok {
+			lit = l
+		}
+	}
+	return lit
+}
+
+// handlerFacts is what scanning a handler body revealed.
+type handlerFacts struct {
+	params    []*model.Parameter
+	body      *model.RequestBody
+	responses map[int]*responseFact
+	methods   []string
+	basicAuth bool
+	// idempotencyKey, conditional, maxBodyBytes and ranges are the HTTP
+	// semantics found by semantics.
+	idempotencyKey string
+	conditional    []string
+	maxBodyBytes   int64
+	ranges         bool
+	// compared lists the precondition headers whose value the handler
+	// compares against an entity tag or a modification time.
+	compared []string
+	// nodes lists every call in the body, for recognizers that run after
+	// the scan.
+	calls []*ast.CallExpr
+}
+
+type responseFact struct {
+	schema      *model.Schema
+	contentType string
+	headers     []string
+	// location is the path template of the Location header.
+	location string
+}
+
+func (h *handlerFacts) response(code int) *responseFact {
+	r := h.responses[code]
+	if r == nil {
+		r = &responseFact{}
+		h.responses[code] = r
+	}
+	return r
+}
+
+func (h *handlerFacts) param(name, in string) *model.Parameter {
+	for _, p := range h.params {
+		if p.Name == name && p.In == in {
+			return p
+		}
+	}
+	p := &model.Parameter{Name: name, In: in, Required: in == "path", Schema: &model.Schema{Type: "string"}}
+	h.params = append(h.params, p)
+	return p
+}
+
+// typed is a type expression together with the file it appears in.
+type typed struct {
+	file *File
+	expr ast.Expr
+}
+
+// scanner inspects one handler body.
+type scanner struct {
+	a     *analyzer
+	file  *File
+	facts *handlerFacts
+	// vars holds declared variable types; values holds assigned
+	// expressions whose type is computed on demand.
+	vars   map[string]typed
+	values map[string]ast.Expr
+	// paramVars maps variables holding a request parameter to it.
+	paramVars map[string]*model.Parameter
+	// queryVars and pathVars hold url.Values and route variable maps.
+	queryVars map[string]bool
+	pathVars  map[string]bool
+	// timeVars maps variables holding a time parsed from a request
+	// header to the header.
+	timeVars map[string]*model.Parameter
+
+	stack       []ast.Node
+	statuses    []statusEvent
+	headers     []headerEvent
+	contentType string
+}
+
+type statusEvent struct {
+	code      int
+	pos       token.Pos
+	container ast.Node
+}
+
+type headerEvent struct {
+	name      string
+	pos       token.Pos
+	container ast.Node
+	// status is the status in effect where the header is set.
+	status int
+	value  ast.Expr
+}
+
+func (a *analyzer) scanHandler(ref *handlerRef) *handlerFacts {
+	facts := &handlerFacts{responses: map[int]*responseFact{}}
+	if ref.body == nil {
+		return facts
+	}
+	s := &scanner{
+		a:         a,
+		file:      ref.file,
+		facts:     facts,
+		vars:      map[string]typed{},
+		values:    map[string]ast.Expr{},
+		paramVars: map[string]*model.Parameter{},
+		queryVars: map[string]bool{},
+		pathVars:  map[string]bool{},
+		timeVars:  map[string]*model.Parameter{},
+	}
+	if ref.ftype != nil && ref.ftype.Params != nil {
+		for _, field := range ref.ftype.Params.List {
+			for _, name := range field.Names {
+				s.vars[name.Name] = typed{ref.file, field.Type}
+			}
+		}
+	}
+	ast.Inspect(ref.body, func(n ast.Node) bool {
+		if n == nil {
+			s.stack = s.stack[:len(s.stack)-1]
+			return true
+		}
+		s.stack = append(s.stack, n)
+		s.visit(n)
+		return true
+	})
+	s.attachHeaders()
+	facts.semantics()
+	return facts
+}
+
+func (s *scanner) visit(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		if len(n.Lhs) == len(n.Rhs) {
+			for i, l := range n.Lhs {
+				s.assign(l, n.Rhs[i])
+			}
+		} else if len(n.Rhs) == 1 {
+			// v, err := f(): the first result is the value.
+			s.assign(n.Lhs[0], n.Rhs[0])
+		}
+	case *ast.ValueSpec:
+		for i, id := range n.Names {
+			if n.Type != nil {
+				s.vars[id.Name] = typed{s.file, n.Type}
+			} else if i < len(n.Values) {
+				s.assign(id, n.Values[i])
+			}
+		}
+	case *ast.CallExpr:
+		s.facts.calls = append(s.facts.calls, n)
+		s.call(n)
+	case *ast.IndexExpr:
+		s.paramIndex(n)
+	case *ast.BinaryExpr:
+		if n.Op == token.EQL || n.Op == token.NEQ {
+			s.methodCheck(n.X, n.Y)
+			s.methodCheck(n.Y, n.X)
+			s.compareCheck(n.X, n.Y)
+			s.compareCheck(n.Y, n.X)
+		}
+	case *ast.SwitchStmt:
+		if n.Tag != nil && isMethodSelector(n.Tag) {
+			for _, stmt := range n.Body.List {
+				for _, e := range stmt.(*ast.CaseClause).List {
+					s.addMethod(e)
+				}
+			}
+		}
+	}
+}
+
+func (s *scanner) assign(lhs, rhs ast.Expr) {
+	id, ok := lhs.(*ast.Ident)
+	if !ok || id.Name == "_" {
+		return
+	}
+	s.values[id.Name] = rhs
+	if p := s.paramSource(rhs); p != nil {
+		s.paramVars[id.Name] = p
+	}
+	if call, ok := rhs.(*ast.CallExpr); ok {
+		if p := s.parsesHeaderTime(call); p != nil {
+			s.timeVars[id.Name] = p
+		}
+		switch {
+		case isQueryValues(call):
+			s.queryVars[id.Name] = true
+		case s.isPathVars(call):
+			s.pathVars[id.Name] = true
+		}
+	}
+}
+
+// readParam returns the request parameter an expression reads, directly
+// or through a variable.
+func (s *scanner) readParam(expr ast.Expr) *model.Parameter {
+	if p := s.paramSource(expr); p != nil {
+		return p
+	}
+	if id, ok := unparen(expr).(*ast.Ident); ok {
+		return s.paramVars[id.Name]
+	}
+	return nil
+}
+
+// compareCheck records a request header compared with ==, != against
+// something other than the empty string, as an entity tag.
+func (s *scanner) compareCheck(x, y ast.Expr) {
+	p := s.readParam(x)
+	if p == nil || p.In != "header" {
+		return
+	}
+	if v, ok := s.constString(y); ok && v == "" {
+		return
+	}
+	s.facts.compared = appendUnique(s.facts.compared, http.CanonicalHeaderKey(p.Name))
+}
+
+// parsesHeaderTime returns the request header a call to http.ParseTime or
+// time.Parse parses.
+func (s *scanner) parsesHeaderTime(call *ast.CallExpr) *model.Parameter {
+	p, name, ok := s.a.qualify(s.file, call.Fun)
+	if !ok || len(call.Args) == 0 || !(p == "net/http" && name == "ParseTime" || p == "time" && name == "Parse") {
+		return nil
+	}
+	if param := s.readParam(call.Args[len(call.Args)-1]); param != nil && param.In == "header" {
+		return param
+	}
+	return nil
+}
+
+// timeCheck records a request header parsed as a time and compared with
+// Before, After, Equal or Compare, as a modification time.
+func (s *scanner) timeCheck(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 {
+		return
+	}
+	switch sel.Sel.Name {
+	case "Before", "After", "Equal", "Compare":
+	default:
+		return
+	}
+	for _, e := range []ast.Expr{sel.X, call.Args[0]} {
+		if id, ok := unparen(e).(*ast.Ident); ok && s.timeVars[id.Name] != nil {
+			s.facts.compared = appendUnique(s.facts.compared, http.CanonicalHeaderKey(s.timeVars[id.Name].Name))
+		}
+	}
+}
+
+func isMethodSelector(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Method"
+}
+
+func (s *scanner) methodCheck(x, y ast.Expr) {
+	if isMethodSelector(x) {
+		s.addMethod(y)
+	}
+}
+
+func (s *scanner) addMethod(expr ast.Expr) {
+	ev := &evaluator{a: s.a, file: s.file}
+	m, ok := ev.String(expr)
+	if !ok || !isHTTPMethod(strings.ToUpper(m)) {
+		return
+	}
+	m = strings.ToUpper(m)
+	for _, have := range s.facts.methods {
+		if have == m {
+			return
+		}
+	}
+	s.facts.methods = append(s.facts.methods, m)
+}
+
+// isQueryValues matches r.URL.Query().
+func isQueryValues(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Query" || len(call.Args) != 0 {
+		return false
+	}
+	inner, ok := sel.X.(*ast.SelectorExpr)
+	return ok && inner.Sel.Name == "URL"
+}
+
+// isPathVars matches mux.Vars(r).
+func (s *scanner) isPathVars(call *ast.CallExpr) bool {
+	p, name, ok := s.a.qualify(s.file, call.Fun)
+	return ok && p == "github.com/gorilla/mux" && name == "Vars"
+}
+
+func (s *scanner) constString(expr ast.Expr) (string, bool) {
+	ev := &evaluator{a: s.a, file: s.file}
+	return ev.String(expr)
+}
+
+// paramSource recognizes expressions that read a request parameter.
+func (s *scanner) paramSource(expr ast.Expr) *model.Parameter {
+	switch e := unparen(expr).(type) {
+	case *ast.CallExpr:
+		return s.paramCall(e)
+	case *ast.IndexExpr:
+		return s.paramIndex(e)
+	}
+	return nil
+}
+
+func (s *scanner) paramCall(call *ast.CallExpr) *model.Parameter {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil
+	}
+	nameArg := call.Args[0]
+	in := ""
+	switch sel.Sel.Name {
+	case "Get":
+		switch x := sel.X.(type) {
+		case *ast.CallExpr:
+			if isQueryValues(x) {
+				in = "query"
+			}
+		case *ast.Ident:
+			if s.queryVars[x.Name] {
+				in = "query"
+			}
+		case *ast.SelectorExpr:
+			if x.Sel.Name == "Header" {
+				in = "header"
+			}
+		}
+	case "FormValue", "Query", "DefaultQuery", "QueryParam", "GetQuery", "QueryArray", "PostFormValue":
+		in = "query"
+	case "GetHeader":
+		in = "header"
+	case "Param", "PathValue", "ByName":
+		in = "path"
+	case "Cookie":
+		in = "cookie"
+	case "URLParam":
+		if p, _, ok := s.a.qualify(s.file, sel); ok && frameworkOf(p) == frameworkChi && len(call.Args) == 2 {
+			in, nameArg = "path", call.Args[1]
+		}
+	}
+	if in == "" {
+		return nil
+	}
+	name, ok := s.constString(nameArg)
+	if !ok || name == "" {
+		return nil
+	}
+	return s.facts.param(name, in)
+}
+
+func (s *scanner) paramIndex(e *ast.IndexExpr) *model.Parameter {
+	isVars := false
+	switch x := e.X.(type) {
+	case *ast.CallExpr:
+		isVars = s.isPathVars(x)
+	case *ast.Ident:
+		isVars = s.pathVars[x.Name]
+	}
+	if !isVars {
+		return nil
+	}
+	name, ok := s.constString(e.Index)
+	if !ok {
+		return nil
+	}
+	return s.facts.param(name, "path")
+}
+
+// call handles every call in the handler body.
+func (s *scanner) call(call *ast.CallExpr) {
+	if s.paramCall(call) != nil {
+		return
+	}
+	s.conversion(call)
+	s.timeCheck(call)
+
+	name := callName(call)
+	p, qname, qualified := s.a.qualify(s.file, call.Fun)
+	switch {
+	case qualified && p == "encoding/json" && qname == "Unmarshal" && len(call.Args) == 2:
+		s.requestBody(call.Args[1], "application/json")
+		return
+	case qualified && p == "net/http":
+		switch qname {
+		case "Error":
+			if len(call.Args) == 3 {
+				s.direct(call.Args[2], nil, "text/plain", call.Pos())
+			}
+		case "NotFound":
+			s.respond(http.StatusNotFound, nil, "text/plain")
+		case "Redirect":
+			if len(call.Args) == 4 {
+				s.direct(call.Args[3], nil, "", call.Pos())
+			}
+		case "MaxBytesReader":
+			// r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+			if len(call.Args) == 3 {
+				ev := &evaluator{a: s.a, file: s.file}
+				if n, ok := ev.Int(call.Args[2]); ok && n > 0 {
+					s.facts.maxBodyBytes = n
+				}
+			}
+		case "ServeContent", "ServeFile":
+			s.facts.ranges = true
+		}
+		return
+	case qualified && p == "github.com/go-chi/render":
+		switch qname {
+		case "Status":
+			if len(call.Args) == 2 {
+				s.status(call.Args[1], call.Pos())
+			}
+		case "JSON", "Render":
+			if len(call.Args) == 3 {
+				s.write(call.Args[2], "application/json", call.Pos())
+			}
+		case "DecodeJSON", "Bind", "Decode":
+			if len(call.Args) == 2 {
+				s.requestBody(call.Args[1], "application/json")
+			}
+		}
+		return
+	}
+
+	sel, isMethod := call.Fun.(*ast.SelectorExpr)
+	if !isMethod || qualified && s.isPackage(sel.X) {
+		s.helper(call)
+		return
+	}
+	switch name {
+	case "Decode":
+		if isJSONDecoder(sel.X) && len(call.Args) == 1 {
+			s.requestBody(call.Args[0], "application/json")
+		}
+	case "ShouldBindJSON", "BindJSON", "ShouldBind", "Bind", "ShouldBindWith", "MustBindWith":
+		if len(call.Args) >= 1 {
+			s.requestBody(call.Args[0], "application/json")
+		}
+	case "ShouldBindQuery", "BindQuery":
+		if len(call.Args) == 1 {
+			s.queryStruct(call.Args[0])
+		}
+	case "FormFile", "MultipartForm", "ParseMultipartForm":
+		if s.facts.body == nil {
+			s.facts.body = &model.RequestBody{ContentType: "multipart/form-data", Required: true, Schema: &model.Schema{Type: "object"}}
+		}
+	case "BasicAuth":
+		s.facts.basicAuth = true
+	case "Encode":
+		if isJSONEncoder(sel.X) && len(call.Args) == 1 {
+			s.write(call.Args[0], "application/json", call.Pos())
+		}
+	case "Write":
+		if len(call.Args) == 1 && s.isResponseWriter(sel.X) {
+			s.write(nil, "", call.Pos())
+		}
+	case "WriteHeader":
+		if len(call.Args) == 1 {
+			s.status(call.Args[0], call.Pos())
+		}
+	case "Status", "AbortWithStatus", "NoContent", "SendStatus":
+		if len(call.Args) == 1 {
+			s.direct(call.Args[0], nil, "", call.Pos())
+		}
+	case "JSON", "IndentedJSON", "PureJSON", "SecureJSON", "AsciiJSON", "JSONP", "JSONPretty", "AbortWithStatusJSON", "JSONBlob":
+		if len(call.Args) >= 2 {
+			s.direct(call.Args[0], call.Args[1], "application/json", call.Pos())
+		}
+	case "XML", "XMLPretty":
+		if len(call.Args) >= 2 {
+			s.direct(call.Args[0], call.Args[1], "application/xml", call.Pos())
+		}
+	case "String", "HTML", "Blob", "Data", "Stream", "Redirect":
+		if len(call.Args) >= 2 {
+			ct := "text/plain"
+			if name == "HTML" {
+				ct = "text/html"
+			}
+			s.direct(call.Args[0], nil, ct, call.Pos())
+		}
+	case "Set", "Add", "Header":
+		s.header(call)
+	default:
+		s.helper(call)
+	}
+}
+
+func (s *scanner) isPackage(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, isImport := s.file.ImportPath(id.Name)
+	_, isVar := s.vars[id.Name]
+	_, isValue := s.values[id.Name]
+	return isImport && !isVar && !isValue
+}
+
+func isJSONDecoder(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	return ok && callName(call) == "NewDecoder"
+}
+
+func isJSONEncoder(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	return ok && callName(call) == "NewEncoder"
+}
+
+func (s *scanner) isResponseWriter(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	t, ok := s.vars[id.Name]
+	return ok && exprString(t.expr) == "http.ResponseWriter"
+}
+
+// conversion refines the type of a parameter passed to a strconv parser.
+func (s *scanner) conversion(call *ast.CallExpr) {
+	p, name, ok := s.a.qualify(s.file, call.Fun)
+	if !ok || len(call.Args) == 0 {
+		return
+	}
+	var schema *model.Schema
+	arg := call.Args[0]
+	switch p + "." + name {
+	case "strconv.Atoi", "strconv.ParseInt", "strconv.ParseUint":
+		schema = &model.Schema{Type: "integer"}
+	case "strconv.ParseFloat":
+		schema = &model.Schema{Type: "number"}
+	case "strconv.ParseBool":
+		schema = &model.Schema{Type: "boolean"}
+	case "github.com/google/uuid.Parse", "github.com/google/uuid.MustParse":
+		schema = &model.Schema{Type: "string", Format: "uuid"}
+	case "time.Parse":
+		if len(call.Args) == 2 {
+			arg = call.Args[1]
+			schema = &model.Schema{Type: "string", Format: "date-time"}
+		}
+	}
+	if schema == nil {
+		return
+	}
+	param := s.paramSource(arg)
+	if id, ok := arg.(*ast.Ident); ok && param == nil {
+		param = s.paramVars[id.Name]
+	}
+	if param != nil {
+		param.Schema = schema
+	}
+}
+
+// queryStruct adds the fields of a struct bound from the query string.
+func (s *scanner) queryStruct(arg ast.Expr) {
+	t, ok := s.typeOf(arg)
+	if !ok {
+		return
+	}
+	td := s.a.lookupType(t.file, derefExpr(t.expr))
+	if td == nil {
+		return
+	}
+	st, ok := td.Spec.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+	for _, field := range st.Fields.List {
+		tag := fieldTag(field)
+		name := tag.Get("form")
+		if name == "" {
+			name, _ = parseJSONTag(tag.Get("query"))
+		}
+		if i := strings.IndexByte(name, ','); i >= 0 {
+			name = name[:i]
+		}
+		if name == "" || name == "-" {
+			continue
+		}
+		p := s.facts.param(name, "query")
+		p.Schema = s.a.schemaForType(td.File, field.Type)
+		p.Description = fieldDoc(field)
+		p.Required = strings.Contains(tag.Get("binding"), "required")
+	}
+}
+
+func (s *scanner) requestBody(arg ast.Expr, contentType string) {
+	t, ok := s.typeOf(arg)
+	if !ok {
+		return
+	}
+	schema := s.a.schemaForType(t.file, derefExpr(t.expr))
+	schema.Nullable = false
+	s.facts.body = &model.RequestBody{ContentType: contentType, Required: true, Schema: schema}
+}
+
+// status records an explicit status code written before the body.
+func (s *scanner) status(expr ast.Expr, pos token.Pos) {
+	code, ok := s.statusCode(expr)
+	if !ok {
+		return
+	}
+	s.statuses = append(s.statuses, statusEvent{code: code, pos: pos, container: s.container()})
+	s.facts.response(code)
+}
+
+func (s *scanner) statusCode(expr ast.Expr) (int, bool) {
+	ev := &evaluator{a: s.a, file: s.file}
+	n, ok := ev.Int(expr)
+	if !ok || n < 100 || n > 599 {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// direct records a response written with its status in one call.
+func (s *scanner) direct(code, value ast.Expr, contentType string, pos token.Pos) {
+	c, ok := s.statusCode(code)
+	if !ok {
+		return
+	}
+	s.statuses = append(s.statuses, statusEvent{code: c, pos: pos, container: s.container()})
+	s.respondValue(c, value, contentType)
+}
+
+// write records a body written with the status most recently set on the
+// path leading to it.
+func (s *scanner) write(value ast.Expr, contentType string, pos token.Pos) {
+	s.respondValue(s.statusAt(pos), value, contentType)
+}
+
+func (s *scanner) respond(code int, schema *model.Schema, contentType string) {
+	r := s.facts.response(code)
+	if schema != nil && r.schema == nil {
+		r.schema = schema
+	}
+	if contentType != "" && r.contentType == "" {
+		r.contentType = contentType
+	}
+}
+
+func (s *scanner) respondValue(code int, value ast.Expr, contentType string) {
+	if s.contentType != "" && contentType != "" {
+		contentType = s.contentType
+	}
+	var schema *model.Schema
+	if value != nil {
+		schema = s.schemaOf(value)
+	}
+	s.respond(code, schema, contentType)
+}
+
+// statusAt finds the status set most recently before pos in the
+// enclosing blocks, defaulting to 200.
+func (s *scanner) statusAt(pos token.Pos) int {
+	for i := len(s.stack) - 1; i >= 0; i-- {
+		best := -1
+		for j, ev := range s.statuses {
+			if ev.container == s.stack[i] && ev.pos < pos {
+				best = j
+			}
+		}
+		if best >= 0 {
+			return s.statuses[best].code
+		}
+	}
+	return http.StatusOK
+}
+
+func (s *scanner) container() ast.Node {
+	for i := len(s.stack) - 1; i >= 0; i-- {
+		switch s.stack[i].(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+			return s.stack[i]
+		}
+	}
+	return nil
+}
+
+// header records w.Header().Set(name, value) and similar calls.
+func (s *scanner) header(call *ast.CallExpr) {
+	sel := call.Fun.(*ast.SelectorExpr)
+	isHeader := false
+	switch x := sel.X.(type) {
+	case *ast.CallExpr:
+		isHeader = callName(x) == "Header"
+	case *ast.SelectorExpr:
+		isHeader = x.Sel.Name == "Header"
+	}
+	if sel.Sel.Name == "Header" && len(call.Args) == 2 {
+		// gin: c.Header(name, value)
+		isHeader = true
+	}
+	if !isHeader || len(call.Args) != 2 {
+		return
+	}
+	if x, ok := sel.X.(*ast.SelectorExpr); ok && x.Sel.Name == "Header" {
+		// r.Header.Set modifies the request, not the response.
+		if id, ok := x.X.(*ast.Ident); ok && !s.isResponseWriter(id) {
+			return
+		}
+	}
+	name, ok := s.constString(call.Args[0])
+	if !ok {
+		return
+	}
+	if http.CanonicalHeaderKey(name) == "Content-Type" {
+		if ct, ok := s.constString(call.Args[1]); ok {
+			s.contentType = ct
+		}
+		return
+	}
+	s.headers = append(s.headers, headerEvent{name: http.CanonicalHeaderKey(name), pos: call.Pos(), container: s.container(), status: s.statusAt(call.Pos()), value: call.Args[1]})
+}
+
+// attachHeaders assigns each response header to the first status written
+// after it in the same block, or to the status in effect at that point.
+func (s *scanner) attachHeaders() {
+	for _, h := range s.headers {
+		code := -1
+		for _, ev := range s.statuses {
+			if ev.container == h.container && ev.pos > h.pos {
+				code = ev.code
+				break
+			}
+		}
+		if code < 0 {
+			code = h.status
+		}
+		r := s.facts.response(code)
+		if !containsString(r.headers, h.name) {
+			r.headers = append(r.headers, h.name)
+		}
+		if h.name == "Location" && r.location == "" {
+			r.location = s.template(h.value, 0)
+		}
+	}
+}
+
+// helper handles calls to functions such as respondJSON(w, status, v) or
+// writeError(w, http.StatusBadRequest, msg): an argument naming a net/http
+// status constant marks a response, and the helper body tells which
+// argument, if any, is encoded as the body.
+func (s *scanner) helper(call *ast.CallExpr) {
+	statusArg := -1
+	for i, arg := range call.Args {
+		if sel, ok := arg.(*ast.SelectorExpr); ok {
+			if p, name, ok := s.a.qualify(s.file, sel); ok && p == "net/http" && strings.HasPrefix(name, "Status") {
+				statusArg = i
+				break
+			}
+		}
+	}
+	if statusArg < 0 {
+		return
+	}
+	code, ok := s.statusCode(call.Args[statusArg])
+	if !ok {
+		return
+	}
+	s.statuses = append(s.statuses, statusEvent{code: code, pos: call.Pos(), container: s.container()})
+	var schema *model.Schema
+	contentType := ""
+	if fd := s.a.lookupFunc(s.file, call.Fun); fd != nil {
+		info := s.a.helperInfo(fd)
+		if info.encodes {
+			contentType = "application/json"
+		}
+		switch {
+		case info.bodyParam >= 0 && info.bodyParam < len(call.Args):
+			schema = s.schemaOf(call.Args[info.bodyParam])
+		case info.schema != nil:
+			schema = info.schema
+		}
+	}
+	s.respond(code, schema, contentType)
+}
+
+// helperFacts describes a response helper function.
+type helperFacts struct {
+	encodes   bool
+	bodyParam int
+	schema    *model.Schema
+}
+
+// helperInfo reports which parameter a response helper encodes, or the
+// schema of the value it builds itself.
+func (a *analyzer) helperInfo(fd *funcDecl) *helperFacts {
+	if info, ok := a.helpers[fd]; ok {
+		return info
+	}
+	info := &helperFacts{bodyParam: -1}
+	// Recursive helpers see the zero facts while they are being scanned.
+	a.helpers[fd] = info
+	if fd.Decl.Body == nil {
+		return info
+	}
+	params := map[string]int{}
+	i := 0
+	for _, field := range fd.Decl.Type.Params.List {
+		for _, name := range field.Names {
+			params[name.Name] = i
+			i++
+		}
+		if len(field.Names) == 0 {
+			i++
+		}
+	}
+	sub := a.scanHandler(&handlerRef{decl: fd, file: fd.File, ftype: fd.Decl.Type, body: fd.Decl.Body})
+	ast.Inspect(fd.Decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var value ast.Expr
+		switch callName(call) {
+		case "Encode":
+			if len(call.Args) == 1 {
+				value = call.Args[0]
+			}
+		case "JSON", "IndentedJSON", "AbortWithStatusJSON":
+			if len(call.Args) == 2 {
+				value = call.Args[1]
+			}
+		case "Marshal", "MarshalIndent":
+			if len(call.Args) >= 1 {
+				value = call.Args[0]
+			}
+		}
+		if value == nil {
+			return true
+		}
+		info.encodes = true
+		if id, ok := value.(*ast.Ident); ok {
+			if idx, ok := params[id.Name]; ok {
+				info.bodyParam = idx
+				return false
+			}
+		}
+		for _, r := range sub.responses {
+			if r.schema != nil {
+				info.schema = r.schema
+			}
+		}
+		return false
+	})
+	return info
+}
+
+// typeOf determines the static type of an expression syntactically.
+func (s *scanner) typeOf(expr ast.Expr) (typed, bool) {
+	return s.typeOfDepth(expr, 0)
+}
+
+func (s *scanner) typeOfDepth(expr ast.Expr, depth int) (typed, bool) {
+	if depth > 12 {
+		return typed{}, false
+	}
+	switch e := unparen(expr).(type) {
+	case *ast.Ident:
+		if t, ok := s.vars[e.Name]; ok {
+			return t, true
+		}
+		if v, ok := s.values[e.Name]; ok {
+			return s.typeOfDepth(v, depth+1)
+		}
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return typed{s.file, e.Type}, true
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return s.typeOfDepth(e.X, depth+1)
+		}
+	case *ast.StarExpr:
+		return s.typeOfDepth(e.X, depth+1)
+	case *ast.CallExpr:
+		return s.callType(e, depth)
+	case *ast.SelectorExpr:
+		return s.fieldType(e, depth)
+	case *ast.IndexExpr:
+		t, ok := s.typeOfDepth(e.X, depth+1)
+		if !ok {
+			return typed{}, false
+		}
+		switch tt := derefExpr(t.expr).(type) {
+		case *ast.ArrayType:
+			return typed{t.file, tt.Elt}, true
+		case *ast.MapType:
+			return typed{t.file, tt.Value}, true
+		}
+	}
+	return typed{}, false
+}
+
+func (s *scanner) callType(call *ast.CallExpr, depth int) (typed, bool) {
+	if id, ok := call.Fun.(*ast.Ident); ok {
+		switch id.Name {
+		case "new", "make":
+			if len(call.Args) > 0 {
+				return typed{s.file, call.Args[0]}, true
+			}
+		case "append":
+			if len(call.Args) > 0 {
+				return s.typeOfDepth(call.Args[0], depth+1)
+			}
+		}
+	}
+	if len(call.Args) == 1 {
+		if _, ok := call.Fun.(*ast.ArrayType); ok {
+			return typed{s.file, call.Fun}, true
+		}
+		if td := s.a.lookupType(s.file, call.Fun); td != nil {
+			return typed{s.file, call.Fun}, true
+		}
+	}
+	fd := s.a.lookupFunc(s.file, call.Fun)
+	if fd == nil || fd.Decl.Type.Results == nil || len(fd.Decl.Type.Results.List) == 0 {
+		return typed{}, false
+	}
+	return typed{fd.File, fd.Decl.Type.Results.List[0].Type}, true
+}
+
+// fieldType resolves x.Field through the struct type of x.
+func (s *scanner) fieldType(sel *ast.SelectorExpr, depth int) (typed, bool) {
+	t, ok := s.typeOfDepth(sel.X, depth+1)
+	if !ok {
+		return typed{}, false
+	}
+	td := s.a.lookupType(t.file, derefExpr(t.expr))
+	if td == nil {
+		return typed{}, false
+	}
+	st, ok := td.Spec.Type.(*ast.StructType)
+	if !ok {
+		return typed{}, false
+	}
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == sel.Sel.Name {
+				return typed{td.File, field.Type}, true
+			}
+		}
+	}
+	return typed{}, false
+}
+
+// schemaOf returns the schema of a value written as a response body.
+func (s *scanner) schemaOf(value ast.Expr) *model.Schema {
+	if lit, ok := unparen(value).(*ast.CompositeLit); ok {
+		if sel, ok := lit.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "H" {
+			// gin.H and echo-style map literals.
+			return &model.Schema{Type: "object"}
+		}
+	}
+	t, ok := s.typeOf(value)
+	if !ok {
+		return nil
+	}
+	schema := s.a.schemaForType(t.file, derefExpr(t.expr))
+	schema.Nullable = false
+	return schema
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// responseList converts the scanned responses to model responses sorted
+// by status code.
+func (h *handlerFacts) responseList() []*model.Response {
+	codes := make([]int, 0, len(h.responses))
+	for code := range h.responses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	out := make([]*model.Response, 0, len(codes))
+	for _, code := range codes {
+		r := h.responses[code]
+		resp := &model.Response{
+			StatusCode:  strconv.Itoa(code),
+			Description: http.StatusText(code),
+			ContentType: r.contentType,
+			Schema:      r.schema,
+		}
+		if resp.Schema != nil && resp.ContentType == "" {
+			resp.ContentType = "application/json"
+		}
+		if len(r.headers) > 0 {
+			resp.Headers = map[string]string{}
+			for _, name := range r.headers {
+				resp.Headers[name] = ""
+			}
+		}
+		out = append(out, resp)
+	}
+	return out
+}
@@ -0,0 +1,178 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Directives the analysis reads from comments.
+const (
+	embedDirective    = "//go:embed"
+	linknameDirective = "//go:linkname"
+)
+
+// limitations lists the constructs of pkg the analysis does not see
+// through: cgo files and the C sources built with them, assembly and
+// other foreign sources, functions declared without a Go body, and
+// symbols linked with //go:linkname. Each is listed once, in source
+// order, so that readers know which parts of the package the document
+// cannot describe.
+func (a *analyzer) limitations(pkg *Package) []*model.Limitation {
+	var list []*model.Limitation
+	add := func(construct string, pos *model.Position, format string, args ...any) {
+		list = append(list, &model.Limitation{Construct: construct, Detail: fmt.Sprintf(format, args...), Pos: pos})
+	}
+
+	var asm, c, syso []string
+	for _, name := range pkg.Foreign {
+		switch path.Ext(name) {
+		case ".s", ".S", ".sx":
+			asm = append(asm, path.Base(name))
+		case ".syso":
+			syso = append(syso, path.Base(name))
+		default:
+			c = append(c, path.Base(name))
+		}
+	}
+	linked := map[string]string{}
+	for _, f := range pkg.Files {
+		for _, imp := range f.AST.Imports {
+			if imp.Path.Value == `"C"` {
+				add(model.LimitationCgo, a.prog.Position(imp.Pos()),
+					"%s uses cgo; the C declarations of its preamble are not analyzed and C types are documented as opaque values", path.Base(f.Name))
+			}
+		}
+		for _, group := range f.AST.Comments {
+			for _, cm := range group.List {
+				fields := strings.Fields(cm.Text)
+				if len(fields) == 0 || fields[0] != linknameDirective || len(fields) < 2 {
+					continue
+				}
+				target := "a symbol of another package"
+				if len(fields) > 2 {
+					target = fields[2]
+				}
+				linked[fields[1]] = target
+				add(model.LimitationLinkname, a.prog.Position(cm.Pos()),
+					"%s is linked to %s with //go:linkname; the analysis does not follow the link", fields[1], target)
+			}
+		}
+	}
+	if len(c) > 0 {
+		add(model.LimitationCgo, nil, "C sources %s are built with cgo and not analyzed", strings.Join(c, ", "))
+	}
+	if len(asm) > 0 {
+		add(model.LimitationAssembly, nil, "assembly sources %s are not analyzed", strings.Join(asm, ", "))
+	}
+	if len(syso) > 0 {
+		add(model.LimitationSyso, nil, "object files %s are linked into the package and not analyzed", strings.Join(syso, ", "))
+	}
+
+	for _, f := range pkg.Files {
+		for _, decl := range f.AST.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body != nil {
+				continue
+			}
+			name := fd.Name.Name
+			if fd.Recv != nil && len(fd.Recv.List) > 0 {
+				name = recvTypeName(fd.Recv.List[0].Type) + "." + name
+			}
+			switch {
+			case linked[fd.Name.Name] != "":
+				// Reported with its directive.
+			case len(asm) > 0:
+				add(model.LimitationAssembly, a.prog.Position(fd.Pos()), "%s is implemented in assembly; its body is not analyzed", name)
+			default:
+				add(model.LimitationNoBody, a.prog.Position(fd.Pos()), "%s is declared without a body; its implementation is not analyzed", name)
+			}
+		}
+	}
+	return list
+}
+
+// assets lists the variables of pkg holding files embedded with
+// //go:embed directives.
+func (a *analyzer) assets(pkg *Package) []*model.Asset {
+	var list []*model.Asset
+	for _, f := range pkg.Files {
+		for _, decl := range f.AST.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				doc := vs.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+				patterns := embedPatterns(doc)
+				if len(patterns) == 0 || len(vs.Names) != 1 {
+					continue
+				}
+				asset := &model.Asset{
+					Var:      vs.Names[0].Name,
+					Patterns: patterns,
+					Source:   a.prog.Position(vs.Pos()),
+				}
+				if vs.Type != nil {
+					asset.Type = exprString(vs.Type)
+				}
+				if text := strings.TrimSpace(doc.Text()); text != "" {
+					asset.Description = text
+				}
+				list = append(list, asset)
+			}
+		}
+	}
+	return list
+}
+
+// embedPatterns returns the patterns of the //go:embed directives of a
+// doc comment. Patterns are separated by spaces and may be quoted.
+func embedPatterns(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var patterns []string
+	for _, c := range doc.List {
+		if !strings.HasPrefix(c.Text, embedDirective+" ") && !strings.HasPrefix(c.Text, embedDirective+"\t") {
+			continue
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(c.Text, embedDirective))
+		for rest != "" {
+			var pattern string
+			if q := rest[0]; q == '"' || q == '`' {
+				end := strings.IndexByte(rest[1:], q)
+				if end < 0 {
+					break
+				}
+				pattern, _ = strconv.Unquote(rest[:end+2])
+				rest = rest[end+2:]
+			} else {
+				pattern, rest, _ = strings.Cut(rest, " ")
+			}
+			if pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+			rest = strings.TrimSpace(rest)
+		}
+	}
+	return patterns
+}
+
+// cType documents a type of cgo's C pseudo-package, whose declarations
+// are in C, as an opaque value.
+func (a *analyzer) cType(t *ast.SelectorExpr) *model.Schema {
+	name := "C." + t.Sel.Name
+	a.diag("CGO_OPAQUE_TYPE", model.SeverityInfo, t.Pos(),
+		fmt.Sprintf("%s is declared in C and documented as an opaque value", name))
+	return &model.Schema{GoType: name, Description: "Opaque C type " + name + "."}
+}
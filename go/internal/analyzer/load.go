@@ -0,0 +1,596 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Options controls which files are analyzed.
+type Options struct {
+	// Recursive descends into subdirectories of the root.
+	Recursive bool
+	// Include restricts analysis to files matching one of these glob
+	// patterns. Patterns are matched against the base name and the
+	// slash-separated path relative to the root.
+	Include []string
+	// Exclude skips files matching one of these glob patterns.
+	Exclude []string
+	// Shard restricts the analysis to one shard of the program; see
+	// AnalyzeShard.
+	Shard Shard
+	// FS is read instead of the operating system's file system when set,
+	// for example to analyze a git revision without checking it out.
+	FS FileSystem
+	// Recovered, when set, is called with every panic the analysis
+	// recovers from; see Panic.
+	Recovered func(*Panic)
+	// Naming configures how component schemas are named.
+	Naming SchemaNaming
+}
+
+// FileSystem is the file system sources are read from. Names are
+// operating system paths, as given to the os package.
+type FileSystem interface {
+	Stat(name string) (fs.FileInfo, error)
+	// ReadDir returns the entries of a directory sorted by name.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	ReadFile(name string) ([]byte, error)
+}
+
+// osFS is the operating system's file system.
+type osFS struct{}
+
+func (osFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFS) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+
+func (opts Options) fs() FileSystem {
+	if opts.FS != nil {
+		return opts.FS
+	}
+	return osFS{}
+}
+
+// Program is a set of parsed packages rooted at a directory.
+type Program struct {
+	Fset *token.FileSet
+	// Root is the directory analysis started from.
+	Root string
+	// Module is the module path declared in the nearest go.mod.
+	Module string
+	// ModuleDir is the directory containing that go.mod.
+	ModuleDir string
+	// GoVersion is the go directive of that go.mod, as "1.21".
+	GoVersion string
+	// Packages are sorted by import path.
+	Packages []*Package
+	// Diagnostics holds parse errors; affected files are still analyzed
+	// with whatever the parser recovered.
+	Diagnostics []*model.Diagnostic
+	// Shard is the shard of the program that was loaded, and Plan a
+	// digest of how the program was divided into shards.
+	Shard Shard
+	Plan  string
+
+	fs        FileSystem
+	recovered func(*Panic)
+	naming    SchemaNaming
+}
+
+// Package is one parsed Go package.
+type Package struct {
+	Name       string
+	ImportPath string
+	// Dir is the package directory relative to the program root.
+	Dir   string
+	Files []*File
+	// Foreign lists the sources of the package directory that are not
+	// Go, such as assembly and C files, relative to the program root.
+	Foreign []string
+}
+
+// File is one parsed Go source file.
+type File struct {
+	// Name is the file path relative to the program root.
+	Name string
+	AST  *ast.File
+	Pkg  *Package
+	// imports maps the local name of each import to its path.
+	imports map[string]string
+}
+
+// ImportPath returns the path imported under the given local name.
+func (f *File) ImportPath(local string) (string, bool) {
+	p, ok := f.imports[local]
+	return p, ok
+}
+
+// SplitPattern splits a path argument such as "./..." into the directory
+// it names and whether it asks for recursive analysis.
+func SplitPattern(root string) (dir string, recursive bool) {
+	if strings.HasSuffix(root, "/...") || root == "..." {
+		root = strings.TrimSuffix(strings.TrimSuffix(root, "..."), "/")
+		if root == "" {
+			root = "."
+		}
+		return root, true
+	}
+	return root, false
+}
+
+// Load parses the Go packages under root. A root ending in "/..." is
+// analyzed recursively regardless of opts.Recursive.
+func Load(root string, opts Options) (*Program, error) {
+	root, recursive := SplitPattern(root)
+	opts.Recursive = opts.Recursive || recursive
+	info, err := opts.fs().Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	prog := &Program{Fset: token.NewFileSet(), Root: abs, fs: opts.fs(), recovered: opts.Recovered, naming: opts.Naming}
+	prog.Module, prog.ModuleDir = findModule(opts.fs(), abs)
+	prog.GoVersion = goDirective(opts.fs(), prog.ModuleDir)
+
+	dirs, err := sourceDirs(abs, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Shard.Count > 0 {
+		prog.Shard = opts.Shard
+		if dirs, prog.Plan, err = prog.planShard(dirs, opts); err != nil {
+			return nil, err
+		}
+	}
+	for _, dir := range dirs {
+		if err := prog.loadDir(dir, opts); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(prog.Packages, func(i, j int) bool {
+		return prog.Packages[i].ImportPath < prog.Packages[j].ImportPath
+	})
+	return prog, nil
+}
+
+// Fingerprint returns a hash of everything Load would read under root:
+// the selected file names and contents and the module path. Analyses of
+// sources with equal fingerprints are equal, so it keys cached results.
+func Fingerprint(root string, opts Options) (string, error) {
+	root, recursive := SplitPattern(root)
+	opts.Recursive = opts.Recursive || recursive
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	dirs, err := sourceDirs(abs, opts)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	module, moduleDir := findModule(opts.fs(), abs)
+	fmt.Fprintf(h, "module %s\n", module)
+	fmt.Fprintf(h, "go %s\n", goDirective(opts.fs(), moduleDir))
+	fmt.Fprintf(h, "naming %s\n", opts.Naming)
+	for _, dir := range dirs {
+		rel, _ := filepath.Rel(abs, dir)
+		names, err := sourceFiles(dir, filepath.ToSlash(rel), opts)
+		if err != nil {
+			return "", err
+		}
+		for _, name := range names {
+			src, err := opts.fs().ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(h, "file %s %d\n", path.Join(filepath.ToSlash(rel), name), len(src))
+			h.Write(src)
+		}
+		// Only the presence of other sources changes the analysis.
+		foreign, err := foreignFiles(dir, filepath.ToSlash(rel), opts)
+		if err != nil {
+			return "", err
+		}
+		for _, name := range foreign {
+			fmt.Fprintf(h, "foreign %s\n", path.Join(filepath.ToSlash(rel), name))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sourceDirs returns the directories Load reads: root, and with
+// opts.Recursive every directory below it that is not skipped.
+func sourceDirs(abs string, opts Options) ([]string, error) {
+	info, err := opts.fs().Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", abs)
+	}
+	dirs := []string{abs}
+	if !opts.Recursive {
+		return dirs, nil
+	}
+	for i := 0; i < len(dirs); i++ {
+		entries, err := opts.fs().ReadDir(dirs[i])
+		if err != nil {
+			return nil, err
+		}
+		var sub []string
+		for _, e := range entries {
+			if e.IsDir() && !SkipDir(e.Name()) {
+				sub = append(sub, filepath.Join(dirs[i], e.Name()))
+			}
+		}
+		// Keep the depth-first order of a directory walk.
+		dirs = append(dirs[:i+1], append(sub, dirs[i+1:]...)...)
+	}
+	return dirs, nil
+}
+
+// sourceFiles returns the names of the Go files of dir selected by opts;
+// rel is dir relative to the program root.
+func sourceFiles(dir, rel string, opts Options) ([]string, error) {
+	entries, err := opts.fs().ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if !selected(name, path.Join(rel, name), opts) {
+			continue
+		}
+		src, err := opts.fs().ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if buildable(name, src) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// buildable reports whether the go command builds the file name with
+// contents src on the host platform: its _GOOS and _GOARCH name suffixes
+// and its //go:build line, or its legacy // +build lines, must hold.
+// Every Go release tag holds, so that files guarded by a newer release
+// are still analyzed, and cgo is assumed to be enabled.
+func buildable(name string, src []byte) bool {
+	if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") || !goodOSArchFile(name) {
+		return false
+	}
+	f, _ := parser.ParseFile(token.NewFileSet(), name, src, parser.PackageClauseOnly|parser.ParseComments)
+	if f == nil {
+		// Leave the syntax error to the parser of the loader.
+		return true
+	}
+	var plusBuild []constraint.Expr
+	for _, group := range f.Comments {
+		if group.Pos() > f.Package {
+			break
+		}
+		for _, c := range group.List {
+			switch {
+			case constraint.IsGoBuild(c.Text):
+				expr, err := constraint.Parse(c.Text)
+				return err != nil || expr.Eval(hasTag)
+			case constraint.IsPlusBuild(c.Text):
+				if expr, err := constraint.Parse(c.Text); err == nil {
+					plusBuild = append(plusBuild, expr)
+				}
+			}
+		}
+	}
+	for _, expr := range plusBuild {
+		if !expr.Eval(hasTag) {
+			return false
+		}
+	}
+	return true
+}
+
+// goodOSArchFile reports whether the _GOOS, _GOARCH or _GOOS_GOARCH
+// suffix of a file name, if any, matches the host platform.
+func goodOSArchFile(name string) bool {
+	name = strings.TrimSuffix(name, ".go")
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return true
+	}
+	// Before the first underscore is the name proper, as in linux.go.
+	l := strings.Split(name[i+1:], "_")
+	if n := len(l); n > 0 && l[n-1] == "test" {
+		l = l[:n-1]
+	}
+	n := len(l)
+	if n >= 2 && knownOS[l[n-2]] && knownArch[l[n-1]] {
+		return hasTag(l[n-2]) && hasTag(l[n-1])
+	}
+	if n >= 1 && (knownOS[l[n-1]] || knownArch[l[n-1]]) {
+		return hasTag(l[n-1])
+	}
+	return true
+}
+
+// hasTag reports whether a build tag holds on the host platform.
+func hasTag(tag string) bool {
+	goos := build.Default.GOOS
+	switch {
+	case tag == goos, tag == build.Default.GOARCH, tag == "gc", tag == "cgo":
+		return true
+	case tag == "unix":
+		return unixOS[goos]
+	case tag == "linux" && goos == "android", tag == "solaris" && goos == "illumos", tag == "darwin" && goos == "ios":
+		return true
+	case strings.HasPrefix(tag, "go1."):
+		_, ok := goMinor(tag)
+		return ok
+	}
+	return containsString(build.Default.BuildTags, tag)
+}
+
+// knownOS, unixOS and knownArch mirror the lists of go/build.
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true,
+	"openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+}
+
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+	"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+	"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true, "riscv": true,
+	"riscv64": true, "s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
+
+// foreignExts are the extensions of the non-Go sources the go command
+// builds into a package.
+var foreignExts = map[string]bool{
+	".s": true, ".S": true, ".sx": true,
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".cxx": true, ".hh": true, ".hpp": true, ".hxx": true, ".m": true,
+	".f": true, ".F": true, ".for": true, ".f90": true,
+	".swig": true, ".swigcxx": true, ".syso": true,
+}
+
+// foreignFiles returns the names of the selected non-Go sources in dir.
+func foreignFiles(dir, rel string, opts Options) ([]string, error) {
+	entries, err := opts.fs().ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && foreignExts[path.Ext(name)] && selected(name, path.Join(rel, name), opts) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// SkipDir reports whether a directory is left out of a recursive walk, as
+// the go command leaves out vendor, testdata and hidden directories.
+func SkipDir(name string) bool {
+	return name == "vendor" || name == "testdata" || name == "node_modules" ||
+		strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+func (prog *Program) loadDir(dir string, opts Options) error {
+	rel := prog.rel(dir)
+	files, err := sourceFiles(dir, rel, opts)
+	if err != nil {
+		return err
+	}
+	foreign, err := foreignFiles(dir, rel, opts)
+	if err != nil {
+		return err
+	}
+	byName := map[string]*Package{}
+	for _, name := range files {
+		relFile := path.Join(rel, name)
+		src, err := opts.fs().ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		f, err := parser.ParseFile(prog.Fset, relFile, src, parser.ParseComments)
+		if err != nil {
+			prog.Diagnostics = append(prog.Diagnostics, &model.Diagnostic{
+				Code:     "PARSE_ERROR",
+				Severity: model.SeverityError,
+				Message:  err.Error(),
+				Pos:      &model.Position{File: relFile, Line: 1},
+			})
+			if f == nil {
+				continue
+			}
+		}
+		pkg := byName[f.Name.Name]
+		if pkg == nil {
+			pkg = &Package{Name: f.Name.Name, ImportPath: prog.importPath(rel), Dir: rel}
+			for _, name := range foreign {
+				pkg.Foreign = append(pkg.Foreign, path.Join(rel, name))
+			}
+			byName[f.Name.Name] = pkg
+		}
+		pkg.Files = append(pkg.Files, newFile(relFile, f, pkg))
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg := byName[name]
+		if len(names) > 1 && strings.HasSuffix(name, "_test") {
+			continue
+		}
+		prog.Packages = append(prog.Packages, pkg)
+	}
+	return nil
+}
+
+func newFile(name string, f *ast.File, pkg *Package) *File {
+	file := &File{Name: name, AST: f, Pkg: pkg, imports: map[string]string{}}
+	for _, imp := range f.Imports {
+		p := strings.Trim(imp.Path.Value, "\"`")
+		local := path.Base(p)
+		if isVersionSuffix(local) {
+			local = path.Base(path.Dir(p))
+		}
+		if imp.Name != nil {
+			local = imp.Name.Name
+		}
+		file.imports[local] = p
+	}
+	return file
+}
+
+// isVersionSuffix reports whether an import path element is a major
+// version suffix such as "v5", which Go drops from the package name.
+func isVersionSuffix(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func selected(base, rel string, opts Options) bool {
+	for _, pat := range opts.Exclude {
+		if match(pat, base, rel) {
+			return false
+		}
+	}
+	if len(opts.Include) == 0 {
+		return true
+	}
+	for _, pat := range opts.Include {
+		if match(pat, base, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pat, base, rel string) bool {
+	if ok, _ := path.Match(pat, base); ok {
+		return true
+	}
+	if ok, _ := path.Match(pat, rel); ok {
+		return true
+	}
+	// A pattern naming a directory matches everything below it.
+	dir := strings.TrimSuffix(pat, "/")
+	return strings.HasPrefix(rel, dir+"/")
+}
+
+func (prog *Program) rel(p string) string {
+	r, err := filepath.Rel(prog.Root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(r)
+}
+
+func (prog *Program) importPath(rel string) string {
+	if prog.Module == "" {
+		return rel
+	}
+	dir := filepath.Join(prog.Root, filepath.FromSlash(rel))
+	r, err := filepath.Rel(prog.ModuleDir, dir)
+	if err != nil || r == "." {
+		return prog.Module
+	}
+	return prog.Module + "/" + filepath.ToSlash(r)
+}
+
+// findModule walks up from dir looking for go.mod and returns the declared
+// module path and the directory it was found in.
+func findModule(fsys FileSystem, dir string) (string, string) {
+	for d := dir; ; {
+		data, err := fsys.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			sc := bufio.NewScanner(bytes.NewReader(data))
+			for sc.Scan() {
+				line := strings.TrimSpace(sc.Text())
+				if strings.HasPrefix(line, "module ") {
+					return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), "\""), d
+				}
+			}
+			return "", d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", ""
+		}
+		d = parent
+	}
+}
+
+// goDirective returns the language version of the go directive of the
+// go.mod in dir, without its patch release.
+func goDirective(fsys FileSystem, dir string) string {
+	if dir == "" {
+		return ""
+	}
+	data, err := fsys.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if fields := strings.Fields(sc.Text()); len(fields) == 2 && fields[0] == "go" {
+			if minor, ok := goMinor(fields[1]); ok {
+				return goRelease(minor)
+			}
+		}
+	}
+	return ""
+}
+
+// Position converts a token position to a model position.
+func (prog *Program) Position(pos token.Pos) *model.Position {
+	if !pos.IsValid() {
+		return nil
+	}
+	p := prog.Fset.Position(pos)
+	return &model.Position{File: p.Filename, Line: p.Line, Column: p.Column}
+}
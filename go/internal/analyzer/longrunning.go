@@ -0,0 +1,200 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// placeholder stands for the parts of a path template only known at run
+// time, such as the operation ID in "/operations/" + op.ID.
+const placeholder = "{}"
+
+// stateProperties are the property names preferred for the state of an
+// operation resource.
+var stateProperties = []string{"status", "state", "phase"}
+
+// template renders a string expression as a path template, following
+// concatenations, fmt.Sprintf and path.Join calls and local variables.
+func (s *scanner) template(expr ast.Expr, depth int) string {
+	if v, ok := s.constString(expr); ok {
+		return v
+	}
+	if depth > 8 {
+		return placeholder
+	}
+	switch e := unparen(expr).(type) {
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			return s.template(e.X, depth+1) + s.template(e.Y, depth+1)
+		}
+	case *ast.Ident:
+		if v, ok := s.values[e.Name]; ok {
+			return s.template(v, depth+1)
+		}
+	case *ast.CallExpr:
+		p, name, ok := s.a.qualify(s.file, e.Fun)
+		if !ok || len(e.Args) == 0 {
+			break
+		}
+		switch p + "." + name {
+		case "fmt.Sprintf":
+			if format, ok := s.constString(e.Args[0]); ok {
+				return formatTemplate(format)
+			}
+		case "path.Join":
+			parts := make([]string, len(e.Args))
+			for i, arg := range e.Args {
+				parts[i] = strings.Trim(s.template(arg, depth+1), "/")
+			}
+			return "/" + strings.Join(parts, "/")
+		}
+	}
+	return placeholder
+}
+
+// formatTemplate replaces the verbs of a fmt format with placeholders.
+func formatTemplate(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			b.WriteByte('%')
+			i++
+			continue
+		}
+		// Skip flags, width, precision and argument indexes up to the verb.
+		for i+1 < len(format) && strings.IndexByte("+-# 0123456789.[]*", format[i+1]) >= 0 {
+			i++
+		}
+		i++
+		b.WriteString(placeholder)
+	}
+	return b.String()
+}
+
+// linkOperations links every endpoint starting a long-running operation to
+// the GET endpoint its Location header points to, and describes the
+// operation resource that endpoint returns.
+func linkOperations(doc *model.Document) {
+	for _, ep := range doc.Endpoints {
+		lr := ep.LongRunning
+		if lr == nil {
+			continue
+		}
+		status := statusEndpoint(doc, lr.Location)
+		if status == nil {
+			continue
+		}
+		lr.StatusEndpoint = status.Key()
+		lr.Location = status.Path
+		for _, r := range status.Responses {
+			if !strings.HasPrefix(r.StatusCode, "2") || r.Schema == nil {
+				continue
+			}
+			if name := r.Schema.RefName(); name != "" {
+				lr.Schema = model.RefTo(name)
+				lr.StateProperty, lr.States = operationStates(doc, doc.Schemas[name])
+			}
+			break
+		}
+	}
+}
+
+// statusEndpoint returns the GET endpoint whose path matches a Location
+// template, preferring the one with the fewest path parameters.
+func statusEndpoint(doc *model.Document, location string) *model.Endpoint {
+	segs := templateSegments(location)
+	var best *model.Endpoint
+	bestParams := 0
+	for _, ep := range doc.Endpoints {
+		if ep.Method != "GET" {
+			continue
+		}
+		if n, ok := matchSegments(segs, strings.Split(strings.Trim(ep.Path, "/"), "/")); ok && (best == nil || n < bestParams) {
+			best, bestParams = ep, n
+		}
+	}
+	return best
+}
+
+// templateSegments splits the path of a Location template, dropping the
+// scheme, host, query and fragment of absolute URLs, and a base URL only
+// known at run time as in baseURL + "/jobs/" + id.
+func templateSegments(location string) []string {
+	host := strings.HasPrefix(location, placeholder)
+	if i := strings.Index(location, "://"); i >= 0 {
+		location, host = location[i+3:], true
+	}
+	if host && !strings.HasPrefix(location, "/") {
+		if j := strings.IndexByte(location, '/'); j >= 0 {
+			location = location[j:]
+		} else {
+			location = ""
+		}
+	}
+	if i := strings.IndexAny(location, "?#"); i >= 0 {
+		location = location[:i]
+	}
+	return strings.Split(strings.Trim(location, "/"), "/")
+}
+
+// matchSegments reports whether template segments match the segments of
+// an endpoint path, and how many path parameters the match used. A path
+// parameter matches any segment; a literal segment only itself.
+func matchSegments(template, path []string) (int, bool) {
+	if len(template) != len(path) {
+		return 0, false
+	}
+	params := 0
+	for i, seg := range path {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if template[i] == "" {
+				return 0, false
+			}
+			params++
+			continue
+		}
+		if template[i] != seg {
+			return 0, false
+		}
+	}
+	return params, true
+}
+
+// operationStates returns the property of an operation resource holding
+// its state and the states it enumerates.
+func operationStates(doc *model.Document, schema *model.Schema) (string, []string) {
+	if schema == nil {
+		return "", nil
+	}
+	enums := map[string][]any{}
+	var names []string
+	for _, name := range schema.PropertyNames() {
+		if prop := doc.Resolve(schema.Properties[name]); prop != nil && len(prop.Enum) > 0 {
+			enums[name] = prop.Enum
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	name := names[0]
+	for _, preferred := range stateProperties {
+		if _, ok := enums[preferred]; ok {
+			name = preferred
+			break
+		}
+	}
+	states := make([]string, len(enums[name]))
+	for i, v := range enums[name] {
+		states[i] = fmt.Sprint(v)
+	}
+	return name, states
+}
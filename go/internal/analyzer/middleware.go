@@ -0,0 +1,550 @@
+package analyzer
+
+import (
+	"go/ast"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Security scheme names registered for detected authentication.
+const (
+	schemeBearer = "bearerAuth"
+	schemeBasic  = "basicAuth"
+	schemeAPIKey = "apiKeyAuth"
+	schemeCookie = "cookieAuth"
+	schemeOAuth2 = "oauth2"
+)
+
+// applyMiddleware records the middleware chain of an endpoint and the
+// rate limits and security schemes it implies, and describes each
+// middleware in the catalog of the document.
+func (a *analyzer) applyMiddleware(ep *model.Endpoint, mws []*mwUse) {
+	for _, mw := range mws {
+		ep.Middleware = append(ep.Middleware, mw.Name)
+		rl := a.rateLimit(mw)
+		if rl != nil && ep.RateLimit == nil {
+			ep.RateLimit = rl
+		}
+		name := a.securityScheme(mw)
+		if name != "" && !containsString(ep.Security, name) {
+			ep.Security = append(ep.Security, name)
+		}
+		a.catalogMiddleware(mw, name, rl)
+	}
+}
+
+// catalogMiddleware adds mw to the middleware catalog of the document
+// unless a middleware of the same name is already there. Function
+// literals have no name to be looked up by and are left out.
+func (a *analyzer) catalogMiddleware(mw *mwUse, scheme string, rl *model.RateLimit) {
+	if _, ok := a.doc.Middleware[mw.Name]; ok {
+		return
+	}
+	if _, ok := unparen(mw.Expr).(*ast.FuncLit); ok {
+		return
+	}
+	m := &model.Middleware{Security: scheme}
+	if rl != nil {
+		limit := *rl
+		m.RateLimit = &limit
+	}
+	if fd := a.mwFunc(mw); fd != nil {
+		m.Kind = a.mwKind(fd)
+		m.Func = fd.Key()
+		if fd.Decl.Doc != nil {
+			m.Description = strings.TrimSpace(fd.Decl.Doc.Text())
+		}
+		m.Source = a.prog.Position(fd.Decl.Pos())
+		a.mwEffects(fd, m)
+	}
+	a.doc.Middleware[mw.Name] = m
+}
+
+// mwKind reports the framework whose middleware signature fd has, or
+// returns from a factory such as RateLimit(100).
+func (a *analyzer) mwKind(fd *funcDecl) string {
+	ft := fd.Decl.Type
+	if ft.Results != nil && len(ft.Results.List) == 1 {
+		switch t := ft.Results.List[0].Type.(type) {
+		case *ast.FuncType:
+			if kind := a.mwSignature(fd.File, t); kind != "" {
+				return kind
+			}
+		default:
+			switch {
+			case a.isFrameworkType(fd.File, t, frameworkGin, "HandlerFunc"):
+				return frameworkGin
+			case a.isFrameworkType(fd.File, t, frameworkEcho, "MiddlewareFunc"):
+				return frameworkEcho
+			}
+		}
+	}
+	return a.mwSignature(fd.File, ft)
+}
+
+// mwSignature reports the framework of a middleware function type:
+// func(http.Handler) http.Handler, func(*gin.Context) or
+// func(echo.HandlerFunc) echo.HandlerFunc.
+func (a *analyzer) mwSignature(f *File, ft *ast.FuncType) string {
+	if ft.Params.NumFields() != 1 {
+		return ""
+	}
+	param := ft.Params.List[0].Type
+	var result ast.Expr
+	if ft.Results.NumFields() == 1 {
+		result = ft.Results.List[0].Type
+	}
+	switch {
+	case a.isFrameworkType(f, param, frameworkNetHTTP, "Handler") && result != nil &&
+		(a.isFrameworkType(f, result, frameworkNetHTTP, "Handler") || a.isFrameworkType(f, result, frameworkNetHTTP, "HandlerFunc")):
+		return frameworkNetHTTP
+	case a.isFrameworkType(f, param, frameworkGin, "Context") && result == nil:
+		return frameworkGin
+	case a.isFrameworkType(f, param, frameworkEcho, "HandlerFunc") && result != nil && a.isFrameworkType(f, result, frameworkEcho, "HandlerFunc"):
+		return frameworkEcho
+	}
+	return ""
+}
+
+// isFrameworkType reports whether expr, or the type it points to, is the
+// named type of a router framework.
+func (a *analyzer) isFrameworkType(f *File, expr ast.Expr, framework, name string) bool {
+	sel, ok := derefExpr(expr).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	p, n, ok := a.qualify(f, sel)
+	return ok && n == name && frameworkOf(p) == framework
+}
+
+// mwEffects records what the body of a middleware does to the requests
+// it sees: the response headers it sets, the statuses it answers with
+// and the values it adds to the request context.
+func (a *analyzer) mwEffects(fd *funcDecl, m *model.Middleware) {
+	if fd.Decl.Body == nil {
+		return
+	}
+	ev := &evaluator{a: a, file: fd.File}
+	statuses := map[int]bool{}
+	ast.Inspect(fd.Decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// return echo.ErrUnauthorized
+			if p, name, ok := a.qualify(fd.File, n); ok && frameworkOf(p) == frameworkEcho {
+				if code := echoErrorStatus(name); code != 0 {
+					statuses[code] = true
+				}
+			}
+		case *ast.CallExpr:
+			a.mwCall(ev, n, m, statuses)
+		}
+		return true
+	})
+	for code := range statuses {
+		m.Statuses = append(m.Statuses, code)
+	}
+	sort.Ints(m.Statuses)
+}
+
+// mwCall records the effect of a call in the body of a middleware.
+func (a *analyzer) mwCall(ev *evaluator, call *ast.CallExpr, m *model.Middleware, statuses map[int]bool) {
+	status := func(expr ast.Expr) {
+		if n, ok := ev.Int(expr); ok && n >= 100 && n <= 599 {
+			statuses[int(n)] = true
+		}
+	}
+	p, qname, qualified := a.qualify(ev.file, call.Fun)
+	switch {
+	case qualified && p == "net/http":
+		switch {
+		case qname == "Error" && len(call.Args) == 3:
+			status(call.Args[2])
+		case qname == "Redirect" && len(call.Args) == 4:
+			status(call.Args[3])
+		case qname == "NotFound":
+			statuses[http.StatusNotFound] = true
+		}
+		return
+	case qualified && p == "context" && qname == "WithValue" && len(call.Args) == 3:
+		m.ContextValues = appendUnique(m.ContextValues, contextKey(ev, call.Args[1]))
+		return
+	case qualified && frameworkOf(p) == frameworkEcho && qname == "NewHTTPError" && len(call.Args) >= 1:
+		status(call.Args[0])
+		return
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	switch sel.Sel.Name {
+	case "WriteHeader", "AbortWithStatus", "NoContent", "SendStatus":
+		if len(call.Args) == 1 {
+			status(call.Args[0])
+		}
+	case "AbortWithStatusJSON", "AbortWithError", "JSON", "String", "XML", "HTML", "Blob", "Data", "Redirect":
+		if len(call.Args) >= 2 {
+			status(call.Args[0])
+		}
+	case "Header", "Set", "Add":
+		if len(call.Args) != 2 {
+			return
+		}
+		header, isCall := sel.X.(*ast.CallExpr)
+		switch {
+		case sel.Sel.Name == "Header" || isCall && callName(header) == "Header" && len(header.Args) == 0:
+			// gin's c.Header(name, value), or w.Header().Set(name, value).
+			// r.Header.Set modifies the request instead.
+			name, ok := ev.String(call.Args[0])
+			if !ok {
+				return
+			}
+			if m.Headers == nil {
+				m.Headers = map[string]string{}
+			}
+			value, _ := ev.String(call.Args[1])
+			m.Headers[http.CanonicalHeaderKey(name)] = value
+		case sel.Sel.Name == "Set":
+			// gin's and echo's c.Set(key, value).
+			if _, ok := sel.X.(*ast.Ident); ok {
+				m.ContextValues = appendUnique(m.ContextValues, contextKey(ev, call.Args[0]))
+			}
+		}
+	}
+}
+
+// contextKey renders the key of a context value: its value when it is a
+// constant string, its expression otherwise, as userKey{}.
+func contextKey(ev *evaluator, expr ast.Expr) string {
+	if s, ok := ev.String(expr); ok {
+		return s
+	}
+	if lit, ok := expr.(*ast.CompositeLit); ok && len(lit.Elts) == 0 {
+		return exprString(lit.Type) + "{}"
+	}
+	return exprString(expr)
+}
+
+// echoErrorStatus returns the status of an echo sentinel error such as
+// ErrUnauthorized, or 0.
+func echoErrorStatus(name string) int {
+	if !strings.HasPrefix(name, "Err") {
+		return 0
+	}
+	for code := 400; code <= 599; code++ {
+		if text := http.StatusText(code); text != "" && name == "Err"+strings.ReplaceAll(text, " ", "") {
+			return code
+		}
+	}
+	return 0
+}
+
+func appendUnique(list []string, s string) []string {
+	if containsString(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// mwFunc returns the declaration implementing a middleware, for
+// middleware factories such as RateLimit(100) as well as plain functions.
+func (a *analyzer) mwFunc(mw *mwUse) *funcDecl {
+	expr := unparen(mw.Expr)
+	if call, ok := expr.(*ast.CallExpr); ok {
+		expr = call.Fun
+	}
+	return a.lookupFunc(mw.File, expr)
+}
+
+var rateLimitWords = []string{"ratelimit", "rate_limit", "limiter", "throttl", "httprate", "tollbooth", "quota"}
+
+// rateLimit detects the quota enforced by a rate limiting middleware,
+// from its arguments or, failing that, from the limiter it constructs.
+func (a *analyzer) rateLimit(mw *mwUse) *model.RateLimit {
+	lower := strings.ToLower(mw.Name)
+	isLimiter := false
+	for _, w := range rateLimitWords {
+		if strings.Contains(lower, w) {
+			isLimiter = true
+			break
+		}
+	}
+	if !isLimiter {
+		return nil
+	}
+	ev := &evaluator{a: a, file: mw.File, locals: mw.locals}
+	if rl := a.quota(ev, mw.Expr); rl != nil {
+		rl.Source = mw.Name
+		return rl
+	}
+	if fd := a.mwFunc(mw); fd != nil && fd.Decl.Body != nil {
+		var found *model.RateLimit
+		ast.Inspect(fd.Decl.Body, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			if call, ok := n.(*ast.CallExpr); ok {
+				found = a.quota(&evaluator{a: a, file: fd.File}, call)
+			}
+			return true
+		})
+		if found != nil {
+			found.Source = mw.Name
+			return found
+		}
+	}
+	return nil
+}
+
+// quota reads a request quota from a call such as
+// httprate.LimitByIP(100, time.Minute), rate.NewLimiter(rate.Every(time.Second), 5)
+// or tollbooth.NewLimiter(10, nil).
+func (a *analyzer) quota(ev *evaluator, expr ast.Expr) *model.RateLimit {
+	call, ok := unparen(expr).(*ast.CallExpr)
+	if !ok {
+		if id, ok := expr.(*ast.Ident); ok && ev.locals != nil {
+			if x, ok := ev.locals[id.Name]; ok {
+				return a.quota(ev, x)
+			}
+		}
+		return nil
+	}
+	p, name, _ := a.qualify(ev.file, call.Fun)
+	switch {
+	case p == "golang.org/x/time/rate" && name == "NewLimiter" && len(call.Args) == 2:
+		return a.rateLimitFromLimit(ev, call.Args[0])
+	case strings.HasSuffix(p, "/tollbooth") && name == "NewLimiter" && len(call.Args) >= 1:
+		if n, ok := ev.eval(call.Args[0]); ok {
+			return perDuration(toFloat(n), 1e9)
+		}
+		return nil
+	}
+
+	var requests float64
+	var window int64
+	haveRequests, haveWindow := false, false
+	for _, arg := range call.Args {
+		if inner, ok := unparen(arg).(*ast.CallExpr); ok {
+			if rl := a.quota(ev, inner); rl != nil {
+				return rl
+			}
+		}
+		if isDurationExpr(ev.file, arg) {
+			if d, ok := ev.Int(arg); ok && !haveWindow && d > 0 {
+				window, haveWindow = d, true
+			}
+			continue
+		}
+		if v, ok := ev.eval(arg); ok && !haveRequests {
+			if f := toFloat(v); f > 0 {
+				requests, haveRequests = f, true
+			}
+		}
+	}
+	if !haveRequests {
+		return nil
+	}
+	if !haveWindow {
+		// A bare number, as in echo's NewRateLimiterMemoryStore(20), is a
+		// rate per second.
+		window = 1e9
+	}
+	return perDuration(requests, window)
+}
+
+// rateLimitFromLimit converts a golang.org/x/time/rate Limit expression.
+func (a *analyzer) rateLimitFromLimit(ev *evaluator, expr ast.Expr) *model.RateLimit {
+	if call, ok := unparen(expr).(*ast.CallExpr); ok {
+		if p, name, ok := a.qualify(ev.file, call.Fun); ok && p == "golang.org/x/time/rate" && len(call.Args) == 1 {
+			switch name {
+			case "Every":
+				if d, ok := ev.Int(call.Args[0]); ok && d > 0 {
+					return perDuration(1, d)
+				}
+				return nil
+			case "Limit":
+				expr = call.Args[0]
+			}
+		}
+	}
+	if v, ok := ev.eval(expr); ok {
+		return perDuration(toFloat(v), 1e9)
+	}
+	return nil
+}
+
+var periods = []struct {
+	name string
+	ns   int64
+}{
+	{model.PeriodSecond, 1e9},
+	{model.PeriodMinute, 60e9},
+	{model.PeriodHour, 3600e9},
+	{model.PeriodDay, 86400e9},
+}
+
+// perDuration expresses "requests per window" in the smallest period that
+// is at least as long as the window.
+func perDuration(requests float64, window int64) *model.RateLimit {
+	if requests <= 0 || window <= 0 {
+		return nil
+	}
+	for _, p := range periods {
+		if window <= p.ns || p.name == model.PeriodDay {
+			n := int(math.Round(requests * float64(p.ns) / float64(window)))
+			if n < 1 {
+				n = 1
+			}
+			return &model.RateLimit{Requests: n, Period: p.name}
+		}
+	}
+	return nil
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// isDurationExpr reports whether expr mentions a time unit or conversion.
+func isDurationExpr(f *File, expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return !found
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if p, ok := f.ImportPath(x.Name); ok && p == "time" {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// securityScheme classifies an authentication middleware, registers the
+// corresponding scheme in the document and returns its name.
+func (a *analyzer) securityScheme(mw *mwUse) string {
+	lower := strings.ToLower(mw.Name)
+	body := a.mwFacts(mw)
+	var name string
+	var scheme *model.SecurityScheme
+	switch {
+	case strings.Contains(lower, "basicauth") || strings.Contains(lower, "basic_auth") || body.basicAuth && isAuthName(lower):
+		name, scheme = schemeBasic, &model.SecurityScheme{Type: model.SecurityHTTP, Scheme: "basic"}
+	case strings.Contains(lower, "apikey") || strings.Contains(lower, "api_key") || strings.Contains(lower, "keyauth"):
+		header := body.apiKeyHeader
+		if header == "" {
+			header = "X-API-Key"
+		}
+		name, scheme = schemeAPIKey, &model.SecurityScheme{Type: model.SecurityAPIKey, In: "header", Name: header}
+	case strings.Contains(lower, "oauth"):
+		name, scheme = schemeOAuth2, &model.SecurityScheme{Type: model.SecurityOAuth2}
+	case strings.Contains(lower, "jwt"):
+		name, scheme = schemeBearer, &model.SecurityScheme{Type: model.SecurityHTTP, Scheme: "bearer", BearerFormat: "JWT"}
+	case strings.Contains(lower, "bearer") || strings.Contains(lower, "token") && isAuthName(lower):
+		name, scheme = schemeBearer, &model.SecurityScheme{Type: model.SecurityHTTP, Scheme: "bearer"}
+	case isAuthName(lower) || strings.Contains(lower, "session"):
+		switch {
+		case body.apiKeyHeader != "":
+			name, scheme = schemeAPIKey, &model.SecurityScheme{Type: model.SecurityAPIKey, In: "header", Name: body.apiKeyHeader}
+		case body.basicAuth:
+			name, scheme = schemeBasic, &model.SecurityScheme{Type: model.SecurityHTTP, Scheme: "basic"}
+		case body.cookie != "":
+			name, scheme = schemeCookie, &model.SecurityScheme{Type: model.SecurityAPIKey, In: "cookie", Name: body.cookie}
+		default:
+			name, scheme = schemeBearer, &model.SecurityScheme{Type: model.SecurityHTTP, Scheme: "bearer"}
+		}
+	default:
+		return ""
+	}
+	if _, exists := a.doc.SecuritySchemes[name]; !exists {
+		scheme.Middleware = mw.Name
+		scheme.Description = "Detected from " + mw.Name + " middleware."
+		a.doc.SecuritySchemes[name] = scheme
+	}
+	return name
+}
+
+// isAuthName matches names such as AuthMiddleware, RequireAuth and
+// authenticate, but not author.
+func isAuthName(lower string) bool {
+	i := strings.Index(lower, "auth")
+	if i < 0 {
+		return false
+	}
+	rest := lower[i+4:]
+	return !strings.HasPrefix(rest, "or") || strings.HasPrefix(rest, "oriz")
+}
+
+// mwBodyFacts describes what an authentication middleware reads.
+type mwBodyFacts struct {
+	basicAuth    bool
+	apiKeyHeader string
+	cookie       string
+}
+
+// mwFacts inspects the body of a middleware declared in the program.
+func (a *analyzer) mwFacts(mw *mwUse) mwBodyFacts {
+	var facts mwBodyFacts
+	fd := a.mwFunc(mw)
+	if fd == nil || fd.Decl.Body == nil {
+		return facts
+	}
+	ev := &evaluator{a: a, file: fd.File}
+	ast.Inspect(fd.Decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch callName(call) {
+		case "BasicAuth":
+			facts.basicAuth = true
+		case "Cookie":
+			if len(call.Args) == 1 {
+				if s, ok := ev.String(call.Args[0]); ok {
+					facts.cookie = s
+				}
+			}
+		case "Get", "GetHeader":
+			if len(call.Args) != 1 {
+				return true
+			}
+			s, ok := ev.String(call.Args[0])
+			if !ok {
+				return true
+			}
+			h := http.CanonicalHeaderKey(s)
+			if strings.Contains(strings.ToLower(h), "key") && facts.apiKeyHeader == "" {
+				facts.apiKeyHeader = h
+			}
+		}
+		return true
+	})
+	return facts
+}
+
+// returnsRouter reports whether fd returns a router or http.Handler, so a
+// call to it can be mounted.
+func (a *analyzer) returnsRouter(fd *funcDecl) bool {
+	results := fd.Decl.Type.Results
+	if results == nil || len(results.List) == 0 {
+		return false
+	}
+	t := results.List[0].Type
+	if exprString(t) == "http.Handler" {
+		return true
+	}
+	w := &walker{g: a.routes, file: fd.File}
+	return w.typeFramework(t) != ""
+}
@@ -0,0 +1,256 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Schema naming strategies.
+const (
+	// NamingShort names schemas after their types, qualifying all the
+	// types sharing a name with their package.
+	NamingShort = "short"
+	// NamingPackage qualifies every schema with its package name, as
+	// billing.Response.
+	NamingPackage = "package-qualified"
+	// NamingModule qualifies every schema with its package's import path
+	// relative to the module, as internal.billing.Response.
+	NamingModule = "module-path"
+)
+
+// SchemaNaming configures how component schemas are named.
+type SchemaNaming struct {
+	// Strategy is NamingShort (the default), NamingPackage or NamingModule.
+	Strategy string `yaml:"naming" json:"strategy,omitempty"`
+	// Rename names the schemas of Go types, given by import path and
+	// name (example.com/app/billing.Response) or by package name and
+	// name (billing.Response). It overrides //apidoc:schema-name
+	// directives and the strategy.
+	Rename map[string]string `yaml:"rename" json:"rename,omitempty"`
+}
+
+// schemaNameRE matches the names OpenAPI allows for components.
+var schemaNameRE = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// schemaNameDirective is the comment naming the schema of a type.
+const schemaNameDirective = "//apidoc:schema-name"
+
+// Validate reports an unknown strategy or an invalid name.
+func (n SchemaNaming) Validate() error {
+	switch n.Strategy {
+	case "", NamingShort, NamingPackage, NamingModule:
+	default:
+		return fmt.Errorf("unknown schema naming strategy %q (want %s, %s or %s)", n.Strategy, NamingShort, NamingPackage, NamingModule)
+	}
+	for _, typ := range sortedKeys(n.Rename) {
+		if !schemaNameRE.MatchString(n.Rename[typ]) {
+			return fmt.Errorf("invalid schema name %q for %s (want letters, digits, '.', '-' and '_')", n.Rename[typ], typ)
+		}
+	}
+	return nil
+}
+
+// String describes the naming for fingerprints and shard plans.
+func (n SchemaNaming) String() string {
+	var b strings.Builder
+	b.WriteString(n.Strategy)
+	for _, typ := range sortedKeys(n.Rename) {
+		fmt.Fprintf(&b, " %s=%s", typ, n.Rename[typ])
+	}
+	return b.String()
+}
+
+// directiveName returns the schema name given to td by an
+// //apidoc:schema-name directive in its doc comment, if any.
+func (a *analyzer) directiveName(td *typeDecl) string {
+	if td.Doc == nil {
+		return ""
+	}
+	for _, c := range td.Doc.List {
+		if !strings.HasPrefix(c.Text, schemaNameDirective) {
+			continue
+		}
+		rest := strings.TrimPrefix(c.Text, schemaNameDirective)
+		if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			continue
+		}
+		name := strings.TrimSpace(rest)
+		if !schemaNameRE.MatchString(name) {
+			a.diag("INVALID_SCHEMA_NAME", model.SeverityWarning, c.Pos(),
+				fmt.Sprintf("%s of %s names no valid schema name; use letters, digits, '.', '-' and '_'", schemaNameDirective, td.Name))
+			return ""
+		}
+		return name
+	}
+	return ""
+}
+
+// recordDirective records the directive name of td, keyed by type, for
+// nameSchemas.
+func (a *analyzer) recordDirective(key string, td *typeDecl) {
+	if name := a.directiveName(td); name != "" {
+		if a.part.SchemaNames == nil {
+			a.part.SchemaNames = map[string]string{}
+		}
+		a.part.SchemaNames[key] = name
+	}
+}
+
+// nameSchemas names the schemas of types, which are keyed by import path
+// and name. Names given by the configuration or by directives are taken
+// first; the other types are named by the strategy. When types share a
+// name, none of them keeps it: each is qualified with more and more of
+// its import path until the names are unique, so that adding a type does
+// not take the name of an existing one. Names depend only on import paths
+// and type names, so they are stable when types move between files of a
+// package.
+func nameSchemas(schemas map[string]*model.Schema, module string, naming SchemaNaming, directives map[string]string) (map[string]string, []*model.Diagnostic) {
+	names := map[string]string{}
+	owner := map[string]string{}
+	var diags []*model.Diagnostic
+	keys := sortedKeys(schemas)
+
+	used := map[string]bool{}
+	var automatic []string
+	for _, key := range keys {
+		s := schemas[key]
+		name, from := "", ""
+		switch {
+		case naming.Rename[key] != "":
+			name, from, used[key] = naming.Rename[key], "schemas.rename", true
+		case naming.Rename[s.GoType] != "":
+			name, from, used[s.GoType] = naming.Rename[s.GoType], "schemas.rename", true
+		case directives[key] != "":
+			name, from = directives[key], schemaNameDirective
+		default:
+			automatic = append(automatic, key)
+			continue
+		}
+		if other, ok := owner[name]; ok {
+			diags = append(diags, &model.Diagnostic{
+				Code:     "DUPLICATE_SCHEMA_NAME",
+				Severity: model.SeverityWarning,
+				Message:  fmt.Sprintf("%s gives %s to both %s and %s; %s is named by the %s strategy", from, name, other, key, key, strategyName(naming)),
+				Pos:      s.Source,
+			})
+			automatic = append(automatic, key)
+			continue
+		}
+		names[key], owner[name] = name, key
+	}
+	for _, typ := range sortedKeys(naming.Rename) {
+		if !used[typ] {
+			diags = append(diags, &model.Diagnostic{
+				Code:     "UNUSED_SCHEMA_RENAME",
+				Severity: model.SeverityWarning,
+				Message:  fmt.Sprintf("schemas.rename names %s %s, but no schema is built for that type", typ, naming.Rename[typ]),
+			})
+		}
+	}
+
+	// Types wanting the same name are named together, so that the
+	// diagnostic lists all of them.
+	wanted := map[string][]string{}
+	var order []string
+	candidates := map[string][]string{}
+	for _, key := range automatic {
+		candidates[key] = nameCandidates(key, schemas[key].GoType, module, naming.Strategy)
+		first := candidates[key][0]
+		if wanted[first] == nil {
+			order = append(order, first)
+		}
+		wanted[first] = append(wanted[first], key)
+	}
+	for _, want := range order {
+		group := wanted[want]
+		var given []string
+		for _, key := range group {
+			cands := candidates[key]
+			if len(group) > 1 {
+				cands = cands[1:]
+			}
+			name := ""
+			for _, c := range cands {
+				if _, taken := owner[c]; !taken {
+					name = c
+					break
+				}
+			}
+			for n := 2; name == ""; n++ {
+				if c := want + "_" + strconv.Itoa(n); owner[c] == "" {
+					name = c
+				}
+			}
+			names[key], owner[name] = name, key
+			given = append(given, key+" as "+name)
+		}
+		if len(group) > 1 || names[group[0]] != want {
+			claimants := group
+			if other, ok := owner[want]; ok && !containsString(group, other) {
+				claimants = append([]string{other}, group...)
+			}
+			diags = append(diags, &model.Diagnostic{
+				Code:       "SCHEMA_NAME_COLLISION",
+				Severity:   model.SeverityWarning,
+				Message:    fmt.Sprintf("schema name %s is wanted by %s; naming %s", want, strings.Join(claimants, ", "), strings.Join(given, ", ")),
+				Suggestion: "name the types with schemas.rename in the configuration file or with an " + schemaNameDirective + " directive",
+				Pos:        schemas[group[len(group)-1]].Source,
+			})
+		}
+	}
+	return names, diags
+}
+
+func strategyName(naming SchemaNaming) string {
+	if naming.Strategy == "" {
+		return NamingShort
+	}
+	return naming.Strategy
+}
+
+// nameCandidates lists the names of the schema of a type, from the one
+// the strategy prefers to the most qualified one: the type name, then
+// qualified with its package name, then with more and more elements of
+// its import path. Under NamingModule the first name is qualified with
+// the import path relative to the module.
+func nameCandidates(key, goType, module, strategy string) []string {
+	i := strings.LastIndex(key, ".")
+	importPath, typeName := key[:i], key[i+1:]
+	elems := strings.Split(importPath, "/")
+	if pkg, _, _ := strings.Cut(goType, "."); pkg != "" {
+		elems[len(elems)-1] = pkg
+	}
+	first := len(elems) - 1
+	if strategy == NamingModule {
+		switch {
+		case importPath == module:
+		case module != "" && strings.HasPrefix(importPath, module+"/"):
+			first = strings.Count(module, "/") + 1
+		default:
+			first = 0
+		}
+	}
+	var names []string
+	if strategy == "" || strategy == NamingShort {
+		names = append(names, typeName)
+	}
+	for start := first; start >= 0; start-- {
+		names = append(names, sanitizeSchemaName(strings.Join(elems[start:], ".")+"."+typeName))
+	}
+	return names
+}
+
+// sanitizeSchemaName replaces the characters OpenAPI does not allow in
+// component names with underscores.
+func sanitizeSchemaName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 128 && schemaNameRE.MatchString(string(r)) {
+			return r
+		}
+		return '_'
+	}, name)
+}
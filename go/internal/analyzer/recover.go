@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Panic is a panic the analysis recovered from. A panic while one
+// extractor analyzes one package, type, function or registration only
+// loses what that step would have contributed: the analysis reports it as
+// an INTERNAL_ERROR diagnostic and goes on with the rest of the program.
+type Panic struct {
+	// Package is the import path of the package being analyzed, and
+	// Stage the extractor that failed: "index", "constants", "schemas",
+	// "routes" or "endpoints".
+	Package string `json:"package"`
+	Stage   string `json:"stage"`
+	// Value is the panic value and Stack the stack trace of the panicking
+	// goroutine.
+	Value string          `json:"value"`
+	Stack string          `json:"stack"`
+	Pos   *model.Position `json:"pos,omitempty"`
+	// Files holds the sources of the package keyed by their paths
+	// relative to the program root, so the failure can be reproduced.
+	Files map[string][]byte `json:"-"`
+}
+
+// panicHook, when set by tests, runs at the start of every guarded step.
+var panicHook func(stage string, pkg *Package)
+
+// guard runs fn, the step of the named extractor analyzing pkg at pos,
+// turning a panic in fn into an INTERNAL_ERROR diagnostic.
+func (a *analyzer) guard(stage string, pkg *Package, pos token.Pos, fn func()) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		p := &Panic{Package: pkg.ImportPath, Stage: stage, Value: fmt.Sprint(v), Stack: string(debug.Stack())}
+		if !pos.IsValid() && len(pkg.Files) > 0 {
+			pos = pkg.Files[0].AST.Package
+		}
+		p.Pos = a.prog.Position(pos)
+		a.diag("INTERNAL_ERROR", model.SeverityError, pos, fmt.Sprintf(
+			"internal error in the %s extractor analyzing package %s: %s; its results are incomplete (rerun with --debug-bundle to report it)",
+			stage, pkg.ImportPath, p.Value))
+		if a.prog.recovered != nil {
+			p.Files = a.prog.sources(pkg)
+			a.prog.recovered(p)
+		}
+	}()
+	if panicHook != nil {
+		panicHook(stage, pkg)
+	}
+	fn()
+}
+
+// sources reads the files of pkg again, keyed by their paths relative to
+// the program root. Files that cannot be read are left out.
+func (prog *Program) sources(pkg *Package) map[string][]byte {
+	files := map[string][]byte{}
+	for _, f := range pkg.Files {
+		src, err := prog.fs.ReadFile(filepath.Join(prog.Root, filepath.FromSlash(f.Name)))
+		if err == nil {
+			files[f.Name] = src
+		}
+	}
+	return files
+}
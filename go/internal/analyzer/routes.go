@@ -0,0 +1,1077 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Router frameworks, named after their import paths.
+const (
+	frameworkNetHTTP    = "net/http"
+	frameworkGorilla    = "gorilla/mux"
+	frameworkChi        = "chi"
+	frameworkGin        = "gin"
+	frameworkEcho       = "echo"
+	frameworkHTTPRouter = "httprouter"
+)
+
+// frameworkOf maps an import path to the router framework it provides.
+func frameworkOf(importPath string) string {
+	switch {
+	case importPath == "net/http":
+		return frameworkNetHTTP
+	case importPath == "github.com/gorilla/mux":
+		return frameworkGorilla
+	case strings.HasPrefix(importPath, "github.com/go-chi/chi"):
+		return frameworkChi
+	case importPath == "github.com/gin-gonic/gin":
+		return frameworkGin
+	case strings.HasPrefix(importPath, "github.com/labstack/echo"):
+		return frameworkEcho
+	case importPath == "github.com/julienschmidt/httprouter":
+		return frameworkHTTPRouter
+	}
+	return ""
+}
+
+// routerConstructors lists the functions that create a new router.
+var routerConstructors = map[string][]string{
+	frameworkNetHTTP:    {"NewServeMux"},
+	frameworkGorilla:    {"NewRouter"},
+	frameworkChi:        {"NewRouter", "NewMux"},
+	frameworkGin:        {"New", "Default"},
+	frameworkEcho:       {"New"},
+	frameworkHTTPRouter: {"New"},
+}
+
+// routerBase is the origin of a router value: a router created in a
+// function or a function parameter that receives one. Sources record every
+// place the base is attached to a parent router, so that prefixes and
+// middleware compose across function boundaries.
+type routerBase struct {
+	fn    *funcDecl
+	param int
+	name  string
+	// pos is where a router that is not a parameter was first seen, and
+	// adopted reports a variable taken for a router because routes are
+	// registered on it.
+	pos     token.Pos
+	adopted bool
+	sources []routerSource
+}
+
+type routerSource struct {
+	parent routerVal
+	pos    token.Pos
+	file   *File
+	// rank orders the sources of a base the way build appends them, so
+	// that shards of a program order contexts like the whole program.
+	rank string
+}
+
+// routerVal is a router, or a group derived from one, at some point in a
+// function body.
+type routerVal struct {
+	base      *routerBase
+	prefix    string
+	mws       []*mwUse
+	framework string
+	// segs are the prefixes the value was derived with since its base.
+	segs []pathSeg
+}
+
+func (v routerVal) derive(seg pathSeg, mws []*mwUse) routerVal {
+	d := v
+	d.prefix = joinPath(v.prefix, seg.value)
+	if seg.value != "" {
+		d.segs = append(append([]pathSeg(nil), v.segs...), seg)
+	}
+	d.mws = append(append([]*mwUse(nil), v.mws...), mws...)
+	return d
+}
+
+// pathSeg is a path prefix or route path and the call argument it was
+// evaluated from, kept to explain how paths compose.
+type pathSeg struct {
+	value string
+	expr  ast.Expr
+	call  *ast.CallExpr
+	file  *File
+}
+
+func (w *walker) seg(call *ast.CallExpr, expr ast.Expr, value string) pathSeg {
+	return pathSeg{value: value, expr: expr, call: call, file: w.file}
+}
+
+// mwUse is a middleware applied to a router or route.
+type mwUse struct {
+	Name   string
+	Expr   ast.Expr
+	File   *File
+	locals map[string]ast.Expr
+}
+
+// registration is one route registered on a router.
+type registration struct {
+	router    routerVal
+	call      *ast.CallExpr
+	methods   []string
+	path      string
+	pathExpr  ast.Expr
+	handler   ast.Expr
+	mws       []*mwUse
+	file      *File
+	pos       token.Pos
+	locals    map[string]ast.Expr
+	framework string
+	fn        *funcDecl
+}
+
+// routeGraph collects registrations from every function and the edges
+// that carry routers between functions.
+type routeGraph struct {
+	a             *analyzer
+	regs          []*registration
+	paramBases    map[*funcDecl]map[int]*routerBase
+	returns       map[*funcDecl][]*routerBase
+	pendingMounts []pendingMount
+	regFiles      map[*Package]bool
+	defaultBase   *routerBase
+	// unresolved holds the registrations whose path could not be
+	// evaluated.
+	unresolved []*registration
+	// seq counts the sources recorded while walking each function.
+	seq map[*funcDecl]int
+}
+
+// pendingMount attaches the routers returned by fn once every function
+// has been walked.
+type pendingMount struct {
+	fn     *funcDecl
+	source routerSource
+}
+
+func newRouteGraph(a *analyzer) *routeGraph {
+	return &routeGraph{
+		a:          a,
+		paramBases: map[*funcDecl]map[int]*routerBase{},
+		returns:    map[*funcDecl][]*routerBase{},
+		regFiles:   map[*Package]bool{},
+		seq:        map[*funcDecl]int{},
+	}
+}
+
+// source returns a source attached by a call at pos in the function the
+// walker walks. Sources of pending mounts are attached after all others.
+func (w *walker) source(v routerVal, pos token.Pos, pending bool) routerSource {
+	phase := 0
+	if pending {
+		phase = 1
+	}
+	w.g.seq[w.fn]++
+	return routerSource{
+		parent: v,
+		pos:    pos,
+		file:   w.file,
+		rank:   fmt.Sprintf("%d\x00%s\x00%08d", phase, w.fn.id(), w.g.seq[w.fn]),
+	}
+}
+
+// baseID identifies a base across analyses of different sets of packages
+// of one program.
+func (g *routeGraph) baseID(b *routerBase) string {
+	switch {
+	case b == g.defaultBase:
+		return b.name
+	case b.param >= 0:
+		return b.fn.id() + "#" + strconv.Itoa(b.param)
+	}
+	p := g.a.prog.Fset.Position(b.pos)
+	return fmt.Sprintf("%s:%d#%s", p.Filename, p.Offset, b.name)
+}
+
+func (g *routeGraph) paramBase(fn *funcDecl, index int, name string) *routerBase {
+	m := g.paramBases[fn]
+	if m == nil {
+		m = map[int]*routerBase{}
+		g.paramBases[fn] = m
+	}
+	b := m[index]
+	if b == nil {
+		b = &routerBase{fn: fn, param: index, name: name}
+		m[index] = b
+	}
+	return b
+}
+
+// defaultMux returns the base for http.DefaultServeMux.
+func (g *routeGraph) defaultMux() *routerBase {
+	if g.defaultBase == nil {
+		g.defaultBase = &routerBase{param: -1, name: "http.DefaultServeMux"}
+	}
+	return g.defaultBase
+}
+
+func (g *routeGraph) registersIn(pkg *Package) bool {
+	return g.regFiles[pkg]
+}
+
+// build walks every function body in a deterministic order.
+func (g *routeGraph) build() {
+	for _, key := range sortedKeys(g.a.funcs) {
+		fd := g.a.funcs[key]
+		if fd.Decl.Body == nil {
+			continue
+		}
+		g.a.guard("routes", fd.File.Pkg, fd.Decl.Pos(), func() {
+			w := newWalker(g, fd, fd.File)
+			w.bindParams(fd.Decl.Type.Params)
+			w.walk(fd.Decl.Body)
+		})
+	}
+	for _, pm := range g.pendingMounts {
+		for _, b := range g.returns[pm.fn] {
+			b.sources = append(b.sources, pm.source)
+		}
+	}
+}
+
+// routeContext is the accumulated prefix and middleware of a router
+// base along one path from a root router.
+type routeContext struct {
+	prefix string
+	mws    []*mwUse
+	// srcs lists the sources along the path and rank their ranks,
+	// innermost first, and top is the root router the path ends at when
+	// that router is attached nowhere.
+	srcs []routerSource
+	rank []string
+	top  *routerBase
+}
+
+const maxContexts = 32
+
+// contexts resolves every prefix and middleware chain a router value can
+// be reached through.
+func (g *routeGraph) contexts(v routerVal) []routeContext {
+	var out []routeContext
+	for _, c := range g.baseContexts(v.base, map[*routerBase]bool{}) {
+		out = append(out, routeContext{
+			prefix: joinPath(c.prefix, v.prefix),
+			mws:    append(append([]*mwUse(nil), c.mws...), v.mws...),
+			srcs:   c.srcs,
+			rank:   c.rank,
+			top:    c.top,
+		})
+	}
+	return out
+}
+
+func (g *routeGraph) baseContexts(b *routerBase, visiting map[*routerBase]bool) []routeContext {
+	if b == nil || visiting[b] {
+		return []routeContext{{}}
+	}
+	if len(b.sources) == 0 {
+		return []routeContext{{top: b}}
+	}
+	visiting[b] = true
+	defer delete(visiting, b)
+	var out []routeContext
+	seen := map[string]bool{}
+	for _, src := range b.sources {
+		for _, c := range g.baseContexts(src.parent.base, visiting) {
+			ctx := routeContext{
+				prefix: joinPath(c.prefix, src.parent.prefix),
+				mws:    append(append([]*mwUse(nil), c.mws...), src.parent.mws...),
+				srcs:   append([]routerSource{src}, c.srcs...),
+				rank:   append([]string{src.rank}, c.rank...),
+				top:    c.top,
+			}
+			key := ctx.prefix + "|" + mwKey(ctx.mws)
+			if seen[key] || len(out) >= maxContexts {
+				continue
+			}
+			seen[key] = true
+			out = append(out, ctx)
+		}
+	}
+	return out
+}
+
+// ancestors returns b and every base it is attached to, directly or
+// through other bases.
+func (g *routeGraph) ancestors(b *routerBase) []*routerBase {
+	seen := map[*routerBase]bool{}
+	var out []*routerBase
+	var visit func(*routerBase)
+	visit = func(b *routerBase) {
+		if b == nil || seen[b] {
+			return
+		}
+		seen[b] = true
+		out = append(out, b)
+		for _, src := range b.sources {
+			visit(src.parent.base)
+		}
+	}
+	visit(b)
+	return out
+}
+
+func mwKey(mws []*mwUse) string {
+	names := make([]string, len(mws))
+	for i, m := range mws {
+		names[i] = m.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// walker tracks router values through one function body.
+type walker struct {
+	g      *routeGraph
+	fn     *funcDecl
+	file   *File
+	env    map[string]*routerVal
+	locals map[string]ast.Expr
+	params map[string]int
+}
+
+func newWalker(g *routeGraph, fn *funcDecl, file *File) *walker {
+	return &walker{
+		g:      g,
+		fn:     fn,
+		file:   file,
+		env:    map[string]*routerVal{},
+		locals: map[string]ast.Expr{},
+		params: map[string]int{},
+	}
+}
+
+func (w *walker) child() *walker {
+	c := newWalker(w.g, w.fn, w.file)
+	for k, v := range w.env {
+		cp := *v
+		c.env[k] = &cp
+	}
+	for k, v := range w.locals {
+		c.locals[k] = v
+	}
+	for k, v := range w.params {
+		c.params[k] = v
+	}
+	return c
+}
+
+func (w *walker) bindParams(params *ast.FieldList) {
+	if params == nil {
+		return
+	}
+	i := 0
+	for _, field := range params.List {
+		fw := w.typeFramework(field.Type)
+		if len(field.Names) == 0 {
+			i++
+			continue
+		}
+		for _, name := range field.Names {
+			w.params[name.Name] = i
+			if fw != "" {
+				w.env[name.Name] = &routerVal{base: w.g.paramBase(w.fn, i, name.Name), framework: fw}
+			}
+			i++
+		}
+	}
+}
+
+// typeFramework reports the framework of a router parameter type such as
+// chi.Router or *gin.RouterGroup.
+func (w *walker) typeFramework(expr ast.Expr) string {
+	sel, ok := derefExpr(expr).(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	p, name, ok := w.g.a.qualify(w.file, sel)
+	if !ok {
+		return ""
+	}
+	fw := frameworkOf(p)
+	switch fw {
+	case frameworkNetHTTP:
+		if name == "ServeMux" {
+			return fw
+		}
+	case frameworkGorilla, frameworkHTTPRouter:
+		if name == "Router" {
+			return fw
+		}
+	case frameworkChi:
+		if name == "Router" || name == "Mux" {
+			return fw
+		}
+	case frameworkGin:
+		switch name {
+		case "Engine", "RouterGroup", "IRouter", "IRoutes":
+			return fw
+		}
+	case frameworkEcho:
+		if name == "Echo" || name == "Group" {
+			return fw
+		}
+	}
+	return ""
+}
+
+func (w *walker) walk(node ast.Node) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			w.assign(n.Lhs, n.Rhs)
+			return false
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, id := range n.Names {
+				lhs[i] = id
+			}
+			w.assign(lhs, n.Values)
+			return false
+		case *ast.ReturnStmt:
+			for _, r := range n.Results {
+				if v, ok := w.eval(r); ok && v.base != nil && v.base.param < 0 {
+					w.g.returns[w.fn] = append(w.g.returns[w.fn], v.base)
+				} else if b := w.wrappedRouter(r); b != nil {
+					w.g.returns[w.fn] = append(w.g.returns[w.fn], b)
+				}
+			}
+			return false
+		case *ast.CallExpr:
+			if _, ok := w.eval(n); ok {
+				return false
+			}
+			w.callEdges(n)
+		}
+		return true
+	})
+}
+
+// wrappedRouter returns the router wrapped by a middleware call such as
+// handlers.CORS()(r) or cors.Default().Handler(r).
+func (w *walker) wrappedRouter(expr ast.Expr) *routerBase {
+	call, ok := unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	for _, arg := range call.Args {
+		if key := exprKey(arg); key != "" {
+			if v, ok := w.env[key]; ok && v.base != nil && v.base.param < 0 {
+				return v.base
+			}
+		}
+		if b := w.wrappedRouter(arg); b != nil {
+			return b
+		}
+	}
+	return nil
+}
+
+func (w *walker) assign(lhs, rhs []ast.Expr) {
+	for i, l := range lhs {
+		if len(rhs) != len(lhs) {
+			break
+		}
+		key := exprKey(l)
+		if key == "" {
+			w.walk(rhs[i])
+			continue
+		}
+		if v, ok := w.eval(rhs[i]); ok && v.base != nil {
+			cp := v
+			w.env[key] = &cp
+			continue
+		}
+		w.locals[key] = rhs[i]
+		w.walk(rhs[i])
+	}
+	if len(rhs) != len(lhs) {
+		for _, r := range rhs {
+			w.walk(r)
+		}
+	}
+}
+
+// exprKey names a variable or field selector so that it can be tracked
+// in the environment, e.g. "r" or "s.router".
+func exprKey(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if e.Name == "_" {
+			return ""
+		}
+		return e.Name
+	case *ast.SelectorExpr:
+		if x := exprKey(e.X); x != "" {
+			return x + "." + e.Sel.Name
+		}
+	}
+	return ""
+}
+
+// flatten splits a method call chain a.B().C() into its base expression
+// and the calls applied to it, innermost first.
+func flatten(expr ast.Expr) (ast.Expr, []*ast.CallExpr) {
+	var calls []*ast.CallExpr
+	for {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			break
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		calls = append([]*ast.CallExpr{call}, calls...)
+		expr = sel.X
+	}
+	return expr, calls
+}
+
+func callName(call *ast.CallExpr) string {
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		return sel.Sel.Name
+	}
+	if id, ok := call.Fun.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// eval evaluates expr as a router value, recording registrations, groups
+// and mounts as side effects. It reports false when expr is not a router
+// operation.
+func (w *walker) eval(expr ast.Expr) (routerVal, bool) {
+	expr = unparen(expr)
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		expr = u.X
+	}
+	base, calls := flatten(expr)
+	v, rest, ok := w.evalBase(base, calls)
+	if !ok {
+		return routerVal{}, false
+	}
+	for i := 0; i < len(rest); i++ {
+		call := rest[i]
+		next, consumed, ok := w.apply(v, base, call, rest[i+1:])
+		if !ok {
+			return routerVal{}, i > 0
+		}
+		v = next
+		i += consumed
+	}
+	return v, true
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		p, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.X
+	}
+}
+
+// evalBase resolves the start of a call chain to a router value and
+// returns the calls still to apply.
+func (w *walker) evalBase(base ast.Expr, calls []*ast.CallExpr) (routerVal, []*ast.CallExpr, bool) {
+	if id, ok := base.(*ast.Ident); ok && len(calls) > 0 {
+		if p, isImport := w.file.ImportPath(id.Name); isImport {
+			if _, shadowed := w.env[id.Name]; !shadowed {
+				return w.evalPackageCall(id.Name, frameworkOf(p), calls)
+			}
+		}
+	}
+	key := exprKey(base)
+	if key == "" {
+		return routerVal{}, nil, false
+	}
+	if v, ok := w.env[key]; ok {
+		return *v, calls, true
+	}
+	// An untracked receiver: adopt it as a router when the first call is
+	// a route registration with a constant path, so that routers stored
+	// in struct fields or passed as untyped parameters are still found.
+	if len(calls) == 0 || !w.looksLikeRegistration(calls[0]) {
+		return routerVal{}, nil, false
+	}
+	v := &routerVal{framework: w.guessFramework(callName(calls[0]))}
+	if i, isParam := w.params[key]; isParam {
+		v.base = w.g.paramBase(w.fn, i, key)
+	} else {
+		v.base = &routerBase{fn: w.fn, param: -1, name: key, pos: calls[0].Pos(), adopted: true}
+	}
+	w.env[key] = v
+	return *v, calls, true
+}
+
+// evalPackageCall handles chains starting with a package function:
+// router constructors, and net/http's registrations on DefaultServeMux.
+func (w *walker) evalPackageCall(pkg, fw string, calls []*ast.CallExpr) (routerVal, []*ast.CallExpr, bool) {
+	name := callName(calls[0])
+	for _, ctor := range routerConstructors[fw] {
+		if name == ctor {
+			return routerVal{base: &routerBase{fn: w.fn, param: -1, name: pkg + "." + ctor, pos: calls[0].Pos()}, framework: fw}, calls[1:], true
+		}
+	}
+	if fw == frameworkNetHTTP && (name == "HandleFunc" || name == "Handle") {
+		return routerVal{base: w.g.defaultMux(), framework: fw}, calls, true
+	}
+	return routerVal{}, nil, false
+}
+
+func (w *walker) looksLikeRegistration(call *ast.CallExpr) bool {
+	name := callName(call)
+	args := call.Args
+	switch {
+	case name == "Use":
+		return len(args) > 0 && w.fileFramework() != ""
+	case name == "Group" || name == "Route":
+		if len(args) == 1 {
+			if _, ok := args[0].(*ast.FuncLit); ok {
+				return w.fileFramework() != ""
+			}
+		}
+	case routeMethods[name] != "", name == "HandleFunc", name == "Handle":
+	default:
+		return false
+	}
+	if len(args) == 0 {
+		return false
+	}
+	s, ok := w.str(args[0])
+	if !ok {
+		return false
+	}
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, ' '); i > 0 && isHTTPMethod(s[:i]) {
+		s = strings.TrimSpace(s[i+1:])
+	}
+	return strings.HasPrefix(s, "/")
+}
+
+// fileFramework returns the router framework imported by the current
+// file, if exactly one is.
+func (w *walker) fileFramework() string {
+	found := ""
+	for _, imp := range w.file.AST.Imports {
+		fw := frameworkOf(strings.Trim(imp.Path.Value, "\""))
+		if fw == "" || fw == frameworkNetHTTP {
+			continue
+		}
+		if found != "" && found != fw {
+			return ""
+		}
+		found = fw
+	}
+	return found
+}
+
+func (w *walker) guessFramework(method string) string {
+	if fw := w.fileFramework(); fw != "" {
+		return fw
+	}
+	switch {
+	case method == "HandleFunc" || method == "Handle":
+		return frameworkNetHTTP
+	case method == strings.ToUpper(method):
+		return frameworkGin
+	}
+	return frameworkChi
+}
+
+// routeMethods maps registration method names to HTTP methods. Upper case
+// names are used by gin, echo and httprouter, capitalized ones by chi.
+var routeMethods = map[string]string{
+	"GET": "GET", "HEAD": "HEAD", "POST": "POST", "PUT": "PUT", "PATCH": "PATCH",
+	"DELETE": "DELETE", "OPTIONS": "OPTIONS", "CONNECT": "CONNECT", "TRACE": "TRACE",
+	"Get": "GET", "Head": "HEAD", "Post": "POST", "Put": "PUT", "Patch": "PATCH",
+	"Delete": "DELETE", "Options": "OPTIONS", "Connect": "CONNECT", "Trace": "TRACE",
+}
+
+// apply applies one method call to a router value. It returns the
+// resulting value and how many of the following calls it consumed.
+func (w *walker) apply(v routerVal, base ast.Expr, call *ast.CallExpr, following []*ast.CallExpr) (routerVal, int, bool) {
+	name := callName(call)
+	args := call.Args
+	switch name {
+	case "Use":
+		mws := w.mwUses(args)
+		if key := exprKey(base); key != "" && w.env[key] != nil && len(following) == 0 {
+			w.env[key].mws = append(w.env[key].mws, mws...)
+		}
+		return v.derive(pathSeg{}, mws), 0, true
+	case "With":
+		return v.derive(pathSeg{}, w.mwUses(args)), 0, true
+	case "Group":
+		if len(args) == 1 {
+			if lit, ok := args[0].(*ast.FuncLit); ok {
+				w.walkRouterFunc(lit, v)
+				return v, 0, true
+			}
+		}
+		if len(args) == 0 {
+			return v, 0, true
+		}
+		prefix, ok := w.str(args[0])
+		if !ok {
+			w.g.a.diag("UNRESOLVED_ROUTE_PATH", model.SeverityInfo, call.Pos(), "cannot evaluate route group prefix "+exprString(args[0]))
+			return routerVal{}, 0, false
+		}
+		return v.derive(w.seg(call, args[0], prefix), w.mwUses(args[1:])), 0, true
+	case "Route":
+		if len(args) < 1 {
+			return routerVal{}, 0, false
+		}
+		prefix, ok := w.str(args[0])
+		if !ok {
+			return routerVal{}, 0, false
+		}
+		sub := v.derive(w.seg(call, args[0], prefix), nil)
+		if len(args) > 1 {
+			if lit, ok := args[1].(*ast.FuncLit); ok {
+				w.walkRouterFunc(lit, sub)
+			}
+		}
+		return sub, 0, true
+	case "PathPrefix":
+		if len(args) != 1 {
+			return routerVal{}, 0, false
+		}
+		prefix, ok := w.str(args[0])
+		if !ok {
+			return routerVal{}, 0, false
+		}
+		if len(following) > 0 {
+			switch callName(following[0]) {
+			case "Subrouter":
+				return v.derive(w.seg(call, args[0], prefix), nil), 1, true
+			case "Handler":
+				if len(following[0].Args) == 1 {
+					w.mountHandler(v, w.seg(call, args[0], prefix), following[0].Args[0], call.Pos())
+				}
+			}
+		}
+		// Any other handler under a path prefix, typically a file server,
+		// is opaque.
+		return routerVal{}, len(following), true
+	case "Subrouter":
+		return v, 0, true
+	case "Mount":
+		if len(args) != 2 {
+			return routerVal{}, 0, false
+		}
+		prefix, ok := w.str(args[0])
+		if !ok {
+			return routerVal{}, 0, false
+		}
+		w.mountHandler(v, w.seg(call, args[0], prefix), args[1], call.Pos())
+		return v, 0, true
+	case "Methods", "Name", "Schemes", "Host", "Headers", "Queries":
+		return v, 0, true
+	}
+	if w.register(v, call, following) {
+		return v, len(following), true
+	}
+	return routerVal{}, 0, false
+}
+
+// walkRouterFunc walks a function literal whose first parameter receives
+// the router value, as in chi's r.Route("/users", func(r chi.Router) {...}).
+func (w *walker) walkRouterFunc(lit *ast.FuncLit, v routerVal) {
+	c := w.child()
+	if params := lit.Type.Params; params != nil && len(params.List) > 0 && len(params.List[0].Names) > 0 {
+		cp := v
+		c.env[params.List[0].Names[0].Name] = &cp
+	}
+	c.walk(lit.Body)
+}
+
+// mount attaches the router in handler to the parent value v. It reports
+// false when handler is not a router.
+func (w *walker) mount(v routerVal, handler ast.Expr, pos token.Pos) bool {
+	src := w.source(v, pos, false)
+	if key := exprKey(handler); key != "" {
+		sub, ok := w.env[key]
+		if ok && sub.base != nil {
+			sub.base.sources = append(sub.base.sources, src)
+		}
+		return ok
+	}
+	call, ok := unparen(handler).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	if sub, ok := w.eval(call); ok && sub.base != nil {
+		sub.base.sources = append(sub.base.sources, src)
+		return true
+	}
+	if fd := w.g.a.lookupFunc(w.file, call.Fun); fd != nil && w.g.a.returnsRouter(fd) {
+		w.g.pendingMounts = append(w.g.pendingMounts, pendingMount{fn: fd, source: w.source(v, pos, true)})
+		return true
+	}
+	return false
+}
+
+// mountHandler mounts handler at the path seg when it is a router,
+// unwrapping http.StripPrefix. It reports whether handler was mounted.
+func (w *walker) mountHandler(v routerVal, seg pathSeg, handler ast.Expr, pos token.Pos) bool {
+	if call, ok := unparen(handler).(*ast.CallExpr); ok && len(call.Args) == 2 {
+		if p, name, ok := w.g.a.qualify(w.file, call.Fun); ok && p == "net/http" && name == "StripPrefix" {
+			if s, ok := w.str(call.Args[0]); ok {
+				seg = w.seg(call, call.Args[0], s)
+			}
+			handler = call.Args[1]
+		}
+	}
+	seg.value = strings.TrimSuffix(seg.value, "/")
+	return w.mount(v.derive(seg, nil), handler, pos)
+}
+
+func (w *walker) str(expr ast.Expr) (string, bool) {
+	ev := &evaluator{a: w.g.a, file: w.file, locals: w.locals}
+	return ev.String(expr)
+}
+
+func (w *walker) mwUses(args []ast.Expr) []*mwUse {
+	var mws []*mwUse
+	for _, arg := range args {
+		if _, ok := arg.(*ast.FuncLit); ok {
+			mws = append(mws, &mwUse{Name: "func literal", Expr: arg, File: w.file, locals: w.locals})
+			continue
+		}
+		mws = append(mws, &mwUse{Name: mwName(arg), Expr: arg, File: w.file, locals: w.locals})
+	}
+	return mws
+}
+
+// mwName renders a middleware expression without its arguments, e.g.
+// "middleware.RateLimit" for middleware.RateLimit(100, time.Minute).
+func mwName(expr ast.Expr) string {
+	if call, ok := unparen(expr).(*ast.CallExpr); ok {
+		return mwName(call.Fun)
+	}
+	return exprString(expr)
+}
+
+// register records a route registration call. following holds the calls
+// chained after it, such as gorilla's .Methods("GET").
+func (w *walker) register(v routerVal, call *ast.CallExpr, following []*ast.CallExpr) bool {
+	name := callName(call)
+	args := call.Args
+	reg := &registration{router: v, call: call, file: w.file, pos: call.Pos(), locals: w.locals, framework: v.framework, fn: w.fn}
+
+	var pathArg ast.Expr
+	var handlers []ast.Expr
+	switch {
+	case routeMethods[name] != "":
+		if len(args) < 2 {
+			return false
+		}
+		reg.methods = []string{routeMethods[name]}
+		pathArg, handlers = args[0], args[1:]
+	case name == "Method" || name == "MethodFunc" || (name == "Handle" || name == "HandlerFunc" || name == "Add") && len(args) == 3:
+		if len(args) < 3 {
+			return false
+		}
+		m, ok := w.str(args[0])
+		if !ok {
+			return false
+		}
+		reg.methods = []string{strings.ToUpper(m)}
+		pathArg, handlers = args[1], args[2:]
+	case name == "Match" && len(args) >= 3:
+		if lit, ok := args[0].(*ast.CompositeLit); ok {
+			for _, elt := range lit.Elts {
+				if m, ok := w.str(elt); ok {
+					reg.methods = append(reg.methods, strings.ToUpper(m))
+				}
+			}
+		}
+		pathArg, handlers = args[1], args[2:]
+	case name == "HandleFunc" || name == "Handle" || name == "Any":
+		if len(args) < 2 {
+			return false
+		}
+		pathArg, handlers = args[0], args[1:]
+	default:
+		return false
+	}
+
+	reg.pathExpr = pathArg
+	// gin takes middleware before the handler, echo after it.
+	if v.framework == frameworkEcho {
+		reg.handler, reg.mws = handlers[0], w.mwUses(handlers[1:])
+	} else {
+		reg.handler, reg.mws = handlers[len(handlers)-1], w.mwUses(handlers[:len(handlers)-1])
+	}
+	p, ok := w.str(pathArg)
+	if !ok {
+		w.g.a.diag("UNRESOLVED_ROUTE_PATH", model.SeverityInfo, call.Pos(), "cannot evaluate route path "+exprString(pathArg))
+		w.g.unresolved = append(w.g.unresolved, reg)
+		return true
+	}
+	p = strings.TrimSpace(p)
+	// Go 1.22 ServeMux patterns: "GET example.com/users/{id}".
+	if i := strings.IndexByte(p, ' '); i > 0 && isHTTPMethod(p[:i]) {
+		reg.methods = []string{p[:i]}
+		p = strings.TrimSpace(p[i+1:])
+	}
+	if i := strings.IndexByte(p, '/'); i > 0 {
+		p = p[i:]
+	}
+	reg.path = p
+	if len(reg.methods) == 0 && len(handlers) == 1 && w.mountHandler(v, w.seg(call, pathArg, p), handlers[0], call.Pos()) {
+		return true
+	}
+
+	for _, f := range following {
+		if callName(f) != "Methods" {
+			continue
+		}
+		reg.methods = nil
+		for _, arg := range f.Args {
+			if m, ok := w.str(arg); ok {
+				reg.methods = append(reg.methods, strings.ToUpper(m))
+			}
+		}
+	}
+	w.g.regs = append(w.g.regs, reg)
+	w.g.regFiles[w.file.Pkg] = true
+	return true
+}
+
+// callEdges records routers passed to functions in the program, so that
+// routes registered by the callee inherit the caller's prefix and
+// middleware.
+func (w *walker) callEdges(call *ast.CallExpr) {
+	var fd *funcDecl
+	for i, arg := range call.Args {
+		v, ok := w.routerArg(arg)
+		if !ok || v.base == nil {
+			continue
+		}
+		if fd == nil {
+			if fd = w.g.a.lookupFunc(w.file, call.Fun); fd == nil {
+				return
+			}
+		}
+		name := paramName(fd.Decl.Type.Params, i)
+		b := w.g.paramBase(fd, i, name)
+		b.sources = append(b.sources, w.source(v, call.Pos(), false))
+	}
+}
+
+// routerArg evaluates a call argument as a router without recording any
+// side effects twice: only tracked variables and pure derivations count.
+func (w *walker) routerArg(arg ast.Expr) (routerVal, bool) {
+	if key := exprKey(arg); key != "" {
+		if v, ok := w.env[key]; ok {
+			return *v, true
+		}
+		// Parameters whose type is not a known router type may still
+		// receive one; link them so the callee can adopt them.
+		if i, ok := w.params[key]; ok {
+			return routerVal{base: w.g.paramBase(w.fn, i, key)}, true
+		}
+		return routerVal{}, false
+	}
+	if call, ok := unparen(arg).(*ast.CallExpr); ok {
+		if _, calls := flatten(call); len(calls) > 0 {
+			return w.eval(arg)
+		}
+	}
+	return routerVal{}, false
+}
+
+func paramName(params *ast.FieldList, index int) string {
+	if params == nil {
+		return ""
+	}
+	i := 0
+	for _, field := range params.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		if index < i+n {
+			if len(field.Names) == 0 {
+				return ""
+			}
+			return field.Names[index-i].Name
+		}
+		i += n
+	}
+	return ""
+}
+
+// joinPath joins a router prefix and a route path.
+func joinPath(prefix, p string) string {
+	switch {
+	case prefix == "":
+		return p
+	case p == "":
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(p, "/")
+}
+
+// normalizePath converts framework path syntax to OpenAPI templates:
+// ":id" and "*path" to "{id}" and "{path}", "{id:[0-9]+}" to "{id}", and
+// "{path...}" to "{path}". Regex constraints are returned by name.
+func normalizePath(p string) (string, map[string]string) {
+	patterns := map[string]string{}
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			segs[i] = "{" + seg[1:] + "}"
+		case strings.HasPrefix(seg, "*") && len(seg) > 1:
+			segs[i] = "{" + seg[1:] + "}"
+		case seg == "*":
+			segs[i] = "{wildcard}"
+		case seg == "{$}":
+			segs[i] = ""
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			inner := seg[1 : len(seg)-1]
+			inner = strings.TrimSuffix(inner, "...")
+			if j := strings.IndexByte(inner, ':'); j >= 0 {
+				patterns[inner[:j]] = inner[j+1:]
+				inner = inner[:j]
+			}
+			segs[i] = "{" + inner + "}"
+		}
+	}
+	out := strings.Join(segs, "/")
+	if out == "" {
+		out = "/"
+	}
+	if len(out) > 1 && strings.HasSuffix(out, "/") && strings.HasSuffix(p, "/") && !strings.HasSuffix(p, "{$}") {
+		out = strings.TrimSuffix(out, "/")
+	}
+	return out, patterns
+}
+
+// sortRegistrations orders registrations by source position.
+func (g *routeGraph) sortRegistrations() {
+	sort.SliceStable(g.regs, func(i, j int) bool {
+		pi, pj := g.a.prog.Fset.Position(g.regs[i].pos), g.a.prog.Fset.Position(g.regs[j].pos)
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+}
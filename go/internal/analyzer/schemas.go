@@ -0,0 +1,521 @@
+package analyzer
+
+import (
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// collectSchemas registers a component schema for every exported struct,
+// enum and sealed interface type. Other named types become components
+// only when they are referenced from an endpoint or another schema.
+func (a *analyzer) collectSchemas() {
+	for _, key := range sortedKeys(a.types) {
+		td := a.types[key]
+		if !ast.IsExported(td.Name) || td.File.Pkg.Name == "main" {
+			continue
+		}
+		a.guard("schemas", td.File.Pkg, td.Spec.Pos(), func() {
+			if _, ok := td.Spec.Type.(*ast.StructType); ok || a.isEnum(td) || a.isUnion(td) {
+				a.namedSchema(td)
+			}
+		})
+	}
+}
+
+func typeKey(td *typeDecl) string {
+	return td.File.Pkg.ImportPath + "." + td.Name
+}
+
+func (a *analyzer) isEnum(td *typeDecl) bool {
+	if _, ok := td.Spec.Type.(*ast.Ident); !ok {
+		return false
+	}
+	return len(a.enums[typeKey(td)]) > 0
+}
+
+// isUnion reports whether td is a sealed interface: an interface whose
+// only method is an unexported marker, such as isPet(), implemented by
+// types of its package. Such interfaces become oneOf schemas.
+func (a *analyzer) isUnion(td *typeDecl) bool {
+	return len(a.variants(td)) > 0
+}
+
+// variants returns the types implementing the marker method of a sealed
+// interface, in declaration order.
+func (a *analyzer) variants(td *typeDecl) []*typeDecl {
+	it, ok := td.Spec.Type.(*ast.InterfaceType)
+	if !ok || len(it.Methods.List) != 1 || len(it.Methods.List[0].Names) != 1 {
+		return nil
+	}
+	marker := it.Methods.List[0].Names[0]
+	sig, ok := it.Methods.List[0].Type.(*ast.FuncType)
+	if !ok || marker.IsExported() || sig.Params.NumFields() > 0 || sig.Results.NumFields() > 0 {
+		return nil
+	}
+	var types []*typeDecl
+	for _, fd := range a.methods[marker.Name] {
+		if fd.File.Pkg != td.File.Pkg || fd.Decl.Type.Params.NumFields() > 0 {
+			continue
+		}
+		if v := a.types[td.File.Pkg.ImportPath+"."+fd.Recv]; v != nil && v != td {
+			types = append(types, v)
+		}
+	}
+	return types
+}
+
+// namedSchema returns a reference to the component schema for td,
+// building it on first use. Named types that are neither structs, enums
+// nor sealed interfaces are inlined.
+func (a *analyzer) namedSchema(td *typeDecl) *model.Schema {
+	key := typeKey(td)
+	if name, ok := a.schemaNames[key]; ok {
+		return model.RefTo(name)
+	}
+	_, isStruct := td.Spec.Type.(*ast.StructType)
+	isUnion := a.isUnion(td)
+	if !isStruct && !isUnion && !a.isEnum(td) {
+		s := a.schemaForType(td.File, td.Spec.Type)
+		if s.Ref == "" && s.Description == "" && td.Doc != nil {
+			s.Description = strings.TrimSpace(td.Doc.Text())
+		}
+		return s
+	}
+
+	// Schemas are named after their type keys until the whole program
+	// has been analyzed; nameSchemas picks the final names.
+	name := key
+	a.schemaNames[key] = name
+	a.recordDirective(key, td)
+	s := &model.Schema{
+		GoType: td.File.Pkg.Name + "." + td.Name,
+		Source: a.prog.Position(td.Spec.Pos()),
+	}
+	if td.Doc != nil {
+		s.Description = strings.TrimSpace(td.Doc.Text())
+	}
+	a.doc.Schemas[name] = s
+
+	switch {
+	case isStruct:
+		s.Type = "object"
+		a.structProperties(td.File, td.Spec.Type.(*ast.StructType), s, map[string]bool{key: true})
+	case isUnion:
+		for _, v := range a.variants(td) {
+			s.OneOf = append(s.OneOf, a.namedSchema(v))
+		}
+	default:
+		base := a.schemaForType(td.File, td.Spec.Type)
+		s.Type, s.Format = base.Type, base.Format
+		for _, c := range a.enums[key] {
+			s.Enum = append(s.Enum, c.Value)
+			s.EnumVarNames = append(s.EnumVarNames, c.Name)
+			s.EnumDescriptions = append(s.EnumDescriptions, c.Doc)
+		}
+		if !hasText(s.EnumDescriptions) {
+			s.EnumDescriptions = nil
+		}
+	}
+	return model.RefTo(name)
+}
+
+func hasText(ss []string) bool {
+	for _, s := range ss {
+		if s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaForType converts a Go type expression to a schema.
+func (a *analyzer) schemaForType(f *File, expr ast.Expr) *model.Schema {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if typ, ok := builtinTypes[t.Name]; ok {
+			return &model.Schema{Type: typ, Format: builtinFormat(t.Name)}
+		}
+		switch t.Name {
+		case "any", "error":
+			if t.Name == "error" {
+				return &model.Schema{Type: "string"}
+			}
+			return &model.Schema{}
+		}
+		if td := a.lookupType(f, t); td != nil {
+			return a.namedSchema(td)
+		}
+		// Type parameters and unresolved identifiers accept anything.
+		return &model.Schema{}
+	case *ast.StarExpr:
+		s := a.schemaForType(f, t.X)
+		s.Nullable = true
+		return s
+	case *ast.ArrayType:
+		if id, ok := t.Elt.(*ast.Ident); ok && (id.Name == "byte" || id.Name == "uint8") {
+			return &model.Schema{Type: "string", Format: "byte"}
+		}
+		return &model.Schema{Type: "array", Items: a.schemaForType(f, t.Elt)}
+	case *ast.MapType:
+		return &model.Schema{Type: "object", AdditionalProperties: a.schemaForType(f, t.Value)}
+	case *ast.InterfaceType:
+		return &model.Schema{}
+	case *ast.StructType:
+		s := &model.Schema{Type: "object"}
+		a.structProperties(f, t, s, map[string]bool{})
+		return s
+	case *ast.SelectorExpr:
+		return a.selectorSchema(f, t)
+	case *ast.IndexExpr:
+		return a.schemaForType(f, t.X)
+	case *ast.IndexListExpr:
+		return a.schemaForType(f, t.X)
+	case *ast.ParenExpr:
+		return a.schemaForType(f, t.X)
+	}
+	return &model.Schema{}
+}
+
+func builtinFormat(name string) string {
+	switch name {
+	case "int32", "uint32", "rune":
+		return "int32"
+	case "int64", "uint64":
+		return "int64"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	}
+	return ""
+}
+
+// wellKnown maps common types from outside the program to schemas.
+var wellKnown = map[string]model.Schema{
+	"time.Time":                             {Type: "string", Format: "date-time"},
+	"time.Duration":                         {Type: "integer", Format: "int64"},
+	"encoding/json.RawMessage":              {},
+	"encoding/json.Number":                  {Type: "number"},
+	"net/url.URL":                           {Type: "string", Format: "uri"},
+	"net.IP":                                {Type: "string", Format: "ipv4"},
+	"math/big.Int":                          {Type: "integer"},
+	"database/sql.NullString":               {Type: "string", Nullable: true},
+	"database/sql.NullInt64":                {Type: "integer", Format: "int64", Nullable: true},
+	"database/sql.NullInt32":                {Type: "integer", Format: "int32", Nullable: true},
+	"database/sql.NullBool":                 {Type: "boolean", Nullable: true},
+	"database/sql.NullFloat64":              {Type: "number", Format: "double", Nullable: true},
+	"database/sql.NullTime":                 {Type: "string", Format: "date-time", Nullable: true},
+	"github.com/google/uuid.UUID":           {Type: "string", Format: "uuid"},
+	"github.com/gofrs/uuid.UUID":            {Type: "string", Format: "uuid"},
+	"github.com/shopspring/decimal.Decimal": {Type: "string", Format: "decimal"},
+}
+
+func (a *analyzer) selectorSchema(f *File, t *ast.SelectorExpr) *model.Schema {
+	p, name, ok := a.qualify(f, t)
+	if !ok {
+		return &model.Schema{}
+	}
+	if p == "C" {
+		return a.cType(t)
+	}
+	if s, ok := wellKnown[p+"."+name]; ok {
+		return &s
+	}
+	if td := a.types[p+"."+name]; td != nil {
+		return a.namedSchema(td)
+	}
+	return &model.Schema{Type: "object", GoType: exprString(t)}
+}
+
+// structProperties adds the JSON properties of a struct type to s.
+// Embedded structs without a JSON name are flattened, as encoding/json
+// does; seen guards against embedding cycles.
+func (a *analyzer) structProperties(f *File, st *ast.StructType, s *model.Schema, seen map[string]bool) {
+	if s.Properties == nil {
+		s.Properties = map[string]*model.Schema{}
+	}
+	for _, field := range st.Fields.List {
+		tag := fieldTag(field)
+		jsonName, opts := parseJSONTag(tag.Get("json"))
+		if jsonName == "-" && opts == "" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			if jsonName == "" {
+				if td := a.lookupType(f, derefExpr(field.Type)); td != nil {
+					if emb, ok := td.Spec.Type.(*ast.StructType); ok && !seen[typeKey(td)] {
+						seen[typeKey(td)] = true
+						a.structProperties(td.File, emb, s, seen)
+						continue
+					}
+				}
+			}
+			name := embeddedName(field.Type)
+			if !ast.IsExported(name) {
+				continue
+			}
+			a.addProperty(f, s, field, name, jsonName, opts, tag)
+			continue
+		}
+		for _, id := range field.Names {
+			if !id.IsExported() {
+				continue
+			}
+			a.addProperty(f, s, field, id.Name, jsonName, opts, tag)
+		}
+	}
+}
+
+func (a *analyzer) addProperty(f *File, s *model.Schema, field *ast.Field, goName, jsonName, opts string, tag reflect.StructTag) {
+	if jsonName == "" {
+		jsonName = goName
+	}
+	prop := a.schemaForType(f, field.Type)
+	if strings.Contains(","+opts+",", ",string,") && prop.Ref == "" {
+		prop.Type, prop.Format = "string", ""
+	}
+	if doc := fieldDoc(field); doc != "" {
+		// OpenAPI 3.0 tooling ignores siblings of $ref, but the
+		// description is still useful to our own renderers.
+		prop.Description = doc
+	}
+	if ex := tag.Get("example"); ex != "" {
+		prop.Example = parseExample(ex, prop.Type)
+	}
+	applySwagTags(prop, tag)
+	required := applyValidation(prop, tag.Get("validate"))
+	if applyValidation(prop, tag.Get("binding")) {
+		required = true
+	}
+	// encoding/json always writes fields without omitempty and never
+	// writes nil pointers with omitempty. Pointers without omitempty, as in
+	// PATCH bodies, stay optional unless a validate or binding tag says
+	// otherwise, since decoding accepts them absent.
+	omitempty := strings.Contains(","+opts+",", ",omitempty,")
+	_, isPtr := field.Type.(*ast.StarExpr)
+	if !omitempty && !isPtr {
+		required = true
+	} else if omitempty && isPtr {
+		prop.Nullable = false
+	}
+
+	if _, exists := s.Properties[jsonName]; !exists {
+		s.PropertyOrder = append(s.PropertyOrder, jsonName)
+	}
+	s.Properties[jsonName] = prop
+	if required && !s.IsRequired(jsonName) {
+		s.Required = append(s.Required, jsonName)
+	}
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	s, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(s)
+}
+
+func parseJSONTag(tag string) (string, string) {
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+func fieldDoc(field *ast.Field) string {
+	if field.Doc != nil {
+		return strings.TrimSpace(field.Doc.Text())
+	}
+	if field.Comment != nil {
+		return strings.TrimSpace(field.Comment.Text())
+	}
+	return ""
+}
+
+func derefExpr(expr ast.Expr) ast.Expr {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return star.X
+	}
+	return expr
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := derefExpr(expr).(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	}
+	return ""
+}
+
+// applySwagTags applies the schema tags understood by swaggo: format,
+// pattern, minimum, maximum, minLength, maxLength and enums.
+func applySwagTags(s *model.Schema, tag reflect.StructTag) {
+	if v := tag.Get("format"); v != "" {
+		s.Format = v
+	}
+	if v := tag.Get("pattern"); v != "" {
+		s.Pattern = v
+	}
+	for key, bound := range map[string]**float64{"minimum": &s.Minimum, "maximum": &s.Maximum} {
+		if n, err := strconv.ParseFloat(tag.Get(key), 64); err == nil {
+			*bound = &n
+		}
+	}
+	for key, bound := range map[string]**int{"minLength": &s.MinLength, "maxLength": &s.MaxLength} {
+		if n, err := strconv.Atoi(tag.Get(key)); err == nil {
+			*bound = &n
+		}
+	}
+	if v := tag.Get("enums"); v != "" {
+		for _, e := range strings.Split(v, ",") {
+			s.Enum = append(s.Enum, parseExample(e, s.Type))
+		}
+	}
+}
+
+// applyValidation maps go-playground/validator rules onto schema
+// constraints and reports whether the field is required.
+func applyValidation(s *model.Schema, rules string) bool {
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url", "uri", "http_url":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "datetime":
+			s.Format = "date-time"
+		case "ipv4", "ipv6", "hostname":
+			s.Format = name
+		case "alpha":
+			s.Pattern = "^[a-zA-Z]+$"
+		case "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]+$"
+		case "numeric":
+			s.Pattern = "^[0-9]+$"
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				s.Enum = append(s.Enum, parseExample(v, s.Type))
+			}
+		case "min", "gte", "max", "lte", "len", "gt", "lt":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(s, name, n)
+		}
+	}
+	return required
+}
+
+func applyBound(s *model.Schema, rule string, n float64) {
+	if s.Type == "string" {
+		i := int(n)
+		switch rule {
+		case "min", "gte":
+			s.MinLength = &i
+		case "gt":
+			i++
+			s.MinLength = &i
+		case "max", "lte":
+			s.MaxLength = &i
+		case "lt":
+			i--
+			s.MaxLength = &i
+		case "len":
+			s.MinLength, s.MaxLength = &i, &i
+		}
+		return
+	}
+	if s.Type != "integer" && s.Type != "number" {
+		return
+	}
+	switch rule {
+	case "min", "gte", "gt":
+		s.Minimum = &n
+	case "max", "lte", "lt":
+		s.Maximum = &n
+	case "len":
+		s.Minimum, s.Maximum = &n, &n
+	}
+}
+
+// parseExample converts an example tag value to the schema's type.
+func parseExample(v, typ string) any {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// exprString renders a type or value expression compactly.
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(e.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(e.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(e.Key) + "]" + exprString(e.Value)
+	case *ast.CallExpr:
+		return exprString(e.Fun) + "(...)"
+	case *ast.IndexExpr:
+		return exprString(e.X) + "[" + exprString(e.Index) + "]"
+	case *ast.ParenExpr:
+		return exprString(e.X)
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.FuncLit:
+		return "func literal"
+	case *ast.BasicLit:
+		return e.Value
+	case *ast.UnaryExpr:
+		return e.Op.String() + exprString(e.X)
+	}
+	return "?"
+}
+
+// lowerFirst lowercases the first rune of s.
+func lowerFirst(s string) string {
+	for i, r := range s {
+		return string(unicode.ToLower(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"net/http"
+	"sort"
+)
+
+// idempotencyHeaders are the request headers carrying an idempotency key.
+var idempotencyHeaders = []string{"Idempotency-Key", "X-Idempotency-Key"}
+
+// preconditions maps each precondition request header to the status
+// answering a request whose precondition does not hold. If-Range has no
+// such status: a stale validator gets the full 200 response, and the 206
+// partial response is documented with the Range support of
+// http.ServeContent.
+var preconditions = map[string]int{
+	"If-None-Match":       http.StatusNotModified,
+	"If-Modified-Since":   http.StatusNotModified,
+	"If-Match":            http.StatusPreconditionFailed,
+	"If-Unmodified-Since": http.StatusPreconditionFailed,
+}
+
+// serveContentHeaders are the precondition headers http.ServeContent and
+// http.ServeFile evaluate.
+var serveContentHeaders = []string{"If-Match", "If-Modified-Since", "If-None-Match", "If-Range", "If-Unmodified-Since"}
+
+// semantics derives the HTTP semantics a handler implements from the
+// request headers it reads and the net/http helpers it calls, adding the
+// responses they imply.
+func (h *handlerFacts) semantics() {
+	for _, p := range h.params {
+		if p.In != "header" {
+			continue
+		}
+		name := http.CanonicalHeaderKey(p.Name)
+		if containsString(idempotencyHeaders, name) {
+			h.idempotencyKey = p.Name
+			continue
+		}
+		// Reading a precondition header is not honoring it: the handler
+		// must write the status or compare the value.
+		if code, ok := preconditions[name]; ok && (h.responses[code] != nil || containsString(h.compared, name)) {
+			h.precondition(name, code)
+		}
+	}
+	if h.ranges {
+		for _, name := range serveContentHeaders {
+			h.precondition(name, preconditions[name])
+		}
+		ok := h.response(http.StatusOK)
+		if !containsString(ok.headers, "Accept-Ranges") {
+			ok.headers = append(ok.headers, "Accept-Ranges")
+		}
+		partial := h.response(http.StatusPartialContent)
+		if !containsString(partial.headers, "Content-Range") {
+			partial.headers = append(partial.headers, "Content-Range")
+		}
+		h.response(http.StatusRequestedRangeNotSatisfiable)
+	}
+	if h.maxBodyBytes > 0 {
+		h.response(http.StatusRequestEntityTooLarge)
+	}
+	sort.Strings(h.conditional)
+}
+
+// precondition records a precondition header the handler honors and,
+// unless code is 0, the response to a request failing it.
+func (h *handlerFacts) precondition(name string, code int) {
+	if containsString(h.conditional, name) {
+		return
+	}
+	h.conditional = append(h.conditional, name)
+	if code == 0 {
+		return
+	}
+	r := h.response(code)
+	// Response headers are recorded in canonical form, Etag for ETag.
+	if name == "If-None-Match" && !containsString(r.headers, "Etag") {
+		r.headers = append(r.headers, "Etag")
+	}
+}
@@ -0,0 +1,508 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Sharding splits the analysis of a large program across processes.
+//
+// The packages no other package of the program imports, usually the main
+// packages of binaries, are the roots of the program. Each shard analyzes
+// the packages reachable through the imports of some of the roots, which
+// is everything the analysis of those roots can look at. Roots are spread
+// over the shards so that roots sharing many packages land in the same
+// shard, keeping the packages parsed by more than one shard few.
+//
+// A shard's analysis is a Partial: the endpoint candidates, schemas,
+// routers and diagnostics the shard's packages produce, before the steps
+// that depend on the whole program. Merge completes the partials of all
+// shards with the same steps an unsharded analysis ends with, so the
+// merged document is identical to the document of the whole program.
+
+// partialVersion versions the Partial format.
+const partialVersion = 3
+
+// Shard selects part Index of Count parts of a program; Index counts from
+// 1. The zero Shard selects the whole program.
+type Shard struct {
+	Index int `json:"index"`
+	Count int `json:"count"`
+}
+
+// ParseShard parses a shard written as "i/n".
+func ParseShard(s string) (Shard, error) {
+	i, n, ok := strings.Cut(s, "/")
+	index, err1 := strconv.Atoi(strings.TrimSpace(i))
+	count, err2 := strconv.Atoi(strings.TrimSpace(n))
+	if !ok || err1 != nil || err2 != nil || count < 1 || index < 1 || index > count {
+		return Shard{}, fmt.Errorf("invalid shard %q (want i/n with 1 <= i <= n)", s)
+	}
+	return Shard{Index: index, Count: count}, nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// Partial is the analysis of one shard of a program. Its fields are an
+// exchange format between the shards and Merge of one build of the
+// analyzer, not a stable interface.
+type Partial struct {
+	Version int    `json:"version"`
+	Shard   Shard  `json:"shard"`
+	Plan    string `json:"plan"`
+	Module  string `json:"module,omitempty"`
+	// RootName names the service when no main package does.
+	RootName string            `json:"rootName"`
+	Packages []*model.Package  `json:"packages"`
+	Services []*PartialService `json:"services,omitempty"`
+	Routers  []*PartialRouter  `json:"routers,omitempty"`
+	// Endpoints lists the endpoint candidates.
+	Endpoints []*PartialEndpoint `json:"endpoints"`
+	// Reached maps every registration to the routers it is reachable
+	// from.
+	Reached map[string][]string `json:"reached,omitempty"`
+	// Attached lists the routers attached to a parent router.
+	Attached []string `json:"attached,omitempty"`
+	// Registered lists the functions registered as handlers.
+	Registered []string `json:"registered,omitempty"`
+	// Schemas are keyed by the import path and name of their types.
+	Schemas map[string]*model.Schema `json:"schemas"`
+	// SchemaNames holds the names //apidoc:schema-name directives give
+	// to the schemas, and Naming the naming options of the analysis.
+	SchemaNames map[string]string   `json:"schemaNames,omitempty"`
+	Naming      SchemaNaming        `json:"naming"`
+	Diagnostics []*model.Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// PartialService is a main package, which may name the service.
+type PartialService struct {
+	ImportPath string `json:"importPath"`
+	Name       string `json:"name"`
+	Registers  bool   `json:"registers,omitempty"`
+}
+
+// PartialRouter is a function returning the routers Bases.
+type PartialRouter struct {
+	Func   string        `json:"func"`
+	Bases  []string      `json:"bases"`
+	Router *model.Router `json:"router"`
+}
+
+// PartialEndpoint is an endpoint candidate. Candidates are considered in
+// Rank order; the first one claiming a method and path wins.
+type PartialEndpoint struct {
+	Rank string `json:"rank"`
+	// Registration identifies the route registration the candidate comes
+	// from, and Root the router it was reached from when that router is
+	// attached nowhere in the shard. Another shard may attach it, making
+	// the candidate void.
+	Registration string `json:"registration,omitempty"`
+	Root         string `json:"root,omitempty"`
+	// Func is the documented handler of a candidate declared by a
+	// "Route:" section, void when the handler is registered anywhere.
+	Func            string                           `json:"func,omitempty"`
+	Endpoint        *model.Endpoint                  `json:"endpoint"`
+	SecuritySchemes map[string]*model.SecurityScheme `json:"securitySchemes,omitempty"`
+	Middleware      map[string]*model.Middleware     `json:"middleware,omitempty"`
+
+	// trace records how the candidate was built, for Explain.
+	trace *candidateTrace
+}
+
+// AnalyzeShard loads the packages of the shard opts.Shard of the program
+// under root and returns their partial analysis.
+func AnalyzeShard(root string, opts Options) (*Partial, error) {
+	if opts.Shard.Count == 0 {
+		return nil, fmt.Errorf("no shard selected")
+	}
+	prog, err := Load(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newAnalyzer(prog).collect(), nil
+}
+
+// Merge combines the partials of every shard of a program into the
+// document of the whole program.
+func Merge(parts []*Partial) (*model.Document, error) {
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("no shards to merge")
+	}
+	first := parts[0]
+	have := map[int]bool{}
+	for _, p := range parts {
+		switch {
+		case p.Version != partialVersion:
+			return nil, fmt.Errorf("shard %s was written by another version of the analyzer", p.Shard)
+		case p.Shard.Count != first.Shard.Count || p.Plan != first.Plan:
+			return nil, fmt.Errorf("shards %s and %s were planned for different sources or options", first.Shard, p.Shard)
+		case have[p.Shard.Index]:
+			return nil, fmt.Errorf("shard %s given twice", p.Shard)
+		}
+		have[p.Shard.Index] = true
+	}
+	var missing []string
+	for i := 1; i <= first.Shard.Count; i++ {
+		if !have[i] {
+			missing = append(missing, Shard{Index: i, Count: first.Shard.Count}.String())
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing shards %s", strings.Join(missing, ", "))
+	}
+	doc, _ := finish(parts)
+	return doc, nil
+}
+
+// finish completes partials into a document. It also returns the names
+// given to the schemas, keyed by type.
+func finish(parts []*Partial) (*model.Document, map[string]string) {
+	first := parts[0]
+	doc := &model.Document{
+		Module:          first.Module,
+		Schemas:         map[string]*model.Schema{},
+		SecuritySchemes: map[string]*model.SecurityScheme{},
+		Middleware:      map[string]*model.Middleware{},
+		Endpoints:       []*model.Endpoint{},
+	}
+	attached := map[string]bool{}
+	registered := map[string]bool{}
+	reached := map[string][]string{}
+	candidates := map[string]*PartialEndpoint{}
+	routers := map[string]*PartialRouter{}
+	services := map[string]*PartialService{}
+	packages := map[string]*model.Package{}
+	diags := map[string]*model.Diagnostic{}
+	for _, p := range parts {
+		for _, pkg := range p.Packages {
+			if key := pkg.ImportPath + "\x00" + pkg.Name; packages[key] == nil {
+				packages[key] = pkg
+			}
+		}
+		for key, s := range p.Schemas {
+			if doc.Schemas[key] == nil {
+				doc.Schemas[key] = s
+			}
+		}
+		for _, id := range p.Attached {
+			attached[id] = true
+		}
+		for _, id := range p.Registered {
+			registered[id] = true
+		}
+		for reg, bases := range p.Reached {
+			reached[reg] = union(reached[reg], bases)
+		}
+		for _, c := range p.Endpoints {
+			if candidates[c.Rank] == nil {
+				candidates[c.Rank] = c
+			}
+		}
+		for _, r := range p.Routers {
+			if routers[r.Func] == nil {
+				routers[r.Func] = r
+			}
+		}
+		for _, s := range p.Services {
+			if services[s.ImportPath] == nil {
+				services[s.ImportPath] = s
+			}
+		}
+		for _, d := range p.Diagnostics {
+			diags[diagKey(d)] = d
+		}
+	}
+
+	for _, key := range sortedKeys(packages) {
+		doc.Packages = append(doc.Packages, packages[key])
+	}
+	sort.SliceStable(doc.Packages, func(i, j int) bool {
+		return doc.Packages[i].ImportPath < doc.Packages[j].ImportPath
+	})
+
+	// Candidates are taken in the order an analysis of the whole program
+	// builds them, skipping those that a shard could not tell were void.
+	ids := map[string]int{}
+	seen := map[string]bool{}
+	count := map[string]int{}
+	for _, rank := range sortedKeys(candidates) {
+		c := candidates[rank]
+		if c.Root != "" && attached[c.Root] || c.Func != "" && registered[c.Func] {
+			continue
+		}
+		for _, name := range sortedKeys(c.SecuritySchemes) {
+			if doc.SecuritySchemes[name] == nil {
+				doc.SecuritySchemes[name] = c.SecuritySchemes[name]
+			}
+		}
+		ep := c.Endpoint
+		if seen[ep.Key()] {
+			continue
+		}
+		seen[ep.Key()] = true
+		for _, name := range sortedKeys(c.Middleware) {
+			if doc.Middleware[name] == nil {
+				doc.Middleware[name] = c.Middleware[name]
+			}
+		}
+		ids[ep.ID]++
+		if n := ids[ep.ID]; n > 1 {
+			ep.ID += "_" + strconv.Itoa(n)
+		}
+		doc.Endpoints = append(doc.Endpoints, ep)
+		for _, b := range reached[c.Registration] {
+			count[b]++
+		}
+	}
+
+	// Routers are listed most endpoints first.
+	for _, key := range sortedKeys(routers) {
+		r := routers[key]
+		n := 0
+		for _, b := range r.Bases {
+			n += count[b]
+		}
+		if n > 0 {
+			router := *r.Router
+			router.Endpoints = n
+			doc.Routers = append(doc.Routers, &router)
+		}
+	}
+	sort.SliceStable(doc.Routers, func(i, j int) bool {
+		return doc.Routers[i].Endpoints > doc.Routers[j].Endpoints
+	})
+
+	doc.Service = serviceName(services, first.RootName)
+
+	directives := map[string]string{}
+	for _, p := range parts {
+		for key, name := range p.SchemaNames {
+			directives[key] = name
+		}
+	}
+	names, collisions := nameSchemas(doc.Schemas, first.Module, first.Naming, directives)
+	for _, d := range collisions {
+		diags[diagKey(d)] = d
+	}
+	doc.RenameSchemas(names)
+	linkOperations(doc)
+
+	for _, key := range sortedKeys(diags) {
+		doc.Diagnostics = append(doc.Diagnostics, diags[key])
+	}
+	sort.SliceStable(doc.Diagnostics, func(i, j int) bool {
+		return positionLess(doc.Diagnostics[i].Pos, doc.Diagnostics[j].Pos)
+	})
+
+	doc.SortEndpoints()
+	if len(doc.SecuritySchemes) == 0 {
+		doc.SecuritySchemes = nil
+	}
+	if len(doc.Middleware) == 0 {
+		doc.Middleware = nil
+	}
+	return doc, names
+}
+
+// serviceName names the upstream service after the binary that serves the
+// API: the first main package that registers routes, or the first main
+// package, falling back to the module or root directory name. services
+// are keyed by import path.
+func serviceName(services map[string]*PartialService, rootName string) string {
+	paths := sortedKeys(services)
+	for _, p := range paths {
+		if services[p].Registers {
+			return services[p].Name
+		}
+	}
+	if len(paths) > 0 {
+		return services[paths[0]].Name
+	}
+	return rootName
+}
+
+func positionLess(a, b *model.Position) bool {
+	switch {
+	case a == nil || b == nil:
+		return a == nil && b != nil
+	case a.File != b.File:
+		return a.File < b.File
+	case a.Line != b.Line:
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+func diagKey(d *model.Diagnostic) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s", d.Pos, d.Code, d.Severity, d.Message, d.Suggestion)
+}
+
+func union(a, b []string) []string {
+	for _, s := range b {
+		if !containsString(a, s) {
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+// planShard returns the directories of dirs that shard opts.Shard
+// analyzes, and a digest of the partition identifying it to Merge.
+func (prog *Program) planShard(dirs []string, opts Options) ([]string, string, error) {
+	type node struct {
+		dir     string
+		files   int
+		imports []string
+		deps    []int
+	}
+	// The plan hashes the sources and the assignment, so that shards
+	// planned for different sources are not merged.
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n", opts.Shard.Count)
+	fmt.Fprintf(h, "naming %s\n", opts.Naming)
+	var nodes []*node
+	byPath := map[string]int{}
+	for _, dir := range dirs {
+		rel := prog.rel(dir)
+		names, err := sourceFiles(dir, rel, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(names) == 0 {
+			continue
+		}
+		n := &node{dir: dir, files: len(names)}
+		for _, name := range names {
+			src, err := opts.fs().ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return nil, "", err
+			}
+			fmt.Fprintf(h, "file %s %d\n", path.Join(rel, name), len(src))
+			h.Write(src)
+			f, _ := parser.ParseFile(token.NewFileSet(), name, src, parser.ImportsOnly)
+			if f == nil {
+				continue
+			}
+			for _, imp := range f.Imports {
+				if p, err := strconv.Unquote(imp.Path.Value); err == nil && !containsString(n.imports, p) {
+					n.imports = append(n.imports, p)
+				}
+			}
+		}
+		byPath[prog.importPath(rel)] = len(nodes)
+		nodes = append(nodes, n)
+	}
+	imported := make([]bool, len(nodes))
+	for i, n := range nodes {
+		for _, p := range n.imports {
+			if j, ok := byPath[p]; ok && j != i {
+				n.deps = append(n.deps, j)
+				imported[j] = true
+			}
+		}
+	}
+	closure := func(root int) map[int]bool {
+		set := map[int]bool{root: true}
+		stack := []int{root}
+		for len(stack) > 0 {
+			n := nodes[stack[len(stack)-1]]
+			stack = stack[:len(stack)-1]
+			for _, d := range n.deps {
+				if !set[d] {
+					set[d] = true
+					stack = append(stack, d)
+				}
+			}
+		}
+		return set
+	}
+	weight := func(set map[int]bool) int {
+		w := 0
+		for i := range set {
+			w += nodes[i].files
+		}
+		return w
+	}
+
+	// Roots are the packages nothing imports, and packages only reached
+	// through an import cycle.
+	type root struct {
+		node    int
+		closure map[int]bool
+		weight  int
+	}
+	var roots []*root
+	covered := map[int]bool{}
+	addRoot := func(i int) {
+		r := &root{node: i, closure: closure(i)}
+		r.weight = weight(r.closure)
+		for j := range r.closure {
+			covered[j] = true
+		}
+		roots = append(roots, r)
+	}
+	for i := range nodes {
+		if !imported[i] {
+			addRoot(i)
+		}
+	}
+	for i := range nodes {
+		if !covered[i] {
+			addRoot(i)
+		}
+	}
+
+	// Heaviest roots first, each to the shard that is lightest once it
+	// holds the root's packages. A shard already holding most of them
+	// grows least, so roots sharing packages tend to share a shard.
+	sort.SliceStable(roots, func(i, j int) bool { return roots[i].weight > roots[j].weight })
+	shards := make([]map[int]bool, opts.Shard.Count)
+	weights := make([]int, len(shards))
+	assigned := make([][]string, len(shards))
+	for k := range shards {
+		shards[k] = map[int]bool{}
+	}
+	for _, r := range roots {
+		best, bestWeight := 0, -1
+		for k, set := range shards {
+			w := weights[k]
+			for i := range r.closure {
+				if !set[i] {
+					w += nodes[i].files
+				}
+			}
+			if bestWeight < 0 || w < bestWeight {
+				best, bestWeight = k, w
+			}
+		}
+		for i := range r.closure {
+			shards[best][i] = true
+		}
+		weights[best] = bestWeight
+		assigned[best] = append(assigned[best], prog.rel(nodes[r.node].dir))
+	}
+
+	for k, rs := range assigned {
+		sort.Strings(rs)
+		fmt.Fprintf(h, "%d %s\n", k+1, strings.Join(rs, " "))
+	}
+	var selected []string
+	for i, n := range nodes {
+		if shards[opts.Shard.Index-1][i] {
+			selected = append(selected, n.dir)
+		}
+	}
+	return selected, hex.EncodeToString(h.Sum(nil)), nil
+}
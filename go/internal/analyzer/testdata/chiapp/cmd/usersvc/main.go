@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httprate"
+
+	"example.com/chiapp/handlers"
+)
+
+const apiPrefix = "/api/v1"
+
+func main() {
+	r := chi.NewRouter()
+	r.Get("/healthz", handlers.Health)
+	r.Route(apiPrefix, func(r chi.Router) {
+		r.Use(httprate.LimitByIP(100, time.Minute))
+		r.Use(handlers.RequireAuth)
+		r.Get("/users", handlers.ListUsers)
+		r.Post("/users", handlers.CreateUser)
+		r.Get("/users/{id:[0-9]+}", handlers.GetUser)
+	})
+	http.ListenAndServe(":8080", r)
+}
@@ -0,0 +1,3 @@
+module example.com/chiapp
+
+go 1.21
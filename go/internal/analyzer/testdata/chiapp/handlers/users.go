@@ -0,0 +1,71 @@
+// Package handlers implements the user service endpoints.
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// User is a registered account.
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email,omitempty"`
+}
+
+// Health reports service health.
+func Health(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequireAuth rejects requests without a bearer token.
+func RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ListUsers returns a page of users.
+//
+// Query Parameters:
+//   - limit: Maximum number of users to return (optional)
+func ListUsers(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	users := make([]User, 0, limit)
+	json.NewEncoder(w).Encode(users)
+}
+
+// CreateUser registers a new user.
+//
+// Returns:
+//   - 201 Created - The created user
+//   - 400 Bad Request - Invalid payload
+func CreateUser(w http.ResponseWriter, r *http.Request) {
+	var u User
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(u)
+}
+
+// GetUser returns a single user.
+//
+// Deprecated: use the accounts service instead.
+func GetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(User{ID: id})
+}
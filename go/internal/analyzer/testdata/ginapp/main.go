@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Order is a customer order.
+type Order struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+func main() {
+	r := gin.Default()
+	v1 := r.Group("/v1", APIKeyAuth())
+	registerOrders(v1)
+	r.Run(":8080")
+}
+
+func registerOrders(g *gin.RouterGroup) {
+	orders := g.Group("/orders")
+	orders.GET("/:id", getOrder)
+	orders.DELETE("/:id", deleteOrder)
+	orders.GET("/files/*path", downloadFile)
+}
+
+// APIKeyAuth checks the X-Api-Key header.
+func APIKeyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("X-Api-Key") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	}
+}
+
+func getOrder(c *gin.Context) {
+	c.JSON(http.StatusOK, Order{ID: c.Param("id")})
+}
+
+func deleteOrder(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
+
+func downloadFile(c *gin.Context) {
+	c.String(http.StatusOK, c.Param("path"))
+}
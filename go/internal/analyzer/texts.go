@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// DocText is a piece of source text that ends up in the API document: a
+// line of a doc comment on a schema type, field, enum constant or handler,
+// or the value of an example struct tag.
+type DocText struct {
+	// Kind is "comment" or "example".
+	Kind string
+	Text string
+	// Pos is the position of the first byte of Text.
+	Pos *model.Position
+	// Offset is the byte offset of Text in Pos.File.
+	Offset int
+	// Editable reports whether Text appears verbatim in the source, so
+	// that edits at Offset replace exactly the bytes of Text.
+	Editable bool
+	// Owners lists the schemas and endpoints the text documents.
+	Owners []DocOwner
+}
+
+// DocOwner is a schema or endpoint documented by a DocText.
+type DocOwner struct {
+	// Kind is "schema" or "endpoint".
+	Kind string
+	// Name is the schema name or the endpoint's "METHOD /path".
+	Name string
+	Pos  *model.Position
+}
+
+// AnalyzeTexts extracts the API document like AnalyzeProgram and also
+// returns the source texts it was built from, in source order per owner.
+func AnalyzeTexts(prog *Program) (*model.Document, []*DocText) {
+	a := newAnalyzer(prog)
+	doc, names := finish([]*Partial{a.collect()})
+	owners := map[string]string{}
+	for key, name := range names {
+		owners[name] = key
+	}
+	c := &textCollector{a: a, seen: map[token.Pos]*DocText{}}
+	for _, name := range doc.SchemaNames() {
+		td := a.types[owners[name]]
+		if td == nil {
+			continue
+		}
+		owner := DocOwner{Kind: "schema", Name: name, Pos: doc.Schemas[name].Source}
+		c.comments(td.Doc, owner)
+		if st, ok := td.Spec.Type.(*ast.StructType); ok {
+			for _, field := range st.Fields.List {
+				c.comments(field.Doc, owner)
+				c.comments(field.Comment, owner)
+				c.example(field.Tag, owner)
+			}
+		}
+		for _, k := range a.enums[typeKey(td)] {
+			c.comments(k.Comments, owner)
+		}
+	}
+	handlers := map[string]*funcDecl{}
+	for _, fd := range a.funcs {
+		handlers[fd.Key()] = fd
+	}
+	for _, ep := range doc.Endpoints {
+		if fd := handlers[ep.Handler]; fd != nil {
+			c.comments(fd.Decl.Doc, DocOwner{Kind: "endpoint", Name: ep.Key(), Pos: ep.Source})
+		}
+	}
+	return doc, c.texts
+}
+
+type textCollector struct {
+	a     *analyzer
+	texts []*DocText
+	// seen dedupes texts shared by several owners, such as the doc
+	// comment of a handler registered on two routes.
+	seen map[token.Pos]*DocText
+}
+
+func (c *textCollector) add(kind, text string, pos token.Pos, editable bool, owner DocOwner) {
+	if t := c.seen[pos]; t != nil {
+		t.Owners = append(t.Owners, owner)
+		return
+	}
+	p := c.a.prog.Fset.Position(pos)
+	t := &DocText{
+		Kind:     kind,
+		Text:     text,
+		Pos:      &model.Position{File: p.Filename, Line: p.Line, Column: p.Column},
+		Offset:   p.Offset,
+		Editable: editable,
+		Owners:   []DocOwner{owner},
+	}
+	c.seen[pos] = t
+	c.texts = append(c.texts, t)
+}
+
+func (c *textCollector) comments(g *ast.CommentGroup, owner DocOwner) {
+	if g == nil {
+		return
+	}
+	for _, cm := range g.List {
+		text := cm.Text[2:]
+		if strings.HasPrefix(cm.Text, "/*") {
+			text = strings.TrimSuffix(text, "*/")
+		}
+		if strings.HasPrefix(text, "go:") || strings.HasPrefix(text, "nolint") {
+			continue
+		}
+		c.add("comment", text, cm.Slash+2, true, owner)
+	}
+}
+
+// example records the value of an example struct tag. Its source bytes
+// are known exactly only in raw string tags, which hold the value
+// unescaped.
+func (c *textCollector) example(tag *ast.BasicLit, owner DocOwner) {
+	if tag == nil {
+		return
+	}
+	raw, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return
+	}
+	value, ok := reflect.StructTag(raw).Lookup("example")
+	if !ok || value == "" {
+		return
+	}
+	if tag.Value[0] == '`' {
+		if i := strings.Index(tag.Value, `example:"`+value+`"`); i >= 0 {
+			c.add("example", value, tag.ValuePos+token.Pos(i+len(`example:"`)), true, owner)
+			return
+		}
+	}
+	c.add("example", value, tag.ValuePos, false, owner)
+}
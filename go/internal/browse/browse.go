@@ -0,0 +1,499 @@
+// Package browse implements an interactive terminal browser for an
+// extracted API document.
+//
+// The left pane lists endpoints grouped by tag and filtered by a fuzzy
+// search; the right pane shows the selected endpoint, or a schema opened
+// from it. The browser only needs an ANSI terminal, so it works over SSH.
+// Browser holds all state and renders to plain lines, which keeps it
+// independent of the terminal; Run connects it to one.
+package browse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Key names the keys the browser handles; printable keys are the
+// characters themselves.
+type Key string
+
+// Special keys.
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyPageUp    Key = "pgup"
+	KeyPageDown  Key = "pgdown"
+	KeyHome      Key = "home"
+	KeyEnd       Key = "end"
+	KeyEnter     Key = "enter"
+	KeyTab       Key = "tab"
+	KeyEsc       Key = "esc"
+	KeyBackspace Key = "backspace"
+	KeyCtrlC     Key = "ctrl+c"
+)
+
+// untagged groups endpoints without tags.
+const untagged = "(untagged)"
+
+// item is a row of the endpoint list: a tag heading when ep is nil.
+type item struct {
+	tag string
+	ep  *model.Endpoint
+}
+
+// Browser is the state of the browser.
+type Browser struct {
+	doc    *model.Document
+	title  string
+	width  int
+	height int
+
+	items  []item
+	cursor int
+	// top is the first list row shown.
+	top int
+
+	query     string
+	searching bool
+	// inDetail is set when keys go to the detail pane.
+	inDetail bool
+	// schemas is the stack of schemas opened from the selected endpoint.
+	schemas []string
+	scroll  int
+	status  string
+	quit    bool
+	// open is set when the user asked to open the source in an editor.
+	open bool
+}
+
+// New returns a browser over doc; title is shown in the header.
+func New(doc *model.Document, title string) *Browser {
+	b := &Browser{doc: doc, title: title, width: 80, height: 24}
+	b.filter()
+	return b
+}
+
+// Resize sets the terminal size.
+func (b *Browser) Resize(width, height int) {
+	b.width, b.height = width, height
+	b.follow()
+}
+
+// Done reports whether the user asked to quit.
+func (b *Browser) Done() bool {
+	return b.quit
+}
+
+// Selected returns the endpoint under the cursor, or nil.
+func (b *Browser) Selected() *model.Endpoint {
+	if b.cursor < len(b.items) {
+		return b.items[b.cursor].ep
+	}
+	return nil
+}
+
+// Source returns the source position of what the detail pane shows.
+func (b *Browser) Source() *model.Position {
+	if n := len(b.schemas); n > 0 {
+		if s := b.doc.Schema(b.schemas[n-1]); s != nil {
+			return s.Source
+		}
+		return nil
+	}
+	if ep := b.Selected(); ep != nil {
+		return ep.Source
+	}
+	return nil
+}
+
+// TakeOpen reports whether the user asked to open the source of the
+// detail pane in an editor since the last call.
+func (b *Browser) TakeOpen() bool {
+	open := b.open
+	b.open = false
+	return open
+}
+
+// SetStatus shows a message in the status line until the next key.
+func (b *Browser) SetStatus(format string, args ...any) {
+	b.status = fmt.Sprintf(format, args...)
+}
+
+// filter rebuilds the list from the query. Without a query, tags are
+// listed alphabetically with their endpoints in document order; with one,
+// the best matching tags and endpoints come first.
+func (b *Browser) filter() {
+	type match struct {
+		ep    *model.Endpoint
+		score int
+	}
+	groups := map[string][]match{}
+	best := map[string]int{}
+	for _, ep := range b.doc.Endpoints {
+		score, ok := fuzzyScore(b.query, searchText(ep))
+		if !ok {
+			continue
+		}
+		tags := ep.Tags
+		if len(tags) == 0 {
+			tags = []string{untagged}
+		}
+		for _, t := range tags {
+			groups[t] = append(groups[t], match{ep, score})
+			if score > best[t] {
+				best[t] = score
+			}
+		}
+	}
+	tags := sortedKeys(groups)
+	if len(groups[untagged]) > 0 {
+		tags = append(removeString(tags, untagged), untagged)
+	}
+	if b.query != "" {
+		sort.SliceStable(tags, func(i, j int) bool { return best[tags[i]] > best[tags[j]] })
+	}
+
+	selected := b.Selected()
+	b.items = b.items[:0]
+	b.cursor, b.top = 0, 0
+	for _, t := range tags {
+		ms := groups[t]
+		if b.query != "" {
+			sort.SliceStable(ms, func(i, j int) bool { return ms[i].score > ms[j].score })
+		}
+		b.items = append(b.items, item{tag: t})
+		for _, m := range ms {
+			b.items = append(b.items, item{tag: t, ep: m.ep})
+		}
+	}
+	for i, it := range b.items {
+		if it.ep != nil && (it.ep == selected || b.items[b.cursor].ep == nil) {
+			b.cursor = i
+			if it.ep == selected {
+				break
+			}
+		}
+	}
+	b.follow()
+}
+
+func removeString(ss []string, s string) []string {
+	out := ss[:0]
+	for _, x := range ss {
+		if x != s {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+func searchText(ep *model.Endpoint) string {
+	return strings.Join([]string{ep.Method, ep.Path, ep.Summary, ep.OperationID, ep.Handler, strings.Join(ep.Tags, " ")}, " ")
+}
+
+// Handle updates the browser for a key press.
+func (b *Browser) Handle(k Key) {
+	b.status = ""
+	if k == KeyCtrlC {
+		b.quit = true
+		return
+	}
+	if b.searching {
+		b.handleSearch(k)
+		return
+	}
+	if b.inDetail {
+		b.handleDetail(k)
+		return
+	}
+	switch k {
+	case "q":
+		b.quit = true
+	case KeyUp, "k":
+		b.move(-1)
+	case KeyDown, "j":
+		b.move(1)
+	case KeyPageUp:
+		b.move(-b.listHeight())
+	case KeyPageDown:
+		b.move(b.listHeight())
+	case KeyHome, "g":
+		b.move(-len(b.items))
+	case KeyEnd, "G":
+		b.move(len(b.items))
+	case "/":
+		b.searching = true
+	case "o":
+		b.open = true
+	case KeyEsc:
+		if b.query != "" {
+			b.query = ""
+			b.filter()
+		}
+	case KeyEnter, KeyTab, KeyRight, "l":
+		if b.Selected() != nil {
+			b.inDetail = true
+		}
+	default:
+		b.openRef(k)
+	}
+}
+
+func (b *Browser) handleSearch(k Key) {
+	switch k {
+	case KeyEnter, KeyDown, KeyUp:
+		b.searching = false
+		if k != KeyEnter {
+			b.Handle(k)
+		}
+		return
+	case KeyEsc:
+		b.searching = false
+		b.query = ""
+	case KeyBackspace:
+		if r := []rune(b.query); len(r) > 0 {
+			b.query = string(r[:len(r)-1])
+		}
+	default:
+		if len([]rune(string(k))) != 1 {
+			return
+		}
+		b.query += string(k)
+	}
+	b.schemas, b.scroll = nil, 0
+	b.filter()
+}
+
+func (b *Browser) handleDetail(k Key) {
+	switch k {
+	case "q":
+		b.quit = true
+	case KeyUp, "k":
+		b.scrollBy(-1)
+	case KeyDown, "j":
+		b.scrollBy(1)
+	case KeyPageUp:
+		b.scrollBy(-b.listHeight())
+	case KeyPageDown, " ":
+		b.scrollBy(b.listHeight())
+	case KeyHome, "g":
+		b.scroll = 0
+	case KeyEnd, "G":
+		b.scrollBy(len(b.detail().lines))
+	case KeyEsc, KeyBackspace, KeyLeft, "h":
+		if n := len(b.schemas); n > 0 {
+			b.schemas = b.schemas[:n-1]
+			b.scroll = 0
+		} else {
+			b.inDetail = false
+		}
+	case KeyTab:
+		b.inDetail = false
+	case "o":
+		b.open = true
+	case "/":
+		b.inDetail = false
+		b.searching = true
+	default:
+		b.openRef(k)
+	}
+}
+
+// openRef opens the schema numbered k in the detail pane.
+func (b *Browser) openRef(k Key) {
+	if len(k) != 1 || k[0] < '1' || k[0] > '9' {
+		return
+	}
+	refs := b.detail().refs
+	n := int(k[0] - '1')
+	if n >= len(refs) {
+		b.SetStatus("no schema [%s]", k)
+		return
+	}
+	b.schemas = append(b.schemas, refs[n])
+	b.scroll = 0
+	b.inDetail = true
+}
+
+// move moves the cursor by n endpoints, skipping tag headings.
+func (b *Browser) move(n int) {
+	if len(b.items) == 0 {
+		return
+	}
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for i := b.cursor; n > 0; n-- {
+		j := i + step
+		for j >= 0 && j < len(b.items) && b.items[j].ep == nil {
+			j += step
+		}
+		if j < 0 || j >= len(b.items) {
+			break
+		}
+		i = j
+		b.cursor = i
+	}
+	b.schemas, b.scroll = nil, 0
+	b.follow()
+}
+
+// follow scrolls the list to keep the cursor, and the heading of the
+// first group, visible.
+func (b *Browser) follow() {
+	h := b.listHeight()
+	if b.cursor < b.top {
+		b.top = b.cursor
+	}
+	if b.cursor >= b.top+h {
+		b.top = b.cursor - h + 1
+	}
+	if b.top == 1 {
+		b.top = 0
+	}
+}
+
+func (b *Browser) scrollBy(n int) {
+	max := len(b.detail().lines) - b.listHeight()
+	b.scroll += n
+	if b.scroll > max {
+		b.scroll = max
+	}
+	if b.scroll < 0 {
+		b.scroll = 0
+	}
+}
+
+// listHeight is the number of rows between the header and status lines.
+func (b *Browser) listHeight() int {
+	if h := b.height - 2; h > 1 {
+		return h
+	}
+	return 1
+}
+
+// listWidth is the width of the endpoint list; the detail pane takes the
+// rest of the screen after a one column separator.
+func (b *Browser) listWidth() int {
+	w := b.width * 2 / 5
+	if w > 60 {
+		w = 60
+	}
+	if w < 20 {
+		w = 20
+	}
+	return w
+}
+
+func (b *Browser) detailWidth() int {
+	if w := b.width - b.listWidth() - 3; w > 10 {
+		return w
+	}
+	return 10
+}
+
+// detail builds the detail pane for the current view.
+func (b *Browser) detail() *detail {
+	if n := len(b.schemas); n > 0 {
+		return schemaDetail(b.doc, b.schemas[n-1], b.detailWidth())
+	}
+	if ep := b.Selected(); ep != nil {
+		return endpointDetail(b.doc, ep, b.detailWidth())
+	}
+	d := &detail{width: b.detailWidth()}
+	if len(b.doc.Endpoints) == 0 {
+		d.text(0, "No endpoints were found.")
+	} else {
+		d.text(0, "No endpoints match the search.")
+	}
+	return d
+}
+
+// ANSI styles.
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleReverse = "\x1b[7m"
+	styleDim     = "\x1b[2m"
+)
+
+// View renders the screen as one string per terminal row, with ANSI
+// styles.
+func (b *Browser) View() []string {
+	rows := make([]string, 0, b.height)
+	header := fmt.Sprintf(" %s  %d endpoints  %d schemas", b.title, len(b.doc.Endpoints), len(b.doc.Schemas))
+	rows = append(rows, styleReverse+pad(header, b.width)+styleReset)
+
+	lw, dw := b.listWidth(), b.detailWidth()
+	d := b.detail()
+	for i := 0; i < b.listHeight(); i++ {
+		var left string
+		if n := b.top + i; n < len(b.items) {
+			it := b.items[n]
+			switch {
+			case it.ep == nil:
+				left = styleBold + pad(it.tag, lw) + styleReset
+			case n == b.cursor && !b.inDetail && !b.searching:
+				left = styleReverse + pad(endpointRow(it.ep), lw) + styleReset
+			case n == b.cursor:
+				left = styleBold + pad(endpointRow(it.ep), lw) + styleReset
+			default:
+				left = pad(endpointRow(it.ep), lw)
+			}
+		} else {
+			left = pad("", lw)
+		}
+		right := ""
+		if n := b.scroll + i; n < len(d.lines) {
+			l := d.lines[n]
+			right = pad(l.text, dw)
+			if l.heading {
+				right = styleBold + right + styleReset
+			}
+		}
+		rows = append(rows, left+" "+styleDim+"│"+styleReset+" "+right)
+	}
+
+	var status string
+	switch {
+	case b.searching:
+		status = "/" + b.query + "█"
+	case b.status != "":
+		status = b.status
+	case b.inDetail:
+		status = "↑↓ scroll  1-9 open schema  ← back  o open in $EDITOR  / search  q quit"
+	default:
+		status = "↑↓ move  → details  1-9 open schema  / search  o open in $EDITOR  q quit"
+		if b.query != "" {
+			status = "filter: " + b.query + "  (esc clears)  " + status
+		}
+	}
+	rows = append(rows, pad(status, b.width))
+	return rows
+}
+
+func endpointRow(ep *model.Endpoint) string {
+	row := fmt.Sprintf("  %-6s %s", ep.Method, ep.Path)
+	if ep.Deprecated {
+		row += " (deprecated)"
+	}
+	return row
+}
+
+// pad truncates or pads s to exactly width cells.
+func pad(s string, width int) string {
+	r := []rune(s)
+	if len(r) > width {
+		if width <= 1 {
+			return string(r[:width])
+		}
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(r))
+}
@@ -0,0 +1,151 @@
+package browse
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func testDoc() *model.Document {
+	return &model.Document{
+		Service: "usersvc",
+		Endpoints: []*model.Endpoint{
+			{
+				Method: "GET", Path: "/users", Summary: "List users", Tags: []string{"users"},
+				Handler: "handlers.ListUsers", Source: &model.Position{File: "handlers/users.go", Line: 12},
+				Parameters: []*model.Parameter{{Name: "limit", In: "query", Schema: &model.Schema{Type: "integer"}, Description: "Maximum number of users"}},
+				Responses:  []*model.Response{{StatusCode: "200", Description: "OK", Schema: &model.Schema{Type: "array", Items: model.RefTo("User")}}},
+			},
+			{
+				Method: "POST", Path: "/users", Summary: "Create a user", Tags: []string{"users"},
+				RequestBody: &model.RequestBody{ContentType: "application/json", Required: true, Schema: model.RefTo("User")},
+				Responses:   []*model.Response{{StatusCode: "201", Schema: model.RefTo("User")}, {StatusCode: "400", Schema: model.RefTo("Error")}},
+			},
+			{Method: "GET", Path: "/healthz", Summary: "Health check"},
+			{Method: "GET", Path: "/orders/{id}", Summary: "Get an order", Tags: []string{"orders"}, Deprecated: true},
+		},
+		Schemas: map[string]*model.Schema{
+			"User": {
+				Type: "object", Description: "A registered user.", GoType: "handlers.User",
+				Source:        &model.Position{File: "handlers/types.go", Line: 8},
+				Properties:    map[string]*model.Schema{"id": {Type: "string", Format: "uuid"}, "role": model.RefTo("Role")},
+				PropertyOrder: []string{"id", "role"},
+				Required:      []string{"id"},
+			},
+			"Role":  {Type: "string", Enum: []any{"admin", "member"}, EnumVarNames: []string{"RoleAdmin", "RoleMember"}},
+			"Error": {Type: "object"},
+		},
+	}
+}
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func screen(b *Browser) string {
+	return ansi.ReplaceAllString(strings.Join(b.View(), "\n"), "")
+}
+
+func TestList(t *testing.T) {
+	b := New(testDoc(), "usersvc")
+	b.Resize(100, 20)
+	var rows []string
+	for _, it := range b.items {
+		if it.ep == nil {
+			rows = append(rows, it.tag)
+		} else {
+			rows = append(rows, "  "+it.ep.Key())
+		}
+	}
+	assert.Equal(t, []string{"orders", "  GET /orders/{id}", "users", "  GET /users", "  POST /users", "(untagged)", "  GET /healthz"}, rows)
+	assert.Equal(t, "GET /orders/{id}", b.Selected().Key())
+
+	b.Handle(KeyDown)
+	assert.Equal(t, "GET /users", b.Selected().Key())
+	b.Handle(KeyEnd)
+	assert.Equal(t, "GET /healthz", b.Selected().Key())
+	b.Handle(KeyUp)
+	assert.Equal(t, "POST /users", b.Selected().Key())
+
+	for _, k := range []Key{"/", "c", "r", "u"} {
+		b.Handle(k)
+	}
+	assert.Equal(t, "POST /users", b.Selected().Key())
+	require.Len(t, b.items, 2)
+	b.Handle(KeyEnter)
+	assert.False(t, b.searching)
+	assert.Contains(t, screen(b), "filter: cru")
+	b.Handle(KeyEsc)
+	assert.Len(t, b.items, 7)
+	assert.Equal(t, "POST /users", b.Selected().Key())
+}
+
+func TestDetail(t *testing.T) {
+	b := New(testDoc(), "usersvc")
+	b.Resize(100, 30)
+	b.Handle(KeyDown)
+	out := screen(b)
+	rows := b.View()
+	assert.Len(t, rows, 30)
+	assert.Contains(t, out, " usersvc  4 endpoints  3 schemas")
+	assert.Contains(t, out, "│ GET /users")
+	assert.Contains(t, out, "│ Source     handlers/users.go:12")
+	assert.Contains(t, out, "│   limit  query  integer")
+	assert.Contains(t, out, "│     Maximum number of users")
+	assert.Contains(t, out, "│   200  OK  array of [1] User")
+	assert.Equal(t, &model.Position{File: "handlers/users.go", Line: 12}, b.Source())
+
+	b.Handle("1")
+	assert.True(t, b.inDetail)
+	out = screen(b)
+	assert.Contains(t, out, "│ User")
+	assert.Contains(t, out, "│ A registered user.")
+	assert.Contains(t, out, "│   id  string (uuid)  required")
+	assert.Contains(t, out, "│   role  [1] Role")
+	assert.Contains(t, out, "│   GET /users")
+	assert.Equal(t, "handlers/types.go", b.Source().File)
+
+	b.Handle("1")
+	assert.Contains(t, screen(b), `│   "admin"  RoleAdmin`)
+	b.Handle("7")
+	assert.Contains(t, screen(b), "no schema [7]")
+	b.Handle(KeyBackspace)
+	b.Handle(KeyBackspace)
+	assert.Contains(t, screen(b), "│ GET /users")
+	b.Handle("o")
+	assert.True(t, b.TakeOpen())
+	assert.False(t, b.TakeOpen())
+	b.Handle(KeyBackspace)
+	assert.False(t, b.inDetail)
+	b.Handle("q")
+	assert.True(t, b.Done())
+}
+
+func TestFuzzyScore(t *testing.T) {
+	_, ok := fuzzyScore("usr post", "POST /users Create a user")
+	assert.True(t, ok)
+	_, ok = fuzzyScore("orders", "GET /users")
+	assert.False(t, ok)
+	boundary, _ := fuzzyScore("cu", "POST /users Create a user")
+	inner, _ := fuzzyScore("cu", "GET /accounts")
+	assert.Greater(t, boundary, inner)
+}
+
+func TestParseKeys(t *testing.T) {
+	assert.Equal(t, []Key{KeyUp, "j", KeyEsc, KeyPageDown, KeyEnter, "é", KeyBackspace, KeyCtrlC},
+		parseKeys([]byte("\x1b[Aj\x1b\x1b[6~\ré\x7f\x03")))
+	assert.Equal(t, []Key{"a"}, parseKeys([]byte("\x1b[99za")))
+}
+
+func TestEditorCommand(t *testing.T) {
+	assert.Equal(t, []string{"vim", "+12", "/src/a.go"}, editorCommand("vim", "/src/a.go", 12))
+	assert.Equal(t, []string{"code", "--wait", "-g", "/src/a.go:12"}, editorCommand("code --wait", "/src/a.go", 12))
+	assert.Equal(t, []string{"/usr/bin/hx", "/src/a.go:12"}, editorCommand("/usr/bin/hx", "/src/a.go", 12))
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, []string{"one  two", "three", "", "  - four five", "  six"}, wrap("one  two three\n\n  - four five six", 13))
+}
@@ -0,0 +1,401 @@
+package browse
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// line is one line of the detail pane.
+type line struct {
+	text string
+	// heading lines are drawn in bold.
+	heading bool
+}
+
+// detail builds the detail pane of an endpoint or schema. Schemas it
+// mentions are numbered in order of appearance; refs lists them so that
+// the number keys can open them.
+type detail struct {
+	doc   *model.Document
+	width int
+	lines []line
+	refs  []string
+}
+
+func (d *detail) heading(s string) {
+	if len(d.lines) > 0 {
+		d.blank()
+	}
+	d.lines = append(d.lines, line{text: s, heading: true})
+}
+
+func (d *detail) blank() {
+	d.lines = append(d.lines, line{})
+}
+
+// text adds s wrapped to the pane width with the given indent.
+func (d *detail) text(indent int, s string) {
+	for _, l := range wrap(s, d.width-indent) {
+		d.lines = append(d.lines, line{text: strings.Repeat(" ", indent) + l})
+	}
+}
+
+// field adds a "Label  value" row.
+func (d *detail) field(label, value string) {
+	if value != "" {
+		d.text(0, fmt.Sprintf("%-11s%s", label, value))
+	}
+}
+
+// ref returns the numbered label of a schema reference.
+func (d *detail) ref(name string) string {
+	for i, r := range d.refs {
+		if r == name {
+			return refLabel(i, name)
+		}
+	}
+	d.refs = append(d.refs, name)
+	return refLabel(len(d.refs)-1, name)
+}
+
+func refLabel(i int, name string) string {
+	if i < 9 {
+		return fmt.Sprintf("[%d] %s", i+1, name)
+	}
+	return name
+}
+
+// typeOf describes a schema in one line, e.g. "array of [1] User".
+func (d *detail) typeOf(s *model.Schema) string {
+	switch {
+	case s == nil:
+		return "any"
+	case s.Ref != "":
+		return d.ref(s.RefName())
+	case len(s.OneOf) > 0:
+		alts := make([]string, len(s.OneOf))
+		for i, alt := range s.OneOf {
+			alts[i] = d.typeOf(alt)
+		}
+		return "one of " + strings.Join(alts, ", ")
+	case s.Type == "array":
+		return "array of " + d.typeOf(s.Items)
+	case s.Type == "object" && s.AdditionalProperties != nil && len(s.Properties) == 0:
+		return "map of " + d.typeOf(s.AdditionalProperties)
+	}
+	t := s.Type
+	if t == "" {
+		t = "any"
+	}
+	if s.Format != "" {
+		t += " (" + s.Format + ")"
+	}
+	if len(s.Enum) > 0 {
+		t += " " + enumList(s.Enum)
+	}
+	if s.Nullable {
+		t += ", nullable"
+	}
+	return t
+}
+
+func enumList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// endpointDetail describes an endpoint.
+func endpointDetail(doc *model.Document, ep *model.Endpoint, width int) *detail {
+	d := &detail{doc: doc, width: width}
+	d.lines = append(d.lines, line{text: ep.Key(), heading: true})
+	if ep.Summary != "" {
+		d.text(0, ep.Summary)
+	}
+	if ep.Deprecated {
+		if ep.Sunset != "" {
+			d.text(0, "Deprecated, sunset on "+ep.Sunset+".")
+		} else {
+			d.text(0, "Deprecated.")
+		}
+	}
+	if ep.Description != "" && ep.Description != ep.Summary {
+		d.blank()
+		d.text(0, ep.Description)
+	}
+	d.blank()
+	d.field("Operation", ep.OperationID)
+	d.field("Tags", strings.Join(ep.Tags, ", "))
+	d.field("Handler", ep.Handler)
+	if ep.Source != nil {
+		d.field("Source", ep.Source.String())
+	}
+	d.field("Security", strings.Join(ep.Security, ", "))
+	d.field("Middleware", strings.Join(ep.Middleware, ", "))
+	if rl := ep.RateLimit; rl != nil {
+		d.field("Rate limit", fmt.Sprintf("%d per %s", rl.Requests, rl.Period))
+	}
+	if ep.MaxBodyBytes > 0 {
+		d.field("Body limit", fmt.Sprintf("%d bytes", ep.MaxBodyBytes))
+	}
+	d.field("Idempotent", ep.IdempotencyKey)
+	d.field("Conditional", strings.Join(ep.Conditional, ", "))
+	if ep.Ranges {
+		d.field("Ranges", "bytes")
+	}
+	if lr := ep.LongRunning; lr != nil {
+		poll := lr.Location
+		if lr.StatusEndpoint != "" {
+			poll = lr.StatusEndpoint
+		}
+		if lr.StateProperty != "" {
+			poll += fmt.Sprintf(" until %s is final (%s)", lr.StateProperty, strings.Join(lr.States, ", "))
+		}
+		d.field("Async", "202 Accepted, poll "+poll)
+	}
+
+	if len(ep.Parameters) > 0 {
+		d.heading("Parameters")
+		for _, p := range ep.Parameters {
+			row := fmt.Sprintf("  %s  %s  %s", p.Name, p.In, d.typeOf(p.Schema))
+			if p.Required {
+				row += "  required"
+			}
+			d.text(0, row)
+			if p.Description != "" {
+				d.text(4, p.Description)
+			}
+			if p.Example != nil {
+				d.text(4, "Example: "+example(p.Example))
+			}
+		}
+	}
+	if b := ep.RequestBody; b != nil {
+		d.heading("Request body")
+		row := "  " + b.ContentType
+		if b.Schema != nil {
+			row += "  " + d.typeOf(b.Schema)
+		}
+		if b.Required {
+			row += "  required"
+		}
+		d.text(0, row)
+		if b.Description != "" {
+			d.text(4, b.Description)
+		}
+		if b.Example != nil {
+			d.text(4, "Example: "+example(b.Example))
+		}
+	}
+	if len(ep.Responses) > 0 {
+		d.heading("Responses")
+		for _, r := range ep.Responses {
+			row := "  " + r.StatusCode
+			if r.Description != "" {
+				row += "  " + r.Description
+			}
+			if r.Schema != nil {
+				row += "  " + d.typeOf(r.Schema)
+			}
+			d.text(0, row)
+			for _, h := range sortedKeys(r.Headers) {
+				d.text(4, fmt.Sprintf("Header %s: %s", h, r.Headers[h]))
+			}
+		}
+	}
+	d.refList()
+	return d
+}
+
+// schemaDetail describes a component schema.
+func schemaDetail(doc *model.Document, name string, width int) *detail {
+	d := &detail{doc: doc, width: width}
+	s := doc.Schema(name)
+	if s == nil {
+		d.lines = append(d.lines, line{text: name, heading: true})
+		d.text(0, "Schema not found.")
+		return d
+	}
+	d.lines = append(d.lines, line{text: name, heading: true})
+	if s.Description != "" {
+		d.text(0, s.Description)
+	}
+	d.blank()
+	d.field("Type", d.typeOf(&model.Schema{Type: s.Type, Format: s.Format, Nullable: s.Nullable}))
+	d.field("Go type", s.GoType)
+	if s.Source != nil {
+		d.field("Source", s.Source.String())
+	}
+	if s.Example != nil {
+		d.field("Example", example(s.Example))
+	}
+
+	if len(s.Properties) > 0 {
+		d.heading("Properties")
+		for _, prop := range s.PropertyNames() {
+			p := s.Properties[prop]
+			row := fmt.Sprintf("  %s  %s", prop, d.typeOf(p))
+			if s.IsRequired(prop) {
+				row += "  required"
+			}
+			d.text(0, row)
+			if p.Description != "" {
+				d.text(4, p.Description)
+			}
+		}
+	}
+	if len(s.Enum) > 0 {
+		d.heading("Values")
+		for i, v := range s.Enum {
+			row := "  " + example(v)
+			if i < len(s.EnumVarNames) {
+				row += "  " + s.EnumVarNames[i]
+			}
+			d.text(0, row)
+			if i < len(s.EnumDescriptions) && s.EnumDescriptions[i] != "" {
+				d.text(4, s.EnumDescriptions[i])
+			}
+		}
+	}
+	if len(s.OneOf) > 0 {
+		d.heading("One of")
+		for _, alt := range s.OneOf {
+			d.text(0, "  "+d.typeOf(alt))
+		}
+	}
+	if s.Items != nil {
+		d.heading("Items")
+		d.text(0, "  "+d.typeOf(s.Items))
+	}
+	if s.AdditionalProperties != nil && len(s.Properties) == 0 {
+		d.heading("Values")
+		d.text(0, "  "+d.typeOf(s.AdditionalProperties))
+	}
+
+	var usedBy []string
+	for _, ep := range doc.Endpoints {
+		if uses(ep, name) {
+			usedBy = append(usedBy, ep.Key())
+		}
+	}
+	if len(usedBy) > 0 {
+		d.heading("Used by")
+		for _, k := range usedBy {
+			d.text(0, "  "+k)
+		}
+	}
+	d.refList()
+	return d
+}
+
+// refList lists the numbered schemas at the end of the pane.
+func (d *detail) refList() {
+	if len(d.refs) == 0 {
+		return
+	}
+	d.heading("Schemas (press the number to open)")
+	for i, name := range d.refs {
+		if i == 9 {
+			break
+		}
+		d.text(0, "  "+refLabel(i, name))
+	}
+}
+
+// uses reports whether an endpoint refers to the named schema directly.
+func uses(ep *model.Endpoint, name string) bool {
+	var schemas []*model.Schema
+	for _, p := range ep.Parameters {
+		schemas = append(schemas, p.Schema)
+	}
+	if ep.RequestBody != nil {
+		schemas = append(schemas, ep.RequestBody.Schema)
+	}
+	for _, r := range ep.Responses {
+		schemas = append(schemas, r.Schema)
+	}
+	for _, s := range schemas {
+		if mentions(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func mentions(s *model.Schema, name string) bool {
+	if s == nil {
+		return false
+	}
+	if s.RefName() == name || mentions(s.Items, name) || mentions(s.AdditionalProperties, name) {
+		return true
+	}
+	for _, alt := range s.OneOf {
+		if mentions(alt, name) {
+			return true
+		}
+	}
+	for _, p := range s.Properties {
+		if mentions(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func example(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// wrap breaks text into lines of at most width runes. Paragraph breaks,
+// indentation and the spacing between words, which aligns columns, are
+// kept.
+func wrap(text string, width int) []string {
+	if width < 10 {
+		width = 10
+	}
+	var out []string
+	for _, src := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		indent := src[:len(src)-len(strings.TrimLeft(src, " \t"))]
+		cur := indent
+		for _, m := range words.FindAllStringSubmatch(src[len(indent):], -1) {
+			sep, w := m[1], m[2]
+			switch {
+			case cur == indent:
+				cur += w
+			case runeLen(cur)+runeLen(sep)+runeLen(w) > width:
+				out = append(out, cur)
+				cur = indent + w
+			default:
+				cur += sep + w
+			}
+		}
+		out = append(out, strings.TrimRight(cur, " \t"))
+	}
+	return out
+}
+
+var words = regexp.MustCompile(`(\s*)(\S+)`)
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
@@ -0,0 +1,53 @@
+package browse
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fuzzyScore matches a query against s. Every word of the query must
+// appear in s as a subsequence, ignoring case. Higher scores mean better
+// matches: runs of consecutive characters and matches at the start of
+// words count more.
+func fuzzyScore(query, s string) (int, bool) {
+	total := 0
+	for _, word := range strings.Fields(query) {
+		score, ok := subsequence([]rune(strings.ToLower(word)), s)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+func subsequence(pattern []rune, s string) (int, bool) {
+	score, i, run := 0, 0, 0
+	prev := ' '
+	for _, r := range s {
+		if i == len(pattern) {
+			break
+		}
+		if unicode.ToLower(r) == pattern[i] {
+			i++
+			run++
+			score += run
+			if isBoundary(prev, r) {
+				score += 3
+			}
+		} else {
+			run = 0
+		}
+		prev = r
+	}
+	return score, i == len(pattern)
+}
+
+// isBoundary reports whether r starts a word after prev, as in "/users",
+// "get_user" or "GetUser".
+func isBoundary(prev, r rune) bool {
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(r)
+}
@@ -0,0 +1,193 @@
+package browse
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Options configures Run.
+type Options struct {
+	// Root is the directory source positions are relative to.
+	Root string
+	// Editor is the command opening source files; it defaults to $VISUAL,
+	// then $EDITOR, then vi.
+	Editor string
+}
+
+// Run runs the browser on the terminal attached to standard input and
+// output until the user quits.
+func Run(b *Browser, opts Options) error {
+	t, err := openTerminal(os.Stdin, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("browse needs an interactive terminal: %w", err)
+	}
+	s := &session{b: b, t: t, opts: opts}
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+	resized, stop := notifyResize()
+	defer stop()
+
+	s.draw()
+	buf := make([]byte, 256)
+	for !b.Done() {
+		select {
+		case <-resized:
+			s.draw()
+		default:
+		}
+		n, err := t.read(buf)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			continue
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			b.Handle(k)
+			if b.TakeOpen() {
+				s.edit()
+			}
+		}
+		s.draw()
+	}
+	return nil
+}
+
+type session struct {
+	b    *Browser
+	t    *terminal
+	opts Options
+}
+
+// enter switches to raw mode and the alternate screen.
+func (s *session) enter() error {
+	if err := s.t.raw(); err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	return nil
+}
+
+// leave restores the terminal.
+func (s *session) leave() {
+	fmt.Fprint(os.Stdout, "\x1b[0m\x1b[?25h\x1b[?1049l")
+	s.t.restore()
+}
+
+func (s *session) draw() {
+	s.b.Resize(s.t.size())
+	var out strings.Builder
+	for i, row := range s.b.View() {
+		fmt.Fprintf(&out, "\x1b[%d;1H%s\x1b[K", i+1, row)
+	}
+	fmt.Fprint(os.Stdout, out.String())
+}
+
+// edit opens the source of the detail pane in the editor, handing it the
+// terminal until it exits.
+func (s *session) edit() {
+	pos := s.b.Source()
+	if pos == nil {
+		s.b.SetStatus("no source location")
+		return
+	}
+	file := pos.File
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(s.opts.Root, file)
+	}
+	editor := s.opts.Editor
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor == "" {
+			editor = os.Getenv(env)
+		}
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := editorCommand(editor, file, pos.Line)
+	s.leave()
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	if enterErr := s.enter(); err == nil {
+		err = enterErr
+	}
+	if err != nil {
+		s.b.SetStatus("%s: %v", args[0], err)
+	}
+}
+
+// editorCommand returns the command line opening file at line in editor,
+// which may include arguments. Editors are told the line the way they
+// expect: "+line file" for vi, emacs, nano and most terminal editors,
+// "-g file:line" for VS Code and "file:line" for Sublime Text and Helix.
+func editorCommand(editor, file string, line int) []string {
+	args := strings.Fields(editor)
+	switch filepath.Base(args[0]) {
+	case "code", "code-insiders", "codium":
+		return append(args, "-g", file+":"+strconv.Itoa(line))
+	case "subl", "hx", "helix", "zed":
+		return append(args, file+":"+strconv.Itoa(line))
+	}
+	return append(args, "+"+strconv.Itoa(line), file)
+}
+
+// escapeKeys maps the escape sequences of special keys.
+var escapeKeys = map[string]Key{
+	"\x1b[A": KeyUp, "\x1b[B": KeyDown, "\x1b[C": KeyRight, "\x1b[D": KeyLeft,
+	"\x1bOA": KeyUp, "\x1bOB": KeyDown, "\x1bOC": KeyRight, "\x1bOD": KeyLeft,
+	"\x1b[5~": KeyPageUp, "\x1b[6~": KeyPageDown,
+	"\x1b[H": KeyHome, "\x1b[F": KeyEnd, "\x1bOH": KeyHome, "\x1bOF": KeyEnd,
+	"\x1b[1~": KeyHome, "\x1b[4~": KeyEnd,
+}
+
+// parseKeys splits terminal input into keys. An escape byte not starting
+// a known sequence is the Esc key; unknown sequences are dropped.
+func parseKeys(in []byte) []Key {
+	var keys []Key
+	s := string(in)
+	for len(s) > 0 {
+		if s[0] == 0x1b && len(s) > 1 && (s[1] == '[' || s[1] == 'O') {
+			end := 2
+			for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+				end++
+			}
+			if end < len(s) {
+				end++
+			}
+			if k, ok := escapeKeys[s[:end]]; ok {
+				keys = append(keys, k)
+			}
+			s = s[end:]
+			continue
+		}
+		switch s[0] {
+		case 0x1b:
+			keys = append(keys, KeyEsc)
+		case '\r', '\n':
+			keys = append(keys, KeyEnter)
+		case '\t':
+			keys = append(keys, KeyTab)
+		case 0x7f, 0x08:
+			keys = append(keys, KeyBackspace)
+		case 0x03:
+			keys = append(keys, KeyCtrlC)
+		default:
+			r, size := utf8.DecodeRuneInString(s)
+			if r >= 0x20 && r != utf8.RuneError {
+				keys = append(keys, Key(string(r)))
+			}
+			s = s[size:]
+			continue
+		}
+		s = s[1:]
+	}
+	return keys
+}
@@ -0,0 +1,23 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package browse
+
+import (
+	"errors"
+	"os"
+)
+
+type terminal struct{}
+
+func openTerminal(in, out *os.File) (*terminal, error) {
+	return nil, errors.New("terminal control is not supported on this platform")
+}
+
+func (t *terminal) raw() error                   { return nil }
+func (t *terminal) restore() error               { return nil }
+func (t *terminal) size() (int, int)             { return 80, 24 }
+func (t *terminal) read(buf []byte) (int, error) { return 0, nil }
+
+func notifyResize() (<-chan os.Signal, func()) {
+	return nil, func() {}
+}
@@ -0,0 +1,86 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package browse
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// winsize is struct winsize of TIOCGWINSZ.
+type winsize struct {
+	rows, cols, xpixel, ypixel uint16
+}
+
+// terminal is the controlling terminal in raw mode.
+type terminal struct {
+	in, out *os.File
+	saved   syscall.Termios
+}
+
+func ioctl(fd uintptr, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// openTerminal fails unless in and out are both terminals.
+func openTerminal(in, out *os.File) (*terminal, error) {
+	t := &terminal{in: in, out: out}
+	if err := ioctl(in.Fd(), ioctlGetTermios, unsafe.Pointer(&t.saved)); err != nil {
+		return nil, err
+	}
+	var ws winsize
+	if err := ioctl(out.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// raw puts the terminal in raw mode, where reads return after a tenth of
+// a second even when no key was pressed.
+func (t *terminal) raw() error {
+	tio := t.saved
+	tio.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	tio.Oflag &^= syscall.OPOST
+	tio.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	tio.Cflag &^= syscall.CSIZE | syscall.PARENB
+	tio.Cflag |= syscall.CS8
+	tio.Cc[syscall.VMIN] = 0
+	tio.Cc[syscall.VTIME] = 1
+	return ioctl(t.in.Fd(), ioctlSetTermios, unsafe.Pointer(&tio))
+}
+
+// restore returns the terminal to the mode it was opened in.
+func (t *terminal) restore() error {
+	return ioctl(t.in.Fd(), ioctlSetTermios, unsafe.Pointer(&t.saved))
+}
+
+// size returns the terminal width and height.
+func (t *terminal) size() (int, int) {
+	var ws winsize
+	if err := ioctl(t.out.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil || ws.cols == 0 {
+		return 80, 24
+	}
+	return int(ws.cols), int(ws.rows)
+}
+
+// read reads pending input; it returns 0 bytes when no key was pressed.
+func (t *terminal) read(buf []byte) (int, error) {
+	n, err := syscall.Read(int(t.in.Fd()), buf)
+	if err == syscall.EINTR || err == syscall.EAGAIN {
+		return 0, nil
+	}
+	return n, err
+}
+
+// notifyResize returns a channel receiving a value when the terminal is
+// resized, and a function to stop the notifications.
+func notifyResize() (<-chan os.Signal, func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGWINCH)
+	return c, func() { signal.Stop(c) }
+}
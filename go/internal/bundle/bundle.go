@@ -0,0 +1,177 @@
+// Package bundle writes debug bundles: zip archives holding what a bug
+// report needs to reproduce a failed analysis.
+//
+// A bundle records the tool and Go versions, the command line, the
+// configuration file, the stack traces of the panics the run recovered
+// from and the sources of the packages they happened in. Sources can be
+// redacted before they are shared, keeping their structure but not the
+// text of comments and string literals.
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Bundle is the content of a debug bundle.
+type Bundle struct {
+	// Version is the version of api-doc-gen-go.
+	Version string
+	// Args are the command-line arguments of the run.
+	Args []string
+	// ConfigName is the configuration file of the run and Config its
+	// contents; both are empty when there was none.
+	ConfigName string
+	Config     []byte
+	// Error is the error the run failed with, if any.
+	Error string
+	// Panics are the panics the run recovered from.
+	Panics []*analyzer.Panic
+	// Redact replaces the text of comments and string literals in the
+	// captured sources; see Redact.
+	Redact bool
+}
+
+// Manifest describes a bundle; it is stored as manifest.json.
+type Manifest struct {
+	Version string `json:"version"`
+	// Build is the module version and VCS revision api-doc-gen-go was
+	// built from, when the binary records them.
+	Build     string   `json:"build,omitempty"`
+	GoVersion string   `json:"goVersion"`
+	Platform  string   `json:"platform"`
+	Args      []string `json:"args"`
+	Config    string   `json:"config,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Redacted  bool     `json:"redacted"`
+	Panics    []*Entry `json:"panics"`
+	// Sources lists the captured source files, relative to sources/.
+	Sources []string `json:"sources"`
+}
+
+// Entry describes one recovered panic; its stack trace is stored in the
+// file named by Stack.
+type Entry struct {
+	Package string          `json:"package,omitempty"`
+	Stage   string          `json:"stage"`
+	Value   string          `json:"value"`
+	Pos     *model.Position `json:"pos,omitempty"`
+	Stack   string          `json:"stack"`
+}
+
+// Write writes the bundle to the zip archive at name.
+func (b *Bundle) Write(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := b.write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (b *Bundle) write(w io.Writer) error {
+	m := &Manifest{
+		Version:   b.Version,
+		Build:     build(),
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Args:      b.Args,
+		Error:     b.Error,
+		Redacted:  b.Redact,
+		Panics:    []*Entry{},
+		Sources:   []string{},
+	}
+	files := map[string][]byte{}
+	for i, p := range b.Panics {
+		m.Panics = append(m.Panics, &Entry{
+			Package: p.Package,
+			Stage:   p.Stage,
+			Value:   p.Value,
+			Pos:     p.Pos,
+			Stack:   fmt.Sprintf("stacks/%d.txt", i+1),
+		})
+		for name, src := range p.Files {
+			files[name] = src
+		}
+	}
+	for name := range files {
+		m.Sources = append(m.Sources, name)
+	}
+	sort.Strings(m.Sources)
+	if b.ConfigName != "" {
+		m.Config = "config/" + filepath.Base(b.ConfigName)
+	}
+
+	zw := zip.NewWriter(w)
+	add := func(name string, data []byte) error {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(data)
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add("manifest.json", append(data, '\n')); err != nil {
+		return err
+	}
+	if m.Config != "" {
+		if err := add(m.Config, b.Config); err != nil {
+			return err
+		}
+	}
+	for i, p := range b.Panics {
+		if err := add(m.Panics[i].Stack, []byte(p.Stack)); err != nil {
+			return err
+		}
+	}
+	for _, name := range m.Sources {
+		src := files[name]
+		if b.Redact && strings.HasSuffix(name, ".go") {
+			src = Redact(src)
+		}
+		if err := add(path.Join("sources", name), src); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// build returns the module version and VCS revision recorded in the
+// running binary.
+func build() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	s := info.Main.Version
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			s += " " + setting.Value
+		case "vcs.modified":
+			if setting.Value == "true" {
+				s += " (modified)"
+			}
+		}
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,90 @@
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func TestRedact(t *testing.T) {
+	src := "//go:build linux\n\n" +
+		"// Package users serves Ünïcode secrets.\n" +
+		"package users\n\n" +
+		"import \"github.com/go-chi/chi/v5\"\n\n" +
+		"type User struct {\n\tName string `json:\"name\"`\n}\n\n" +
+		"const token = \"s3cret\\n\\u00e9\"\n\n" +
+		"func Routes(r chi.Router) { r.Get(`/users/{id}`, nil) /* get one */ }\n"
+	want := "//go:build linux\n\n" +
+		"// xxxxxxx xxxxx xxxxxx xxxxxxxxx xxxxxxx.\n" +
+		"package users\n\n" +
+		"import \"github.com/go-chi/chi/v5\"\n\n" +
+		"type User struct {\n\tName string `json:\"name\"`\n}\n\n" +
+		"const token = \"xxxxxx\\n\\u00e9\"\n\n" +
+		"func Routes(r chi.Router) { r.Get(`/xxxxx/{xx}`, nil) /* xxx xxx */ }\n"
+
+	got := Redact([]byte(src))
+	assert.Equal(t, want, string(got))
+	_, err := parser.ParseFile(token.NewFileSet(), "", got, parser.ParseComments)
+	assert.NoError(t, err)
+
+	broken := []byte("package x\n\nfunc (")
+	assert.Len(t, Redact(broken), len(broken))
+}
+
+func TestWrite(t *testing.T) {
+	b := &Bundle{
+		Version:    "1.0.0",
+		Args:       []string{"parse", "./..."},
+		ConfigName: "conf/api-doc-gen.yaml",
+		Config:     []byte("glossary: terms.yaml\n"),
+		Error:      "internal error: boom",
+		Panics: []*analyzer.Panic{{
+			Package: "example.com/app/users",
+			Stage:   "routes",
+			Value:   "boom",
+			Stack:   "goroutine 1 [running]:\n",
+			Pos:     &model.Position{File: "users/users.go", Line: 3},
+			Files:   map[string][]byte{"users/users.go": []byte("package users\n\n// Secret.\nfunc F() {}\n")},
+		}},
+		Redact: true,
+	}
+	name := filepath.Join(t.TempDir(), "bundle.zip")
+	require.NoError(t, b.Write(name))
+
+	zr, err := zip.OpenReader(name)
+	require.NoError(t, err)
+	defer zr.Close()
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[f.Name] = string(data)
+	}
+
+	assert.Equal(t, "glossary: terms.yaml\n", files["config/api-doc-gen.yaml"])
+	assert.Equal(t, "goroutine 1 [running]:\n", files["stacks/1.txt"])
+	assert.Equal(t, "package users\n\n// xxxxxx.\nfunc F() {}\n", files["sources/users/users.go"])
+
+	var m Manifest
+	require.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &m))
+	assert.Equal(t, "1.0.0", m.Version)
+	assert.NotEmpty(t, m.GoVersion)
+	assert.Equal(t, []string{"parse", "./..."}, m.Args)
+	assert.Equal(t, "config/api-doc-gen.yaml", m.Config)
+	assert.True(t, m.Redacted)
+	assert.Equal(t, []string{"users/users.go"}, m.Sources)
+	require.Len(t, m.Panics, 1)
+	assert.Equal(t, &Entry{Package: "example.com/app/users", Stage: "routes", Value: "boom", Pos: &model.Position{File: "users/users.go", Line: 3}, Stack: "stacks/1.txt"}, m.Panics[0])
+}
@@ -0,0 +1,101 @@
+package bundle
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// Redact replaces the letters, digits and non-ASCII bytes of the comments
+// and string literals of a Go source file with 'x'. Everything the
+// analyzer needs to follow the code is kept: identifiers, punctuation in
+// literals such as the slashes and braces of route paths, escape
+// sequences, import paths, struct tags and compiler directives. Offsets
+// and line numbers are unchanged, so positions in the bundle still point
+// at the same code.
+//
+// Redaction can hide the cause of a failure that depends on the redacted
+// text, such as a doc comment section or the value of a constant.
+func Redact(src []byte) []byte {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if f == nil {
+		return src
+	}
+	out := append([]byte(nil), src...)
+	tf := fset.File(f.Pos())
+	span := func(pos, end token.Pos) []byte {
+		return out[tf.Offset(pos):tf.Offset(end)]
+	}
+
+	keep := map[*ast.BasicLit]bool{}
+	for _, imp := range f.Imports {
+		keep[imp.Path] = true
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if field, ok := n.(*ast.Field); ok && field.Tag != nil {
+			keep[field.Tag] = true
+		}
+		return true
+	})
+	ast.Inspect(f, func(n ast.Node) bool {
+		lit, ok := n.(*ast.BasicLit)
+		if !ok || keep[lit] || (lit.Kind != token.STRING && lit.Kind != token.CHAR) {
+			return true
+		}
+		b := span(lit.Pos(), lit.End())
+		if len(b) < 2 {
+			return true
+		}
+		redactText(b[1:len(b)-1], b[0] != '`')
+		return true
+	})
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			if isDirective(c.Text) {
+				continue
+			}
+			redactText(span(c.Pos(), c.End())[2:], false)
+		}
+	}
+	return out
+}
+
+// redactText redacts b in place. In interpreted literals, escape
+// sequences are kept so that the literal stays valid.
+func redactText(b []byte, escapes bool) {
+	for i := 0; i < len(b); i++ {
+		if escapes && b[i] == '\\' && i+1 < len(b) {
+			i += escapeLen(b[i+1])
+			continue
+		}
+		c := b[i]
+		if c >= 0x80 || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			b[i] = 'x'
+		}
+	}
+}
+
+// escapeLen returns the length of an escape sequence after its backslash,
+// given the character following the backslash.
+func escapeLen(c byte) int {
+	switch {
+	case c == 'x':
+		return 3
+	case c == 'u':
+		return 5
+	case c == 'U':
+		return 9
+	case '0' <= c && c <= '7':
+		return 3
+	}
+	return 1
+}
+
+// isDirective reports whether a comment is read by the toolchain, such as
+// //go:build, // +build or //line.
+func isDirective(text string) bool {
+	return strings.HasPrefix(text, "//go:") || strings.HasPrefix(text, "//line ") ||
+		strings.HasPrefix(text, "// +build") || strings.HasPrefix(text, "/*line ")
+}
@@ -0,0 +1,260 @@
+// Package cache keeps analysis results between runs so that commands over
+// unchanged sources start instantly.
+//
+// Entries are files named by a key derived from a fingerprint of the
+// analyzed sources and the identity of the running binary, so a rebuilt
+// api-doc-gen-go never reads results of an older analyzer.
+//
+// One cache directory may be shared by concurrent processes, such as
+// parallel CI jobs. Entries are written to a temporary file and renamed
+// into place, so readers see either a complete entry or none. Every entry
+// carries a checksum of its contents; an entry that fails it, because a
+// disk filled up or a job was killed mid-copy, is deleted and counts as a
+// miss. Eviction takes a lock file so that two processes never prune the
+// directory at once.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaVersion versions the entry format, the key derivation and the
+// shape of cached values. Entries of other versions are never read, and
+// are the first to go when the cache is over its size limit.
+const SchemaVersion = 1
+
+// magic starts the header line of every entry.
+const magic = "api-doc-gen-go-cache"
+
+// staleTemp is the age after which a temporary file is taken to be left
+// over from a killed process.
+const staleTemp = time.Hour
+
+// Cache is a directory of cached entries.
+type Cache struct {
+	Dir string
+	// MaxSize bounds the total size of the directory in bytes; zero means
+	// unbounded. The least recently used entries are evicted first.
+	MaxSize int64
+}
+
+// DefaultDir returns the cache directory used when none is configured,
+// api-doc-gen-go under the user cache directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "api-doc-gen-go"), nil
+}
+
+// New returns the cache kept in dir, bounded to maxSize bytes.
+func New(dir string, maxSize int64) *Cache {
+	return &Cache{Dir: dir, MaxSize: maxSize}
+}
+
+// Key derives an entry key from the values identifying the entry.
+func Key(parts ...string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d\x00", SchemaVersion)
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Binary identifies the running executable by path, size and modification
+// time, or returns "" when it cannot be determined.
+func Binary() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %d %d", exe, info.Size(), info.ModTime().UnixNano())
+}
+
+// entries is the directory of the entries of the current version.
+func (c *Cache) entries() string {
+	return filepath.Join(c.Dir, fmt.Sprintf("v%d", SchemaVersion))
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.entries(), key)
+}
+
+// Get decodes the entry stored under key into v and reports whether there
+// was one. Corrupt entries are removed and count as missing. A hit marks
+// the entry as recently used.
+func (c *Cache) Get(key string, v any) bool {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	payload, ok := verify(data)
+	if !ok || json.Unmarshal(payload, v) != nil {
+		os.Remove(path)
+		return false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return true
+}
+
+// Put stores v under key, replacing any entry atomically, and evicts old
+// entries when the cache outgrows its size limit.
+func (c *Cache) Put(key string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dir := c.entries()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	sum := sha256.Sum256(payload)
+	fmt.Fprintf(tmp, "%s %d %s\n", magic, SchemaVersion, hex.EncodeToString(sum[:]))
+	if _, err := tmp.Write(payload); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return err
+	}
+	if c.MaxSize > 0 {
+		return c.evict()
+	}
+	return nil
+}
+
+// verify checks the header of an entry and returns its payload.
+func verify(data []byte) ([]byte, bool) {
+	nl := bytes.IndexByte(data, '\n')
+	if nl < 0 {
+		return nil, false
+	}
+	fields := strings.Fields(string(data[:nl]))
+	if len(fields) != 3 || fields[0] != magic || fields[1] != strconv.Itoa(SchemaVersion) {
+		return nil, false
+	}
+	payload := data[nl+1:]
+	sum := sha256.Sum256(payload)
+	return payload, fields[2] == hex.EncodeToString(sum[:])
+}
+
+type entry struct {
+	path    string
+	size    int64
+	used    time.Time
+	current bool
+}
+
+// evict removes entries, those of other versions first and then the least
+// recently used, until the cache fits in MaxSize. Temporary files left by
+// killed processes are removed as well.
+func (c *Cache) evict() error {
+	unlock, err := lock(filepath.Join(c.Dir, ".lock"))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var entries []entry
+	var total int64
+	current := c.entries()
+	err = filepath.WalkDir(c.Dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || p == filepath.Join(c.Dir, ".lock") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// Removed by a concurrent Get of a corrupt entry.
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			if time.Since(info.ModTime()) > staleTemp {
+				os.Remove(p)
+			} else {
+				total += info.Size()
+			}
+			return nil
+		}
+		entries = append(entries, entry{path: p, size: info.Size(), used: info.ModTime(), current: filepath.Dir(p) == current})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].current != entries[j].current {
+			return !entries[i].current
+		}
+		return entries[i].used.Before(entries[j].used)
+	})
+	for _, e := range entries {
+		if total <= c.MaxSize {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= e.size
+	}
+	return nil
+}
+
+// ParseSize parses a size such as "512MB", "2GiB" or "1048576". Units are
+// powers of 1024 whether or not they are spelled with an i.
+func ParseSize(s string) (int64, error) {
+	t := strings.ToUpper(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		shift  uint
+	}{{"TIB", 40}, {"GIB", 30}, {"MIB", 20}, {"KIB", 10}, {"TB", 40}, {"GB", 30}, {"MB", 20}, {"KB", 10}, {"T", 40}, {"G", 30}, {"M", 20}, {"K", 10}, {"B", 0}}
+	var shift uint
+	for _, u := range units {
+		if strings.HasSuffix(t, u.suffix) {
+			t, shift = strings.TrimSpace(strings.TrimSuffix(t, u.suffix)), u.shift
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(t, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(int64(1)<<shift)), nil
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func TestGetPut(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "cache"), 0)
+	key := Key("sources", "binary")
+	assert.NotEqual(t, Key("source", "sbinary"), key)
+
+	var doc model.Document
+	assert.False(t, c.Get(key, &doc))
+
+	want := &model.Document{Service: "api", Endpoints: []*model.Endpoint{{Method: "GET", Path: "/users"}}}
+	require.NoError(t, c.Put(key, want))
+	require.True(t, c.Get(key, &doc))
+	assert.Equal(t, want, &doc)
+}
+
+func TestCorruptEntries(t *testing.T) {
+	c := New(t.TempDir(), 0)
+	key := Key("k")
+	require.NoError(t, c.Put(key, map[string]int{"a": 1}))
+	data, err := os.ReadFile(c.path(key))
+	require.NoError(t, err)
+
+	for name, corrupt := range map[string][]byte{
+		"truncated":     data[:len(data)-3],
+		"flipped":       append(append([]byte{}, data[:len(data)-2]...), '2', '}'),
+		"no header":     []byte(`{"a":1}`),
+		"other version": append([]byte(fmt.Sprintf("%s %d ", magic, SchemaVersion+1)), data[len(magic)+3:]...),
+	} {
+		require.NoError(t, os.WriteFile(c.path(key), corrupt, 0o644))
+		var v map[string]int
+		assert.False(t, c.Get(key, &v), name)
+		assert.NoFileExists(t, c.path(key), name)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, 0)
+	payload := make([]byte, 1000)
+	old := time.Now().Add(-time.Hour)
+	for i := 0; i < 4; i++ {
+		key := Key(fmt.Sprint(i))
+		require.NoError(t, c.Put(key, payload))
+		used := old.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(c.path(key), used, used))
+	}
+	stale := filepath.Join(dir, "v0", "stale")
+	require.NoError(t, os.MkdirAll(filepath.Dir(stale), 0o755))
+	require.NoError(t, os.WriteFile(stale, payload, 0o644))
+	leftover := filepath.Join(c.entries(), ".tmp-123")
+	require.NoError(t, os.WriteFile(leftover, payload, 0o644))
+	require.NoError(t, os.Chtimes(leftover, old.Add(-time.Hour), old.Add(-time.Hour)))
+
+	// Reading entry 0 makes entry 1 the least recently used.
+	var v []byte
+	require.True(t, c.Get(Key("0"), &v))
+
+	c.MaxSize = 4500
+	require.NoError(t, c.Put(Key("4"), payload))
+	assert.NoFileExists(t, stale)
+	assert.NoFileExists(t, leftover)
+	for i, kept := range []bool{true, false, false, true, true} {
+		assert.Equal(t, kept, c.Get(Key(fmt.Sprint(i)), &v), i)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Each worker opens the cache like a separate process would.
+			c := New(dir, 20000)
+			for i := 0; i < 50; i++ {
+				key := Key(fmt.Sprint(i % 10))
+				want := map[string]int{"n": i % 10}
+				var got map[string]int
+				if c.Get(key, &got) {
+					assert.Equal(t, want, got)
+				}
+				assert.NoError(t, c.Put(key, want))
+			}
+		}(w)
+	}
+	wg.Wait()
+	matches, err := filepath.Glob(filepath.Join(dir, "v*", ".tmp-*"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{"1048576": 1 << 20, "512MB": 512 << 20, "2GiB": 2 << 30, "1.5k": 1536, "0": 0} {
+		got, err := ParseSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := ParseSize("lots")
+	assert.Error(t, err)
+	_, err = ParseSize("-1MB")
+	assert.Error(t, err)
+}
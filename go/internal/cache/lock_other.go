@@ -0,0 +1,33 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package cache
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// staleLock is the age after which a lock file is taken to be left over
+// from a killed process.
+const staleLock = time.Minute
+
+// lock takes an exclusive lock by creating the file at path, waiting for
+// other processes holding it, and returns the function releasing it.
+func lock(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
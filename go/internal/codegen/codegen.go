@@ -0,0 +1,68 @@
+// Package codegen holds what the source generators share: the assembly of
+// Go files, with their header, package clause, the imports the code uses
+// and gofmt formatting, and the splitting of names into words.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// qualifier matches the package name of a qualified identifier, as json
+// in json.Marshal, but not the field of a selector such as r.URL.Path.
+var qualifier = regexp.MustCompile(`(?:^|[^\w.])(\w+)\.`)
+
+// File returns the formatted source of a file of package pkg holding
+// code, preceded by the header comment and an import declaration of those
+// of the import paths the code refers to. The package name of an import
+// path is its last element.
+func File(header, pkg string, imports []string, code string) ([]byte, error) {
+	// Comments may mention package names; only code counts.
+	used := map[string]bool{}
+	for _, line := range strings.Split(code, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		for _, m := range qualifier.FindAllStringSubmatch(line, -1) {
+			used[m[1]] = true
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString(header + "\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n")
+	for _, imp := range imports {
+		if used[path.Base(imp)] {
+			fmt.Fprintf(&b, "%q\n", imp)
+		}
+	}
+	b.WriteString(")\n\n")
+	b.WriteString(code)
+	return format.Source(b.Bytes())
+}
+
+// Words splits a name at non-alphanumeric characters, at lower-to-upper
+// case changes and before the last capital of an initialism followed by a
+// lower case letter, so that "HTTPServer_url" is HTTP, Server and url.
+func Words(s string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			if unicode.IsUpper(runes[i]) && (!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				out = append(out, string(runes[start:i]))
+				start = i
+			}
+		}
+		out = append(out, string(runes[start:]))
+	}
+	return out
+}
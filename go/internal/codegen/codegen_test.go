@@ -0,0 +1,42 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	code := `// Encode is like json.Marshal but reports failures with errors.New.
+func Encode(r *http.Request) ([]byte, error) {
+	return json.Marshal(r.URL.Path)
+}
+`
+	out, err := File("// Code generated by test. DO NOT EDIT.", "api", []string{"encoding/json", "errors", "net/http", "net/url"}, code)
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by test. DO NOT EDIT.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Encode is like json.Marshal but reports failures with errors.New.
+func Encode(r *http.Request) ([]byte, error) {
+	return json.Marshal(r.URL.Path)
+}
+`, string(out))
+
+	_, err = File("// Code generated by test. DO NOT EDIT.", "api", nil, "func {")
+	assert.Error(t, err)
+}
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"HTTP", "Server", "url"}, Words("HTTPServer_url"))
+	assert.Equal(t, []string{"user", "ID"}, Words("userID"))
+	assert.Equal(t, []string{"créé", "Le"}, Words("crééLe"))
+	assert.Empty(t, Words("--"))
+}
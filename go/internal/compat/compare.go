@@ -0,0 +1,397 @@
+package compat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Entry statuses of a comparison.
+const (
+	Same    = "same"
+	Changed = "changed"
+	TSOnly  = "ts-only"
+	GoOnly  = "go-only"
+)
+
+// Report lists the differences between a TS output and a Go output.
+type Report struct {
+	Endpoints []*Entry `json:"endpoints"`
+	Schemas   []*Entry `json:"schemas"`
+}
+
+// Entry compares an endpoint, named "METHOD /path", or a schema.
+type Entry struct {
+	Name string `json:"name"`
+	// ID is the ID of an endpoint, from the Go output when both have it.
+	ID          string        `json:"id,omitempty"`
+	Status      string        `json:"status"`
+	Differences []*Difference `json:"differences,omitempty"`
+}
+
+// Difference is a field on which the outputs disagree. TS or Go is empty
+// when the field is missing from that output.
+type Difference struct {
+	Field string `json:"field"`
+	TS    string `json:"ts,omitempty"`
+	Go    string `json:"go,omitempty"`
+}
+
+// Differs reports whether the outputs disagree on any endpoint or schema.
+func (r *Report) Differs() bool {
+	for _, list := range [][]*Entry{r.Endpoints, r.Schemas} {
+		for _, e := range list {
+			if e.Status != Same {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadParseResponse reads the JSON output of a parse: a ParseResponse, an
+// array of them, one per parsed file, whose endpoints and schemas are
+// merged, or the AST of a ParseResponse alone. A failed parse is an error.
+func ReadParseResponse(data []byte) (*ParseResponse, error) {
+	var list []*ParseResponse
+	if err := json.Unmarshal(data, &list); err != nil {
+		var probe struct {
+			AST       json.RawMessage `json:"ast"`
+			Endpoints json.RawMessage `json:"endpoints"`
+		}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, err
+		}
+		resp := &ParseResponse{}
+		if probe.AST == nil && probe.Endpoints != nil {
+			resp.Status, resp.AST = "success", &AST{}
+			err = json.Unmarshal(data, resp.AST)
+		} else {
+			err = json.Unmarshal(data, resp)
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = []*ParseResponse{resp}
+	}
+
+	merged := &ParseResponse{Status: "success", AST: &AST{}}
+	schemas := map[string]bool{}
+	for _, resp := range list {
+		if resp == nil {
+			continue
+		}
+		if resp.Status == "failed" {
+			msg := "parse failed"
+			if len(resp.Errors) > 0 {
+				msg = resp.Errors[0].Code + ": " + resp.Errors[0].Message
+			}
+			return nil, fmt.Errorf("parse %s: %s", resp.ParseID, msg)
+		}
+		if resp.AST == nil {
+			continue
+		}
+		merged.AST.Endpoints = append(merged.AST.Endpoints, resp.AST.Endpoints...)
+		for _, s := range resp.AST.Schemas {
+			if !schemas[s.Name] {
+				schemas[s.Name] = true
+				merged.AST.Schemas = append(merged.AST.Schemas, s)
+			}
+		}
+		merged.Warnings = append(merged.Warnings, resp.Warnings...)
+	}
+	if len(merged.AST.Endpoints) == 0 && len(merged.AST.Schemas) == 0 {
+		return nil, errors.New("no endpoints or schemas found")
+	}
+	return merged, nil
+}
+
+// pathParamRE matches the parameters of paths written as {id}, :id or
+// <id>.
+var pathParamRE = regexp.MustCompile(`\{[^}]*\}|:[A-Za-z_][A-Za-z0-9_]*|<[^>]*>`)
+
+// routeKey identifies the route of an endpoint whatever its parameters
+// are named: GET /users/{} for GET /users/{id} and GET /users/:userID/.
+func routeKey(e *Endpoint) string {
+	p := pathParamRE.ReplaceAllString(e.Path, "{}")
+	if len(p) > 1 {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return strings.ToUpper(e.Method) + " " + p
+}
+
+// Compare compares the endpoints and schemas of ts, the output of
+// GoDocParser, with those of goOut, the output of GoDoc. Endpoints are
+// matched by method and path, path parameters being equal whatever their
+// names, and then by ID, since GoDocParser guesses the paths of handlers
+// whose doc comments name no route. Texts are compared with their white
+// space collapsed. GoDocParser tags no endpoint, so tags are compared
+// only when the TS output has them.
+func Compare(ts, goOut *ParseResponse) *Report {
+	r := &Report{Endpoints: []*Entry{}, Schemas: []*Entry{}}
+	tsEps, goEps := endpoints(ts), endpoints(goOut)
+
+	matched := map[*Endpoint]*Endpoint{}
+	byRoute := map[string][]*Endpoint{}
+	for _, g := range goEps {
+		byRoute[routeKey(g)] = append(byRoute[routeKey(g)], g)
+	}
+	taken := map[*Endpoint]bool{}
+	for _, t := range tsEps {
+		for _, g := range byRoute[routeKey(t)] {
+			if !taken[g] {
+				matched[t], taken[g] = g, true
+				break
+			}
+		}
+	}
+	byID := map[string]*Endpoint{}
+	for _, g := range goEps {
+		if !taken[g] && byID[g.ID] == nil {
+			byID[g.ID] = g
+		}
+	}
+	for _, t := range tsEps {
+		if g := byID[t.ID]; matched[t] == nil && g != nil && !taken[g] {
+			matched[t], taken[g] = g, true
+		}
+	}
+
+	for _, t := range tsEps {
+		g := matched[t]
+		if g == nil {
+			r.Endpoints = append(r.Endpoints, &Entry{Name: t.Key(), ID: t.ID, Status: TSOnly})
+			continue
+		}
+		e := &Entry{Name: g.Key(), ID: g.ID, Differences: compareEndpoints(t, g)}
+		e.Status = Same
+		if len(e.Differences) > 0 {
+			e.Status = Changed
+		}
+		r.Endpoints = append(r.Endpoints, e)
+	}
+	for _, g := range goEps {
+		if !taken[g] {
+			r.Endpoints = append(r.Endpoints, &Entry{Name: g.Key(), ID: g.ID, Status: GoOnly})
+		}
+	}
+	sort.SliceStable(r.Endpoints, func(i, j int) bool { return r.Endpoints[i].Name < r.Endpoints[j].Name })
+
+	tsSchemas, goSchemas := dataModels(ts), dataModels(goOut)
+	for _, name := range sortedKeys(tsSchemas) {
+		g := goSchemas[name]
+		if g == nil {
+			r.Schemas = append(r.Schemas, &Entry{Name: name, Status: TSOnly})
+			continue
+		}
+		e := &Entry{Name: name, Status: Same}
+		d := &diff{}
+		d.text("description", tsSchemas[name].Description, g.Description)
+		if t := tsSchemas[name].Schema; t != nil && len(t.Properties) > 0 && g.Schema != nil {
+			d.text("properties", strings.Join(sortedKeys(t.Properties), ", "), strings.Join(sortedKeys(g.Schema.Properties), ", "))
+		}
+		if e.Differences = d.list; len(e.Differences) > 0 {
+			e.Status = Changed
+		}
+		r.Schemas = append(r.Schemas, e)
+	}
+	for _, name := range sortedKeys(goSchemas) {
+		if tsSchemas[name] == nil {
+			r.Schemas = append(r.Schemas, &Entry{Name: name, Status: GoOnly})
+		}
+	}
+	sort.SliceStable(r.Schemas, func(i, j int) bool { return r.Schemas[i].Name < r.Schemas[j].Name })
+	return r
+}
+
+func endpoints(resp *ParseResponse) []*Endpoint {
+	if resp == nil || resp.AST == nil {
+		return nil
+	}
+	return resp.AST.Endpoints
+}
+
+func dataModels(resp *ParseResponse) map[string]*DataModel {
+	m := map[string]*DataModel{}
+	if resp == nil || resp.AST == nil {
+		return m
+	}
+	for _, s := range resp.AST.Schemas {
+		m[s.Name] = s
+	}
+	return m
+}
+
+// diff collects the differences of an entry.
+type diff struct {
+	list []*Difference
+}
+
+func (d *diff) add(field, ts, goValue string) {
+	d.list = append(d.list, &Difference{Field: field, TS: ts, Go: goValue})
+}
+
+// text records a difference between texts that differ once their white
+// space is collapsed.
+func (d *diff) text(field, ts, goValue string) {
+	ts, goValue = strings.Join(strings.Fields(ts), " "), strings.Join(strings.Fields(goValue), " ")
+	if ts != goValue {
+		d.add(field, ts, goValue)
+	}
+}
+
+func compareEndpoints(t, g *Endpoint) []*Difference {
+	d := &diff{}
+	if t.Path != g.Path {
+		d.add("path", t.Path, g.Path)
+	}
+	if !strings.EqualFold(t.Method, g.Method) {
+		d.add("method", t.Method, g.Method)
+	}
+	if t.ID != g.ID {
+		d.add("id", t.ID, g.ID)
+	}
+	d.text("summary", t.Summary, g.Summary)
+	d.text("description", t.Description, g.Description)
+	if len(t.Tags) > 0 {
+		d.text("tags", strings.Join(t.Tags, ", "), strings.Join(g.Tags, ", "))
+	}
+	if t.Deprecated != g.Deprecated {
+		d.add("deprecated", fmt.Sprint(t.Deprecated), fmt.Sprint(g.Deprecated))
+	}
+
+	goParams := map[string]int{}
+	for i, p := range g.Parameters {
+		goParams[p.In+" "+p.Name] = i
+	}
+	seen := map[string]bool{}
+	for _, tp := range t.Parameters {
+		key := tp.In + " " + tp.Name
+		i, ok := goParams[key]
+		if !ok {
+			d.add("parameter "+key, "present", "")
+			continue
+		}
+		seen[key] = true
+		gp := g.Parameters[i]
+		field := "parameter " + key
+		if tp.Required != gp.Required {
+			d.add(field+" required", fmt.Sprint(tp.Required), fmt.Sprint(gp.Required))
+		}
+		d.text(field+" description", tp.Description, gp.Description)
+		d.text(field+" schema", schemaText(tp.Schema), schemaText(gp.Schema))
+	}
+	for _, gp := range g.Parameters {
+		if key := gp.In + " " + gp.Name; !seen[key] {
+			d.add("parameter "+key, "", "present")
+		}
+	}
+
+	switch {
+	case t.RequestBody == nil && g.RequestBody != nil:
+		d.add("request body", "", contentText(g.RequestBody.Content))
+	case t.RequestBody != nil && g.RequestBody == nil:
+		d.add("request body", contentText(t.RequestBody.Content), "")
+	case t.RequestBody != nil:
+		d.text("request body", contentText(t.RequestBody.Content), contentText(g.RequestBody.Content))
+		d.text("request body description", t.RequestBody.Description, g.RequestBody.Description)
+	}
+
+	goResponses := map[string]*Response{}
+	for _, r := range g.Responses {
+		goResponses[r.StatusCode] = r
+	}
+	for _, tr := range t.Responses {
+		gr := goResponses[tr.StatusCode]
+		field := "response " + tr.StatusCode
+		if gr == nil {
+			d.add(field, "present", "")
+			continue
+		}
+		delete(goResponses, tr.StatusCode)
+		d.text(field+" description", tr.Description, gr.Description)
+		d.text(field+" content", contentText(tr.Content), contentText(gr.Content))
+	}
+	for _, r := range g.Responses {
+		if goResponses[r.StatusCode] != nil {
+			d.add("response "+r.StatusCode, "", "present")
+		}
+	}
+	return d.list
+}
+
+// contentText describes bodies by media type, as "application/json
+// UserResponse".
+func contentText(content map[string]*MediaType) string {
+	var parts []string
+	for _, ct := range sortedKeys(content) {
+		part := ct
+		if mt := content[ct]; mt != nil && mt.Schema != nil {
+			part += " " + schemaText(mt.Schema)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// schemaText describes a schema by its type: the schema it references, or
+// its type, with the type of its items for arrays.
+func schemaText(s *model.Schema) string {
+	switch {
+	case s == nil:
+		return ""
+	case s.RefName() != "":
+		return s.RefName()
+	case s.Type == "array":
+		if items := schemaText(s.Items); items != "" {
+			return "array of " + items
+		}
+	}
+	return s.Type
+}
+
+// WriteText writes the report for reading: an entry per endpoint and
+// schema that differs, with its differences, and a summary line.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	section := func(title string, entries []*Entry) {
+		counts := map[string]int{}
+		for _, e := range entries {
+			counts[e.Status]++
+			name := e.Name
+			if e.ID != "" {
+				name += " (" + e.ID + ")"
+			}
+			switch e.Status {
+			case TSOnly:
+				fmt.Fprintf(&b, "- %s: only in the TS output\n", name)
+			case GoOnly:
+				fmt.Fprintf(&b, "+ %s: only in the Go output\n", name)
+			case Changed:
+				fmt.Fprintf(&b, "~ %s\n", name)
+				for _, d := range e.Differences {
+					fmt.Fprintf(&b, "    %s: %s -> %s\n", d.Field, quoteOrNone(d.TS), quoteOrNone(d.Go))
+				}
+			}
+		}
+		fmt.Fprintf(&b, "%d %s: %d same, %d changed, %d only in TS, %d only in Go\n",
+			len(entries), title, counts[Same], counts[Changed], counts[TSOnly], counts[GoOnly])
+	}
+	section("endpoints", r.Endpoints)
+	section("schemas", r.Schemas)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func quoteOrNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return fmt.Sprintf("%q", s)
+}
@@ -0,0 +1,231 @@
+package compat
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func testDocument() *model.Document {
+	return &model.Document{
+		Packages: []*model.Package{{Name: "user", ImportPath: "example.com/user", Description: "Package user serves\nusers."}},
+		Endpoints: []*model.Endpoint{
+			{
+				ID:          "GET_GetUsers",
+				Method:      "GET",
+				Path:        "/api/users",
+				OperationID: "getUsers",
+				Summary:     "GetUsers lists users.",
+				Description: "GetUsers lists users.\nActive ones only.",
+				Tags:        []string{"users"},
+				Parameters: []*model.Parameter{
+					{Name: "limit", In: "query", Description: "Maximum number of users", Schema: &model.Schema{Type: "integer"}},
+				},
+				Responses: []*model.Response{
+					{StatusCode: "200", Description: "The users", ContentType: "application/json",
+						Schema: &model.Schema{Type: "array", Items: model.RefTo("user.User")}},
+					{StatusCode: "500", Description: "Server error", Headers: map[string]string{"Retry-After": "Seconds to wait"}},
+				},
+				Security: []string{"bearerAuth"},
+				Handler:  "user.GetUsers",
+				Source:   &model.Position{File: "user.go", Line: 12, Column: 1},
+			},
+			{
+				ID:          "POST_CreateUser",
+				Method:      "POST",
+				Path:        "/api/users",
+				Summary:     "CreateUser creates a user.",
+				RequestBody: &model.RequestBody{ContentType: "application/json", Required: true, Schema: model.RefTo("Request")},
+				Responses:   []*model.Response{{StatusCode: "201", Description: "Created", ContentType: "application/json", Schema: model.RefTo("user.User")}},
+				Handler:     "user.CreateUser",
+			},
+		},
+		Schemas: map[string]*model.Schema{
+			"user.User":  {Type: "object", Description: "User is a user.", GoType: "user.User", Properties: map[string]*model.Schema{"id": {Type: "integer"}}},
+			"admin.User": {Type: "object", GoType: "admin.User"},
+			"Request":    {Type: "object", GoType: "user.Request", Properties: map[string]*model.Schema{"owner": model.RefTo("user.User")}},
+		},
+		Diagnostics: []*model.Diagnostic{
+			{Code: "UNKNOWN_TYPE", Severity: model.SeverityWarning, Message: "x is unknown", Pos: &model.Position{File: "user.go", Line: 3}},
+			{Code: "NOTE", Severity: model.SeverityInfo, Message: "a note"},
+		},
+	}
+}
+
+func TestGoDoc(t *testing.T) {
+	resp := GoDoc(testDocument(), Options{Source: "./..."})
+	assert.Equal(t, "success", resp.Status)
+	assert.Regexp(t, `^go_parse_[0-9a-f]{9}$`, resp.ParseID)
+	assert.Equal(t, &ParseMetadata{SourceType: "go-doc", Version: "1.0.0", EndpointCount: 2, SchemaCount: 3}, resp.Metadata)
+	assert.Equal(t, &ASTMetadata{SourceFile: "./...", Package: "user", PackageDescription: "Package user serves users.", CommentCount: 6, Functions: 2, Types: 3}, resp.AST.Metadata)
+	require.Len(t, resp.Warnings, 1)
+	assert.Equal(t, &Warning{Code: "UNKNOWN_TYPE", Message: "x is unknown", Location: &Location{File: "user.go", Line: 3}}, resp.Warnings[0])
+
+	var names []string
+	for _, s := range resp.AST.Schemas {
+		names = append(names, s.Name)
+	}
+	// The two User types keep their qualified names.
+	assert.Equal(t, []string{"Request", "admin.User", "user.User"}, names)
+
+	data, err := json.Marshal(resp.AST.Endpoints)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+  {
+    "id": "GET_GetUsers", "path": "/api/users", "method": "GET", "operationId": "getUsers",
+    "summary": "GetUsers lists users.", "description": "GetUsers lists users.\nActive ones only.",
+    "tags": ["users"],
+    "parameters": [{"name": "limit", "in": "query", "description": "Maximum number of users", "required": false, "schema": {"type": "integer"}}],
+    "responses": [
+      {"statusCode": "200", "description": "The users",
+       "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/user.User"}}}}},
+      {"statusCode": "500", "description": "Server error",
+       "headers": {"Retry-After": {"description": "Seconds to wait", "schema": {"type": "string"}}}}
+    ],
+    "security": [{"bearerAuth": []}],
+    "deprecated": false,
+    "sourceLocation": {"filePath": "user.go", "startLine": 12, "endLine": 12, "startColumn": 1}
+  },
+  {
+    "id": "POST_CreateUser", "path": "/api/users", "method": "POST",
+    "summary": "CreateUser creates a user.", "description": "",
+    "tags": [], "parameters": [],
+    "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Request"}}}, "required": true},
+    "responses": [{"statusCode": "201", "description": "Created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/user.User"}}}}],
+    "deprecated": false
+  }
+]`, string(data))
+
+	doc := testDocument()
+	delete(doc.Schemas, "admin.User")
+	resp = GoDoc(doc, Options{})
+	assert.Equal(t, "User", resp.AST.Schemas[1].Name)
+	assert.Equal(t, "#/components/schemas/User", resp.AST.Schemas[0].Schema.Properties["owner"].Ref)
+	assert.Equal(t, "#/components/schemas/User", resp.AST.Endpoints[1].Responses[0].Content["application/json"].Schema.Ref)
+	// The document is left unchanged.
+	assert.Equal(t, "#/components/schemas/user.User", doc.Schemas["Request"].Properties["owner"].Ref)
+}
+
+// tsOutput is output of GoDocParser: a guessed path, untyped parameters
+// and placeholder response schemas.
+const tsOutput = `{
+  "status": "success",
+  "parseId": "go_parse_1733900000000_abc123xyz",
+  "ast": {
+    "endpoints": [
+      {
+        "id": "GET_GetUsers", "path": "/api/users", "method": "GET",
+        "summary": "GetUsers lists users.", "description": "GetUsers lists users. Active ones only.",
+        "tags": [],
+        "parameters": [
+          {"name": "limit", "in": "query", "description": "Maximum number of users", "required": false, "schema": {"type": "string"}},
+          {"name": "offset", "in": "query", "description": "Users to skip", "required": false, "schema": {"type": "string"}}
+        ],
+        "responses": [
+          {"statusCode": "200", "description": "The users", "content": {"application/json": {"schema": {"type": "object"}}}},
+          {"statusCode": "500", "description": "Server error", "content": {"application/json": {"schema": {"type": "object"}}}}
+        ],
+        "deprecated": false
+      },
+      {
+        "id": "POST_CreateUser", "path": "/api/user", "method": "POST",
+        "summary": "CreateUser creates a user.", "description": "",
+        "tags": [], "parameters": [],
+        "responses": [{"statusCode": "200", "description": "Successful response", "content": {"application/json": {"schema": {"type": "object"}}}}],
+        "deprecated": false
+      },
+      {
+        "id": "DELETE_DeleteUser", "path": "/api/user/{id}", "method": "DELETE",
+        "summary": "DeleteUser deletes a user.", "description": "DeleteUser deletes a user.",
+        "tags": [], "parameters": [], "responses": [], "deprecated": false
+      }
+    ],
+    "schemas": [
+      {"name": "User", "schema": {"type": "object", "description": "User is a user.", "properties": {}}, "description": "User is a user."},
+      {"name": "unknown", "schema": {"type": "object", "properties": {}}}
+    ],
+    "components": [],
+    "metadata": {"sourceFile": "user.go", "package": "user"}
+  }
+}`
+
+func TestCompare(t *testing.T) {
+	ts, err := ReadParseResponse([]byte(tsOutput))
+	require.NoError(t, err)
+	doc := testDocument()
+	delete(doc.Schemas, "admin.User")
+	doc.Endpoints = append(doc.Endpoints, &model.Endpoint{ID: "GET_Health", Method: "GET", Path: "/healthz", Responses: []*model.Response{}})
+	doc.Endpoints[0].Path = "/api/users/"
+	report := Compare(ts, GoDoc(doc, Options{}))
+	assert.True(t, report.Differs())
+
+	byName := map[string]*Entry{}
+	for _, e := range report.Endpoints {
+		byName[e.Name] = e
+	}
+	assert.Len(t, report.Endpoints, 4)
+	assert.Equal(t, TSOnly, byName["DELETE /api/user/{id}"].Status)
+	assert.Equal(t, GoOnly, byName["GET /healthz"].Status)
+
+	get := byName["GET /api/users/"]
+	require.NotNil(t, get)
+	assert.Equal(t, Changed, get.Status)
+	assert.Equal(t, []*Difference{
+		{Field: "path", TS: "/api/users", Go: "/api/users/"},
+		{Field: "parameter query limit schema", TS: "string", Go: "integer"},
+		{Field: "parameter query offset", TS: "present"},
+		{Field: "response 200 content", TS: "application/json object", Go: "application/json array of User"},
+		{Field: "response 500 content", TS: "application/json object"},
+	}, get.Differences)
+
+	// Matched by ID, since its path was guessed.
+	post := byName["POST /api/users"]
+	require.NotNil(t, post)
+	assert.Equal(t, []*Difference{
+		{Field: "path", TS: "/api/user", Go: "/api/users"},
+		{Field: "request body", Go: "application/json Request"},
+		{Field: "response 200", TS: "present"},
+		{Field: "response 201", Go: "present"},
+	}, post.Differences)
+
+	var schemas []string
+	for _, e := range report.Schemas {
+		schemas = append(schemas, e.Name+" "+e.Status)
+	}
+	assert.Equal(t, []string{"Request go-only", "User same", "unknown ts-only"}, schemas)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	assert.Contains(t, buf.String(), "- DELETE /api/user/{id} (DELETE_DeleteUser): only in the TS output\n")
+	assert.Contains(t, buf.String(), "~ POST /api/users (POST_CreateUser)\n    path: \"/api/user\" -> \"/api/users\"\n    request body: (none) -> \"application/json Request\"\n")
+	assert.Contains(t, buf.String(), "4 endpoints: 0 same, 2 changed, 1 only in TS, 1 only in Go\n")
+	assert.Contains(t, buf.String(), "3 schemas: 1 same, 0 changed, 1 only in TS, 1 only in Go\n")
+
+	same := Compare(ts, ts)
+	assert.False(t, same.Differs())
+}
+
+func TestReadParseResponse(t *testing.T) {
+	list := `[
+  {"status": "success", "parseId": "a", "ast": {"endpoints": [{"id": "GET_A", "method": "GET", "path": "/a"}], "schemas": [{"name": "A", "schema": {"type": "object"}}]}},
+  {"status": "success", "parseId": "b", "ast": {"endpoints": [{"id": "GET_B", "method": "GET", "path": "/b"}], "schemas": [{"name": "A", "schema": {"type": "object"}}]}}
+]`
+	resp, err := ReadParseResponse([]byte(list))
+	require.NoError(t, err)
+	assert.Len(t, resp.AST.Endpoints, 2)
+	assert.Len(t, resp.AST.Schemas, 1)
+
+	resp, err = ReadParseResponse([]byte(`{"endpoints": [{"id": "GET_A", "method": "GET", "path": "/a"}], "schemas": []}`))
+	require.NoError(t, err)
+	assert.Equal(t, "GET /a", resp.AST.Endpoints[0].Key())
+
+	_, err = ReadParseResponse([]byte(`{"status": "failed", "parseId": "c", "errors": [{"status": "error", "code": "PARSE_ERROR", "message": "boom"}]}`))
+	assert.EqualError(t, err, "parse c: PARSE_ERROR: boom")
+	_, err = ReadParseResponse([]byte(`{"status": "success", "parseId": "d", "ast": {"endpoints": [], "schemas": []}}`))
+	assert.EqualError(t, err, "no endpoints or schemas found")
+}
@@ -0,0 +1,340 @@
+// Package compat shapes documents like the output of the parsers of the
+// Node pipeline that the Go component replaces, and compares the two, so
+// that services can move to the Go component one at a time.
+//
+// The ts-godoc mode reproduces the ParseResponse of GoDocParser
+// (src/parsers/languages/go-parser.ts): endpoints identified as
+// METHOD_HandlerName, schemas listed as data models named after their Go
+// types, and request and response bodies keyed by media type. What the Go
+// analysis knows beyond the regular expressions of GoDocParser, such as
+// typed parameters and response schemas, is kept in those shapes.
+package compat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// TSGoDoc names the mode reproducing the output of GoDocParser.
+const TSGoDoc = "ts-godoc"
+
+// ParseResponse is the result of a parse request, as the ParseResponse
+// of src/parsers/parser-service.ts.
+type ParseResponse struct {
+	Status   string         `json:"status" yaml:"status"`
+	ParseID  string         `json:"parseId" yaml:"parseId"`
+	AST      *AST           `json:"ast,omitempty" yaml:"ast,omitempty"`
+	Metadata *ParseMetadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Warnings []*Warning     `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	Errors   []*ParseError  `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// AST holds the endpoints and schemas of a parse.
+type AST struct {
+	Endpoints  []*Endpoint  `json:"endpoints" yaml:"endpoints"`
+	Schemas    []*DataModel `json:"schemas" yaml:"schemas"`
+	Components []any        `json:"components" yaml:"components"`
+	Metadata   *ASTMetadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// ASTMetadata describes the parsed source. GoDocParser counts the doc
+// comments of one file; here Functions counts the handlers and Types the
+// schemas of the analyzed packages.
+type ASTMetadata struct {
+	SourceFile         string `json:"sourceFile" yaml:"sourceFile"`
+	Package            string `json:"package" yaml:"package"`
+	PackageDescription string `json:"packageDescription" yaml:"packageDescription"`
+	CommentCount       int    `json:"commentCount" yaml:"commentCount"`
+	Functions          int    `json:"functions" yaml:"functions"`
+	Types              int    `json:"types" yaml:"types"`
+}
+
+// ParseMetadata summarizes a parse. ParseTime is in seconds.
+type ParseMetadata struct {
+	SourceType    string  `json:"sourceType" yaml:"sourceType"`
+	Version       string  `json:"version" yaml:"version"`
+	EndpointCount int     `json:"endpointCount" yaml:"endpointCount"`
+	SchemaCount   int     `json:"schemaCount" yaml:"schemaCount"`
+	ParseTime     float64 `json:"parseTime" yaml:"parseTime"`
+	FileSize      int     `json:"fileSize" yaml:"fileSize"`
+}
+
+// Warning is a problem found while parsing.
+type Warning struct {
+	Code     string    `json:"code" yaml:"code"`
+	Message  string    `json:"message" yaml:"message"`
+	Location *Location `json:"location,omitempty" yaml:"location,omitempty"`
+}
+
+// Location is a position in a source file.
+type Location struct {
+	File   string `json:"file" yaml:"file"`
+	Line   int    `json:"line" yaml:"line"`
+	Column int    `json:"column" yaml:"column"`
+}
+
+// ParseError is the failure of a parse.
+type ParseError struct {
+	Status  string         `json:"status" yaml:"status"`
+	Code    string         `json:"code" yaml:"code"`
+	Message string         `json:"message" yaml:"message"`
+	Details map[string]any `json:"details,omitempty" yaml:"details,omitempty"`
+}
+
+// Endpoint is an HTTP operation, as the ApiEndpoint of
+// src/core/models/api-spec.ts.
+type Endpoint struct {
+	ID             string                `json:"id" yaml:"id"`
+	Path           string                `json:"path" yaml:"path"`
+	Method         string                `json:"method" yaml:"method"`
+	OperationID    string                `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary        string                `json:"summary" yaml:"summary"`
+	Description    string                `json:"description" yaml:"description"`
+	Tags           []string              `json:"tags" yaml:"tags"`
+	Parameters     []*model.Parameter    `json:"parameters" yaml:"parameters"`
+	RequestBody    *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses      []*Response           `json:"responses" yaml:"responses"`
+	Security       []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+	Deprecated     bool                  `json:"deprecated" yaml:"deprecated"`
+	SourceLocation *SourceLocation       `json:"sourceLocation,omitempty" yaml:"sourceLocation,omitempty"`
+}
+
+// Key returns the "METHOD /path" form identifying the endpoint.
+func (e *Endpoint) Key() string {
+	return e.Method + " " + e.Path
+}
+
+// RequestBody is the payload of an endpoint, keyed by media type.
+type RequestBody struct {
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Content     map[string]*MediaType `json:"content" yaml:"content"`
+	Required    bool                  `json:"required" yaml:"required"`
+}
+
+// Response is one status code of an endpoint, its body keyed by media
+// type.
+type Response struct {
+	StatusCode  string                `json:"statusCode" yaml:"statusCode"`
+	Description string                `json:"description" yaml:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// Header is a response header.
+type Header struct {
+	Description string        `json:"description,omitempty" yaml:"description,omitempty"`
+	Schema      *model.Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// MediaType is the body of one media type.
+type MediaType struct {
+	Schema  *model.Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example any           `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+// DataModel is a named schema, as the DataModel of
+// src/core/models/schema.ts.
+type DataModel struct {
+	Name        string        `json:"name" yaml:"name"`
+	Schema      *model.Schema `json:"schema" yaml:"schema"`
+	Description string        `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// SourceLocation is where an endpoint is declared.
+type SourceLocation struct {
+	FilePath    string `json:"filePath" yaml:"filePath"`
+	StartLine   int    `json:"startLine" yaml:"startLine"`
+	EndLine     int    `json:"endLine" yaml:"endLine"`
+	StartColumn int    `json:"startColumn,omitempty" yaml:"startColumn,omitempty"`
+}
+
+// Options describe the parse a document came from.
+type Options struct {
+	// Source is the analyzed path, reported as the source file.
+	Source string
+	// Elapsed is the time the analysis took.
+	Elapsed time.Duration
+}
+
+// GoDoc returns doc as GoDocParser reports a parse. Schemas are named
+// after their Go types without package, as GoDocParser names them, unless
+// types of several packages share a name; those keep the names the
+// analysis gave them. Errors and warnings of the analysis are reported as
+// warnings.
+func GoDoc(doc *model.Document, opts Options) *ParseResponse {
+	c := &converter{names: tsNames(doc.Schemas)}
+	ast := &AST{Endpoints: []*Endpoint{}, Schemas: []*DataModel{}, Components: []any{}}
+	handlers := map[string]bool{}
+	for _, ep := range doc.Endpoints {
+		ast.Endpoints = append(ast.Endpoints, c.endpoint(ep))
+		handlers[ep.Handler] = true
+	}
+	for _, name := range doc.SchemaNames() {
+		s := doc.Schemas[name]
+		ast.Schemas = append(ast.Schemas, &DataModel{Name: c.names[name], Schema: c.schema(s), Description: s.Description})
+	}
+	sort.SliceStable(ast.Schemas, func(i, j int) bool { return ast.Schemas[i].Name < ast.Schemas[j].Name })
+
+	meta := &ASTMetadata{SourceFile: opts.Source, Package: "main", Functions: len(handlers), Types: len(ast.Schemas)}
+	if len(doc.Packages) > 0 {
+		pkg := doc.Packages[0]
+		for _, p := range doc.Packages {
+			if p.Description != "" {
+				pkg = p
+				break
+			}
+		}
+		meta.Package, meta.PackageDescription = pkg.Name, strings.Join(strings.Fields(pkg.Description), " ")
+	}
+	meta.CommentCount = meta.Functions + meta.Types
+	if meta.PackageDescription != "" {
+		meta.CommentCount++
+	}
+	ast.Metadata = meta
+
+	sum := sha256.Sum256([]byte(opts.Source))
+	resp := &ParseResponse{
+		Status:  "success",
+		ParseID: "go_parse_" + hex.EncodeToString(sum[:])[:9],
+		AST:     ast,
+		Metadata: &ParseMetadata{
+			SourceType:    "go-doc",
+			Version:       "1.0.0",
+			EndpointCount: len(ast.Endpoints),
+			SchemaCount:   len(ast.Schemas),
+			ParseTime:     opts.Elapsed.Seconds(),
+		},
+	}
+	for _, d := range doc.Diagnostics {
+		if d.Severity == model.SeverityInfo {
+			continue
+		}
+		w := &Warning{Code: d.Code, Message: d.Message}
+		if d.Pos != nil {
+			w.Location = &Location{File: d.Pos.File, Line: d.Pos.Line, Column: d.Pos.Column}
+		}
+		resp.Warnings = append(resp.Warnings, w)
+	}
+	return resp
+}
+
+// tsNames maps the schema names of the analysis to the names GoDocParser
+// gives the types: their names without package.
+func tsNames(schemas map[string]*model.Schema) map[string]string {
+	bare := map[string]string{}
+	count := map[string]int{}
+	for name, s := range schemas {
+		b := name
+		if s.GoType != "" {
+			b = s.GoType
+		}
+		if i := strings.LastIndex(b, "."); i >= 0 && !strings.Contains(b[:i], "[") {
+			b = b[i+1:]
+		}
+		bare[name] = b
+		count[b]++
+	}
+	names := map[string]string{}
+	for name, b := range bare {
+		if count[b] > 1 {
+			b = name
+		}
+		names[name] = b
+	}
+	return names
+}
+
+// converter converts endpoints and schemas, renaming schema references.
+type converter struct {
+	names map[string]string
+}
+
+func (c *converter) endpoint(ep *model.Endpoint) *Endpoint {
+	out := &Endpoint{
+		ID:          ep.ID,
+		Path:        ep.Path,
+		Method:      ep.Method,
+		OperationID: ep.OperationID,
+		Summary:     ep.Summary,
+		Description: ep.Description,
+		Tags:        ep.Tags,
+		Parameters:  []*model.Parameter{},
+		Responses:   []*Response{},
+		Deprecated:  ep.Deprecated,
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	for _, p := range ep.Parameters {
+		cp := *p
+		cp.Schema = c.schema(p.Schema)
+		out.Parameters = append(out.Parameters, &cp)
+	}
+	if b := ep.RequestBody; b != nil {
+		out.RequestBody = &RequestBody{
+			Description: b.Description,
+			Content:     map[string]*MediaType{b.ContentType: {Schema: c.schema(b.Schema), Example: b.Example}},
+			Required:    b.Required,
+		}
+	}
+	for _, r := range ep.Responses {
+		resp := &Response{StatusCode: r.StatusCode, Description: r.Description}
+		if r.ContentType != "" {
+			resp.Content = map[string]*MediaType{r.ContentType: {Schema: c.schema(r.Schema), Example: r.Example}}
+		}
+		for _, name := range sortedKeys(r.Headers) {
+			if resp.Headers == nil {
+				resp.Headers = map[string]*Header{}
+			}
+			resp.Headers[name] = &Header{Description: r.Headers[name], Schema: &model.Schema{Type: "string"}}
+		}
+		out.Responses = append(out.Responses, resp)
+	}
+	for _, scheme := range ep.Security {
+		out.Security = append(out.Security, map[string][]string{scheme: {}})
+	}
+	if ep.Source != nil {
+		out.SourceLocation = &SourceLocation{FilePath: ep.Source.File, StartLine: ep.Source.Line, EndLine: ep.Source.Line, StartColumn: ep.Source.Column}
+	}
+	return out
+}
+
+// schema copies s, renaming the schemas it references.
+func (c *converter) schema(s *model.Schema) *model.Schema {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	if name := s.RefName(); name != "" && c.names[name] != "" {
+		out.Ref = model.SchemaRefPrefix + c.names[name]
+	}
+	if s.Properties != nil {
+		out.Properties = make(map[string]*model.Schema, len(s.Properties))
+		for name, p := range s.Properties {
+			out.Properties[name] = c.schema(p)
+		}
+	}
+	out.Items = c.schema(s.Items)
+	out.AdditionalProperties = c.schema(s.AdditionalProperties)
+	if s.OneOf != nil {
+		out.OneOf = make([]*model.Schema, len(s.OneOf))
+		for i, v := range s.OneOf {
+			out.OneOf[i] = c.schema(v)
+		}
+	}
+	return &out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+// Package config loads the YAML configuration file of the Go component.
+//
+// The file is optional. When --config is not given, api-doc-gen.yaml in
+// the current directory is used if it exists. Unknown keys are rejected so
+// that misspelled settings do not pass silently.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/graphql"
+)
+
+// DefaultFile is the configuration file looked up in the current
+// directory.
+const DefaultFile = "api-doc-gen.yaml"
+
+// Config holds every setting of the configuration file.
+type Config struct {
+	// GraphQL configures --format graphql-sdl.
+	GraphQL GraphQL `yaml:"graphql"`
+	// Glossary is the glossary file checked by validate. A relative path
+	// is resolved against the directory of the configuration file.
+	Glossary string `yaml:"glossary"`
+	// Schemas configures how component schemas are named.
+	Schemas analyzer.SchemaNaming `yaml:"schemas"`
+}
+
+// GraphQL configures the GraphQL SDL export.
+type GraphQL struct {
+	Naming graphql.Naming `yaml:"naming"`
+}
+
+// Load reads the configuration file at path. An empty path loads
+// DefaultFile if it exists and returns an empty configuration otherwise.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	explicit := path != ""
+	if !explicit {
+		path = DefaultFile
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Schemas.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: schemas: %w", path, err)
+	}
+	if cfg.Glossary != "" && !filepath.IsAbs(cfg.Glossary) {
+		cfg.Glossary = filepath.Join(filepath.Dir(path), cfg.Glossary)
+	}
+	return cfg, nil
+}
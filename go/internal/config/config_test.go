@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "apidoc.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("graphql:\n  naming:\n    fields: preserve\n    rename:\n      pkg.User: Account\n"), 0o644))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "preserve", cfg.GraphQL.Naming.Fields)
+	assert.Equal(t, map[string]string{"pkg.User": "Account"}, cfg.GraphQL.Naming.Rename)
+
+	require.NoError(t, os.WriteFile(path, []byte("glossary: docs/glossary.yaml\n"), 0o644))
+	cfg, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "docs", "glossary.yaml"), cfg.Glossary)
+
+	require.NoError(t, os.WriteFile(path, []byte("schemas:\n  naming: package-qualified\n  rename:\n    example.com/app/billing.Response: Invoice\n"), 0o644))
+	cfg, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "package-qualified", cfg.Schemas.Strategy)
+	assert.Equal(t, map[string]string{"example.com/app/billing.Response": "Invoice"}, cfg.Schemas.Rename)
+
+	require.NoError(t, os.WriteFile(path, []byte("schemas:\n  naming: long\n"), 0o644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, `schemas: unknown schema naming strategy "long"`)
+
+	require.NoError(t, os.WriteFile(path, []byte("graphql:\n  nameing: {}\n"), 0o644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "field nameing not found")
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,250 @@
+// Package examples synthesizes example values for schemas that have none.
+//
+// Values are derived from the schema and the name of the field or
+// parameter they belong to: formats (email, uuid, date-time, uri, ...),
+// enums, numeric bounds, string lengths and patterns are respected, and
+// field names such as "email", "firstName", "createdAt" or "price" select
+// realistic values. References are followed with a limit on how deeply a
+// schema may be nested within itself. Documented examples are always kept.
+//
+// Generation is deterministic: every value is drawn from a random source
+// seeded with the configured seed and the value's path in the document, so
+// the same seed yields the same examples, and adding a field does not
+// change the examples of its siblings.
+package examples
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Options configures example generation.
+type Options struct {
+	// Seed selects the generated values.
+	Seed int64
+	// MaxDepth is how many times a schema may be nested within itself
+	// before recursive references are left out; it defaults to 1.
+	MaxDepth int
+}
+
+// Set holds the examples of a document.
+type Set struct {
+	// Schemas maps schema names to examples.
+	Schemas map[string]any `json:"schemas" yaml:"schemas"`
+	// Endpoints maps endpoint keys ("GET /users/{id}") to the examples of
+	// their parameters, request body and responses.
+	Endpoints map[string]*Endpoint `json:"endpoints" yaml:"endpoints"`
+}
+
+// Endpoint holds the examples of one endpoint.
+type Endpoint struct {
+	Parameters  map[string]any `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody any            `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]any `json:"responses,omitempty" yaml:"responses,omitempty"`
+}
+
+// Generator builds examples for the schemas of a document.
+type Generator struct {
+	doc  *model.Document
+	opts Options
+	// schemas caches the examples of named schemas, so that every
+	// top-level use of a schema shows the same example.
+	schemas map[string]any
+}
+
+// New returns a generator for doc.
+func New(doc *model.Document, opts Options) *Generator {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = 1
+	}
+	return &Generator{doc: doc, opts: opts, schemas: map[string]any{}}
+}
+
+// Schema returns an example for the named schema.
+func (g *Generator) Schema(name string) any {
+	if v, ok := g.schemas[name]; ok {
+		return v
+	}
+	v, _ := g.value(g.doc.Schemas[name], name, name, name, map[string]int{name: 1})
+	g.schemas[name] = v
+	return v
+}
+
+// Value returns an example for s. Name is the field or parameter the value
+// is for, and path identifies it within the document; both select the
+// value.
+func (g *Generator) Value(s *model.Schema, name, path string) any {
+	v, _ := g.value(s, name, "", path, map[string]int{})
+	return v
+}
+
+// Generate returns the examples of every schema and endpoint of doc.
+func Generate(doc *model.Document, opts Options) *Set {
+	g := New(doc, opts)
+	set := &Set{Schemas: map[string]any{}, Endpoints: map[string]*Endpoint{}}
+	for _, name := range doc.SchemaNames() {
+		set.Schemas[name] = g.Schema(name)
+	}
+	for _, ep := range doc.Endpoints {
+		e := &Endpoint{}
+		for _, p := range ep.Parameters {
+			if e.Parameters == nil {
+				e.Parameters = map[string]any{}
+			}
+			e.Parameters[p.Name] = g.parameter(ep, p)
+		}
+		if rb := ep.RequestBody; rb != nil {
+			e.RequestBody = g.requestBody(ep, rb)
+		}
+		for _, r := range ep.Responses {
+			if v := g.response(ep, r); v != nil {
+				if e.Responses == nil {
+					e.Responses = map[string]any{}
+				}
+				e.Responses[r.StatusCode] = v
+			}
+		}
+		set.Endpoints[ep.Key()] = e
+	}
+	return set
+}
+
+// Fill sets the example of every schema, parameter, request body and
+// response of doc that has a schema but no example.
+func Fill(doc *model.Document, opts Options) {
+	g := New(doc, opts)
+	for _, name := range doc.SchemaNames() {
+		if s := doc.Schemas[name]; s.Example == nil {
+			s.Example = g.Schema(name)
+		}
+	}
+	for _, ep := range doc.Endpoints {
+		for _, p := range ep.Parameters {
+			if p.Example == nil {
+				p.Example = g.parameter(ep, p)
+			}
+		}
+		if rb := ep.RequestBody; rb != nil && rb.Example == nil {
+			rb.Example = g.requestBody(ep, rb)
+		}
+		for _, r := range ep.Responses {
+			if r.Example == nil {
+				r.Example = g.response(ep, r)
+			}
+		}
+	}
+}
+
+func (g *Generator) parameter(ep *model.Endpoint, p *model.Parameter) any {
+	if p.Example != nil {
+		return p.Example
+	}
+	s := p.Schema
+	if s == nil {
+		s = &model.Schema{Type: "string"}
+	}
+	return g.Value(s, p.Name, ep.Key()+" "+p.In+" "+p.Name)
+}
+
+func (g *Generator) requestBody(ep *model.Endpoint, rb *model.RequestBody) any {
+	if rb.Example != nil || rb.Schema == nil {
+		return rb.Example
+	}
+	return g.Value(rb.Schema, rb.Schema.RefName(), ep.Key()+" body")
+}
+
+func (g *Generator) response(ep *model.Endpoint, r *model.Response) any {
+	if r.Example != nil || r.Schema == nil {
+		return r.Example
+	}
+	return g.Value(r.Schema, r.Schema.RefName(), ep.Key()+" "+r.StatusCode)
+}
+
+// value returns an example for s, or false when s recurses beyond the
+// depth limit and should be left out. Owner names the schema whose
+// property s is; refs counts the expansions of each named schema on the
+// current path.
+func (g *Generator) value(s *model.Schema, name, owner, path string, refs map[string]int) (any, bool) {
+	if s == nil {
+		return nil, false
+	}
+	if ref := s.RefName(); ref != "" {
+		target := g.doc.Schemas[ref]
+		if target == nil || refs[ref] > g.opts.MaxDepth {
+			return nil, false
+		}
+		if target.Example != nil {
+			return target.Example, true
+		}
+		if len(refs) == 0 {
+			return g.Schema(ref), true
+		}
+		refs[ref]++
+		defer func() {
+			if refs[ref]--; refs[ref] == 0 {
+				delete(refs, ref)
+			}
+		}()
+		if name == "" {
+			name = ref
+		}
+		if target.Type == "object" {
+			owner = ref
+		}
+		return g.value(target, name, owner, path, refs)
+	}
+	if s.Example != nil {
+		return s.Example, true
+	}
+	if len(s.OneOf) > 0 {
+		for i, alt := range s.OneOf {
+			if v, ok := g.value(alt, name, owner, path+"|"+strconv.Itoa(i), refs); ok {
+				return v, true
+			}
+		}
+		return nil, false
+	}
+	r := g.rand(path)
+	if len(s.Enum) > 0 {
+		return s.Enum[r.Intn(len(s.Enum))], true
+	}
+	switch s.Type {
+	case "object":
+		obj := map[string]any{}
+		for _, prop := range s.PropertyNames() {
+			if v, ok := g.value(s.Properties[prop], prop, owner, path+"."+prop, refs); ok {
+				obj[prop] = v
+			}
+		}
+		if s.AdditionalProperties != nil && len(s.Properties) == 0 {
+			if v, ok := g.value(s.AdditionalProperties, name, owner, path+".*", refs); ok {
+				obj[mapKey(name)] = v
+			}
+		}
+		return obj, true
+	case "array":
+		if v, ok := g.value(s.Items, singular(name), owner, path+"[]", refs); ok {
+			return []any{v}, true
+		}
+		return []any{}, true
+	case "integer":
+		return integer(s, name, r), true
+	case "number":
+		return number(s, name, r), true
+	case "boolean":
+		return boolean(name, r), true
+	}
+	return str(s, name, owner, r), true
+}
+
+// rand returns the random source for the value at path.
+func (g *Generator) rand(path string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(strconv.FormatInt(g.opts.Seed, 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
@@ -0,0 +1,125 @@
+package examples
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func float(f float64) *float64 { return &f }
+
+func length(n int) *int { return &n }
+
+func testDocument() *model.Document {
+	return &model.Document{
+		Schemas: map[string]*model.Schema{
+			"User": {
+				Type: "object",
+				Properties: map[string]*model.Schema{
+					"id":        {Type: "string", Format: "uuid"},
+					"email":     {Type: "string"},
+					"firstName": {Type: "string"},
+					"age":       {Type: "integer", Minimum: float(18), Maximum: float(21)},
+					"role":      {Type: "string", Enum: []any{"admin", "member"}},
+					"code":      {Type: "string", Pattern: `^[A-Z]{3}-\d{2}$`},
+					"nickname":  {Type: "string", MinLength: length(12), MaxLength: length(14)},
+					"createdAt": {Type: "string", Format: "date-time"},
+					"homepage":  {Type: "string", Format: "uri"},
+					"manager":   model.RefTo("User"),
+					"reports":   {Type: "array", Items: model.RefTo("User")},
+					"nickname2": {Type: "string", Example: "documented"},
+				},
+				PropertyOrder: []string{"id", "email", "firstName", "age", "role", "code", "nickname", "createdAt", "homepage", "manager", "reports", "nickname2"},
+			},
+		},
+		Endpoints: []*model.Endpoint{{
+			Method: "GET", Path: "/users/{id}",
+			Parameters: []*model.Parameter{
+				{Name: "id", In: "path", Schema: &model.Schema{Type: "integer"}},
+				{Name: "verbose", In: "query", Schema: &model.Schema{Type: "boolean"}, Example: false},
+			},
+			Responses: []*model.Response{
+				{StatusCode: "200", Schema: model.RefTo("User")},
+				{StatusCode: "404"},
+			},
+		}},
+	}
+}
+
+func TestSchemaExample(t *testing.T) {
+	doc := testDocument()
+	user, ok := New(doc, Options{Seed: 1}).Schema("User").(map[string]any)
+	require.True(t, ok)
+
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, user["id"])
+	assert.Regexp(t, `^[a-z]+\.[a-z]+@example\.com$`, user["email"])
+	assert.Contains(t, firstNames, user["firstName"])
+	assert.GreaterOrEqual(t, user["age"], int64(18))
+	assert.LessOrEqual(t, user["age"], int64(21))
+	assert.Contains(t, []any{"admin", "member"}, user["role"])
+	assert.Regexp(t, `^[A-Z]{3}-\d{2}$`, user["code"])
+	assert.GreaterOrEqual(t, len(user["nickname"].(string)), 12)
+	assert.LessOrEqual(t, len(user["nickname"].(string)), 14)
+	assert.Regexp(t, `^2024-\d\d-\d\dT\d\d:\d\d:\d\dZ$`, user["createdAt"])
+	assert.Equal(t, "https://example.com/homepage", user["homepage"])
+	assert.Equal(t, "documented", user["nickname2"])
+
+	// User is expanded once within itself, and no further.
+	manager, ok := user["manager"].(map[string]any)
+	require.True(t, ok)
+	assert.NotContains(t, manager, "manager")
+	assert.Equal(t, []any{}, manager["reports"])
+	require.Len(t, user["reports"], 1)
+}
+
+func TestDeterministic(t *testing.T) {
+	a := Generate(testDocument(), Options{Seed: 7})
+	b := Generate(testDocument(), Options{Seed: 7})
+	c := Generate(testDocument(), Options{Seed: 8})
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a.Schemas["User"], c.Schemas["User"])
+}
+
+func TestFill(t *testing.T) {
+	doc := testDocument()
+	Fill(doc, Options{Seed: 1})
+
+	ep := doc.Endpoints[0]
+	assert.IsType(t, int64(0), ep.Parameters[0].Example)
+	assert.Equal(t, false, ep.Parameters[1].Example)
+	assert.Equal(t, doc.Schemas["User"].Example, ep.Responses[0].Example)
+	assert.Nil(t, ep.Responses[1].Example)
+}
+
+func TestFromPattern(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, pattern := range []string{
+		`^[a-zA-Z]+$`,
+		`^\d{3}-\d{4}$`,
+		`^(foo|bar)+baz?$`,
+		`^[^/]{2,5}$`,
+		`^v[0-9]+\.[0-9]+(\.[0-9]+)?$`,
+		`^\w+@\w+\.com$`,
+	} {
+		re := regexp.MustCompile(pattern)
+		v, ok := fromPattern(re, 0, -1, r)
+		require.True(t, ok, pattern)
+		assert.Regexp(t, re, v)
+	}
+
+	_, ok := fromPattern(regexp.MustCompile(`^a{10}$`), 0, 5, r)
+	assert.False(t, ok)
+}
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"created", "at"}, words("createdAt"))
+	assert.Equal(t, []string{"created", "at"}, words("created_at"))
+	assert.Equal(t, []string{"user", "id"}, words("userID"))
+	assert.Equal(t, []string{"url", "path"}, words("URLPath"))
+	assert.Equal(t, []string{"x", "request", "id"}, words("X-Request-ID"))
+}
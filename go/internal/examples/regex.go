@@ -0,0 +1,121 @@
+package examples
+
+import (
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRepeat bounds the repetitions generated for unbounded operators.
+const maxRepeat = 3
+
+// patternTries is how many strings are generated for a pattern before
+// giving up on finding one of the required length.
+const patternTries = 20
+
+// fromPattern generates a string matching re with a length in
+// [minLen, maxLen]; maxLen < 0 means unbounded. Generation walks the
+// parsed expression, choosing alternatives, repetition counts and class
+// members at random, preferring printable ASCII.
+func fromPattern(re *regexp.Regexp, minLen, maxLen int, r *rand.Rand) (string, bool) {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	parsed = parsed.Simplify()
+	for i := 0; i < patternTries; i++ {
+		var b strings.Builder
+		if !generate(parsed, &b, r) {
+			return "", false
+		}
+		v := b.String()
+		n := utf8.RuneCountInString(v)
+		if re.MatchString(v) && n >= minLen && (maxLen < 0 || n <= maxLen) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func generate(re *syntax.Regexp, b *strings.Builder, r *rand.Rand) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		c, ok := classRune(re.Rune, r)
+		if !ok {
+			return false
+		}
+		b.WriteRune(c)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(alphanumeric[r.Intn(len(alphanumeric))])
+	case syntax.OpCapture:
+		return generate(re.Sub[0], b, r)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := repeatBounds(re)
+		for n := lo + r.Intn(hi-lo+1); n > 0; n-- {
+			if !generate(re.Sub[0], b, r) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !generate(sub, b, r) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return generate(re.Sub[r.Intn(len(re.Sub))], b, r)
+	}
+	// Anchors, word boundaries and empty matches produce no text.
+	return true
+}
+
+func repeatBounds(re *syntax.Regexp) (lo, hi int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxRepeat
+	case syntax.OpPlus:
+		return 1, maxRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	lo, hi = re.Min, re.Max
+	if hi < 0 {
+		hi = lo + maxRepeat
+	}
+	return lo, hi
+}
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// classRune picks a rune from a character class given as pairs of range
+// bounds, restricted to printable ASCII when the class allows it.
+func classRune(ranges []rune, r *rand.Rand) (rune, bool) {
+	if len(ranges) == 0 {
+		return 0, false
+	}
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	i := 2 * r.Intn(len(ranges)/2)
+	lo, hi := ranges[i], ranges[i+1]
+	return lo + rune(r.Intn(int(hi-lo)+1)), true
+}
@@ -0,0 +1,400 @@
+package examples
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/codegen"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// integer returns an integer within the schema's bounds, in a range
+// suited to the field name when the schema leaves it open.
+func integer(s *model.Schema, name string, r *rand.Rand) int64 {
+	lo, hi := intRange(words(name))
+	if s.Minimum != nil {
+		lo = int64(math.Ceil(*s.Minimum))
+		if hi < lo {
+			hi = lo + 100
+		}
+	}
+	if s.Maximum != nil {
+		hi = int64(math.Floor(*s.Maximum))
+		if lo > hi {
+			lo = hi - 100
+			if s.Minimum != nil {
+				return hi
+			}
+		}
+	}
+	if hi-lo+1 <= 0 {
+		return lo
+	}
+	return lo + r.Int63n(hi-lo+1)
+}
+
+func intRange(w []string) (lo, hi int64) {
+	switch last(w) {
+	case "id":
+		return 1, 1000
+	case "age":
+		return 18, 80
+	case "year":
+		return 2000, 2030
+	case "month":
+		return 1, 12
+	case "day":
+		return 1, 28
+	case "hour":
+		return 0, 23
+	case "minute", "minutes", "second", "seconds":
+		return 0, 59
+	case "page":
+		return 1, 5
+	case "limit", "size", "pagesize":
+		return 10, 100
+	case "offset", "skip":
+		return 0, 100
+	case "port":
+		return 1024, 65535
+	case "percent", "percentage", "progress":
+		return 0, 100
+	case "rating", "stars":
+		return 1, 5
+	case "price", "amount", "total", "cost", "balance":
+		return 1, 500
+	case "count", "quantity", "qty":
+		return 1, 10
+	}
+	return 1, 100
+}
+
+// number returns a number with two decimals within the schema's bounds.
+func number(s *model.Schema, name string, r *rand.Rand) float64 {
+	lo, hi := 1.0, 100.0
+	switch last(words(name)) {
+	case "lat", "latitude":
+		lo, hi = -90, 90
+	case "lng", "lon", "longitude":
+		lo, hi = -180, 180
+	case "price", "amount", "total", "cost", "balance":
+		lo, hi = 1, 500
+	case "rate", "ratio", "score", "probability":
+		lo, hi = 0, 1
+	case "percent", "percentage":
+		lo, hi = 0, 100
+	}
+	if s.Minimum != nil {
+		lo = *s.Minimum
+		if hi < lo {
+			hi = lo + 100
+		}
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+		if lo > hi {
+			lo = hi - 100
+			if s.Minimum != nil {
+				return hi
+			}
+		}
+	}
+	v := math.Round((lo+r.Float64()*(hi-lo))*100) / 100
+	return math.Min(math.Max(v, lo), hi)
+}
+
+func boolean(name string, r *rand.Rand) bool {
+	switch first(words(name)) {
+	case "is", "has", "can", "active", "enabled", "verified", "visible", "public":
+		return true
+	case "deleted", "disabled", "archived", "hidden", "locked":
+		return false
+	}
+	return r.Intn(2) == 0
+}
+
+// str returns a string respecting the schema's format, pattern and length.
+// Owner is the schema the field belongs to, which tells a person's name
+// from a product's.
+func str(s *model.Schema, name, owner string, r *rand.Rand) string {
+	v := formatted(s.Format, name, r)
+	if v == "" {
+		v = named(words(name), owner, r)
+	}
+	var re *regexp.Regexp
+	if s.Pattern != "" {
+		re, _ = regexp.Compile(s.Pattern)
+	}
+	fits := func(v string) bool {
+		n := utf8.RuneCountInString(v)
+		return (re == nil || re.MatchString(v)) &&
+			(s.MinLength == nil || n >= *s.MinLength) &&
+			(s.MaxLength == nil || n <= *s.MaxLength)
+	}
+	if fits(v) {
+		return v
+	}
+	if re != nil {
+		minLen, maxLen := 0, -1
+		if s.MinLength != nil {
+			minLen = *s.MinLength
+		}
+		if s.MaxLength != nil {
+			maxLen = *s.MaxLength
+		}
+		if p, ok := fromPattern(re, minLen, maxLen, r); ok {
+			return p
+		}
+		return v
+	}
+	return fitLength(v, s.MinLength, s.MaxLength)
+}
+
+// fitLength pads or truncates v to the length bounds.
+func fitLength(v string, minLen, maxLen *int) string {
+	runes := []rune(v)
+	if maxLen != nil && len(runes) > *maxLen {
+		runes = runes[:*maxLen]
+	}
+	if minLen != nil {
+		for i := 0; len(runes) < *minLen; i++ {
+			runes = append(runes, rune('a'+i%26))
+		}
+	}
+	return string(runes)
+}
+
+// epoch is the earliest generated timestamp.
+var epoch = time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+func timestamp(r *rand.Rand) time.Time {
+	return epoch.Add(time.Duration(r.Intn(365*24*3600)) * time.Second)
+}
+
+// formatted returns a value for a string format, or "" for formats
+// without a specific value.
+func formatted(format, name string, r *rand.Rand) string {
+	switch format {
+	case "email":
+		return email(r)
+	case "uuid":
+		return uuid(r)
+	case "date-time":
+		return timestamp(r).Format(time.RFC3339)
+	case "date":
+		return timestamp(r).Format("2006-01-02")
+	case "time":
+		return timestamp(r).Format("15:04:05")
+	case "uri", "url":
+		return "https://example.com/" + slug(name)
+	case "hostname":
+		return "api.example.com"
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+r.Intn(254))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+r.Intn(0xfffe))
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte("example " + strings.Join(words(name), " ")))
+	case "decimal":
+		return fmt.Sprintf("%d.%02d", 1+r.Intn(500), r.Intn(100))
+	case "password":
+		return "correct-horse-battery-" + fmt.Sprint(10+r.Intn(90))
+	}
+	return ""
+}
+
+var (
+	firstNames = []string{"Ada", "Alan", "Grace", "Linus", "Margaret", "Ken", "Barbara", "Dennis"}
+	lastNames  = []string{"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Thompson", "Liskov", "Ritchie"}
+	cities     = []string{"Berlin", "Lisbon", "Toronto", "Osaka", "Austin", "Nairobi"}
+	countries  = []string{"DE", "PT", "CA", "JP", "US", "KE"}
+	currencies = []string{"EUR", "USD", "GBP", "JPY"}
+	timezones  = []string{"Europe/Berlin", "America/New_York", "Asia/Tokyo"}
+	statuses   = []string{"active", "pending", "completed"}
+)
+
+// personOwners are schema name words that describe people.
+var personOwners = []string{"user", "person", "customer", "employee", "author", "member", "account", "contact", "profile", "owner", "admin"}
+
+func pick(r *rand.Rand, options []string) string {
+	return options[r.Intn(len(options))]
+}
+
+func email(r *rand.Rand) string {
+	return strings.ToLower(pick(r, firstNames)+"."+pick(r, lastNames)) + "@example.com"
+}
+
+func uuid(r *rand.Rand) string {
+	var b [16]byte
+	r.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// named returns a value suited to a field named by words.
+func named(w []string, owner string, r *rand.Rand) string {
+	joined := strings.Join(w, "")
+	switch joined {
+	case "firstname", "givenname", "forename":
+		return pick(r, firstNames)
+	case "lastname", "surname", "familyname":
+		return pick(r, lastNames)
+	case "username", "login", "handle", "nickname", "screenname":
+		return strings.ToLower(pick(r, firstNames)[:1] + pick(r, lastNames))
+	case "fullname", "displayname", "authorname", "contactname":
+		return pick(r, firstNames) + " " + pick(r, lastNames)
+	case "name":
+		if isPerson(owner) {
+			return pick(r, firstNames) + " " + pick(r, lastNames)
+		}
+		if owner != "" {
+			return "Example " + strings.Join(words(owner), " ")
+		}
+		return "example"
+	case "mimetype", "contenttype", "mediatype":
+		return "application/json"
+	case "countrycode":
+		return pick(r, countries)
+	case "currencycode":
+		return pick(r, currencies)
+	}
+	switch last(w) {
+	case "id", "uuid", "guid":
+		return uuid(r)
+	case "email", "mail":
+		return email(r)
+	case "at", "time", "timestamp":
+		return timestamp(r).Format(time.RFC3339)
+	case "date", "birthday", "dob":
+		return timestamp(r).Format("2006-01-02")
+	case "url", "uri", "link", "website", "homepage", "href":
+		return "https://example.com/" + slug(strings.Join(w[:len(w)-1], "-"))
+	case "avatar", "image", "photo", "picture", "thumbnail":
+		return "https://example.com/images/" + slug(joined) + ".png"
+	case "phone", "mobile", "telephone", "tel", "fax":
+		return fmt.Sprintf("+1-555-01%02d", r.Intn(100))
+	case "city", "town":
+		return pick(r, cities)
+	case "country":
+		return pick(r, countries)
+	case "zip", "zipcode", "postcode", "postal":
+		return fmt.Sprintf("%05d", r.Intn(100000))
+	case "street", "address", "line1":
+		return fmt.Sprintf("%d Main Street", 1+r.Intn(999))
+	case "company", "organization", "organisation", "org", "employer":
+		return "Acme Corporation"
+	case "title", "subject", "headline":
+		return "Getting started"
+	case "description", "summary", "bio", "about", "comment", "note", "notes", "message", "body", "text", "content":
+		return "A short example " + strings.Join(w, " ") + "."
+	case "password", "passphrase":
+		return "correct-horse-battery-" + fmt.Sprint(10+r.Intn(90))
+	case "token", "secret", "hash", "signature", "key", "apikey", "nonce":
+		b := make([]byte, 16)
+		r.Read(b)
+		return fmt.Sprintf("%x", b)
+	case "currency":
+		return pick(r, currencies)
+	case "language", "lang", "locale":
+		return "en-US"
+	case "timezone", "tz":
+		return pick(r, timezones)
+	case "color", "colour":
+		return fmt.Sprintf("#%06x", r.Intn(0x1000000))
+	case "ip":
+		return fmt.Sprintf("192.0.2.%d", 1+r.Intn(254))
+	case "host", "hostname", "domain":
+		return "api.example.com"
+	case "slug":
+		return "getting-started"
+	case "status":
+		return pick(r, statuses)
+	case "state", "province", "region":
+		if isPerson(owner) || strings.Contains(strings.ToLower(owner), "address") {
+			return "CA"
+		}
+		return pick(r, statuses)
+	case "sku", "code":
+		return fmt.Sprintf("%c%c%c-%03d", 'A'+r.Intn(26), 'A'+r.Intn(26), 'A'+r.Intn(26), r.Intn(1000))
+	case "version":
+		return fmt.Sprintf("1.%d.0", r.Intn(10))
+	case "type", "kind", "category":
+		return "standard"
+	}
+	if len(w) == 0 {
+		return "string"
+	}
+	return "example " + strings.Join(w, " ")
+}
+
+func isPerson(owner string) bool {
+	for _, w := range words(owner) {
+		for _, p := range personOwners {
+			if w == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// words splits an identifier into lower case words at case changes,
+// digit boundaries and separators: "createdAt", "created_at" and
+// "CreatedAt" all become [created at], and "userID" becomes [user id].
+func words(name string) []string {
+	out := codegen.Words(name)
+	for i, w := range out {
+		out[i] = strings.ToLower(w)
+	}
+	return out
+}
+
+func first(w []string) string {
+	if len(w) == 0 {
+		return ""
+	}
+	return w[0]
+}
+
+func last(w []string) string {
+	if len(w) == 0 {
+		return ""
+	}
+	return w[len(w)-1]
+}
+
+func slug(s string) string {
+	w := words(s)
+	if len(w) == 0 {
+		return "resource"
+	}
+	return strings.Join(w, "-")
+}
+
+// singular names the items of an array field: "tags" becomes "tag".
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ses"), strings.HasSuffix(name, "xes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "ss"):
+		return name
+	}
+	return strings.TrimSuffix(name, "s")
+}
+
+// mapKey returns an example key for a map field.
+func mapKey(name string) string {
+	if w := words(singular(name)); len(w) > 0 {
+		return strings.Join(w, "_") + "_key"
+	}
+	return "key"
+}
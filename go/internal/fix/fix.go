@@ -0,0 +1,110 @@
+// Package fix applies the source edits that diagnostics carry as fixes.
+//
+// Edits address byte offsets in the files as they were analyzed. Each edit
+// is checked against the current contents before it is applied, so files
+// changed since the analysis are never corrupted: edits that no longer
+// match are skipped instead.
+package fix
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Result reports what Apply did.
+type Result struct {
+	// Files lists the changed files, relative to the root, in order.
+	Files []string
+	// Fixed lists the diagnostics whose fixes were applied.
+	Fixed []*model.Diagnostic
+	// Skipped counts the diagnostics whose fixes were not applied because
+	// an edit no longer matched the file or overlapped another fix.
+	Skipped int
+}
+
+// Apply applies the fixes of diags to the files under root. The edits of
+// one diagnostic are applied together or not at all; when fixes overlap,
+// the earlier diagnostic wins.
+func Apply(root string, diags []*model.Diagnostic) (*Result, error) {
+	res := &Result{}
+	files := map[string][]byte{}
+	accepted := map[string][]*model.Edit{}
+	for _, d := range diags {
+		if len(d.Fix) == 0 {
+			continue
+		}
+		ok := true
+		for _, e := range d.Fix {
+			src, loaded := files[e.File]
+			if !loaded {
+				var err error
+				if src, err = os.ReadFile(filepath.Join(root, e.File)); err != nil {
+					return nil, err
+				}
+				files[e.File] = src
+			}
+			if !matches(src, e) || overlaps(accepted[e.File], e) {
+				ok = false
+				break
+			}
+		}
+		if !ok || selfOverlapping(d.Fix) {
+			res.Skipped++
+			continue
+		}
+		for _, e := range d.Fix {
+			accepted[e.File] = append(accepted[e.File], e)
+		}
+		res.Fixed = append(res.Fixed, d)
+	}
+
+	for name, edits := range accepted {
+		sort.Slice(edits, func(i, j int) bool { return edits[i].Offset > edits[j].Offset })
+		src := files[name]
+		for _, e := range edits {
+			src = append(src[:e.Offset:e.Offset], append([]byte(e.New), src[e.Offset+len(e.Old):]...)...)
+		}
+		path := filepath.Join(root, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, src, info.Mode().Perm()); err != nil {
+			return nil, err
+		}
+		res.Files = append(res.Files, name)
+	}
+	sort.Strings(res.Files)
+	return res, nil
+}
+
+func matches(src []byte, e *model.Edit) bool {
+	end := e.Offset + len(e.Old)
+	return e.Offset >= 0 && end <= len(src) && bytes.Equal(src[e.Offset:end], []byte(e.Old))
+}
+
+func overlaps(edits []*model.Edit, e *model.Edit) bool {
+	for _, o := range edits {
+		if o.File == e.File && e.Offset < o.Offset+len(o.Old) && o.Offset < e.Offset+len(e.Old) {
+			return true
+		}
+		// Two insertions at one offset have no defined order.
+		if o.File == e.File && o.Offset == e.Offset {
+			return true
+		}
+	}
+	return false
+}
+
+func selfOverlapping(edits []*model.Edit) bool {
+	for i, e := range edits {
+		if overlaps(edits[:i], e) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("// the whitelist of github users\n"), 0o600))
+
+	edit := func(offset int, old, new string) *model.Diagnostic {
+		return &model.Diagnostic{Fix: []*model.Edit{{File: "a.go", Offset: offset, Old: old, New: new}}}
+	}
+	diags := []*model.Diagnostic{
+		edit(7, "whitelist", "allowlist"),
+		edit(20, "github", "GitHub"),
+		// Overlaps the first fix.
+		edit(7, "white", "black"),
+		// No longer matches the file.
+		edit(27, "usres", "users"),
+		{Code: "NO_FIX"},
+	}
+	res, err := Apply(dir, diags)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, res.Files)
+	assert.Equal(t, diags[:2], res.Fixed)
+	assert.Equal(t, 2, res.Skipped)
+
+	src, err := os.ReadFile(filepath.Join(dir, "a.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "// the allowlist of GitHub users\n", string(src))
+	info, err := os.Stat(filepath.Join(dir, "a.go"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
@@ -0,0 +1,751 @@
+// Package fuzzgen generates native Go fuzz tests for extracted endpoints.
+//
+// Every endpoint gets a FuzzXxx function whose fuzzed arguments are its
+// path, query, header and cookie parameters and the scalar properties of
+// its JSON request body. Arguments are constrained by the extracted
+// schemas (enums, bounds, lengths and patterns) before the request is
+// built, so that fuzzing explores inputs the API documents as valid. The
+// seed corpus comes from documented examples. Requests are served by the
+// application's router through net/http/httptest, and a test fails when a
+// handler panics or responds with a 5xx status.
+package fuzzgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/codegen"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Options configures the generated test file.
+type Options struct {
+	// Package is the package clause of the generated file.
+	Package string
+	// Router is a Go expression of type http.Handler evaluated once per
+	// fuzz test, e.g. "NewRouter()".
+	Router string
+	// Headers are set on every request, e.g. an Authorization header
+	// accepted by the authentication middleware.
+	Headers map[string]string
+}
+
+// Generate renders a _test.go file fuzzing every endpoint of doc.
+func Generate(doc *model.Document, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	if opts.Router == "" {
+		return nil, fmt.Errorf("router expression is required")
+	}
+	g := &generator{doc: doc, opts: opts, helpers: map[string]bool{}, names: map[string]bool{}}
+	for _, ep := range doc.Endpoints {
+		g.endpoint(ep)
+	}
+	return g.file()
+}
+
+type generator struct {
+	doc     *model.Document
+	opts    Options
+	funcs   bytes.Buffer
+	helpers map[string]bool
+	names   map[string]bool
+}
+
+// fuzzInput is one fuzzed argument.
+type fuzzInput struct {
+	name   string
+	goType string
+	seed   string
+	zero   string
+}
+
+// fuzzFunc accumulates the code of one fuzz test.
+type fuzzFunc struct {
+	g      *generator
+	inputs []fuzzInput
+	idents map[string]bool
+	// setup runs once before fuzzing; body builds and serves a request.
+	setup []string
+	body  []string
+	after []string
+}
+
+func (g *generator) endpoint(ep *model.Endpoint) {
+	f := &fuzzFunc{g: g, idents: map[string]bool{}}
+	for _, reserved := range reservedIdents {
+		f.idents[reserved] = true
+	}
+
+	f.path(ep)
+	query := false
+	for _, p := range ep.Parameters {
+		if p.In == "query" {
+			if !query {
+				f.body = append(f.body, "q := url.Values{}")
+				query = true
+			}
+			f.queryParam(p)
+		}
+	}
+	if query {
+		f.body = append(f.body, "if len(q) > 0 {", "target += \"?\" + q.Encode()", "}")
+	}
+	reader := f.requestBody(ep)
+	if len(f.inputs) == 0 {
+		// Fuzz tests need at least one argument; an arbitrary query string
+		// still exercises query parsing.
+		f.inputs = append(f.inputs, fuzzInput{name: "rawQuery", goType: "string", seed: `""`, zero: `"a=1"`})
+		f.body = append(f.body, "if rawQuery != \"\" {", "target += \"?\" + url.PathEscape(rawQuery)", "}")
+	}
+	g.helpers["apidocRequest"] = true
+	f.body = append(f.body, fmt.Sprintf("req := apidocRequest(%q, target, %s)", ep.Method, reader))
+	if ep.RequestBody != nil {
+		f.body = append(f.body, fmt.Sprintf("req.Header.Set(\"Content-Type\", %q)", ep.RequestBody.ContentType))
+	}
+	for _, p := range ep.Parameters {
+		switch p.In {
+		case "header":
+			f.headerParam(p)
+		case "cookie":
+			f.cookieParam(p)
+		}
+	}
+	f.body = append(f.body, f.after...)
+	f.body = append(f.body, "apidocServe(t, h, req)")
+	g.helpers["apidocServe"] = true
+
+	name := g.funcName(ep)
+	w := &g.funcs
+	fmt.Fprintf(w, "// %s fuzzes %s %s and fails on panics and 5xx responses.\n", name, ep.Method, ep.Path)
+	fmt.Fprintf(w, "func %s(f *testing.F) {\n", name)
+	seeds, zeros := make([]string, len(f.inputs)), make([]string, len(f.inputs))
+	params := make([]string, len(f.inputs))
+	for i, in := range f.inputs {
+		seeds[i], zeros[i] = in.seed, in.zero
+		params[i] = in.name + " " + in.goType
+	}
+	fmt.Fprintf(w, "f.Add(%s)\n", strings.Join(seeds, ", "))
+	if strings.Join(zeros, ",") != strings.Join(seeds, ",") {
+		fmt.Fprintf(w, "f.Add(%s)\n", strings.Join(zeros, ", "))
+	}
+	for _, line := range f.setup {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "h := %s\n", g.opts.Router)
+	fmt.Fprintf(w, "f.Fuzz(func(t *testing.T, %s) {\n", strings.Join(params, ", "))
+	for _, line := range f.body {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprint(w, "})\n}\n\n")
+}
+
+// funcName derives a unique test name from the operation ID.
+func (g *generator) funcName(ep *model.Endpoint) string {
+	base := ep.OperationID
+	if base == "" {
+		base = ep.ID
+	}
+	name := "Fuzz" + exported(base)
+	if g.names[name] {
+		name += exported(strings.ToLower(ep.Method))
+	}
+	for i := 2; g.names[name]; i++ {
+		name = fmt.Sprintf("Fuzz%s%d", exported(base), i)
+	}
+	g.names[name] = true
+	return name
+}
+
+// path emits the code building the request path in the variable target,
+// escaping fuzzed path parameters.
+func (f *fuzzFunc) path(ep *model.Endpoint) {
+	params := map[string]*model.Parameter{}
+	for _, p := range ep.Parameters {
+		if p.In == "path" {
+			params[p.Name] = p
+		}
+	}
+	var parts []string
+	rest := ep.Path
+	for {
+		open := strings.IndexByte(rest, '{')
+		end := strings.IndexByte(rest, '}')
+		if open < 0 || end < open {
+			if rest != "" {
+				parts = append(parts, strconv.Quote(rest))
+			}
+			break
+		}
+		if open > 0 {
+			parts = append(parts, strconv.Quote(rest[:open]))
+		}
+		name := rest[open+1 : end]
+		p := params[name]
+		if p == nil {
+			p = &model.Parameter{Name: name, In: "path", Required: true, Schema: &model.Schema{Type: "string"}}
+		}
+		v := f.scalar(p.Name, p.Schema, p.Example, true)
+		if v.goType == "string" {
+			f.body = append(f.body, fmt.Sprintf("if %s == \"\" {", v.name), "t.Skip()", "}")
+		}
+		parts = append(parts, "url.PathEscape("+v.text+")")
+		rest = rest[end+1:]
+	}
+	if len(parts) == 0 {
+		parts = []string{`"/"`}
+	}
+	f.body = append(f.body, "target := "+strings.Join(parts, " + "))
+}
+
+func (f *fuzzFunc) queryParam(p *model.Parameter) {
+	v := f.scalar(p.Name, p.Schema, p.Example, p.Required)
+	set := fmt.Sprintf("q.Set(%q, %s)", p.Name, v.text)
+	if v.goType == "string" && !p.Required {
+		f.body = append(f.body, fmt.Sprintf("if %s != \"\" {", v.name), set, "}")
+		return
+	}
+	f.body = append(f.body, set)
+}
+
+func (f *fuzzFunc) headerParam(p *model.Parameter) {
+	v := f.scalar(p.Name, p.Schema, p.Example, p.Required)
+	f.g.helpers["apidocHeader"] = true
+	set := fmt.Sprintf("req.Header.Set(%q, apidocHeader(%s))", p.Name, v.text)
+	if v.goType == "string" && !p.Required {
+		f.body = append(f.body, fmt.Sprintf("if %s != \"\" {", v.name), set, "}")
+		return
+	}
+	f.body = append(f.body, set)
+}
+
+func (f *fuzzFunc) cookieParam(p *model.Parameter) {
+	v := f.scalar(p.Name, p.Schema, p.Example, p.Required)
+	set := fmt.Sprintf("req.AddCookie(&http.Cookie{Name: %q, Value: %s})", p.Name, v.text)
+	if v.goType == "string" && !p.Required {
+		f.body = append(f.body, fmt.Sprintf("if %s != \"\" {", v.name), set, "}")
+		return
+	}
+	f.body = append(f.body, set)
+}
+
+// requestBody emits the code building the request body and returns the
+// io.Reader expression passed to the request.
+func (f *fuzzFunc) requestBody(ep *model.Endpoint) string {
+	rb := ep.RequestBody
+	if rb == nil {
+		return "nil"
+	}
+	schema := f.g.doc.Resolve(rb.Schema)
+	isJSON := strings.Contains(rb.ContentType, "json")
+	if isJSON && schema != nil && schema.Type == "object" && len(schema.Properties) > 0 {
+		f.body = append(f.body, "body := map[string]any{}")
+		example, _ := rb.Example.(map[string]any)
+		for _, name := range schema.PropertyNames() {
+			prop := f.g.doc.Resolve(schema.Properties[name])
+			required := schema.IsRequired(name)
+			var ex any
+			if example != nil {
+				ex = example[name]
+			}
+			if ex == nil && prop != nil {
+				ex = prop.Example
+			}
+			if kindOf(prop) == "" {
+				f.fixedProperty(name, prop, ex, required)
+				continue
+			}
+			v := f.scalar("body "+name, prop, ex, required)
+			set := fmt.Sprintf("body[%q] = %s", name, v.name)
+			if v.goType == "string" && !required {
+				f.body = append(f.body, fmt.Sprintf("if %s != \"\" {", v.name), set, "}")
+			} else {
+				f.body = append(f.body, set)
+			}
+		}
+		f.body = append(f.body,
+			"payload, err := json.Marshal(body)",
+			"if err != nil {", "t.Skip()", "}")
+		return "bytes.NewReader(payload)"
+	}
+
+	seed := `[]byte("")`
+	if rb.Example != nil {
+		if b, err := exampleBytes(rb.Example, isJSON); err == nil {
+			seed = fmt.Sprintf("[]byte(%s)", quote(string(b)))
+		}
+	} else if isJSON {
+		seed = `[]byte("{}")`
+	}
+	name := f.ident("raw body")
+	f.inputs = append(f.inputs, fuzzInput{name: name, goType: "[]byte", seed: seed, zero: `[]byte("")`})
+	return "bytes.NewReader(" + name + ")"
+}
+
+// fixedProperty sends a non-scalar body property with its example value,
+// or an empty value of its type when it is required.
+func (f *fuzzFunc) fixedProperty(name string, prop *model.Schema, example any, required bool) {
+	var value string
+	switch {
+	case example != nil:
+		b, err := json.Marshal(example)
+		if err != nil {
+			return
+		}
+		value = string(b)
+	case !required:
+		return
+	case prop != nil && prop.Type == "array":
+		value = "[]"
+	case prop != nil && prop.Type == "object":
+		value = "{}"
+	default:
+		value = "null"
+	}
+	f.body = append(f.body, fmt.Sprintf("body[%q] = json.RawMessage(%s)", name, quote(value)))
+}
+
+// scalarVar is a fuzzed argument after constraints are applied, and the
+// expression formatting it as a string.
+type scalarVar struct {
+	name   string
+	goType string
+	text   string
+}
+
+// scalar declares a fuzzed argument for a parameter or property, emits
+// the code constraining it by its schema and returns it.
+func (f *fuzzFunc) scalar(name string, s *model.Schema, example any, required bool) scalarVar {
+	s = f.g.doc.Resolve(s)
+	if s == nil {
+		s = &model.Schema{Type: "string"}
+	}
+	kind := kindOf(s)
+	if kind == "" {
+		kind = "string"
+	}
+	if s.Type == "array" && s.Items != nil {
+		if items := f.g.doc.Resolve(s.Items); kindOf(items) != "" {
+			s, kind = items, kindOf(items)
+		}
+	}
+	v := scalarVar{name: f.ident(name)}
+	in := fuzzInput{name: v.name}
+	switch kind {
+	case "integer":
+		v.goType, in.zero = "int64", "int64(0)"
+		v.text = "strconv.FormatInt(" + v.name + ", 10)"
+		f.intConstraints(v.name, s)
+	case "number":
+		v.goType, in.zero = "float64", "float64(0)"
+		v.text = "strconv.FormatFloat(" + v.name + ", 'g', -1, 64)"
+		f.floatConstraints(v.name, s)
+	case "boolean":
+		v.goType, in.zero = "bool", "false"
+		v.text = "strconv.FormatBool(" + v.name + ")"
+	default:
+		v.goType, in.zero = "string", `""`
+		v.text = v.name
+		f.stringConstraints(v.name, s)
+	}
+	in.goType = v.goType
+	in.seed = seedLiteral(kind, seedValue(s, example, kind))
+	f.inputs = append(f.inputs, in)
+	return v
+}
+
+func (f *fuzzFunc) intConstraints(v string, s *model.Schema) {
+	if enum := intEnum(s.Enum); len(enum) > 0 {
+		f.g.helpers["apidocPickInt"] = true
+		f.body = append(f.body, fmt.Sprintf("%s = apidocPickInt(%s, []int64{%s})", v, v, joinInts(enum)))
+		return
+	}
+	switch {
+	case s.Minimum != nil && s.Maximum != nil:
+		f.g.helpers["apidocRange"] = true
+		f.body = append(f.body, fmt.Sprintf("%s = apidocRange(%s, %d, %d)", v, v, int64(*s.Minimum), int64(*s.Maximum)))
+	case s.Minimum != nil:
+		f.body = append(f.body, fmt.Sprintf("if %s < %d {", v, int64(*s.Minimum)), fmt.Sprintf("%s = %d", v, int64(*s.Minimum)), "}")
+	case s.Maximum != nil:
+		f.body = append(f.body, fmt.Sprintf("if %s > %d {", v, int64(*s.Maximum)), fmt.Sprintf("%s = %d", v, int64(*s.Maximum)), "}")
+	}
+}
+
+func (f *fuzzFunc) floatConstraints(v string, s *model.Schema) {
+	f.body = append(f.body, fmt.Sprintf("if math.IsNaN(%s) || math.IsInf(%s, 0) {", v, v), "t.Skip()", "}")
+	if s.Minimum != nil {
+		f.body = append(f.body, fmt.Sprintf("if %s < %s {", v, floatLit(*s.Minimum)), fmt.Sprintf("%s = %s", v, floatLit(*s.Minimum)), "}")
+	}
+	if s.Maximum != nil {
+		f.body = append(f.body, fmt.Sprintf("if %s > %s {", v, floatLit(*s.Maximum)), fmt.Sprintf("%s = %s", v, floatLit(*s.Maximum)), "}")
+	}
+}
+
+func (f *fuzzFunc) stringConstraints(v string, s *model.Schema) {
+	if len(s.Enum) > 0 {
+		options := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			options = append(options, strconv.Quote(fmt.Sprint(e)))
+		}
+		f.g.helpers["apidocPick"] = true
+		f.body = append(f.body, fmt.Sprintf("%s = apidocPick(%s, []string{%s})", v, v, strings.Join(options, ", ")))
+		return
+	}
+	if s.MaxLength != nil {
+		f.g.helpers["apidocTruncate"] = true
+		f.body = append(f.body, fmt.Sprintf("%s = apidocTruncate(%s, %d)", v, v, *s.MaxLength))
+	}
+	if s.MinLength != nil && *s.MinLength > 0 {
+		f.g.helpers["apidocPad"] = true
+		f.body = append(f.body, fmt.Sprintf("%s = apidocPad(%s, %d)", v, v, *s.MinLength))
+	}
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err == nil {
+			re := f.ident(v + "Pattern")
+			f.setup = append(f.setup, fmt.Sprintf("%s := regexp.MustCompile(%s)", re, quote(s.Pattern)))
+			f.body = append(f.body, fmt.Sprintf("if !%s.MatchString(%s) {", re, v), "t.Skip()", "}")
+		}
+	}
+}
+
+// kindOf returns the scalar JSON type of a schema, or "" for objects,
+// arrays and schemas without a type.
+func kindOf(s *model.Schema) string {
+	if s == nil {
+		return ""
+	}
+	switch s.Type {
+	case "string", "integer", "number", "boolean":
+		return s.Type
+	}
+	return ""
+}
+
+// seedValue picks the seed for an argument: the documented example, the
+// schema example, the first enum value, or a value valid for the schema.
+func seedValue(s *model.Schema, example any, kind string) any {
+	if example != nil {
+		return example
+	}
+	if s.Example != nil {
+		return s.Example
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+	switch kind {
+	case "integer", "number":
+		if s.Minimum != nil && *s.Minimum > 1 {
+			return *s.Minimum
+		}
+		if s.Maximum != nil && *s.Maximum < 1 {
+			return *s.Maximum
+		}
+		return 1
+	case "boolean":
+		return true
+	}
+	switch s.Format {
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "user@example.com"
+	case "uri":
+		return "https://example.com"
+	}
+	return "1"
+}
+
+// seedLiteral renders a seed value as a Go literal of the argument type.
+func seedLiteral(kind string, v any) string {
+	switch kind {
+	case "integer":
+		switch n := v.(type) {
+		case int:
+			return fmt.Sprintf("int64(%d)", n)
+		case int64:
+			return fmt.Sprintf("int64(%d)", n)
+		case float64:
+			return fmt.Sprintf("int64(%d)", int64(n))
+		case string:
+			if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+				return fmt.Sprintf("int64(%d)", i)
+			}
+		}
+		return "int64(1)"
+	case "number":
+		switch n := v.(type) {
+		case int:
+			return "float64(" + floatLit(float64(n)) + ")"
+		case int64:
+			return "float64(" + floatLit(float64(n)) + ")"
+		case float64:
+			return "float64(" + floatLit(n) + ")"
+		case string:
+			if x, err := strconv.ParseFloat(n, 64); err == nil {
+				return "float64(" + floatLit(x) + ")"
+			}
+		}
+		return "float64(1)"
+	case "boolean":
+		switch b := v.(type) {
+		case bool:
+			return strconv.FormatBool(b)
+		case string:
+			if x, err := strconv.ParseBool(b); err == nil {
+				return strconv.FormatBool(x)
+			}
+		}
+		return "true"
+	}
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+func floatLit(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+func intEnum(values []any) []int64 {
+	var out []int64
+	for _, v := range values {
+		switch n := v.(type) {
+		case int:
+			out = append(out, int64(n))
+		case int64:
+			out = append(out, n)
+		case float64:
+			out = append(out, int64(n))
+		default:
+			return nil
+		}
+	}
+	return out
+}
+
+func joinInts(ns []int64) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.FormatInt(n, 10)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func exampleBytes(v any, isJSON bool) ([]byte, error) {
+	if s, ok := v.(string); ok && !isJSON {
+		return []byte(s), nil
+	}
+	return json.Marshal(v)
+}
+
+// quote renders s as a raw string literal when possible.
+func quote(s string) string {
+	if !strings.Contains(s, "`") && strconv.CanBackquote(s) {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// reservedIdents are names used by the generated code.
+var reservedIdents = []string{
+	"t", "f", "h", "q", "req", "target", "body", "payload", "err",
+	"bytes", "json", "math", "http", "httptest", "url", "regexp", "strconv", "strings", "testing",
+	"any", "string", "bool", "int64", "float64", "len", "nil", "true", "false",
+	"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough",
+	"for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range",
+	"return", "select", "struct", "switch", "type", "var",
+}
+
+// ident returns a unique lowerCamelCase identifier for name.
+func (f *fuzzFunc) ident(name string) string {
+	id := lowerCamel(name)
+	if id == "" || !unicode.IsLetter([]rune(id)[0]) {
+		id = "p" + exported(id)
+	}
+	base := id
+	for i := 2; f.idents[id]; i++ {
+		id = fmt.Sprintf("%s%d", base, i)
+	}
+	f.idents[id] = true
+	return id
+}
+
+func lowerCamel(s string) string {
+	var b strings.Builder
+	for i, w := range codegen.Words(s) {
+		if i == 0 {
+			b.WriteString(strings.ToLower(w[:1]) + w[1:])
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+func exported(s string) string {
+	var b strings.Builder
+	for _, w := range codegen.Words(s) {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+// file assembles and formats the generated source.
+func (g *generator) file() ([]byte, error) {
+	var helpers bytes.Buffer
+	names := make([]string, 0, len(g.helpers))
+	for name := range g.helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		helpers.WriteString(helperSource[name])
+		helpers.WriteString("\n")
+	}
+	helpers.WriteString(g.headersSource())
+
+	code := g.funcs.String() + helpers.String()
+	out, err := codegen.File("// Code generated by api-doc-gen-go gen fuzz. DO NOT EDIT.", g.opts.Package, imports, code)
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated fuzz tests: %w", err)
+	}
+	return out, nil
+}
+
+func (g *generator) headersSource() string {
+	var b strings.Builder
+	b.WriteString("// apidocHeaders are set on every request.\n")
+	b.WriteString("var apidocHeaders = map[string]string{\n")
+	keys := make([]string, 0, len(g.opts.Headers))
+	for k := range g.opts.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%q: %q,\n", k, g.opts.Headers[k])
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// imports are the packages the generated code may use.
+var imports = []string{
+	"bytes",
+	"encoding/json",
+	"io",
+	"math",
+	"net/http",
+	"net/http/httptest",
+	"net/url",
+	"regexp",
+	"strconv",
+	"strings",
+	"testing",
+	"unicode/utf8",
+}
+
+var helperSource = map[string]string{
+	"apidocRequest": `// apidocRequest builds a request carrying apidocHeaders.
+func apidocRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	for k, v := range apidocHeaders {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+`,
+	"apidocServe": `// apidocServe serves req and fails the test if the handler panics or
+// responds with a 5xx status.
+func apidocServe(t *testing.T, h http.Handler, req *http.Request) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	defer func() {
+		if p := recover(); p != nil {
+			t.Fatalf("%s %s panicked: %v", req.Method, req.URL, p)
+		}
+	}()
+	h.ServeHTTP(rec, req)
+	if rec.Code >= 500 {
+		t.Fatalf("%s %s: status %d: %s", req.Method, req.URL, rec.Code, rec.Body.String())
+	}
+}
+`,
+	"apidocHeader": `// apidocHeader drops the bytes not allowed in header values.
+func apidocHeader(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' || r == 0x7f || r > 0x7e {
+			return -1
+		}
+		return r
+	}, s)
+}
+`,
+	"apidocPick": `// apidocPick maps s onto one of the allowed values.
+func apidocPick(s string, options []string) string {
+	for _, o := range options {
+		if s == o {
+			return s
+		}
+	}
+	var h uint32
+	for i := 0; i < len(s); i++ {
+		h = h*31 + uint32(s[i])
+	}
+	return options[h%uint32(len(options))]
+}
+`,
+	"apidocPickInt": `// apidocPickInt maps n onto one of the allowed values.
+func apidocPickInt(n int64, options []int64) int64 {
+	i := n % int64(len(options))
+	if i < 0 {
+		i = -i
+	}
+	return options[i]
+}
+`,
+	"apidocRange": `// apidocRange maps n into [lo, hi].
+func apidocRange(n, lo, hi int64) int64 {
+	if n >= lo && n <= hi {
+		return n
+	}
+	span := uint64(hi-lo) + 1
+	if span == 0 {
+		return n
+	}
+	return lo + int64(uint64(n)%span)
+}
+`,
+	"apidocTruncate": `// apidocTruncate shortens s to at most n runes.
+func apidocTruncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+`,
+	"apidocPad": `// apidocPad lengthens s to at least n runes.
+func apidocPad(s string, n int) string {
+	if c := utf8.RuneCountInString(s); c < n {
+		s += strings.Repeat("a", n-c)
+	}
+	return s
+}
+`,
+}
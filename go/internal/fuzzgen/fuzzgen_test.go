@@ -0,0 +1,105 @@
+package fuzzgen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func TestGenerate(t *testing.T) {
+	doc, err := analyzer.Analyze("testdata/netapp", analyzer.Options{Recursive: true})
+	require.NoError(t, err)
+	require.Len(t, doc.Routers, 1)
+	assert.Equal(t, "NewRouter", doc.Routers[0].Func)
+	assert.Equal(t, 3, doc.Routers[0].Endpoints)
+
+	out, err := Generate(doc, Options{Package: "server", Router: "NewRouter()", Headers: map[string]string{"Authorization": "Bearer test"}})
+	require.NoError(t, err)
+	src := string(out)
+
+	assert.Contains(t, src, "func FuzzListItems(f *testing.F) {")
+	assert.Contains(t, src, "func FuzzGetItem(f *testing.F) {")
+	assert.Contains(t, src, `target := "/items/" + url.PathEscape(strconv.FormatInt(id, 10))`)
+	assert.Contains(t, src, "f.Fuzz(func(t *testing.T, bodyId int64, bodyName string, bodyKind string, bodyPrice float64) {")
+	assert.Contains(t, src, `f.Add(int64(1), "widget", "tool", float64(1.0))`)
+	assert.Contains(t, src, `bodyKind = apidocPick(bodyKind, []string{"tool", "toy"})`)
+	assert.Contains(t, src, "bodyName = apidocTruncate(bodyName, 40)")
+	assert.Contains(t, src, `"Authorization": "Bearer test",`)
+	assert.NotContains(t, src, `"regexp"`)
+
+	if testing.Short() {
+		t.Skip("skipping go test of generated fuzz tests in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	copyDir(t, "testdata/netapp", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server", "apidoc_fuzz_test.go"), out, 0o644))
+	cmd := exec.Command(goTool, "test", "-run", "Fuzz", "./server")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOWORK=off")
+	result, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(result))
+}
+
+func TestGenerateConstraints(t *testing.T) {
+	min, max := 1.0, 5.0
+	minLen := 3
+	doc := &model.Document{
+		Endpoints: []*model.Endpoint{{
+			Method: "PUT", Path: "/orders/{code}", OperationID: "updateOrder",
+			Parameters: []*model.Parameter{
+				{Name: "code", In: "path", Required: true, Schema: &model.Schema{Type: "string", Pattern: "^[A-Z]{3}$"}},
+				{Name: "page", In: "query", Schema: &model.Schema{Type: "integer", Minimum: &min, Maximum: &max}},
+				{Name: "X-Request-ID", In: "header", Schema: &model.Schema{Type: "string", Format: "uuid", MinLength: &minLen}},
+				{Name: "session", In: "cookie", Required: true, Schema: &model.Schema{Type: "string"}, Example: "abc"},
+			},
+			RequestBody: &model.RequestBody{ContentType: "text/plain", Example: "hello"},
+		}},
+	}
+	out, err := Generate(doc, Options{Package: "orders_test", Router: "orders.Handler()"})
+	require.NoError(t, err)
+	src := string(out)
+
+	assert.Contains(t, src, "codePattern := regexp.MustCompile(`^[A-Z]{3}$`)")
+	assert.Contains(t, src, "if !codePattern.MatchString(code) {")
+	assert.Contains(t, src, "page = apidocRange(page, 1, 5)")
+	assert.Contains(t, src, `req.Header.Set("X-Request-ID", apidocHeader(xRequestID))`)
+	assert.Contains(t, src, `req.AddCookie(&http.Cookie{Name: "session", Value: session})`)
+	assert.Contains(t, src, "f.Add(\"1\", int64(1), []byte(`hello`), \"3fa85f64-5717-4562-b3fc-2c963f66afa6\", \"abc\")")
+	assert.Contains(t, src, "h := orders.Handler()")
+	assert.Equal(t, 1, strings.Count(src, "func FuzzUpdateOrder("))
+
+	_, err = Generate(doc, Options{Package: "orders_test"})
+	assert.Error(t, err)
+}
+
+func copyDir(t *testing.T, src, dst string) {
+	t.Helper()
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+	require.NoError(t, err)
+}
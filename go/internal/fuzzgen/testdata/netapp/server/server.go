@@ -0,0 +1,105 @@
+// Package server serves an item catalogue.
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Item is a catalogue entry.
+type Item struct {
+	ID    int      `json:"id" example:"1"`
+	Name  string   `json:"name" validate:"required,min=1,max=40" example:"widget"`
+	Kind  string   `json:"kind" validate:"oneof=tool toy"`
+	Price float64  `json:"price" validate:"min=0"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type store struct {
+	mu    sync.Mutex
+	items map[int]Item
+}
+
+// NewRouter returns the HTTP handler serving the API.
+func NewRouter() http.Handler {
+	s := &store{items: map[int]Item{1: {ID: 1, Name: "widget", Kind: "tool"}}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items", s.listItems)
+	mux.HandleFunc("POST /items", s.createItem)
+	mux.HandleFunc("GET /items/{id}", s.getItem)
+	return logRequests(mux)
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listItems lists catalogue items.
+//
+// Responses:
+//   - 200: []Item
+func (s *store) listItems(w http.ResponseWriter, r *http.Request) {
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]Item, 0, limit)
+	for _, it := range s.items {
+		if len(items) == limit {
+			break
+		}
+		items = append(items, it)
+	}
+	json.NewEncoder(w).Encode(items)
+}
+
+// createItem adds an item to the catalogue.
+//
+// Responses:
+//   - 201: Item
+//   - 400: invalid item
+func (s *store) createItem(w http.ResponseWriter, r *http.Request) {
+	var it Item
+	if err := json.NewDecoder(r.Body).Decode(&it); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	it.ID = len(s.items) + 1
+	s.items[it.ID] = it
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(it)
+}
+
+// getItem returns one item.
+//
+// Responses:
+//   - 200: Item
+//   - 404: item not found
+func (s *store) getItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	it, ok := s.items[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(it)
+}
@@ -0,0 +1,313 @@
+package gateway
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Envoy filter names and type URLs.
+const (
+	envoyRouter         = "envoy.filters.http.router"
+	envoyLocalRateLimit = "envoy.filters.http.local_ratelimit"
+	envoyJWT            = "envoy.filters.http.jwt_authn"
+	envoyBasicAuth      = "envoy.filters.http.basic_auth"
+	envoyAPIKeyAuth     = "envoy.filters.http.api_key_auth"
+
+	typePrefix = "type.googleapis.com/"
+)
+
+// envoyFilterConfig is per-route filter configuration keyed by filter name.
+type envoyFilterConfig map[string]map[string]any
+
+type envoyRoute struct {
+	Name                 string            `yaml:"name"`
+	Match                map[string]any    `yaml:"match"`
+	Route                map[string]any    `yaml:"route"`
+	TypedPerFilterConfig envoyFilterConfig `yaml:"typed_per_filter_config,omitempty"`
+}
+
+// envoy renders a static bootstrap configuration with one listener, one
+// virtual host and one cluster for the upstream service. Authentication
+// filters are installed once and enabled per route; rate limits use the
+// local rate limit filter configured per route.
+func envoy(doc *model.Document, opts Options) ([]byte, error) {
+	upstream, err := url.Parse(opts.Upstream)
+	if err != nil || upstream.Hostname() == "" {
+		return nil, fmt.Errorf("invalid upstream URL %q", opts.Upstream)
+	}
+	port := 80
+	if upstream.Scheme == "https" {
+		port = 443
+	}
+	if p := upstream.Port(); p != "" {
+		if port, err = strconv.Atoi(p); err != nil {
+			return nil, fmt.Errorf("invalid upstream URL %q", opts.Upstream)
+		}
+	}
+
+	used := schemes(doc)
+	var envoyRoutes []envoyRoute
+	for _, r := range routes(doc) {
+		er := envoyRoute{
+			Name:  r.name(),
+			Match: envoyMatch(r),
+			Route: map[string]any{"cluster": opts.Service},
+		}
+		if rl := r.rateLimit(); rl != nil {
+			er.perFilter(envoyLocalRateLimit, envoyRateLimit(r.name(), rl))
+		}
+		er.authConfig(doc, used, r.security())
+		envoyRoutes = append(envoyRoutes, er)
+	}
+
+	filters := []map[string]any{}
+	if hasRateLimit(doc) {
+		filters = append(filters, typed(envoyLocalRateLimit, "envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit", map[string]any{
+			"stat_prefix": "http_local_rate_limiter",
+		}))
+	}
+	filters = append(filters, envoyAuthFilters(doc, used)...)
+	filters = append(filters, typed(envoyRouter, "envoy.extensions.filters.http.router.v3.Router", nil))
+
+	clusters := []any{envoyCluster(opts.Service, upstream, port)}
+	if usesJWT(doc, used) {
+		jwks, _ := url.Parse("https://TODO")
+		clusters = append(clusters, envoyCluster("jwks", jwks, 443))
+	}
+
+	config := map[string]any{
+		"static_resources": map[string]any{
+			"listeners": []any{map[string]any{
+				"name": "listener_http",
+				"address": map[string]any{"socket_address": map[string]any{
+					"address": "0.0.0.0", "port_value": opts.Port,
+				}},
+				"filter_chains": []any{map[string]any{
+					"filters": []any{typed("envoy.filters.network.http_connection_manager",
+						"envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager",
+						map[string]any{
+							"stat_prefix": "ingress_http",
+							"route_config": map[string]any{
+								"name": opts.Service,
+								"virtual_hosts": []any{map[string]any{
+									"name":    opts.Service,
+									"domains": []string{"*"},
+									"routes":  envoyRoutes,
+								}},
+							},
+							"http_filters": filters,
+						})},
+				}},
+			}},
+			"clusters": clusters,
+		},
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(config); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// typed returns a named filter with a typed_config of the given type.
+func typed(name, typeName string, config map[string]any) map[string]any {
+	tc := map[string]any{"@type": typePrefix + typeName}
+	for k, v := range config {
+		tc[k] = v
+	}
+	return map[string]any{"name": name, "typed_config": tc}
+}
+
+func (er *envoyRoute) perFilter(filter string, config map[string]any) {
+	if er.TypedPerFilterConfig == nil {
+		er.TypedPerFilterConfig = envoyFilterConfig{}
+	}
+	er.TypedPerFilterConfig[filter] = config
+}
+
+// envoyMatch matches a route's path exactly, or by regular expression when
+// it has parameters, and its methods through the :method pseudo-header.
+func envoyMatch(r *route) map[string]any {
+	m := map[string]any{}
+	if model.PathParams(r.path) == nil {
+		m["path"] = r.path
+	} else {
+		m["safe_regex"] = map[string]any{"regex": pathRegex(r.path, false)}
+	}
+	method := map[string]any{"name": ":method"}
+	if len(r.methods) == 1 {
+		method["string_match"] = map[string]any{"exact": r.methods[0]}
+	} else {
+		method["string_match"] = map[string]any{"safe_regex": map[string]any{"regex": "^(" + strings.Join(r.methods, "|") + ")$"}}
+	}
+	m["headers"] = []any{method}
+	return m
+}
+
+func envoyRateLimit(name string, rl *model.RateLimit) map[string]any {
+	return map[string]any{
+		"@type":       typePrefix + "envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit",
+		"stat_prefix": name,
+		"token_bucket": map[string]any{
+			"max_tokens":      rl.Requests,
+			"tokens_per_fill": rl.Requests,
+			"fill_interval":   strconv.Itoa(periodSeconds(rl.Period)) + "s",
+		},
+		"filter_enabled":  fullPercent("local_rate_limit_enabled"),
+		"filter_enforced": fullPercent("local_rate_limit_enforced"),
+	}
+}
+
+func fullPercent(key string) map[string]any {
+	return map[string]any{
+		"runtime_key":   key,
+		"default_value": map[string]any{"numerator": 100, "denominator": "HUNDRED"},
+	}
+}
+
+func hasRateLimit(doc *model.Document) bool {
+	for _, ep := range doc.Endpoints {
+		if ep.RateLimit != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// envoyAuthFilters installs one filter per kind of scheme in use. JWT
+// providers are selected per route by requirement name; basic and API key
+// authentication are disabled on routes that do not require them.
+func envoyAuthFilters(doc *model.Document, used []string) []map[string]any {
+	var filters []map[string]any
+	providers := map[string]any{}
+	requirements := map[string]any{}
+	var sources []any
+	for _, name := range used {
+		s := doc.SecuritySchemes[name]
+		switch {
+		case isJWT(s):
+			providers[name] = map[string]any{
+				"issuer":      "TODO",
+				"forward":     true,
+				"remote_jwks": map[string]any{"http_uri": map[string]any{"uri": "TODO", "cluster": "jwks", "timeout": "5s"}},
+			}
+			requirements[name] = map[string]any{"provider_name": name}
+		case s.Type == model.SecurityHTTP && s.Scheme == "basic":
+			filters = append(filters, typed(envoyBasicAuth, "envoy.extensions.filters.http.basic_auth.v3.BasicAuth", map[string]any{
+				"users": map[string]any{"inline_string": "TODO:{SHA}TODO"},
+			}))
+		case s.Type == model.SecurityAPIKey:
+			in := s.In
+			if in == "" {
+				in = "header"
+			}
+			sources = append(sources, map[string]any{in: s.Name})
+		}
+	}
+	if len(sources) > 0 {
+		filters = append(filters, typed(envoyAPIKeyAuth, "envoy.extensions.filters.http.api_key_auth.v3.ApiKeyAuth", map[string]any{
+			"credentials": []any{map[string]any{"key": "TODO", "client": "TODO"}},
+			"key_sources": sources,
+		}))
+	}
+	if len(providers) > 0 {
+		jwt := typed(envoyJWT, "envoy.extensions.filters.http.jwt_authn.v3.JwtAuthentication", map[string]any{
+			"providers":       providers,
+			"requirement_map": requirements,
+		})
+		filters = append([]map[string]any{jwt}, filters...)
+	}
+	return filters
+}
+
+func usesJWT(doc *model.Document, used []string) bool {
+	for _, name := range used {
+		if isJWT(doc.SecuritySchemes[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+// authConfig enables the authentication filters a route requires and
+// disables the others.
+func (er *envoyRoute) authConfig(doc *model.Document, used, security []string) {
+	required := map[string]bool{}
+	for _, name := range security {
+		required[name] = true
+	}
+	jwtRequirement := ""
+	hasJWT, hasAPIKey, needsAPIKey := false, false, false
+	for _, name := range used {
+		s := doc.SecuritySchemes[name]
+		switch {
+		case isJWT(s):
+			hasJWT = true
+			if required[name] && jwtRequirement == "" {
+				jwtRequirement = name
+			}
+		case s.Type == model.SecurityHTTP && s.Scheme == "basic":
+			if !required[name] {
+				er.perFilter(envoyBasicAuth, disabled())
+			}
+		case s.Type == model.SecurityAPIKey:
+			hasAPIKey = true
+			needsAPIKey = needsAPIKey || required[name]
+		}
+	}
+	if hasAPIKey && !needsAPIKey {
+		er.perFilter(envoyAPIKeyAuth, disabled())
+	}
+	if !hasJWT {
+		return
+	}
+	perRoute := map[string]any{"@type": typePrefix + "envoy.extensions.filters.http.jwt_authn.v3.PerRouteConfig"}
+	if jwtRequirement != "" {
+		perRoute["requirement_name"] = jwtRequirement
+	} else {
+		perRoute["disabled"] = true
+	}
+	er.perFilter(envoyJWT, perRoute)
+}
+
+func disabled() map[string]any {
+	return map[string]any{"@type": typePrefix + "envoy.config.route.v3.FilterConfig", "disabled": true}
+}
+
+func envoyCluster(name string, upstream *url.URL, port int) map[string]any {
+	cluster := map[string]any{
+		"name":            name,
+		"type":            "STRICT_DNS",
+		"connect_timeout": "5s",
+		"lb_policy":       "ROUND_ROBIN",
+		"load_assignment": map[string]any{
+			"cluster_name": name,
+			"endpoints": []any{map[string]any{
+				"lb_endpoints": []any{map[string]any{
+					"endpoint": map[string]any{"address": map[string]any{"socket_address": map[string]any{
+						"address": upstream.Hostname(), "port_value": port,
+					}}},
+				}},
+			}},
+		},
+	}
+	if upstream.Scheme == "https" {
+		cluster["transport_socket"] = typed("envoy.transport_sockets.tls", "envoy.extensions.transport_sockets.tls.v3.UpstreamTlsContext", map[string]any{
+			"sni": upstream.Hostname(),
+		})
+	}
+	return cluster
+}
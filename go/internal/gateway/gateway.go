@@ -0,0 +1,213 @@
+// Package gateway generates API gateway configuration from the endpoints
+// extracted by the analyzer.
+//
+// Each target converts route templates to the gateway's path syntax, lists
+// the methods of every route, turns detected rate limiting middleware into
+// the gateway's rate limit settings and emits authentication plugin stubs
+// for the security schemes an endpoint requires. Stubs carry placeholder
+// values, marked with "TODO", that must be filled in before deployment.
+package gateway
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Supported targets.
+const (
+	TargetKong    = "kong"
+	TargetEnvoy   = "envoy"
+	TargetKrakenD = "krakend"
+)
+
+// Targets lists the supported targets.
+var Targets = []string{TargetKong, TargetEnvoy, TargetKrakenD}
+
+// Options configures the generated configuration.
+type Options struct {
+	// Service names the upstream service. It defaults to the binary that
+	// serves the API.
+	Service string
+	// Upstream is the base URL of the service. It defaults to
+	// http://<service>:8080.
+	Upstream string
+	// Port is the port the gateway listens on; it defaults to 8000.
+	Port int
+}
+
+// Generate renders the configuration for target.
+func Generate(doc *model.Document, target string, opts Options) ([]byte, error) {
+	opts = opts.withDefaults(doc)
+	switch target {
+	case TargetKong:
+		return kong(doc, opts)
+	case TargetEnvoy:
+		return envoy(doc, opts)
+	case TargetKrakenD:
+		return krakend(doc, opts)
+	}
+	return nil, fmt.Errorf("unknown gateway target %q (want one of %s)", target, strings.Join(Targets, ", "))
+}
+
+func (o Options) withDefaults(doc *model.Document) Options {
+	if o.Service == "" {
+		o.Service = doc.Service
+	}
+	if o.Service == "" {
+		o.Service = "api"
+	}
+	o.Service = slug(o.Service)
+	if o.Upstream == "" {
+		o.Upstream = "http://" + o.Service + ":8080"
+	}
+	o.Upstream = strings.TrimSuffix(o.Upstream, "/")
+	if o.Port == 0 {
+		o.Port = 8000
+	}
+	return o
+}
+
+// route is the endpoints sharing one path, which gateways configure as a
+// single route with several methods when their settings agree.
+type route struct {
+	path      string
+	methods   []string
+	endpoints []*model.Endpoint
+}
+
+// routes groups endpoints by path and by identical gateway settings, in
+// document order.
+func routes(doc *model.Document) []*route {
+	var out []*route
+	index := map[string]*route{}
+	for _, ep := range doc.Endpoints {
+		key := ep.Path + "|" + settingsKey(ep)
+		r := index[key]
+		if r == nil {
+			r = &route{path: ep.Path}
+			index[key] = r
+			out = append(out, r)
+		}
+		r.methods = append(r.methods, ep.Method)
+		r.endpoints = append(r.endpoints, ep)
+	}
+	return out
+}
+
+func settingsKey(ep *model.Endpoint) string {
+	key := strings.Join(ep.Security, ",")
+	if rl := ep.RateLimit; rl != nil {
+		key += fmt.Sprintf("|%d/%s", rl.Requests, rl.Period)
+	}
+	return key
+}
+
+// name identifies a route in the generated configuration, e.g.
+// "get-post-api-v1-users".
+func (r *route) name() string {
+	return slug(strings.ToLower(strings.Join(r.methods, "-")) + "-" + r.path)
+}
+
+func (r *route) security() []string {
+	return r.endpoints[0].Security
+}
+
+func (r *route) rateLimit() *model.RateLimit {
+	return r.endpoints[0].RateLimit
+}
+
+// params returns the names of the route parameters of kind in, sorted.
+func (r *route) params(in string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, ep := range r.endpoints {
+		for _, p := range ep.Parameters {
+			if p.In == in && !seen[p.Name] {
+				seen[p.Name] = true
+				names = append(names, p.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+var nonSlug = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+func slug(s string) string {
+	s = strings.Trim(nonSlug.ReplaceAllString(s, "-"), "-")
+	if s == "" {
+		return "root"
+	}
+	return s
+}
+
+// pathRegex converts a route template to an anchored regular expression
+// whose path parameters are named capture groups, using syntax accepted by
+// both PCRE and RE2.
+func pathRegex(p string, named bool) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for len(p) > 0 {
+		open := strings.IndexByte(p, '{')
+		end := strings.IndexByte(p, '}')
+		if open < 0 || end < open {
+			b.WriteString(regexp.QuoteMeta(p))
+			break
+		}
+		b.WriteString(regexp.QuoteMeta(p[:open]))
+		param := p[open+1 : end]
+		if named {
+			b.WriteString("(?P<" + slug(param) + ">" + paramPattern + ")")
+		} else {
+			b.WriteString(paramPattern)
+		}
+		p = p[end+1:]
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// paramPattern matches one path segment.
+const paramPattern = "[^/]+"
+
+// periodSeconds returns the length of a rate limit period in seconds.
+func periodSeconds(period string) int {
+	switch period {
+	case model.PeriodMinute:
+		return 60
+	case model.PeriodHour:
+		return 3600
+	case model.PeriodDay:
+		return 86400
+	}
+	return 1
+}
+
+// schemes returns the security schemes used by any endpoint, sorted by
+// name.
+func schemes(doc *model.Document) []string {
+	used := map[string]bool{}
+	for _, ep := range doc.Endpoints {
+		for _, s := range ep.Security {
+			if doc.SecuritySchemes[s] != nil {
+				used[s] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isJWT reports whether a scheme is validated as a JSON Web Token.
+func isJWT(s *model.SecurityScheme) bool {
+	return s.Type == model.SecurityOAuth2 || s.Type == model.SecurityHTTP && s.Scheme == "bearer"
+}
@@ -0,0 +1,120 @@
+package gateway
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func testDocument() *model.Document {
+	limit := &model.RateLimit{Requests: 100, Period: model.PeriodMinute}
+	return &model.Document{
+		Service: "usersvc",
+		Endpoints: []*model.Endpoint{
+			{Method: "GET", Path: "/api/v1/users", Security: []string{"bearerAuth"}, RateLimit: limit,
+				Parameters: []*model.Parameter{{Name: "limit", In: "query"}}},
+			{Method: "POST", Path: "/api/v1/users", Security: []string{"bearerAuth"}, RateLimit: limit},
+			{Method: "GET", Path: "/api/v1/users/{id}", Security: []string{"bearerAuth"}, RateLimit: limit},
+			{Method: "DELETE", Path: "/api/v1/users/{id}", Security: []string{"apiKeyAuth"}},
+			{Method: "GET", Path: "/healthz"},
+		},
+		SecuritySchemes: map[string]*model.SecurityScheme{
+			"bearerAuth": {Type: model.SecurityHTTP, Scheme: "bearer"},
+			"apiKeyAuth": {Type: model.SecurityAPIKey, In: "header", Name: "X-API-Key"},
+		},
+	}
+}
+
+func TestKong(t *testing.T) {
+	out, err := Generate(testDocument(), TargetKong, Options{})
+	require.NoError(t, err)
+
+	var config kongConfig
+	require.NoError(t, yaml.Unmarshal(out, &config))
+	assert.Equal(t, "3.0", config.FormatVersion)
+	require.Len(t, config.Services, 1)
+	svc := config.Services[0]
+	assert.Equal(t, "usersvc", svc.Name)
+	assert.Equal(t, "http://usersvc:8080", svc.URL)
+	require.Len(t, svc.Routes, 4)
+
+	users := svc.Routes[0]
+	assert.Equal(t, []string{"GET", "POST"}, users.Methods)
+	assert.Equal(t, []string{"~/api/v1/users$"}, users.Paths)
+	require.Len(t, users.Plugins, 2)
+	assert.Equal(t, "rate-limiting", users.Plugins[0].Name)
+	assert.Equal(t, 100, users.Plugins[0].Config["minute"])
+	assert.Equal(t, "jwt", users.Plugins[1].Name)
+
+	get := svc.Routes[1]
+	assert.Equal(t, []string{"~/api/v1/users/(?<id>[^/]+)$"}, get.Paths)
+
+	del := svc.Routes[2]
+	assert.Equal(t, []string{"DELETE"}, del.Methods)
+	require.Len(t, del.Plugins, 1)
+	assert.Equal(t, "key-auth", del.Plugins[0].Name)
+
+	assert.Empty(t, svc.Routes[3].Plugins)
+}
+
+func TestEnvoy(t *testing.T) {
+	out, err := Generate(testDocument(), TargetEnvoy, Options{Upstream: "https://users.internal"})
+	require.NoError(t, err)
+
+	var config struct {
+		StaticResources struct {
+			Clusters []struct {
+				Name string `yaml:"name"`
+			} `yaml:"clusters"`
+		} `yaml:"static_resources"`
+	}
+	require.NoError(t, yaml.Unmarshal(out, &config))
+	require.Len(t, config.StaticResources.Clusters, 2)
+	assert.Equal(t, "usersvc", config.StaticResources.Clusters[0].Name)
+	assert.Equal(t, "jwks", config.StaticResources.Clusters[1].Name)
+
+	s := string(out)
+	assert.Contains(t, s, "regex: ^/api/v1/users/[^/]+$")
+	assert.Contains(t, s, "path: /healthz")
+	assert.Contains(t, s, "regex: ^(GET|POST)$")
+	assert.Contains(t, s, "fill_interval: 60s")
+	assert.Contains(t, s, "requirement_name: bearerAuth")
+	assert.Contains(t, s, "port_value: 443")
+}
+
+func TestKrakenD(t *testing.T) {
+	out, err := Generate(testDocument(), TargetKrakenD, Options{Service: "users", Port: 8080})
+	require.NoError(t, err)
+
+	var config krakendConfig
+	require.NoError(t, json.Unmarshal(out, &config))
+	assert.Equal(t, 3, config.Version)
+	assert.Equal(t, 8080, config.Port)
+	require.Len(t, config.Endpoints, 5)
+
+	list := config.Endpoints[0]
+	assert.Equal(t, "/api/v1/users", list.Endpoint)
+	assert.Equal(t, []string{"limit"}, list.InputQueryStrings)
+	assert.Equal(t, []string{"Authorization"}, list.InputHeaders)
+	assert.Equal(t, []string{"http://users:8080"}, list.Backend[0].Host)
+	assert.Contains(t, list.ExtraConfig, "qos/ratelimit/router")
+	assert.Contains(t, list.ExtraConfig, "auth/validator")
+
+	get := config.Endpoints[2]
+	assert.Equal(t, "/api/v1/users/{id}", get.Endpoint)
+	assert.Equal(t, "/api/v1/users/{id}", get.Backend[0].URLPattern)
+
+	del := config.Endpoints[3]
+	assert.Equal(t, []string{"X-API-Key"}, del.InputHeaders)
+	assert.Contains(t, del.ExtraConfig, "auth/api-keys")
+}
+
+func TestUnknownTarget(t *testing.T) {
+	_, err := Generate(testDocument(), "nginx", Options{})
+	assert.EqualError(t, err, `unknown gateway target "nginx" (want one of kong, envoy, krakend)`)
+}
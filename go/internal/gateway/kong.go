@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Kong declarative configuration, as loaded by `kong config db_import` and
+// decK.
+type kongConfig struct {
+	FormatVersion string        `yaml:"_format_version"`
+	Services      []kongService `yaml:"services"`
+}
+
+type kongService struct {
+	Name    string       `yaml:"name"`
+	URL     string       `yaml:"url"`
+	Routes  []kongRoute  `yaml:"routes"`
+	Plugins []kongPlugin `yaml:"plugins,omitempty"`
+}
+
+type kongRoute struct {
+	Name      string       `yaml:"name"`
+	Methods   []string     `yaml:"methods"`
+	Paths     []string     `yaml:"paths"`
+	StripPath bool         `yaml:"strip_path"`
+	Tags      []string     `yaml:"tags,omitempty"`
+	Plugins   []kongPlugin `yaml:"plugins,omitempty"`
+}
+
+type kongPlugin struct {
+	Name   string         `yaml:"name"`
+	Config map[string]any `yaml:"config,omitempty"`
+}
+
+func kong(doc *model.Document, opts Options) ([]byte, error) {
+	svc := kongService{Name: opts.Service, URL: opts.Upstream}
+	for _, r := range routes(doc) {
+		kr := kongRoute{
+			Name:    r.name(),
+			Methods: r.methods,
+			Paths:   []string{kongPath(r.path)},
+			Tags:    r.endpoints[0].Tags,
+		}
+		if rl := r.rateLimit(); rl != nil {
+			kr.Plugins = append(kr.Plugins, kongPlugin{
+				Name:   "rate-limiting",
+				Config: map[string]any{rl.Period: rl.Requests, "policy": "local"},
+			})
+		}
+		for _, name := range r.security() {
+			if s := doc.SecuritySchemes[name]; s != nil {
+				kr.Plugins = append(kr.Plugins, kongAuthPlugin(s))
+			}
+		}
+		svc.Routes = append(svc.Routes, kr)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(kongConfig{FormatVersion: "3.0", Services: []kongService{svc}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// kongPath converts a route template to a Kong path. Templates with
+// parameters become regex paths, prefixed with "~", with named captures;
+// static paths are anchored so that they do not match as prefixes.
+func kongPath(p string) string {
+	if !strings.Contains(p, "{") {
+		return "~" + pathRegex(p, false)[1:]
+	}
+	return "~" + strings.ReplaceAll(pathRegex(p, true)[1:], "(?P<", "(?<")
+}
+
+// kongAuthPlugin returns the Kong plugin enforcing a security scheme.
+func kongAuthPlugin(s *model.SecurityScheme) kongPlugin {
+	switch {
+	case s.Type == model.SecurityHTTP && s.Scheme == "basic":
+		return kongPlugin{Name: "basic-auth", Config: map[string]any{"hide_credentials": true}}
+	case s.Type == model.SecurityAPIKey:
+		cfg := map[string]any{"key_names": []string{s.Name}, "hide_credentials": true}
+		if s.In != "header" {
+			cfg["key_in_header"] = false
+			cfg["key_in_body"] = false
+		}
+		return kongPlugin{Name: "key-auth", Config: cfg}
+	case s.Type == model.SecurityOAuth2:
+		return kongPlugin{Name: "oauth2", Config: map[string]any{
+			"scopes":                    []string{},
+			"enable_client_credentials": true,
+			"provision_key":             "TODO",
+		}}
+	}
+	cfg := map[string]any{"header_names": []string{"authorization"}, "claims_to_verify": []string{"exp"}}
+	return kongPlugin{Name: "jwt", Config: cfg}
+}
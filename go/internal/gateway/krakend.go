@@ -0,0 +1,143 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// KrakenD configuration file, version 3.
+type krakendConfig struct {
+	Schema    string            `json:"$schema"`
+	Version   int               `json:"version"`
+	Name      string            `json:"name"`
+	Port      int               `json:"port"`
+	Endpoints []krakendEndpoint `json:"endpoints"`
+}
+
+type krakendEndpoint struct {
+	Endpoint          string           `json:"endpoint"`
+	Method            string           `json:"method"`
+	OutputEncoding    string           `json:"output_encoding"`
+	InputQueryStrings []string         `json:"input_query_strings,omitempty"`
+	InputHeaders      []string         `json:"input_headers,omitempty"`
+	Backend           []krakendBackend `json:"backend"`
+	ExtraConfig       map[string]any   `json:"extra_config,omitempty"`
+}
+
+type krakendBackend struct {
+	URLPattern string   `json:"url_pattern"`
+	Method     string   `json:"method"`
+	Host       []string `json:"host"`
+	Encoding   string   `json:"encoding"`
+}
+
+// krakend renders one KrakenD endpoint per method and path; KrakenD routes
+// a single method per endpoint. Responses are proxied unchanged with the
+// no-op encoding so that status codes and headers reach the client.
+func krakend(doc *model.Document, opts Options) ([]byte, error) {
+	config := krakendConfig{
+		Schema:    "https://www.krakend.io/schema/v2.7/krakend.json",
+		Version:   3,
+		Name:      opts.Service,
+		Port:      opts.Port,
+		Endpoints: []krakendEndpoint{},
+	}
+	for _, r := range routes(doc) {
+		for i, method := range r.methods {
+			ep := r.endpoints[i]
+			ke := krakendEndpoint{
+				Endpoint:          r.path,
+				Method:            method,
+				OutputEncoding:    "no-op",
+				InputQueryStrings: r.params("query"),
+				InputHeaders:      r.params("header"),
+				Backend: []krakendBackend{{
+					URLPattern: r.path,
+					Method:     method,
+					Host:       []string{opts.Upstream},
+					Encoding:   "no-op",
+				}},
+			}
+			extra := map[string]any{}
+			if rl := ep.RateLimit; rl != nil {
+				extra["qos/ratelimit/router"] = map[string]any{
+					"max_rate": rl.Requests,
+					"every":    krakendPeriod(rl.Period),
+				}
+			}
+			for _, name := range ep.Security {
+				s := doc.SecuritySchemes[name]
+				if s == nil {
+					continue
+				}
+				krakendAuth(extra, s)
+				if s.Type == model.SecurityAPIKey && s.In == "header" || s.Type == model.SecurityHTTP {
+					header := "Authorization"
+					if s.Type == model.SecurityAPIKey {
+						header = s.Name
+					}
+					known := false
+					for _, h := range ke.InputHeaders {
+						known = known || h == header
+					}
+					if !known {
+						ke.InputHeaders = append(ke.InputHeaders, header)
+					}
+				}
+			}
+			if len(extra) > 0 {
+				ke.ExtraConfig = extra
+			}
+			config.Endpoints = append(config.Endpoints, ke)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(config); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func krakendPeriod(period string) string {
+	switch period {
+	case model.PeriodMinute:
+		return "1m"
+	case model.PeriodHour:
+		return "1h"
+	case model.PeriodDay:
+		return "24h"
+	}
+	return "1s"
+}
+
+// krakendAuth adds the component enforcing a security scheme. Basic and
+// API key authentication are KrakenD Enterprise components.
+func krakendAuth(extra map[string]any, s *model.SecurityScheme) {
+	switch {
+	case isJWT(s):
+		extra["auth/validator"] = map[string]any{
+			"alg":                  "RS256",
+			"jwk_url":              "TODO",
+			"cache":                true,
+			"operation_debug":      false,
+			"disable_jwk_security": false,
+		}
+	case s.Type == model.SecurityHTTP && s.Scheme == "basic":
+		extra["auth/basic"] = map[string]any{"htpasswd_path": "TODO"}
+	case s.Type == model.SecurityAPIKey:
+		strategy := "header"
+		if s.In == "query" {
+			strategy = "query_string"
+		}
+		extra["auth/api-keys"] = map[string]any{
+			"strategy":   strategy,
+			"identifier": s.Name,
+		}
+	}
+}
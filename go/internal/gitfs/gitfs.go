@@ -0,0 +1,283 @@
+// Package gitfs reads the files of a git revision straight from the object
+// database, so that the sources of any commit can be analyzed without
+// touching the working tree.
+//
+// The tree of the revision is listed once when it is opened. File contents
+// are read on first use through a single long-running "git cat-file --batch"
+// process and kept in memory.
+package gitfs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is the tree of a commit, addressed by the operating system paths the
+// files have in the working tree of the repository.
+type FS struct {
+	// Commit is the full hash of the commit.
+	Commit string
+	// Top is the top-level directory of the working tree.
+	Top string
+
+	files map[string]*blob
+	dirs  map[string][]fs.DirEntry
+
+	mu    sync.Mutex
+	batch *exec.Cmd
+	in    io.WriteCloser
+	out   *bufio.Reader
+}
+
+type blob struct {
+	hash string
+	info *info
+	data []byte
+}
+
+// Open opens the revision rev, a commit, tag or any other name git
+// resolves to a commit, of the repository containing dir. dir need not
+// exist in the working tree.
+func Open(dir, rev string) (*FS, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	base := abs
+	for {
+		if info, err := os.Stat(base); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(base)
+		if parent == base {
+			return nil, fmt.Errorf("%s: no such directory", dir)
+		}
+		base = parent
+	}
+	// The top-level directory is derived from the prefix of base rather
+	// than asked of git, which resolves symbolic links.
+	prefix, err := git(base, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	top := base
+	for _, elem := range strings.Split(strings.TrimSuffix(strings.TrimSpace(string(prefix)), "/"), "/") {
+		if elem != "" {
+			top = filepath.Dir(top)
+		}
+	}
+	commit, err := git(base, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("revision %s: %w", rev, err)
+	}
+	f := &FS{
+		Commit: strings.TrimSpace(string(commit)),
+		Top:    top,
+		files:  map[string]*blob{},
+		dirs:   map[string][]fs.DirEntry{".": nil},
+	}
+	tree, err := git(base, "ls-tree", "-r", "-l", "-z", "--full-tree", f.Commit)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.index(tree); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// index records the entries of "git ls-tree -r -l -z" output. Symbolic
+// links and submodules are left out.
+func (f *FS) index(tree []byte) error {
+	seen := map[string]bool{".": true}
+	for _, rec := range bytes.Split(tree, []byte{0}) {
+		if len(rec) == 0 {
+			continue
+		}
+		meta, name, ok := strings.Cut(string(rec), "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			return fmt.Errorf("unexpected git ls-tree output %q", rec)
+		}
+		if fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected git ls-tree output %q", rec)
+		}
+		mode := fs.FileMode(0o644)
+		if fields[0] == "100755" {
+			mode = 0o755
+		}
+		b := &blob{hash: fields[2], info: &info{name: path.Base(name), size: size, mode: mode}}
+		f.files[name] = b
+		f.add(path.Dir(name), b.info)
+		for dir := path.Dir(name); !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			f.add(path.Dir(dir), &info{name: path.Base(dir), mode: fs.ModeDir | 0o755})
+		}
+	}
+	for _, entries := range f.dirs {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	}
+	return nil
+}
+
+func (f *FS) add(dir string, fi *info) {
+	f.dirs[dir] = append(f.dirs[dir], fs.FileInfoToDirEntry(fi))
+}
+
+// rel converts an operating system path to a slash-separated path in the
+// tree, or returns false when name lies outside the working tree.
+func (f *FS) rel(name string) (string, bool) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(f.Top, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Stat returns the file info of name in the tree.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	rel, ok := f.rel(name)
+	if ok {
+		if b, ok := f.files[rel]; ok {
+			return b.info, nil
+		}
+		if _, ok := f.dirs[rel]; ok {
+			return &info{name: filepath.Base(name), mode: fs.ModeDir | 0o755}, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir returns the entries of the directory name sorted by name.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	rel, ok := f.rel(name)
+	if ok {
+		if entries, ok := f.dirs[rel]; ok {
+			return append([]fs.DirEntry(nil), entries...), nil
+		}
+	}
+	return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile returns the contents of the file name in the tree.
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	rel, ok := f.rel(name)
+	var b *blob
+	if ok {
+		b = f.files[rel]
+	}
+	if b == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if b.data == nil {
+		data, err := f.cat(b.hash)
+		if err != nil {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		b.data = data
+	}
+	return append([]byte(nil), b.data...), nil
+}
+
+// cat reads a blob through the batch process, starting it on first use.
+func (f *FS) cat(hash string) ([]byte, error) {
+	if f.batch == nil {
+		cmd := exec.Command("git", "-C", f.Top, "cat-file", "--batch")
+		in, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		f.batch, f.in, f.out = cmd, in, bufio.NewReader(out)
+	}
+	if _, err := fmt.Fprintln(f.in, hash); err != nil {
+		return nil, err
+	}
+	header, err := f.out.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 || fields[0] != hash {
+		return nil, fmt.Errorf("git cat-file: %s", strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: %s", strings.TrimSpace(header))
+	}
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(f.out, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+// Close stops the batch process.
+func (f *FS) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.batch == nil {
+		return nil
+	}
+	f.in.Close()
+	err := f.batch.Wait()
+	f.batch = nil
+	return err
+}
+
+// git runs a git command in dir and returns its standard output.
+func git(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// info describes a file or directory of the tree.
+type info struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (i *info) Name() string       { return i.name }
+func (i *info) Size() int64        { return i.size }
+func (i *info) Mode() fs.FileMode  { return i.mode }
+func (i *info) ModTime() time.Time { return time.Time{} }
+func (i *info) IsDir() bool        { return i.mode.IsDir() }
+func (i *info) Sys() any           { return nil }
@@ -0,0 +1,88 @@
+package gitfs
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repo creates a git repository with a commit tagged v1 and, on top of it,
+// a working tree whose files differ from that commit.
+func repo(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	run("init", "-q")
+	write("go.mod", "module example.com/api\n")
+	write("api/users.go", "package api\n\ntype User struct{}\n")
+	write("api/orders/orders.go", "package orders\n")
+	run("add", "-A")
+	run("commit", "-q", "-m", "v1")
+	run("tag", "v1")
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "api", "orders")))
+	write("api/users.go", "package api\n\ntype User struct{ ID string }\n")
+	write("api/new.go", "package api\n")
+	return dir
+}
+
+func TestFS(t *testing.T) {
+	dir := repo(t)
+	// The directory removed from the working tree exists in the revision.
+	f, err := Open(filepath.Join(dir, "api", "orders"), "v1")
+	require.NoError(t, err)
+	defer f.Close()
+	assert.Len(t, f.Commit, 40)
+
+	data, err := f.ReadFile(filepath.Join(dir, "api", "users.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package api\n\ntype User struct{}\n", string(data))
+	data, err = f.ReadFile(filepath.Join(dir, "api", "orders", "orders.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package orders\n", string(data))
+
+	_, err = f.ReadFile(filepath.Join(dir, "api", "new.go"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = f.ReadFile(filepath.Join(filepath.Dir(dir), "go.mod"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	entries, err := f.ReadDir(filepath.Join(dir, "api"))
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"orders", "users.go"}, names)
+	assert.True(t, entries[0].IsDir())
+
+	info, err := f.Stat(dir)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	info, err = f.Stat(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("module example.com/api\n")), info.Size())
+	assert.NoError(t, f.Close())
+}
+
+func TestOpenErrors(t *testing.T) {
+	dir := repo(t)
+	_, err := Open(dir, "v2")
+	assert.ErrorContains(t, err, "revision v2")
+	_, err = Open(t.TempDir(), "HEAD")
+	assert.Error(t, err)
+}
@@ -0,0 +1,264 @@
+// Package glossary checks API documentation against a product glossary of
+// preferred and banned terms.
+//
+// A glossary file looks like:
+//
+//	severity: warning
+//	terms:
+//	  - term: allowlist
+//	    definition: Values that are explicitly permitted.
+//	    replaces: [whitelist, white list]
+//	  - term: GitHub
+//	  - term: OAuth
+//	banned:
+//	  - term: simply
+//	    reason: Sounds dismissive to readers who find the step hard.
+//
+// Terms spelled with capitals are also checked for capitalization, so
+// "Github" and "oauth" are reported. Words inside backquotes and URLs are
+// not checked.
+package glossary
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Diagnostic codes reported by Check.
+const (
+	CodeBannedTerm     = "GLOSSARY_BANNED_TERM"
+	CodeCapitalization = "GLOSSARY_CAPITALIZATION"
+)
+
+// Glossary is a parsed glossary file.
+type Glossary struct {
+	// Severity of violations: "error", "warning" (the default) or "info".
+	Severity string   `yaml:"severity"`
+	Terms    []Term   `yaml:"terms"`
+	Banned   []Banned `yaml:"banned"`
+}
+
+// Term is a preferred term.
+type Term struct {
+	Term       string `yaml:"term"`
+	Definition string `yaml:"definition"`
+	// Replaces lists terms to be written as Term instead.
+	Replaces []string `yaml:"replaces"`
+}
+
+// Banned is a term that must not be used.
+type Banned struct {
+	Term string `yaml:"term"`
+	// Use is the suggested replacement, if there is one.
+	Use    string `yaml:"use"`
+	Reason string `yaml:"reason"`
+}
+
+// Load reads a glossary file.
+func Load(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	g := &Glossary{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(g); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := g.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
+
+func (g *Glossary) validate() error {
+	switch g.Severity {
+	case "":
+		g.Severity = model.SeverityWarning
+	case model.SeverityError, model.SeverityWarning, model.SeverityInfo:
+	default:
+		return fmt.Errorf("unknown severity %q", g.Severity)
+	}
+	for i, t := range g.Terms {
+		if strings.TrimSpace(t.Term) == "" {
+			return fmt.Errorf("terms[%d]: term is empty", i)
+		}
+	}
+	for i, b := range g.Banned {
+		if strings.TrimSpace(b.Term) == "" {
+			return fmt.Errorf("banned[%d]: term is empty", i)
+		}
+	}
+	return nil
+}
+
+// rule matches one spelling to look for.
+type rule struct {
+	re *regexp.Regexp
+	// want is the canonical spelling; empty for banned terms without a
+	// replacement.
+	want string
+	// variant reports whether any match is a violation; otherwise only
+	// matches spelled differently from want are.
+	variant bool
+	reason  string
+}
+
+func (g *Glossary) rules() []*rule {
+	var rules []*rule
+	for _, t := range g.Terms {
+		for _, r := range t.Replaces {
+			rules = append(rules, &rule{re: termRegexp(r), want: t.Term, variant: true})
+		}
+		if hasUpper(t.Term) {
+			rules = append(rules, &rule{re: termRegexp(t.Term), want: t.Term})
+		}
+	}
+	for _, b := range g.Banned {
+		rules = append(rules, &rule{re: termRegexp(b.Term), want: b.Use, variant: true, reason: b.Reason})
+	}
+	return rules
+}
+
+// termRegexp matches a term as whole words, case-insensitively, allowing
+// any run of spaces between its words.
+func termRegexp(term string) *regexp.Regexp {
+	words := strings.Fields(term)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?i)(?:^|[^\pL\pN_])(` + strings.Join(words, `\s+`) + `)(?:$|[^\pL\pN_])`)
+}
+
+// masked matches the spans of a text that are not prose: code in
+// backquotes, URLs and host names.
+var masked = regexp.MustCompile("`[^`]*`|\\b[a-zA-Z][a-zA-Z0-9+.-]*://\\S*|\\b[\\w-]+(?:\\.[\\w-]+)*\\.(?:com|org|net|io|dev)\\b\\S*")
+
+// Check reports the glossary violations in texts. Violations with a known
+// replacement carry it as a suggestion and, in editable texts, as a fix.
+func (g *Glossary) Check(texts []*analyzer.DocText) []*model.Diagnostic {
+	rules := g.rules()
+	var diags []*model.Diagnostic
+	for _, t := range texts {
+		var taken [][2]int
+		for _, span := range masked.FindAllStringIndex(t.Text, -1) {
+			taken = append(taken, [2]int{span[0], span[1]})
+		}
+		for _, r := range rules {
+			for _, m := range findAll(r.re, t.Text) {
+				got := t.Text[m[0]:m[1]]
+				if !r.variant && got == r.want || overlaps(taken, m) {
+					continue
+				}
+				taken = append(taken, [2]int{m[0], m[1]})
+				diags = append(diags, g.diagnostic(t, r, m, got))
+			}
+		}
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Pos, diags[j].Pos
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return diags
+}
+
+// findAll returns the spans of the term group of every match, including
+// matches whose leading separator is the trailing one of the previous.
+func findAll(re *regexp.Regexp, s string) [][2]int {
+	var spans [][2]int
+	for start := 0; start < len(s); {
+		m := re.FindStringSubmatchIndex(s[start:])
+		if m == nil {
+			break
+		}
+		spans = append(spans, [2]int{start + m[2], start + m[3]})
+		start += m[3]
+	}
+	return spans
+}
+
+func overlaps(taken [][2]int, m [2]int) bool {
+	for _, t := range taken {
+		if m[0] < t[1] && t[0] < m[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Glossary) diagnostic(t *analyzer.DocText, r *rule, m [2]int, got string) *model.Diagnostic {
+	d := &model.Diagnostic{Severity: g.Severity, Pos: position(t, m[0])}
+	if r.variant {
+		d.Code = CodeBannedTerm
+		if r.want != "" {
+			d.Suggestion = matchCase(r.want, got)
+			d.Message = fmt.Sprintf("use %q instead of %q", d.Suggestion, got)
+		} else {
+			d.Message = fmt.Sprintf("avoid %q", got)
+		}
+		if r.reason != "" {
+			d.Message += ": " + r.reason
+		}
+	} else {
+		d.Code = CodeCapitalization
+		d.Suggestion = r.want
+		d.Message = fmt.Sprintf("write %q as %q", got, r.want)
+	}
+	if d.Suggestion != "" && t.Editable {
+		d.Fix = []*model.Edit{{File: t.Pos.File, Offset: t.Offset + m[0], Old: got, New: d.Suggestion}}
+	}
+	return d
+}
+
+// position returns the position of byte i of a text.
+func position(t *analyzer.DocText, i int) *model.Position {
+	p := &model.Position{File: t.Pos.File, Line: t.Pos.Line, Column: t.Pos.Column + i}
+	if nl := strings.LastIndexByte(t.Text[:i], '\n'); nl >= 0 {
+		p.Line += strings.Count(t.Text[:i], "\n")
+		p.Column = i - nl
+	}
+	return p
+}
+
+// matchCase capitalizes a lowercase replacement when the text it replaces
+// starts a sentence, as in "Whitelist" -> "Allowlist".
+func matchCase(want, got string) string {
+	if hasUpper(want) {
+		return want
+	}
+	r, _ := utf8.DecodeRuneInString(got)
+	if !unicode.IsUpper(r) {
+		return want
+	}
+	w, size := utf8.DecodeRuneInString(want)
+	return string(unicode.ToUpper(w)) + want[size:]
+}
+
+func hasUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package glossary
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/fix"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func check(t *testing.T, dir string) (*Glossary, []*analyzer.DocText, []*model.Diagnostic) {
+	t.Helper()
+	g, err := Load("testdata/glossary.yaml")
+	require.NoError(t, err)
+	prog, err := analyzer.Load(dir, analyzer.Options{})
+	require.NoError(t, err)
+	_, texts := analyzer.AnalyzeTexts(prog)
+	return g, texts, g.Check(texts)
+}
+
+func TestCheck(t *testing.T) {
+	_, _, diags := check(t, "testdata/api")
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%d:%d %s %s fix=%t", d.Pos.Line, d.Pos.Column, d.Code, d.Suggestion, len(d.Fix) > 0))
+	}
+	assert.Equal(t, []string{
+		"8:14 GLOSSARY_CAPITALIZATION GitHub fix=true",
+		"8:44 GLOSSARY_BANNED_TERM allowlist fix=true",
+		"11:18 GLOSSARY_CAPITALIZATION GitHub fix=true",
+		"12:38 GLOSSARY_BANNED_TERM allowlist fix=true",
+		"13:44 GLOSSARY_BANNED_TERM Allowlist fix=true",
+		"14:15 GLOSSARY_BANNED_TERM allowlist fix=false",
+		"21:19 GLOSSARY_BANNED_TERM  fix=false",
+		"25:48 GLOSSARY_CAPITALIZATION OAuth fix=true",
+	}, got)
+	assert.Equal(t, `avoid "simply": Sounds dismissive.`, diags[6].Message)
+	assert.Equal(t, model.SeverityWarning, diags[0].Severity)
+}
+
+func TestFix(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/api/api.go")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api.go"), src, 0o644))
+
+	_, _, diags := check(t, dir)
+	res, err := fix.Apply(dir, diags)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api.go"}, res.Files)
+	assert.Len(t, res.Fixed, 6)
+
+	fixed, err := os.ReadFile(filepath.Join(dir, "api.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(fixed), "// User is a GitHub account allowed by the allowlist.\n// See https://github.com/acme/whitelist and `whitelist`.")
+	assert.Contains(t, string(fixed), "`json:\"login\" example:\"allowlist-user\"`")
+	assert.Contains(t, string(fixed), "// Role in the Allowlist.")
+	assert.Contains(t, string(fixed), "authenticated with OAuth.")
+
+	_, _, diags = check(t, dir)
+	assert.Len(t, diags, 2)
+}
+
+func TestPage(t *testing.T) {
+	g, texts, _ := check(t, "testdata/api")
+	page := string(g.Page(texts, PageOptions{SourceBase: ".."}))
+	assert.Contains(t, page, "## allowlist\n\nValues that are explicitly permitted.\n\nInstead of: whitelist, white list\n")
+	assert.Contains(t, page, "## OAuth\n\nThe authorization framework.\n\nUsed by: [`GET /users`](../api.go#L")
+	assert.Contains(t, page, "## GitHub\n\nUsed by: [User](../api.go#L10)\n")
+	assert.Contains(t, page, "| simply |  | Sounds dismissive. |")
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glossary.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("severity: fatal\n"), 0o644))
+	_, err := Load(path)
+	assert.ErrorContains(t, err, `unknown severity "fatal"`)
+
+	require.NoError(t, os.WriteFile(path, []byte("terms:\n  - term: allowlist\n    replace: [whitelist]\n"), 0o644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "field replace not found")
+}
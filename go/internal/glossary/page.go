@@ -0,0 +1,88 @@
+package glossary
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+)
+
+// PageOptions configures Page.
+type PageOptions struct {
+	// SourceBase is prepended to source file paths in links, e.g. "../"
+	// when the page is written one directory below the analyzed root.
+	SourceBase string
+}
+
+// Page renders the glossary as a Markdown page. Each term links to the
+// schemas and endpoints whose documentation uses it.
+func (g *Glossary) Page(texts []*analyzer.DocText, opts PageOptions) []byte {
+	var b strings.Builder
+	b.WriteString("<!-- Code generated by api-doc-gen-go gen glossary. DO NOT EDIT. -->\n\n")
+	b.WriteString("# Glossary\n")
+	for _, t := range g.Terms {
+		fmt.Fprintf(&b, "\n## %s\n", t.Term)
+		if t.Definition != "" {
+			fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(t.Definition))
+		}
+		if len(t.Replaces) > 0 {
+			fmt.Fprintf(&b, "\nInstead of: %s\n", strings.Join(t.Replaces, ", "))
+		}
+		if owners := usedBy(termRegexp(t.Term), texts); len(owners) > 0 {
+			links := make([]string, len(owners))
+			for i, o := range owners {
+				links[i] = link(o, opts.SourceBase)
+			}
+			fmt.Fprintf(&b, "\nUsed by: %s\n", strings.Join(links, ", "))
+		}
+	}
+	if len(g.Banned) > 0 {
+		b.WriteString("\n## Terms to avoid\n\n| Term | Use instead | Reason |\n| --- | --- | --- |\n")
+		for _, t := range g.Banned {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", cell(t.Term), cell(t.Use), cell(t.Reason))
+		}
+	}
+	return []byte(b.String())
+}
+
+// usedBy returns the owners of the texts mentioning a term, schemas
+// first, each once.
+func usedBy(re *regexp.Regexp, texts []*analyzer.DocText) []analyzer.DocOwner {
+	var schemas, endpoints []analyzer.DocOwner
+	seen := map[string]bool{}
+	for _, t := range texts {
+		if !re.MatchString(masked.ReplaceAllString(t.Text, " ")) {
+			continue
+		}
+		for _, o := range t.Owners {
+			if seen[o.Kind+" "+o.Name] {
+				continue
+			}
+			seen[o.Kind+" "+o.Name] = true
+			if o.Kind == "schema" {
+				schemas = append(schemas, o)
+			} else {
+				endpoints = append(endpoints, o)
+			}
+		}
+	}
+	return append(schemas, endpoints...)
+}
+
+func link(o analyzer.DocOwner, base string) string {
+	label := o.Name
+	if o.Kind == "endpoint" {
+		label = "`" + o.Name + "`"
+	}
+	if o.Pos == nil {
+		return label
+	}
+	return fmt.Sprintf("[%s](%s#L%d)", label, path.Join(base, filepath.ToSlash(o.Pos.File)), o.Pos.Line)
+}
+
+func cell(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "|", `\|`)
+}
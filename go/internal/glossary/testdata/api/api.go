@@ -0,0 +1,32 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// User is a Github account allowed by the whitelist.
+// See https://github.com/acme/whitelist and `whitelist`.
+type User struct {
+	// Login is the github login.
+	Login string `json:"login" example:"whitelist-user"`
+	Role  Role   `json:"role"` // Role in the White List.
+	Note  string "json:\"note\" example:\"whitelist\\tnote\""
+}
+
+// Role is a user role.
+type Role string
+
+const (
+	// RoleAdmin can simply do anything.
+	RoleAdmin Role = "admin"
+)
+
+// GetUser returns the user authenticated with Oauth.
+func GetUser(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(User{})
+}
+
+func Routes() {
+	http.HandleFunc("/users", GetUser)
+}
@@ -0,0 +1,495 @@
+// Package graphql renders extracted schemas as GraphQL SDL.
+//
+// Object schemas become object types, and those reachable from a request
+// body or parameter also become input types. Enum schemas become enums,
+// and oneOf schemas whose alternatives are all objects become unions;
+// other oneOf schemas and free-form objects use a JSON scalar. A field is
+// non-null when its property is required and not nullable, which the
+// analyzer derives from omitempty and pointers.
+//
+// The output is written for the regex-based parser in
+// src/parsers/languages/graphql-parser.ts: descriptions are # comments,
+// before a definition or at the end of a field's line, and never contain
+// braces.
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/codegen"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Naming strategies.
+const (
+	// CasePreserve keeps names, replacing characters GraphQL does not
+	// allow with underscores.
+	CasePreserve = "preserve"
+	// CasePascal writes type names as PascalCase.
+	CasePascal = "pascal"
+	// CaseCamel writes field names as camelCase.
+	CaseCamel = "camel"
+	// CaseUpperSnake writes enum values as UPPER_SNAKE_CASE.
+	CaseUpperSnake = "upper-snake"
+)
+
+// Naming configures how schema, property and enum value names become
+// GraphQL names.
+type Naming struct {
+	// Types is the case of type names: pascal (default) or preserve.
+	Types string `yaml:"types"`
+	// Fields is the case of field names: camel (default) or preserve.
+	Fields string `yaml:"fields"`
+	// EnumValues is the case of enum values: upper-snake (default) or
+	// preserve.
+	EnumValues string `yaml:"enumValues"`
+	// Prefix is prepended to every type name.
+	Prefix string `yaml:"prefix"`
+	// InputSuffix is appended to the names of input types; it defaults
+	// to "Input".
+	InputSuffix string `yaml:"inputSuffix"`
+	// Rename maps schema names to type names, overriding the rules above.
+	Rename map[string]string `yaml:"rename"`
+}
+
+// Options configures the SDL output.
+type Options struct {
+	Naming Naming
+}
+
+func (n Naming) withDefaults() (Naming, error) {
+	for _, c := range []struct {
+		value *string
+		def   string
+		valid []string
+	}{
+		{&n.Types, CasePascal, []string{CasePascal, CasePreserve}},
+		{&n.Fields, CaseCamel, []string{CaseCamel, CasePreserve}},
+		{&n.EnumValues, CaseUpperSnake, []string{CaseUpperSnake, CasePreserve}},
+	} {
+		if *c.value == "" {
+			*c.value = c.def
+		}
+		if !contains(c.valid, *c.value) {
+			return n, fmt.Errorf("unknown naming strategy %q (want one of %s)", *c.value, strings.Join(c.valid, ", "))
+		}
+	}
+	if n.InputSuffix == "" {
+		n.InputSuffix = "Input"
+	}
+	return n, nil
+}
+
+// Generate renders the schemas of doc as GraphQL SDL.
+func Generate(doc *model.Document, opts Options) ([]byte, error) {
+	naming, err := opts.Naming.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		doc:     doc,
+		naming:  naming,
+		types:   map[string]string{},
+		taken:   map[string]string{},
+		scalars: map[string]bool{},
+		inputs:  map[string]bool{},
+	}
+	for _, name := range doc.SchemaNames() {
+		if err := g.claim(name, g.typeName(name)); err != nil {
+			return nil, err
+		}
+	}
+	g.collectInputs()
+	for _, name := range doc.SchemaNames() {
+		if g.inputs[name] {
+			if err := g.claim(name+" input", g.types[name]+naming.InputSuffix); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var defs []string
+	for _, name := range doc.SchemaNames() {
+		if def := g.definition(name, doc.Schemas[name], false); def != "" {
+			defs = append(defs, def)
+		}
+	}
+	for _, name := range doc.SchemaNames() {
+		if g.inputs[name] {
+			defs = append(defs, g.definition(name, doc.Schemas[name], true))
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# Code generated by api-doc-gen-go parse --format graphql-sdl. DO NOT EDIT.\n\n")
+	scalars := make([]string, 0, len(g.scalars))
+	for s := range g.scalars {
+		scalars = append(scalars, s)
+	}
+	sort.Strings(scalars)
+	for _, s := range scalars {
+		fmt.Fprintf(&b, "scalar %s\n", s)
+	}
+	if len(scalars) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString(strings.Join(defs, "\n"))
+	return b.Bytes(), nil
+}
+
+type generator struct {
+	doc    *model.Document
+	naming Naming
+	// types maps schema names to GraphQL type names.
+	types map[string]string
+	// taken maps GraphQL type names to the schema they were given to.
+	taken   map[string]string
+	scalars map[string]bool
+	// inputs holds the schemas that also become input types.
+	inputs map[string]bool
+}
+
+// claim assigns a GraphQL type name, failing when two schemas would share
+// it.
+func (g *generator) claim(key, name string) error {
+	if other, ok := g.taken[name]; ok {
+		return fmt.Errorf("schemas %s and %s both map to GraphQL type %s; add a rename to the graphql naming configuration", other, key, name)
+	}
+	g.taken[name] = key
+	if !strings.HasSuffix(key, " input") {
+		g.types[key] = name
+	}
+	return nil
+}
+
+func (g *generator) typeName(schema string) string {
+	if name, ok := g.naming.Rename[schema]; ok {
+		return name
+	}
+	if g.naming.Types == CasePreserve {
+		return g.naming.Prefix + sanitize(schema)
+	}
+	return g.naming.Prefix + pascal(schema)
+}
+
+func (g *generator) fieldName(prop string) string {
+	if g.naming.Fields == CasePreserve {
+		return sanitize(prop)
+	}
+	if name := camel(prop); name != "" {
+		return name
+	}
+	return sanitize(prop)
+}
+
+// collectInputs marks the schemas reachable from request bodies and
+// parameters.
+func (g *generator) collectInputs() {
+	var visit func(s *model.Schema)
+	visit = func(s *model.Schema) {
+		if s == nil {
+			return
+		}
+		if name := s.RefName(); name != "" {
+			target := g.doc.Schemas[name]
+			if target == nil || g.inputs[name] {
+				return
+			}
+			if g.isObject(target) {
+				g.inputs[name] = true
+			}
+			visit(target)
+			return
+		}
+		for _, p := range s.Properties {
+			visit(p)
+		}
+		visit(s.Items)
+		visit(s.AdditionalProperties)
+	}
+	for _, ep := range g.doc.Endpoints {
+		if ep.RequestBody != nil {
+			visit(ep.RequestBody.Schema)
+		}
+		for _, p := range ep.Parameters {
+			visit(p.Schema)
+		}
+	}
+}
+
+// isObject reports whether s becomes an object or input type.
+func (g *generator) isObject(s *model.Schema) bool {
+	return len(s.Properties) > 0 && len(s.OneOf) == 0
+}
+
+// definition renders the type, input, enum or union for a schema, or ""
+// for schemas that are used inline.
+func (g *generator) definition(name string, s *model.Schema, input bool) string {
+	var b strings.Builder
+	writeComment(&b, "", s.Description)
+	typeName := g.types[name]
+	switch {
+	case input:
+		fmt.Fprintf(&b, "input %s%s {\n", typeName, g.naming.InputSuffix)
+		g.fields(&b, s, true)
+		b.WriteString("}\n")
+	case len(s.Enum) > 0:
+		fmt.Fprintf(&b, "enum %s {\n", typeName)
+		g.enumValues(&b, name, s)
+		b.WriteString("}\n")
+	case len(s.OneOf) > 0:
+		members, ok := g.unionMembers(s)
+		if !ok {
+			return ""
+		}
+		fmt.Fprintf(&b, "union %s = %s\n", typeName, strings.Join(members, " | "))
+	case g.isObject(s):
+		fmt.Fprintf(&b, "type %s {\n", typeName)
+		g.fields(&b, s, false)
+		b.WriteString("}\n")
+	default:
+		return ""
+	}
+	return b.String()
+}
+
+func (g *generator) fields(b *strings.Builder, s *model.Schema, input bool) {
+	for _, prop := range s.PropertyNames() {
+		ps := s.Properties[prop]
+		typ := g.typeRef(ps, input)
+		if s.IsRequired(prop) && !g.nullable(ps) {
+			typ += "!"
+		}
+		fmt.Fprintf(b, "  %s: %s", g.fieldName(prop), typ)
+		if desc := commentText(ps.Description); desc != "" {
+			b.WriteString(" # " + strings.Join(strings.Fields(desc), " "))
+		}
+		b.WriteString("\n")
+	}
+}
+
+func (g *generator) nullable(s *model.Schema) bool {
+	if s.Nullable {
+		return true
+	}
+	if t := g.doc.Schemas[s.RefName()]; t != nil {
+		return t.Nullable
+	}
+	return false
+}
+
+// typeRef returns the GraphQL type of a field, without the non-null
+// marker of the field itself.
+func (g *generator) typeRef(s *model.Schema, input bool) string {
+	if name := s.RefName(); name != "" {
+		target := g.doc.Schemas[name]
+		if target == nil {
+			return g.scalar("JSON")
+		}
+		switch {
+		case len(target.Enum) > 0:
+			return g.types[name]
+		case len(target.OneOf) > 0:
+			if _, ok := g.unionMembers(target); ok && !input {
+				return g.types[name]
+			}
+			return g.scalar("JSON")
+		case g.isObject(target):
+			if input {
+				return g.types[name] + g.naming.InputSuffix
+			}
+			return g.types[name]
+		}
+		return g.typeRef(target, input)
+	}
+	switch s.Type {
+	case "array":
+		if s.Items == nil {
+			return "[" + g.scalar("JSON") + "]"
+		}
+		item := g.typeRef(s.Items, input)
+		if !g.nullable(s.Items) {
+			item += "!"
+		}
+		return "[" + item + "]"
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return g.scalar("DateTime")
+		case "date":
+			return g.scalar("Date")
+		case "email":
+			return g.scalar("EmailAddress")
+		case "uuid":
+			return "ID"
+		}
+		return "String"
+	case "integer":
+		if s.Format == "int64" {
+			return g.scalar("Int64")
+		}
+		return "Int"
+	case "number":
+		return "Float"
+	case "boolean":
+		return "Boolean"
+	}
+	return g.scalar("JSON")
+}
+
+// scalar records a custom scalar and returns its name.
+func (g *generator) scalar(name string) string {
+	g.scalars[name] = true
+	return name
+}
+
+// unionMembers returns the member types of a oneOf schema, and false when
+// not every alternative is an object type, as GraphQL unions require.
+func (g *generator) unionMembers(s *model.Schema) ([]string, bool) {
+	var members []string
+	for _, alt := range s.OneOf {
+		name := alt.RefName()
+		target := g.doc.Schemas[name]
+		if target == nil || !g.isObject(target) {
+			return nil, false
+		}
+		members = append(members, g.types[name])
+	}
+	return members, true
+}
+
+func (g *generator) enumValues(b *strings.Builder, schema string, s *model.Schema) {
+	seen := map[string]bool{}
+	for i, v := range s.Enum {
+		name := g.enumValue(fmt.Sprint(v))
+		_, isString := v.(string)
+		if (!isString || !validName(name) || seen[name]) && i < len(s.EnumVarNames) {
+			// Numeric values, and strings that are not valid names,
+			// are named after the Go constant.
+			goType := schema
+			if dot := strings.LastIndexByte(goType, '.'); dot >= 0 {
+				goType = goType[dot+1:]
+			}
+			name = g.enumValue(strings.TrimPrefix(s.EnumVarNames[i], goType))
+		}
+		if !validName(name) || seen[name] {
+			name = fmt.Sprintf("VALUE_%d", i)
+		}
+		seen[name] = true
+		fmt.Fprintf(b, "  %s", name)
+		if i < len(s.EnumDescriptions) && s.EnumDescriptions[i] != "" {
+			b.WriteString(" # " + strings.Join(strings.Fields(commentText(s.EnumDescriptions[i])), " "))
+		}
+		b.WriteString("\n")
+	}
+}
+
+func (g *generator) enumValue(v string) string {
+	if g.naming.EnumValues == CasePreserve {
+		return sanitize(v)
+	}
+	return upperSnake(v)
+}
+
+// writeComment writes a description as # comment lines.
+func writeComment(b *strings.Builder, indent, text string) {
+	text = strings.TrimSpace(commentText(text))
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(b, "%s# %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+// commentText replaces braces, which end definitions in the TypeScript
+// parser even inside comments.
+func commentText(s string) string {
+	return strings.NewReplacer("{", "(", "}", ")").Replace(s)
+}
+
+var nameRE = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+func validName(s string) bool {
+	return nameRE.MatchString(s) && s != "true" && s != "false" && s != "null"
+}
+
+// sanitize replaces the characters GraphQL names do not allow.
+func sanitize(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || i > 0 && unicode.IsDigit(r)):
+			b.WriteRune(r)
+		case i == 0 && unicode.IsDigit(r):
+			b.WriteString("_")
+			b.WriteRune(r)
+		default:
+			b.WriteString("_")
+		}
+	}
+	return b.String()
+}
+
+// words splits a name into the words of its ASCII letters and digits.
+func words(s string) []string {
+	return codegen.Words(strings.Map(func(r rune) rune {
+		if r >= unicode.MaxASCII {
+			return ' '
+		}
+		return r
+	}, s))
+}
+
+func pascal(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return prefixDigit(b.String())
+}
+
+func camel(s string) string {
+	var b strings.Builder
+	for i, w := range words(s) {
+		if i == 0 {
+			if strings.ToUpper(w) == w {
+				b.WriteString(strings.ToLower(w))
+			} else {
+				b.WriteString(strings.ToLower(w[:1]) + w[1:])
+			}
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return prefixDigit(b.String())
+}
+
+func upperSnake(s string) string {
+	ws := words(s)
+	for i, w := range ws {
+		ws[i] = strings.ToUpper(w)
+	}
+	return prefixDigit(strings.Join(ws, "_"))
+}
+
+func prefixDigit(s string) string {
+	if s != "" && unicode.IsDigit(rune(s[0])) {
+		return "_" + s
+	}
+	return s
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package graphql
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func testDocument() *model.Document {
+	return &model.Document{
+		Schemas: map[string]*model.Schema{
+			"Order": {
+				Type:        "object",
+				Description: "Order is a purchase {with braces}.",
+				Properties: map[string]*model.Schema{
+					"id":         {Type: "string", Format: "uuid"},
+					"created_at": {Type: "string", Format: "date-time", Description: "When the order\nwas placed."},
+					"note":       {Type: "string", Nullable: true},
+					"status":     model.RefTo("Status"),
+					"priority":   model.RefTo("Priority"),
+					"items":      {Type: "array", Items: model.RefTo("LineItem")},
+					"payment":    model.RefTo("Payment"),
+					"metadata":   {Type: "object", AdditionalProperties: &model.Schema{}},
+				},
+				PropertyOrder: []string{"id", "created_at", "note", "status", "priority", "items", "payment", "metadata"},
+				Required:      []string{"id", "created_at", "note", "status", "items"},
+			},
+			"LineItem": {
+				Type:       "object",
+				Properties: map[string]*model.Schema{"sku": {Type: "string"}, "qty": {Type: "integer", Format: "int64"}},
+				Required:   []string{"sku"},
+			},
+			"Card":   {Type: "object", Properties: map[string]*model.Schema{"last4": {Type: "string"}}},
+			"Wallet": {Type: "object", Properties: map[string]*model.Schema{"provider": {Type: "string"}}},
+			"Payment": {
+				OneOf: []*model.Schema{model.RefTo("Card"), model.RefTo("Wallet")},
+			},
+			"Status": {
+				Type:             "string",
+				Enum:             []any{"pending", "in-transit"},
+				EnumVarNames:     []string{"StatusPending", "StatusInTransit"},
+				EnumDescriptions: []string{"Not shipped yet.", ""},
+			},
+			"Priority": {
+				Type:         "integer",
+				Enum:         []any{int64(1), int64(2)},
+				EnumVarNames: []string{"PriorityLow", "PriorityHigh"},
+			},
+		},
+		Endpoints: []*model.Endpoint{{
+			Method: "POST", Path: "/orders",
+			RequestBody: &model.RequestBody{ContentType: "application/json", Schema: model.RefTo("Order")},
+		}},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	out, err := Generate(testDocument(), Options{})
+	require.NoError(t, err)
+	sdl := string(out)
+
+	assert.Contains(t, sdl, "scalar DateTime\nscalar Int64\nscalar JSON\n")
+	assert.Contains(t, sdl, "# Order is a purchase (with braces).\ntype Order {\n")
+	assert.Contains(t, sdl, "  id: ID!\n")
+	assert.Contains(t, sdl, "  createdAt: DateTime! # When the order was placed.\n")
+	assert.Contains(t, sdl, "  note: String\n")
+	assert.Contains(t, sdl, "  status: Status!\n")
+	assert.Contains(t, sdl, "  items: [LineItem!]!\n")
+	assert.Contains(t, sdl, "  payment: Payment\n")
+	assert.Contains(t, sdl, "  metadata: JSON\n")
+	assert.Contains(t, sdl, "enum Status {\n  PENDING # Not shipped yet.\n  IN_TRANSIT\n}")
+	assert.Contains(t, sdl, "enum Priority {\n  LOW\n  HIGH\n}")
+	assert.Contains(t, sdl, "union Payment = Card | Wallet\n")
+	assert.Contains(t, sdl, "input OrderInput {\n")
+	assert.Contains(t, sdl, "  items: [LineItemInput!]!\n")
+	assert.Contains(t, sdl, "  payment: JSON\n")
+	assert.Contains(t, sdl, "  qty: Int64\n")
+	assert.NotContains(t, sdl, "input CardInput")
+}
+
+// TestTypeScriptParser checks the output against the regular expressions
+// of src/parsers/languages/graphql-parser.ts.
+func TestTypeScriptParser(t *testing.T) {
+	out, err := Generate(testDocument(), Options{})
+	require.NoError(t, err)
+	sdl := string(out)
+
+	defRE := regexp.MustCompile(`(type|input)\s+(\w+)\s*{([^}]*)}`)
+	fieldRE := regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?\s*:\s*(.+?)(?:\s*#\s*(.*))?$`)
+	defs := map[string]int{}
+	for _, m := range defRE.FindAllStringSubmatch(sdl, -1) {
+		for _, line := range strings.Split(m[3], "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			require.Regexp(t, fieldRE, line)
+			defs[m[2]]++
+		}
+	}
+	assert.Equal(t, map[string]int{"Order": 8, "OrderInput": 8, "LineItem": 2, "LineItemInput": 2, "Card": 1, "Wallet": 1}, defs)
+	desc := fieldRE.FindStringSubmatch("createdAt: DateTime! # When the order was placed.")
+	assert.Equal(t, "DateTime!", desc[3])
+	assert.Equal(t, "When the order was placed.", desc[4])
+}
+
+func TestNaming(t *testing.T) {
+	naming := Naming{Fields: CasePreserve, EnumValues: CasePreserve, Prefix: "Shop", InputSuffix: "Args", Rename: map[string]string{"LineItem": "OrderLine"}}
+	out, err := Generate(testDocument(), Options{Naming: naming})
+	require.NoError(t, err)
+	sdl := string(out)
+	assert.Contains(t, sdl, "type ShopOrder {\n")
+	assert.Contains(t, sdl, "  created_at: DateTime!")
+	assert.Contains(t, sdl, "  items: [OrderLine!]!\n")
+	assert.Contains(t, sdl, "input OrderLineArgs {\n")
+	assert.Contains(t, sdl, "enum ShopStatus {\n  pending # Not shipped yet.\n  in_transit\n}")
+
+	_, err = Generate(testDocument(), Options{Naming: Naming{Rename: map[string]string{"Card": "Wallet"}}})
+	assert.ErrorContains(t, err, "both map to GraphQL type Wallet")
+	_, err = Generate(testDocument(), Options{Naming: Naming{Types: "snake"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+// Package inject keeps generated API sections of hand-written Markdown
+// files up to date.
+//
+// A region starts with a begin marker naming the fragment to render and
+// ends with an end marker, each on a line of its own:
+//
+//	<!-- apidoc:begin endpoints tag=users -->
+//	...
+//	<!-- apidoc:end -->
+//
+// Apply replaces the content of every region with the fragment rendered
+// from an API document and leaves the rest of the file untouched. Markers
+// inside fenced code blocks are text, so pages can document the syntax.
+package inject
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/markdown"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Kinds lists the fragments a region can hold, with the attributes each
+// accepts:
+//
+//   - endpoints: a table of endpoints, restricted to those tagged tag=,
+//     under the path prefix path= or serving method=.
+//   - schemas: the field tables of the schemas named by name=, a
+//     comma-separated list, or of every schema.
+//   - config: the configuration reference of the schema named by schema=,
+//     one row per setting.
+//   - middleware: the middleware catalog, or the middleware chain of the
+//     endpoint named by route=, as route="GET /users/{id}".
+var Kinds = map[string][]string{
+	"endpoints":  {"tag", "path", "method"},
+	"schemas":    {"name"},
+	"config":     {"schema"},
+	"middleware": {"route"},
+}
+
+var (
+	beginMarker = regexp.MustCompile(`^<!--\s*apidoc:begin\s+(\S+)(.*?)\s*-->$`)
+	endMarker   = regexp.MustCompile(`^<!--\s*apidoc:end\s*-->$`)
+	attribute   = regexp.MustCompile(`^\s+([\w-]+)=("[^"]*"|[^\s"]+)`)
+	fence       = regexp.MustCompile("^(```+|~~~+)")
+)
+
+// Region is a marked region of a file.
+type Region struct {
+	Kind  string
+	Attrs map[string]string
+	// Line is the line of the begin marker, counting from 1.
+	Line int
+	// begin and end are the indexes of the lines of the markers.
+	begin, end int
+}
+
+// String returns the begin marker of the region.
+func (r *Region) String() string {
+	s := "apidoc:begin " + r.Kind
+	keys := make([]string, 0, len(r.Attrs))
+	for k := range r.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := r.Attrs[k]
+		if strings.ContainsAny(v, " \t") {
+			v = `"` + v + `"`
+		}
+		s += " " + k + "=" + v
+	}
+	return s
+}
+
+// Parse returns the regions of a Markdown file.
+func Parse(src []byte) ([]*Region, error) {
+	lines := strings.Split(string(src), "\n")
+	var regions []*Region
+	var open *Region
+	var inFence string
+	for i, line := range lines {
+		text := strings.TrimSpace(line)
+		if m := fence.FindString(text); m != "" {
+			switch {
+			case inFence == "":
+				inFence = m
+			case strings.HasPrefix(m, inFence) && strings.TrimLeft(text, m[:1]) == "":
+				inFence = ""
+			}
+			continue
+		}
+		if inFence != "" {
+			continue
+		}
+		if m := beginMarker.FindStringSubmatch(text); m != nil {
+			if open != nil {
+				return nil, fmt.Errorf("%d: region begins inside the region of line %d", i+1, open.Line)
+			}
+			r, err := parseBegin(m[1], m[2])
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i+1, err)
+			}
+			r.Line, r.begin = i+1, i
+			open = r
+			continue
+		}
+		if endMarker.MatchString(text) {
+			if open == nil {
+				return nil, fmt.Errorf("%d: apidoc:end without apidoc:begin", i+1)
+			}
+			open.end = i
+			regions = append(regions, open)
+			open = nil
+		}
+	}
+	if open != nil {
+		return nil, fmt.Errorf("%d: region is not closed by <!-- apidoc:end -->", open.Line)
+	}
+	return regions, nil
+}
+
+func parseBegin(kind, rest string) (*Region, error) {
+	allowed, ok := Kinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown fragment %q (want endpoints, schemas, config or middleware)", kind)
+	}
+	r := &Region{Kind: kind, Attrs: map[string]string{}}
+	for rest != "" {
+		m := attribute.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("cannot parse attributes %q; write them as key=value", strings.TrimSpace(rest))
+		}
+		rest = rest[len(m[0]):]
+		known := false
+		for _, a := range allowed {
+			known = known || a == m[1]
+		}
+		if !known {
+			return nil, fmt.Errorf("%s takes no attribute %s (want %s)", kind, m[1], strings.Join(allowed, ", "))
+		}
+		r.Attrs[m[1]] = strings.Trim(m[2], `"`)
+	}
+	if kind == "config" && r.Attrs["schema"] == "" {
+		return nil, fmt.Errorf("config needs the schema to describe, e.g. schema=Config")
+	}
+	return r, nil
+}
+
+// Render renders the fragment of a region from doc.
+func Render(doc *model.Document, r *Region) (string, error) {
+	var opts markdown.Options
+	switch r.Kind {
+	case "endpoints":
+		var eps []*model.Endpoint
+		for _, ep := range doc.Endpoints {
+			if tag := r.Attrs["tag"]; tag != "" {
+				tagged := false
+				for _, t := range ep.Tags {
+					tagged = tagged || t == tag
+				}
+				if !tagged {
+					continue
+				}
+			}
+			if p := r.Attrs["path"]; p != "" && ep.Path != p && !strings.HasPrefix(ep.Path, strings.TrimSuffix(p, "/")+"/") {
+				continue
+			}
+			if m := r.Attrs["method"]; m != "" && !strings.EqualFold(ep.Method, m) {
+				continue
+			}
+			eps = append(eps, ep)
+		}
+		return markdown.Endpoints(eps, opts), nil
+	case "schemas":
+		names := markdown.SortedSchemaNames(doc)
+		if list := r.Attrs["name"]; list != "" {
+			names = strings.Split(list, ",")
+			for i := range names {
+				names[i] = strings.TrimSpace(names[i])
+			}
+		}
+		return markdown.Schemas(doc, names, opts)
+	case "config":
+		return markdown.Config(doc, r.Attrs["schema"], opts)
+	case "middleware":
+		route := r.Attrs["route"]
+		if route == "" {
+			return markdown.Middleware(doc, opts), nil
+		}
+		for _, ep := range doc.Endpoints {
+			if strings.EqualFold(ep.Key(), route) {
+				return markdown.Chain(doc, ep), nil
+			}
+		}
+		return "", fmt.Errorf("no endpoint %s", route)
+	}
+	return "", fmt.Errorf("unknown fragment %q", r.Kind)
+}
+
+// Apply renders every region of a Markdown file from doc. It returns the
+// updated file and the regions whose content changed.
+func Apply(src []byte, doc *model.Document) ([]byte, []*Region, error) {
+	regions, err := Parse(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := strings.Split(string(src), "\n")
+	var b strings.Builder
+	var stale []*Region
+	next := 0
+	for _, r := range regions {
+		fragment, err := Render(doc, r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%d: %w", r.Line, err)
+		}
+		content := "\n" + fragment + "\n"
+		if strings.Join(lines[r.begin+1:r.end], "\n")+"\n" != content {
+			stale = append(stale, r)
+		}
+		for _, line := range lines[next : r.begin+1] {
+			b.WriteString(line + "\n")
+		}
+		b.WriteString(content)
+		next = r.end
+	}
+	b.WriteString(strings.Join(lines[next:], "\n"))
+	return []byte(b.String()), stale, nil
+}
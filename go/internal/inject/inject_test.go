@@ -0,0 +1,78 @@
+package inject
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func testDoc() *model.Document {
+	return &model.Document{
+		Endpoints: []*model.Endpoint{
+			{Method: "GET", Path: "/orders", Summary: "List orders", Tags: []string{"orders"}},
+			{Method: "GET", Path: "/users", Summary: "List users", Tags: []string{"users"}},
+			{Method: "POST", Path: "/users", Summary: "Create a user | admin", Tags: []string{"users"}, Deprecated: true},
+		},
+		Schemas: map[string]*model.Schema{
+			"User": {
+				Type:          "object",
+				Description:   "A registered user.",
+				Properties:    map[string]*model.Schema{"id": {Type: "string", Format: "uuid"}, "role": {Type: "string", Enum: []any{"admin", "member"}}},
+				PropertyOrder: []string{"id", "role"},
+				Required:      []string{"id"},
+			},
+		},
+	}
+}
+
+func TestApply(t *testing.T) {
+	src := "# Users\n\nHand-written prose.\n\n" +
+		"<!-- apidoc:begin endpoints tag=users -->\nold table\n<!-- apidoc:end -->\n\n" +
+		"```markdown\n<!-- apidoc:begin nonsense -->\n```\n\n" +
+		"<!-- apidoc:begin schemas name=User -->\n<!-- apidoc:end -->\n\nMore prose.\n"
+	want := "# Users\n\nHand-written prose.\n\n" +
+		"<!-- apidoc:begin endpoints tag=users -->\n\n" +
+		"| Method | Path | Summary |\n| --- | --- | --- |\n" +
+		"| GET | `/users` | List users |\n" +
+		"| POST | `/users` | **Deprecated.** Create a user \\| admin |\n" +
+		"\n<!-- apidoc:end -->\n\n" +
+		"```markdown\n<!-- apidoc:begin nonsense -->\n```\n\n" +
+		"<!-- apidoc:begin schemas name=User -->\n\n" +
+		"**User**: A registered user.\n\n" +
+		"| Field | Type | Required | Description |\n| --- | --- | --- | --- |\n" +
+		"| `id` | string (uuid) | yes |  |\n" +
+		"| `role` | string | no | One of `admin`, `member`. |\n" +
+		"\n<!-- apidoc:end -->\n\nMore prose.\n"
+
+	out, stale, err := Apply([]byte(src), testDoc())
+	require.NoError(t, err)
+	assert.Equal(t, want, string(out))
+	require.Len(t, stale, 2)
+	assert.Equal(t, 5, stale[0].Line)
+	assert.Equal(t, "apidoc:begin endpoints tag=users", stale[0].String())
+
+	again, stale, err := Apply(out, testDoc())
+	require.NoError(t, err)
+	assert.Equal(t, want, string(again))
+	assert.Empty(t, stale)
+}
+
+func TestParseErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"<!-- apidoc:begin endpoints -->\n":                                    "1: region is not closed by <!-- apidoc:end -->",
+		"<!-- apidoc:end -->\n":                                                "1: apidoc:end without apidoc:begin",
+		"<!-- apidoc:begin tables -->\n<!-- apidoc:end -->\n":                  `1: unknown fragment "tables" (want endpoints, schemas, config or middleware)`,
+		"<!-- apidoc:begin endpoints name=x -->\n<!-- apidoc:end -->\n":        "1: endpoints takes no attribute name (want tag, path, method)",
+		"<!-- apidoc:begin config -->\n<!-- apidoc:end -->\n":                  "1: config needs the schema to describe, e.g. schema=Config",
+		"<!-- apidoc:begin endpoints -->\n<!-- apidoc:begin schemas -->\n":     "2: region begins inside the region of line 1",
+		"x\n<!-- apidoc:begin endpoints tag users -->\n<!-- apidoc:end -->\n":  `2: cannot parse attributes "tag users"; write them as key=value`,
+		"<!-- apidoc:begin schemas name=Order -->\n<!-- apidoc:end -->\n":      "1: no schema named Order",
+		"<!-- apidoc:begin config schema=\"Order\" -->\n<!-- apidoc:end -->\n": "1: no schema named Order",
+	} {
+		_, _, err := Apply([]byte(src), testDoc())
+		assert.EqualError(t, err, want, src)
+	}
+}
@@ -0,0 +1,387 @@
+// Package inventory builds one API inventory across the Go modules of many
+// repositories: a row per endpoint with the service serving it, its
+// authentication, stability, owner, documentation coverage and the commit
+// that last changed it.
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Module is a Go module found under the inventory root.
+type Module struct {
+	// Dir is the directory of the module's go.mod.
+	Dir string
+	// Path is the module path it declares.
+	Path string
+	// Repo is the top-level directory of the git repository containing
+	// the module, or Dir outside of one.
+	Repo string
+	// Nested lists the directories of the modules nested in this one,
+	// relative to Dir with a trailing slash; they are analyzed on their
+	// own.
+	Nested []string
+}
+
+// Discover returns the Go modules under root, sorted by directory.
+// Vendored, test data, hidden and unreadable directories are skipped.
+func Discover(root string) ([]*Module, error) {
+	var modules []*Module
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// An unreadable directory costs its repository, not the
+			// inventory.
+			if p == root || d == nil || !d.IsDir() {
+				return err
+			}
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			if p != root && analyzer.SkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "go.mod" {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(p)
+		modules = append(modules, &Module{Dir: dir, Path: modulePath(data), Repo: repoRoot(dir)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+	for _, m := range modules {
+		for _, other := range modules {
+			if rel, err := filepath.Rel(m.Dir, other.Dir); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+				m.Nested = append(m.Nested, filepath.ToSlash(rel)+"/")
+			}
+		}
+	}
+	return modules, nil
+}
+
+// modulePath returns the module path declared by a go.mod file.
+func modulePath(gomod []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(gomod))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// repoRoot returns the nearest directory at or above dir holding a .git
+// directory or file, or dir.
+func repoRoot(dir string) string {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// Row is one endpoint of the inventory.
+type Row struct {
+	Repo    string `json:"repo"`
+	Module  string `json:"module"`
+	Service string `json:"service"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	// Auth lists the security schemes the endpoint requires, or "none".
+	Auth      string `json:"auth"`
+	Stability string `json:"stability"`
+	Owner     string `json:"owner,omitempty"`
+	// DocCoverage is the percentage of the endpoint's summary, parameters
+	// and responses that are documented.
+	DocCoverage int `json:"docCoverage"`
+	// Source is the registration of the endpoint, relative to the
+	// repository.
+	Source      string `json:"source,omitempty"`
+	LastCommit  string `json:"lastCommit,omitempty"`
+	LastChanged string `json:"lastChanged,omitempty"`
+}
+
+// Failure records a module whose analysis failed.
+type Failure struct {
+	Repo   string `json:"repo"`
+	Module string `json:"module"`
+	Error  string `json:"error"`
+}
+
+// Inventory is the consolidated inventory of all modules.
+type Inventory struct {
+	Endpoints []*Row     `json:"endpoints"`
+	Failures  []*Failure `json:"failures,omitempty"`
+}
+
+// Stability values.
+const (
+	Stable       = "stable"
+	Experimental = "experimental"
+	Deprecated   = "deprecated"
+)
+
+// experimentalSegments are path segments marking endpoints that are not
+// stable yet.
+var experimentalSegments = map[string]bool{"alpha": true, "beta": true, "experimental": true, "preview": true, "unstable": true}
+
+// Rows returns the rows of the endpoints of doc, the analysis of module m.
+// root is the inventory root repositories are named relative to.
+func Rows(root string, m *Module, doc *model.Document, owners *Owners, history *History) []*Row {
+	repo := relName(root, m.Repo)
+	var rows []*Row
+	for _, ep := range doc.Endpoints {
+		row := &Row{
+			Repo:        repo,
+			Module:      m.Path,
+			Service:     doc.Service,
+			Method:      ep.Method,
+			Path:        ep.Path,
+			Auth:        "none",
+			Stability:   stability(ep),
+			DocCoverage: Coverage(ep),
+		}
+		if len(ep.Security) > 0 {
+			row.Auth = strings.Join(ep.Security, " ")
+		}
+		if ep.Source != nil && ep.Source.File != "" {
+			file := filepath.ToSlash(filepath.Join(relName(m.Repo, m.Dir), ep.Source.File))
+			row.Source = file + ":" + strconv.Itoa(ep.Source.Line)
+			row.Owner = strings.Join(owners.Match(file), " ")
+			row.LastCommit, row.LastChanged = history.LastChange(m.Repo, file)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// relName returns target relative to base with forward slashes, "." for
+// base itself.
+func relName(base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return filepath.ToSlash(target)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func stability(ep *model.Endpoint) string {
+	if ep.Deprecated {
+		return Deprecated
+	}
+	for _, seg := range strings.Split(strings.ToLower(ep.Path), "/") {
+		if experimentalSegments[seg] {
+			return Experimental
+		}
+	}
+	for _, tag := range ep.Tags {
+		if experimentalSegments[strings.ToLower(tag)] {
+			return Experimental
+		}
+	}
+	return Stable
+}
+
+// Coverage returns the percentage of the documentable parts of an
+// endpoint that are documented: its summary, the description of each
+// parameter, and the description of each response beyond its status text.
+func Coverage(ep *model.Endpoint) int {
+	total, documented := 1, 0
+	if ep.Summary != "" {
+		documented++
+	}
+	for _, p := range ep.Parameters {
+		total++
+		if p.Description != "" {
+			documented++
+		}
+	}
+	for _, r := range ep.Responses {
+		total++
+		code, _ := strconv.Atoi(r.StatusCode)
+		if r.Description != "" && r.Description != http.StatusText(code) && r.Description != "Successful response" {
+			documented++
+		}
+	}
+	return documented * 100 / total
+}
+
+// Owners holds the rules of a CODEOWNERS file.
+type Owners struct {
+	rules []ownerRule
+}
+
+type ownerRule struct {
+	re     *regexp.Regexp
+	owners []string
+}
+
+// codeownersFiles are the places GitHub looks for CODEOWNERS, in order.
+var codeownersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// LoadOwners reads the CODEOWNERS file of a repository. A repository
+// without one has no owners.
+func LoadOwners(repo string) (*Owners, error) {
+	for _, name := range codeownersFiles {
+		data, err := os.ReadFile(filepath.Join(repo, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ParseOwners(data), nil
+	}
+	return &Owners{}, nil
+}
+
+// ParseOwners parses CODEOWNERS rules: a gitignore-style pattern followed
+// by owners on each line.
+func ParseOwners(data []byte) *Owners {
+	o := &Owners{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		o.rules = append(o.rules, ownerRule{re: ownerPattern(fields[0]), owners: fields[1:]})
+	}
+	return o
+}
+
+// ownerPattern compiles a CODEOWNERS pattern matching repository paths.
+// A pattern containing a slash other than a trailing one is anchored at
+// the repository root; one naming a directory matches everything below,
+// but dir/* only the files directly in dir.
+func ownerPattern(pat string) *regexp.Regexp {
+	anchored := strings.Contains(strings.TrimSuffix(pat, "/"), "/")
+	below := !strings.HasSuffix(pat, "/*")
+	pat = strings.Trim(pat, "/")
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pat); i++ {
+		switch c := pat[i]; {
+		case strings.HasPrefix(pat[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pat[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if below {
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// Match returns the owners of a file, given relative to the repository
+// with forward slashes: those of the last rule matching it.
+func (o *Owners) Match(file string) []string {
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if o.rules[i].re.MatchString(file) {
+			return o.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// History finds the commits that last changed files, asking git once per
+// file. It is safe for concurrent use.
+type History struct {
+	mu      sync.Mutex
+	changes map[string][2]string
+}
+
+// LastChange returns the abbreviated hash and the date of the commit that
+// last changed file in repo, or empty strings outside of a repository.
+func (h *History) LastChange(repo, file string) (commit, date string) {
+	key := repo + "\x00" + file
+	h.mu.Lock()
+	c, ok := h.changes[key]
+	h.mu.Unlock()
+	if ok {
+		return c[0], c[1]
+	}
+	out, err := exec.Command("git", "-C", repo, "log", "-1", "--format=%h %cs", "--", path.Clean(file)).Output()
+	if err == nil {
+		c[0], c[1], _ = strings.Cut(strings.TrimSpace(string(out)), " ")
+	}
+	h.mu.Lock()
+	if h.changes == nil {
+		h.changes = map[string][2]string{}
+	}
+	h.changes[key] = c
+	h.mu.Unlock()
+	return c[0], c[1]
+}
+
+// csvHeader names the columns of WriteCSV.
+var csvHeader = []string{"repo", "module", "service", "method", "path", "auth", "stability", "owner", "doc_coverage", "source", "last_commit", "last_changed"}
+
+// WriteCSV writes the endpoints of the inventory as CSV with a header row.
+func (inv *Inventory) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range inv.Endpoints {
+		record := []string{r.Repo, r.Module, r.Service, r.Method, r.Path, r.Auth, r.Stability, r.Owner,
+			strconv.Itoa(r.DocCoverage), r.Source, r.LastCommit, r.LastChanged}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writing inventory: %w", err)
+	}
+	return nil
+}
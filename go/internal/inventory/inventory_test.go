@@ -0,0 +1,136 @@
+package inventory
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"billing/.git/HEAD":            "ref: refs/heads/main\n",
+		"billing/go.mod":               "module example.com/billing\n\ngo 1.19\n",
+		"billing/tools/go.mod":         "module \"example.com/billing/tools\"\n",
+		"billing/vendor/x/go.mod":      "module example.com/x\n",
+		"billing/testdata/app/go.mod":  "module example.com/app\n",
+		"users/.git/HEAD":              "ref: refs/heads/main\n",
+		"users/services/api/go.mod":    "module example.com/users/api\n",
+		"users/.cache/mod/y/go.mod":    "module example.com/y\n",
+		"users/_examples/hello/go.mod": "module example.com/hello\n",
+		"scratch/go.mod":               "module scratch\n",
+	})
+	modules, err := Discover(root)
+	require.NoError(t, err)
+	var got []string
+	for _, m := range modules {
+		got = append(got, m.Path+" "+relName(root, m.Repo))
+	}
+	assert.Equal(t, []string{
+		"example.com/billing billing",
+		"example.com/billing/tools billing",
+		"scratch scratch",
+		"example.com/users/api users",
+	}, got)
+	assert.Equal(t, []string{"tools/"}, modules[0].Nested)
+	assert.Empty(t, modules[1].Nested)
+}
+
+func TestOwners(t *testing.T) {
+	owners := ParseOwners([]byte(`# Default owners.
+*                 @platform
+/handlers/        @users-team   # the API
+docs/*            @docs
+**/billing/*.go   @billing @finance
+/cmd/api/main.go  @leads
+`))
+	for file, want := range map[string][]string{
+		"README.md":                {"@platform"},
+		"handlers/users.go":        {"@users-team"},
+		"handlers/v2/orders.go":    {"@users-team"},
+		"internal/handlers/x.go":   {"@platform"},
+		"docs/index.md":            {"@docs"},
+		"docs/api/index.md":        {"@platform"},
+		"internal/billing/pay.go":  {"@billing", "@finance"},
+		"billing/pay.go":           {"@billing", "@finance"},
+		"cmd/api/main.go":          {"@leads"},
+		"services/cmd/api/main.go": {"@platform"},
+	} {
+		assert.Equal(t, want, owners.Match(file), file)
+	}
+
+	repo := t.TempDir()
+	o, err := LoadOwners(repo)
+	require.NoError(t, err)
+	assert.Nil(t, o.Match("main.go"))
+	writeFiles(t, repo, map[string]string{".github/CODEOWNERS": "* @octo\n"})
+	o, err = LoadOwners(repo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@octo"}, o.Match("main.go"))
+}
+
+func TestRows(t *testing.T) {
+	doc := &model.Document{Service: "usersvc", Endpoints: []*model.Endpoint{
+		{
+			Method:   "GET",
+			Path:     "/users/{id}",
+			Summary:  "Get a user",
+			Security: []string{"bearerAuth"},
+			Parameters: []*model.Parameter{
+				{Name: "id", In: "path", Description: "User ID"},
+				{Name: "fields", In: "query"},
+			},
+			Responses: []*model.Response{
+				{StatusCode: "200", Description: "The user"},
+				{StatusCode: "404", Description: "Not Found"},
+			},
+			Source: &model.Position{File: "handlers/users.go", Line: 12},
+		},
+		{Method: "POST", Path: "/beta/imports", Responses: []*model.Response{{StatusCode: "202", Description: "Successful response"}}},
+		{Method: "DELETE", Path: "/users/{id}", Deprecated: true, Summary: "Delete a user"},
+	}}
+	root := t.TempDir()
+	m := &Module{Dir: filepath.Join(root, "users", "services", "api"), Path: "example.com/users/api", Repo: filepath.Join(root, "users")}
+	owners := ParseOwners([]byte("/services/api/handlers/ @users-team\n"))
+	rows := Rows(root, m, doc, owners, &History{})
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, &Row{
+		Repo:        "users",
+		Module:      "example.com/users/api",
+		Service:     "usersvc",
+		Method:      "GET",
+		Path:        "/users/{id}",
+		Auth:        "bearerAuth",
+		Stability:   Stable,
+		Owner:       "@users-team",
+		DocCoverage: 60,
+		Source:      "services/api/handlers/users.go:12",
+	}, rows[0])
+	assert.Equal(t, "none", rows[1].Auth)
+	assert.Equal(t, Experimental, rows[1].Stability)
+	assert.Equal(t, 0, rows[1].DocCoverage)
+	assert.Equal(t, Deprecated, rows[2].Stability)
+	assert.Equal(t, 100, rows[2].DocCoverage)
+
+	var buf bytes.Buffer
+	inv := &Inventory{Endpoints: rows[:1]}
+	require.NoError(t, inv.WriteCSV(&buf))
+	assert.Equal(t, "repo,module,service,method,path,auth,stability,owner,doc_coverage,source,last_commit,last_changed\n"+
+		"users,example.com/users/api,usersvc,GET,/users/{id},bearerAuth,stable,@users-team,60,services/api/handlers/users.go:12,,\n", buf.String())
+}
@@ -0,0 +1,334 @@
+// Package markdown renders parts of an API document as Markdown fragments:
+// endpoint tables, schema field tables, configuration references and
+// middleware chains.
+//
+// Fragments are GitHub Flavored Markdown without headings, so that they
+// fit at any level of the page they are placed in.
+package markdown
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/codegen"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Options configures rendering.
+type Options struct {
+	// SchemaLink returns the link target of the named schema. Schema names
+	// are rendered as code without links when it is nil or returns "".
+	SchemaLink func(name string) string
+	// EndpointLink returns the link target of an endpoint. Paths are
+	// rendered as code without links when it is nil or returns "".
+	EndpointLink func(ep *model.Endpoint) string
+	// Escape, when set, escapes prose taken from the document, such as
+	// summaries and descriptions, for the target Markdown dialect.
+	Escape func(string) string
+}
+
+// text returns prose from the document escaped as opts asks.
+func (opts Options) text(s string) string {
+	if opts.Escape == nil {
+		return s
+	}
+	return opts.Escape(s)
+}
+
+// Endpoints renders a table of endpoints with their method, path and
+// summary, in the order given.
+func Endpoints(eps []*model.Endpoint, opts Options) string {
+	if len(eps) == 0 {
+		return "_No endpoints._\n"
+	}
+	var b strings.Builder
+	b.WriteString("| Method | Path | Summary |\n| --- | --- | --- |\n")
+	for _, ep := range eps {
+		path := "`" + Cell(ep.Path) + "`"
+		if opts.EndpointLink != nil {
+			if target := opts.EndpointLink(ep); target != "" {
+				path = "[" + path + "](" + target + ")"
+			}
+		}
+		summary := Cell(opts.text(ep.Summary))
+		if ep.Deprecated {
+			summary = strings.TrimSpace("**Deprecated.** " + summary)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", ep.Method, path, summary)
+	}
+	return b.String()
+}
+
+// Schemas renders the named schemas of doc, each as its name and
+// description followed by its field table.
+func Schemas(doc *model.Document, names []string, opts Options) (string, error) {
+	var b strings.Builder
+	for i, name := range names {
+		s := doc.Schemas[name]
+		if s == nil {
+			return "", fmt.Errorf("no schema named %s", name)
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "**%s**", name)
+		if d := OneLine(opts.text(s.Description)); d != "" {
+			b.WriteString(": " + d)
+		}
+		b.WriteString("\n\n")
+		b.WriteString(SchemaTable(s, opts))
+	}
+	return b.String(), nil
+}
+
+// SchemaTable renders the fields of an object schema, or the values of an
+// enum schema, as a table.
+func SchemaTable(s *model.Schema, opts Options) string {
+	var b strings.Builder
+	switch {
+	case len(s.Enum) > 0:
+		b.WriteString("| Value | Description |\n| --- | --- |\n")
+		for i, v := range s.Enum {
+			desc := ""
+			if i < len(s.EnumDescriptions) {
+				desc = s.EnumDescriptions[i]
+			}
+			fmt.Fprintf(&b, "| `%s` | %s |\n", Cell(fmt.Sprint(v)), Cell(opts.text(desc)))
+		}
+	case len(s.Properties) > 0:
+		b.WriteString("| Field | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+		for _, name := range s.PropertyNames() {
+			p := s.Properties[name]
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", Cell(name), Type(p, opts), YesNo(s.IsRequired(name)), Cell(Description(p, opts)))
+		}
+	case len(s.OneOf) > 0:
+		fmt.Fprintf(&b, "One of %s.\n", Type(s, opts))
+	default:
+		fmt.Fprintf(&b, "Type: %s.\n", Type(s, opts))
+	}
+	return b.String()
+}
+
+// Config renders the named schema as a configuration reference: a table of
+// every leaf setting under its dotted key, following nested objects,
+// references and arrays of objects ("servers[].host").
+func Config(doc *model.Document, name string, opts Options) (string, error) {
+	s := doc.Schemas[name]
+	if s == nil {
+		return "", fmt.Errorf("no schema named %s", name)
+	}
+	var b strings.Builder
+	b.WriteString("| Key | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+	var walk func(prefix string, s *model.Schema, stack map[string]bool)
+	walk = func(prefix string, s *model.Schema, stack map[string]bool) {
+		for _, key := range s.PropertyNames() {
+			p := s.Properties[key]
+			full, obj, ref := prefix+key, resolve(doc, p), p.RefName()
+			if p.Type == "array" && p.Items != nil {
+				if items := resolve(doc, p.Items); len(items.Properties) > 0 && !stack[p.Items.RefName()] {
+					full, obj, ref = full+"[]", items, p.Items.RefName()
+				}
+			}
+			// A schema nested in itself is not expanded again.
+			if len(obj.Properties) > 0 && !stack[ref] {
+				if ref != "" {
+					stack[ref] = true
+				}
+				walk(full+".", obj, stack)
+				delete(stack, ref)
+				continue
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", Cell(full), Type(p, opts), YesNo(s.IsRequired(key)), Cell(Description(p, opts)))
+		}
+	}
+	walk("", s, map[string]bool{name: true})
+	return b.String(), nil
+}
+
+// Middleware renders the middleware catalog of doc as a table of every
+// middleware with its kind, effects and description.
+func Middleware(doc *model.Document, opts Options) string {
+	if len(doc.Middleware) == 0 {
+		return "_No middleware._\n"
+	}
+	names := make([]string, 0, len(doc.Middleware))
+	for name := range doc.Middleware {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("| Middleware | Kind | Effects | Description |\n| --- | --- | --- | --- |\n")
+	for _, name := range names {
+		m := doc.Middleware[name]
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", Cell(name), m.Kind, Cell(Effects(m)), Cell(opts.text(m.Description)))
+	}
+	return b.String()
+}
+
+// Chain renders the middleware chain of an endpoint as a numbered list in
+// the order requests go through it, each middleware with its effects.
+func Chain(doc *model.Document, ep *model.Endpoint) string {
+	if len(ep.Middleware) == 0 {
+		return "_No middleware._\n"
+	}
+	var b strings.Builder
+	for i, name := range ep.Middleware {
+		fmt.Fprintf(&b, "%d. `%s`", i+1, name)
+		if m := doc.Middleware[name]; m != nil {
+			if effects := Effects(m); effects != "" {
+				b.WriteString(": " + effects)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Effects summarizes what a middleware does, as "answers `401` or `403`;
+// sets `X-Frame-Options: DENY`; adds `user` to the request context".
+func Effects(m *model.Middleware) string {
+	var effects []string
+	if len(m.Statuses) > 0 {
+		codes := make([]string, len(m.Statuses))
+		for i, code := range m.Statuses {
+			codes[i] = fmt.Sprintf("`%d`", code)
+		}
+		effects = append(effects, "answers "+orList(codes))
+	}
+	if len(m.Headers) > 0 {
+		names := make([]string, 0, len(m.Headers))
+		for name := range m.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			if v := m.Headers[name]; v != "" {
+				name += ": " + v
+			}
+			names[i] = "`" + name + "`"
+		}
+		effects = append(effects, "sets "+strings.Join(names, ", "))
+	}
+	if len(m.ContextValues) > 0 {
+		keys := make([]string, len(m.ContextValues))
+		for i, k := range m.ContextValues {
+			keys[i] = "`" + k + "`"
+		}
+		effects = append(effects, "adds "+strings.Join(keys, ", ")+" to the request context")
+	}
+	if m.Security != "" {
+		effects = append(effects, "authenticates with `"+m.Security+"`")
+	}
+	if rl := m.RateLimit; rl != nil {
+		effects = append(effects, fmt.Sprintf("allows %d requests per %s", rl.Requests, rl.Period))
+	}
+	return strings.Join(effects, "; ")
+}
+
+// orList joins items as "a, b or c".
+func orList(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " or " + items[len(items)-1]
+}
+
+// resolve follows a reference to a component schema of doc.
+func resolve(doc *model.Document, s *model.Schema) *model.Schema {
+	if name := s.RefName(); name != "" && doc.Schemas[name] != nil {
+		return doc.Schemas[name]
+	}
+	return s
+}
+
+// Type renders the type of a schema: the name of a referenced schema,
+// "T[]" for arrays, "map[string]T" for maps, alternatives joined by "|",
+// and JSON types with their format, such as "string (date-time)".
+func Type(s *model.Schema, opts Options) string {
+	if s == nil {
+		return "any"
+	}
+	if name := s.RefName(); name != "" {
+		text := "`" + name + "`"
+		if opts.SchemaLink != nil {
+			if target := opts.SchemaLink(name); target != "" {
+				text = "[" + text + "](" + target + ")"
+			}
+		}
+		return text
+	}
+	var t string
+	switch {
+	case len(s.OneOf) > 0:
+		alts := make([]string, len(s.OneOf))
+		for i, v := range s.OneOf {
+			alts[i] = Type(v, opts)
+		}
+		t = strings.Join(alts, ` \| `)
+	case s.Type == "array":
+		t = Type(s.Items, opts) + "[]"
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		t = "map[string]" + Type(s.AdditionalProperties, opts)
+	case s.Type == "":
+		t = "any"
+	default:
+		t = s.Type
+		if s.Format != "" {
+			t += " (" + s.Format + ")"
+		}
+	}
+	if s.Nullable {
+		t += ", nullable"
+	}
+	return t
+}
+
+// Description returns the description of a field schema, followed by
+// its allowed values when it is an enum.
+func Description(s *model.Schema, opts Options) string {
+	d := OneLine(opts.text(s.Description))
+	if len(s.Enum) > 0 {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = "`" + fmt.Sprint(v) + "`"
+		}
+		d = strings.TrimSpace(d + " One of " + strings.Join(values, ", ") + ".")
+	}
+	return d
+}
+
+// Cell escapes text for a table cell.
+func Cell(s string) string {
+	return strings.ReplaceAll(OneLine(s), "|", `\|`)
+}
+
+// OneLine joins the lines of s with spaces.
+func OneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// YesNo renders a flag in a table cell.
+func YesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// Slug returns the lower kebab-case form of a name for file names and
+// anchors, splitting camel case: "getUserByID" becomes "get-user-by-id".
+// It is empty when the name has no letters or digits.
+func Slug(s string) string {
+	return strings.ToLower(strings.Join(codegen.Words(s), "-"))
+}
+
+// SortedSchemaNames returns the names of the schemas of doc in order.
+func SortedSchemaNames(doc *model.Document) []string {
+	names := make([]string, 0, len(doc.Schemas))
+	for name := range doc.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,103 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func TestType(t *testing.T) {
+	link := Options{SchemaLink: func(name string) string { return "schemas/" + name + ".md" }}
+	for _, tc := range []struct {
+		schema *model.Schema
+		opts   Options
+		want   string
+	}{
+		{nil, Options{}, "any"},
+		{&model.Schema{Type: "integer", Format: "int64"}, Options{}, "integer (int64)"},
+		{model.RefTo("User"), Options{}, "`User`"},
+		{model.RefTo("User"), link, "[`User`](schemas/User.md)"},
+		{&model.Schema{Type: "array", Items: model.RefTo("User")}, Options{}, "`User`[]"},
+		{&model.Schema{Type: "object", AdditionalProperties: &model.Schema{Type: "string"}}, Options{}, "map[string]string"},
+		{&model.Schema{OneOf: []*model.Schema{model.RefTo("Cat"), model.RefTo("Dog")}}, Options{}, "`Cat` \\| `Dog`"},
+		{&model.Schema{Type: "string", Nullable: true}, Options{}, "string, nullable"},
+	} {
+		assert.Equal(t, tc.want, Type(tc.schema, tc.opts))
+	}
+}
+
+func TestConfig(t *testing.T) {
+	doc := &model.Document{Schemas: map[string]*model.Schema{
+		"Config": {
+			Type: "object",
+			Properties: map[string]*model.Schema{
+				"listen":  {Type: "string", Description: "Address to listen on."},
+				"db":      model.RefTo("Database"),
+				"servers": {Type: "array", Items: model.RefTo("Server")},
+				"log":     {Type: "object", Properties: map[string]*model.Schema{"level": {Type: "string", Enum: []any{"debug", "info"}}}},
+			},
+			PropertyOrder: []string{"listen", "db", "servers", "log"},
+			Required:      []string{"listen"},
+		},
+		"Database": {
+			Type:          "object",
+			Properties:    map[string]*model.Schema{"url": {Type: "string", Description: "Connection\nstring."}, "replica": model.RefTo("Database")},
+			PropertyOrder: []string{"url", "replica"},
+			Required:      []string{"url"},
+		},
+		"Server": {Type: "object", Properties: map[string]*model.Schema{"host": {Type: "string"}}},
+	}}
+
+	got, err := Config(doc, "Config", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "| Key | Type | Required | Description |\n| --- | --- | --- | --- |\n"+
+		"| `listen` | string | yes | Address to listen on. |\n"+
+		"| `db.url` | string | yes | Connection string. |\n"+
+		"| `db.replica` | `Database` | no |  |\n"+
+		"| `servers[].host` | string | no |  |\n"+
+		"| `log.level` | string | no | One of `debug`, `info`. |\n", got)
+
+	_, err = Config(doc, "Missing", Options{})
+	assert.EqualError(t, err, "no schema named Missing")
+}
+
+func TestMiddleware(t *testing.T) {
+	doc := &model.Document{Middleware: map[string]*model.Middleware{
+		"auth.Require": {
+			Kind:          "net/http",
+			Description:   "Require rejects requests\nwithout a session.",
+			Statuses:      []int{401, 403},
+			ContextValues: []string{"userKey{}"},
+			Security:      "cookieAuth",
+		},
+		"secure.Headers": {Kind: "gin", Headers: map[string]string{"X-Request-Id": "", "X-Frame-Options": "DENY"}},
+		"httprate.LimitByIP": {
+			RateLimit: &model.RateLimit{Requests: 100, Period: model.PeriodMinute},
+		},
+	}}
+	assert.Equal(t, "| Middleware | Kind | Effects | Description |\n| --- | --- | --- | --- |\n"+
+		"| `auth.Require` | net/http | answers `401` or `403`; adds `userKey{}` to the request context; authenticates with `cookieAuth` | Require rejects requests without a session. |\n"+
+		"| `httprate.LimitByIP` |  | allows 100 requests per minute |  |\n"+
+		"| `secure.Headers` | gin | sets `X-Frame-Options: DENY`, `X-Request-Id` |  |\n", Middleware(doc, Options{}))
+	assert.Equal(t, "_No middleware._\n", Middleware(&model.Document{}, Options{}))
+
+	ep := &model.Endpoint{Method: "GET", Path: "/me", Middleware: []string{"secure.Headers", "middleware.Logger", "auth.Require"}}
+	assert.Equal(t, "1. `secure.Headers`: sets `X-Frame-Options: DENY`, `X-Request-Id`\n"+
+		"2. `middleware.Logger`\n"+
+		"3. `auth.Require`: answers `401` or `403`; adds `userKey{}` to the request context; authenticates with `cookieAuth`\n", Chain(doc, ep))
+}
+
+func TestSlug(t *testing.T) {
+	for in, want := range map[string]string{
+		"getUserByID":     "get-user-by-id",
+		"HTTPServer":      "http-server",
+		"GET /users/{id}": "get-users-id",
+		"user_v2":         "user-v2",
+		"{}":              "",
+	} {
+		assert.Equal(t, want, Slug(in), in)
+	}
+}
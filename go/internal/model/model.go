@@ -0,0 +1,522 @@
+// Package model defines the API document produced by the Go parser component.
+//
+// The shapes mirror the TypeScript models in src/core/models/api-spec.ts and
+// src/core/models/schema.ts so that the Node pipeline can consume the output
+// of `api-doc-gen-go parse` without translation.
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaRefPrefix is the prefix used for references between schemas.
+const SchemaRefPrefix = "#/components/schemas/"
+
+// Document is the complete result of analyzing a Go source tree.
+type Document struct {
+	// Module is the module path from go.mod, if one was found.
+	Module string `json:"module,omitempty" yaml:"module,omitempty"`
+	// Service is the name of the binary that serves the API.
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+	// Packages lists the analyzed packages in import path order.
+	Packages []*Package `json:"packages,omitempty" yaml:"packages,omitempty"`
+	// Routers lists the functions that build the application's router.
+	Routers []*Router `json:"routers,omitempty" yaml:"routers,omitempty"`
+	// Endpoints lists the extracted HTTP endpoints sorted by path and method.
+	Endpoints []*Endpoint `json:"endpoints" yaml:"endpoints"`
+	// Schemas holds the data models keyed by schema name.
+	Schemas map[string]*Schema `json:"schemas" yaml:"schemas"`
+	// SecuritySchemes holds the authentication schemes keyed by name.
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+	// Middleware catalogs the middleware in the chains of the endpoints,
+	// keyed by the names Endpoint.Middleware lists.
+	Middleware map[string]*Middleware `json:"x-middleware,omitempty" yaml:"x-middleware,omitempty"`
+	// Diagnostics collects problems found during analysis.
+	Diagnostics []*Diagnostic `json:"diagnostics,omitempty" yaml:"diagnostics,omitempty"`
+}
+
+// Package describes one analyzed Go package.
+type Package struct {
+	Name        string   `json:"name" yaml:"name"`
+	ImportPath  string   `json:"importPath" yaml:"importPath"`
+	Dir         string   `json:"dir" yaml:"dir"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Files       []string `json:"files" yaml:"files"`
+	// Assets are the files embedded with //go:embed directives.
+	Assets []*Asset `json:"x-embed-assets,omitempty" yaml:"x-embed-assets,omitempty"`
+	// Limitations lists the constructs of the package the analysis does
+	// not see through, such as cgo and assembly.
+	Limitations []*Limitation `json:"x-analysis-limitations,omitempty" yaml:"x-analysis-limitations,omitempty"`
+	// GoMinVersion is the oldest Go release that builds the package, as
+	// "1.21": the go directive of its module or the newest language
+	// feature or standard library API it uses, whichever is later.
+	GoMinVersion string `json:"x-go-min-version,omitempty" yaml:"x-go-min-version,omitempty"`
+	// GoRequirements lists, for the exported symbols of the package and
+	// for its unexported code, the features that need the newest release.
+	GoRequirements []*GoRequirement `json:"x-go-requirements,omitempty" yaml:"x-go-requirements,omitempty"`
+}
+
+// GoRequirement is a language feature or standard library API that needs
+// a Go release.
+type GoRequirement struct {
+	// Symbol is the exported declaration using the feature, as Client or
+	// Client.Do, or empty for unexported code and imports.
+	Symbol  string `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+	Version string `json:"version" yaml:"version"`
+	// Feature names the language feature, as "type parameters", or the
+	// API, as "slices.Contains".
+	Feature string    `json:"feature" yaml:"feature"`
+	Pos     *Position `json:"pos,omitempty" yaml:"pos,omitempty"`
+}
+
+// Asset is a variable holding files embedded with a //go:embed
+// directive, typically served as static assets.
+type Asset struct {
+	Var string `json:"var" yaml:"var"`
+	// Type is the type of the variable: string, []byte or embed.FS.
+	Type        string    `json:"type" yaml:"type"`
+	Patterns    []string  `json:"patterns" yaml:"patterns"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Source      *Position `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// Limitation constructs.
+const (
+	LimitationCgo      = "cgo"
+	LimitationAssembly = "assembly"
+	LimitationLinkname = "linkname"
+	LimitationNoBody   = "external-function"
+	LimitationSyso     = "syso"
+)
+
+// Limitation is a construct of a package the analysis does not see
+// through. What it declares is documented as far as its Go declarations
+// tell.
+type Limitation struct {
+	Construct string    `json:"construct" yaml:"construct"`
+	Detail    string    `json:"detail" yaml:"detail"`
+	Pos       *Position `json:"pos,omitempty" yaml:"pos,omitempty"`
+}
+
+// Router is a function taking no arguments that returns the router, or an
+// http.Handler wrapping it, on which endpoints are registered. Generated
+// tests call it to serve requests.
+type Router struct {
+	// Func is the function name.
+	Func string `json:"func" yaml:"func"`
+	// Package and ImportPath identify the package declaring the function.
+	Package    string `json:"package" yaml:"package"`
+	ImportPath string `json:"importPath" yaml:"importPath"`
+	// Dir is the package directory relative to the analyzed root.
+	Dir string `json:"dir" yaml:"dir"`
+	// Endpoints counts the endpoints reachable through the router.
+	Endpoints int `json:"endpoints" yaml:"endpoints"`
+}
+
+// Endpoint is a single HTTP operation. Sunset is the date, as 2006-01-02,
+// after which a deprecated endpoint is removed. IdempotencyKey, Conditional,
+// MaxBodyBytes and Ranges record the HTTP semantics its handler implements:
+// the header that makes retries safe, the precondition headers it honors,
+// its request body size limit and whether it serves byte ranges.
+type Endpoint struct {
+	ID             string         `json:"id" yaml:"id"`
+	Method         string         `json:"method" yaml:"method"`
+	Path           string         `json:"path" yaml:"path"`
+	OperationID    string         `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary        string         `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description    string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags           []string       `json:"tags" yaml:"tags"`
+	Parameters     []*Parameter   `json:"parameters" yaml:"parameters"`
+	RequestBody    *RequestBody   `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses      []*Response    `json:"responses" yaml:"responses"`
+	Security       []string       `json:"security,omitempty" yaml:"security,omitempty"`
+	Middleware     []string       `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+	RateLimit      *RateLimit     `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	IdempotencyKey string         `json:"x-idempotency-key,omitempty" yaml:"x-idempotency-key,omitempty"`
+	Conditional    []string       `json:"x-conditional-requests,omitempty" yaml:"x-conditional-requests,omitempty"`
+	MaxBodyBytes   int64          `json:"x-max-body-bytes,omitempty" yaml:"x-max-body-bytes,omitempty"`
+	Ranges         bool           `json:"x-range-requests,omitempty" yaml:"x-range-requests,omitempty"`
+	LongRunning    *LongRunning   `json:"x-long-running,omitempty" yaml:"x-long-running,omitempty"`
+	Deprecated     bool           `json:"deprecated" yaml:"deprecated"`
+	Sunset         string         `json:"x-sunset,omitempty" yaml:"x-sunset,omitempty"`
+	Handler        string         `json:"handler,omitempty" yaml:"handler,omitempty"`
+	Framework      string         `json:"framework,omitempty" yaml:"framework,omitempty"`
+	Source         *Position      `json:"sourceLocation,omitempty" yaml:"sourceLocation,omitempty"`
+	Extensions     map[string]any `json:"extensions,omitempty" yaml:"extensions,omitempty"`
+}
+
+// Key returns the "METHOD /path" form used to identify an endpoint.
+func (e *Endpoint) Key() string {
+	return e.Method + " " + e.Path
+}
+
+// DateLayout is the layout of dates such as Endpoint.Sunset.
+const DateLayout = "2006-01-02"
+
+// LongRunning describes an endpoint starting an operation that completes
+// asynchronously: it answers 202 Accepted with a Location header naming a
+// status endpoint, polled until the operation reaches a final state.
+type LongRunning struct {
+	// Location is the path template of the Location header.
+	Location string `json:"location" yaml:"location"`
+	// StatusEndpoint is the "METHOD /path" key of the status endpoint.
+	StatusEndpoint string `json:"statusEndpoint,omitempty" yaml:"statusEndpoint,omitempty"`
+	// Schema references the operation resource the status endpoint returns.
+	Schema *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	// StateProperty names the property of Schema holding the operation
+	// state, one of States.
+	StateProperty string   `json:"stateProperty,omitempty" yaml:"stateProperty,omitempty"`
+	States        []string `json:"states,omitempty" yaml:"states,omitempty"`
+}
+
+// Parameter is a path, query, header or cookie parameter.
+type Parameter struct {
+	Name        string  `json:"name" yaml:"name"`
+	In          string  `json:"in" yaml:"in"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool    `json:"required" yaml:"required"`
+	Schema      *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example     any     `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+// RequestBody describes the payload accepted by an endpoint.
+type RequestBody struct {
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	ContentType string  `json:"contentType" yaml:"contentType"`
+	Required    bool    `json:"required" yaml:"required"`
+	Schema      *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example     any     `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+// Response describes one status code returned by an endpoint.
+type Response struct {
+	StatusCode  string            `json:"statusCode" yaml:"statusCode"`
+	Description string            `json:"description" yaml:"description"`
+	ContentType string            `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Schema      *Schema           `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example     any               `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+// RateLimit is a request quota detected from rate limiting middleware.
+type RateLimit struct {
+	Requests int    `json:"requests" yaml:"requests"`
+	Period   string `json:"period" yaml:"period"`
+	Source   string `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// Rate limit periods understood by the gateway generators.
+const (
+	PeriodSecond = "second"
+	PeriodMinute = "minute"
+	PeriodHour   = "hour"
+	PeriodDay    = "day"
+)
+
+// SecurityScheme describes how clients authenticate, following OpenAPI.
+type SecurityScheme struct {
+	Type         string `json:"type" yaml:"type"`
+	Scheme       string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty" yaml:"in,omitempty"`
+	Name         string `json:"name,omitempty" yaml:"name,omitempty"`
+	Description  string `json:"description,omitempty" yaml:"description,omitempty"`
+	Middleware   string `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+}
+
+// Security scheme types.
+const (
+	SecurityHTTP   = "http"
+	SecurityAPIKey = "apiKey"
+	SecurityOAuth2 = "oauth2"
+)
+
+// Middleware is a function wrapping the handlers of a route, and the
+// effects analysis found it to have on the requests it sees.
+type Middleware struct {
+	// Kind is the router framework whose middleware signature it has:
+	// "net/http" for func(http.Handler) http.Handler, "gin" for
+	// gin.HandlerFunc and "echo" for echo.MiddlewareFunc. It is empty
+	// when the middleware is not declared in the analyzed sources.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Func is the declaration implementing it, a middleware or a function
+	// returning one.
+	Func        string `json:"func,omitempty" yaml:"func,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Headers maps the response headers it sets to their value, empty
+	// when the value is computed.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Statuses lists the status codes it answers with itself, ending the
+	// chain before the handler runs.
+	Statuses []int `json:"statuses,omitempty" yaml:"statuses,omitempty"`
+	// ContextValues lists the keys of the values it adds to the request
+	// context for the handlers after it.
+	ContextValues []string   `json:"contextValues,omitempty" yaml:"contextValues,omitempty"`
+	Security      string     `json:"security,omitempty" yaml:"security,omitempty"`
+	RateLimit     *RateLimit `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	Source        *Position  `json:"sourceLocation,omitempty" yaml:"sourceLocation,omitempty"`
+}
+
+// Schema is a JSON Schema subset describing a Go type.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	PropertyOrder        []string           `json:"x-order,omitempty" yaml:"x-order,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
+	EnumVarNames         []string           `json:"x-enum-varnames,omitempty" yaml:"x-enum-varnames,omitempty"`
+	EnumDescriptions     []string           `json:"x-enum-descriptions,omitempty" yaml:"x-enum-descriptions,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Example              any                `json:"example,omitempty" yaml:"example,omitempty"`
+	GoType               string             `json:"x-go-type,omitempty" yaml:"x-go-type,omitempty"`
+	Source               *Position          `json:"x-source,omitempty" yaml:"x-source,omitempty"`
+}
+
+// RefName returns the schema name a $ref points to, or "" for inline schemas.
+func (s *Schema) RefName() string {
+	if s == nil || !strings.HasPrefix(s.Ref, SchemaRefPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(s.Ref, SchemaRefPrefix)
+}
+
+// RefTo returns a schema referencing the named component schema.
+func RefTo(name string) *Schema {
+	return &Schema{Ref: SchemaRefPrefix + name}
+}
+
+// Walk calls fn for s and every schema nested in it, parents first.
+func (s *Schema) Walk(fn func(*Schema)) {
+	if s == nil {
+		return
+	}
+	fn(s)
+	for _, name := range s.PropertyNames() {
+		s.Properties[name].Walk(fn)
+	}
+	s.Items.Walk(fn)
+	s.AdditionalProperties.Walk(fn)
+	for _, v := range s.OneOf {
+		v.Walk(fn)
+	}
+}
+
+// PropertyNames returns the property names in declaration order, falling
+// back to sorted order for schemas that were not built from Go structs.
+func (s *Schema) PropertyNames() []string {
+	if len(s.PropertyOrder) == len(s.Properties) {
+		return s.PropertyOrder
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRequired reports whether the named property is required.
+func (s *Schema) IsRequired(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Position is a location in a source file.
+type Position struct {
+	File   string `json:"file" yaml:"file"`
+	Line   int    `json:"line" yaml:"line"`
+	Column int    `json:"column,omitempty" yaml:"column,omitempty"`
+}
+
+func (p *Position) String() string {
+	if p == nil {
+		return "-"
+	}
+	if p.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+// Diagnostic severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Diagnostic is a problem or note reported during analysis or validation.
+type Diagnostic struct {
+	Code     string    `json:"code" yaml:"code"`
+	Severity string    `json:"severity" yaml:"severity"`
+	Message  string    `json:"message" yaml:"message"`
+	Pos      *Position `json:"position,omitempty" yaml:"position,omitempty"`
+	// Suggestion is replacement text for the problem, if one is known.
+	Suggestion string `json:"suggestion,omitempty" yaml:"suggestion,omitempty"`
+	// Fix lists the source edits that apply the suggestion.
+	Fix []*Edit `json:"fix,omitempty" yaml:"fix,omitempty"`
+}
+
+// Edit replaces Old, found at byte Offset of File, with New. File is
+// relative to the analyzed root.
+type Edit struct {
+	File   string `json:"file" yaml:"file"`
+	Offset int    `json:"offset" yaml:"offset"`
+	Old    string `json:"old" yaml:"old"`
+	New    string `json:"new" yaml:"new"`
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", d.Pos, d.Severity, d.Message, d.Code)
+}
+
+// Schema returns the named schema, or nil.
+func (d *Document) Schema(name string) *Schema {
+	return d.Schemas[name]
+}
+
+// Resolve follows a $ref to its component schema. Inline schemas are
+// returned unchanged.
+func (d *Document) Resolve(s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 16; i++ {
+		target := d.Schemas[s.RefName()]
+		if target == nil {
+			return s
+		}
+		s = target
+	}
+	return s
+}
+
+// WalkSchemas calls fn for every schema of the document: the component
+// schemas, the schemas of parameters, request bodies, responses and
+// long-running operations, and the schemas nested in them. A schema shared
+// by several of them is visited once.
+func (d *Document) WalkSchemas(fn func(*Schema)) {
+	seen := map[*Schema]bool{}
+	visit := func(s *Schema) {
+		s.Walk(func(s *Schema) {
+			if !seen[s] {
+				seen[s] = true
+				fn(s)
+			}
+		})
+	}
+	for _, name := range d.SchemaNames() {
+		visit(d.Schemas[name])
+	}
+	for _, ep := range d.Endpoints {
+		for _, p := range ep.Parameters {
+			visit(p.Schema)
+		}
+		if ep.RequestBody != nil {
+			visit(ep.RequestBody.Schema)
+		}
+		for _, r := range ep.Responses {
+			visit(r.Schema)
+		}
+		if ep.LongRunning != nil {
+			visit(ep.LongRunning.Schema)
+		}
+	}
+}
+
+// RenameSchemas renames component schemas, and the references to them,
+// by the old names in names. Schemas not in names keep their names.
+func (d *Document) RenameSchemas(names map[string]string) {
+	d.WalkSchemas(func(s *Schema) {
+		if to, ok := names[s.RefName()]; ok {
+			s.Ref = SchemaRefPrefix + to
+		}
+	})
+	renamed := make(map[string]*Schema, len(d.Schemas))
+	for name, s := range d.Schemas {
+		if to, ok := names[name]; ok {
+			name = to
+		}
+		renamed[name] = s
+	}
+	d.Schemas = renamed
+}
+
+// SchemaNames returns the schema names in sorted order.
+func (d *Document) SchemaNames() []string {
+	names := make([]string, 0, len(d.Schemas))
+	for name := range d.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tags returns the distinct endpoint tags in sorted order.
+func (d *Document) Tags() []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, ep := range d.Endpoints {
+		for _, t := range ep.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// SortEndpoints orders endpoints by path, then method.
+func (d *Document) SortEndpoints() {
+	sort.SliceStable(d.Endpoints, func(i, j int) bool {
+		a, b := d.Endpoints[i], d.Endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return methodRank(a.Method) < methodRank(b.Method)
+	})
+}
+
+var methodOrder = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE", "CONNECT"}
+
+func methodRank(m string) int {
+	for i, o := range methodOrder {
+		if o == m {
+			return i
+		}
+	}
+	return len(methodOrder)
+}
+
+// PathParams returns the names of the {param} segments in an endpoint path.
+func PathParams(path string) []string {
+	var names []string
+	for {
+		open := strings.IndexByte(path, '{')
+		if open < 0 {
+			return names
+		}
+		end := strings.IndexByte(path[open:], '}')
+		if end < 0 {
+			return names
+		}
+		names = append(names, path[open+1:open+end])
+		path = path[open+end+1:]
+	}
+}
@@ -0,0 +1,707 @@
+// Package modelgen generates Go model types from schemas, the reverse of
+// what the analyzer extracts.
+//
+// Object schemas become structs with json tags; optional properties are
+// tagged omitempty and, unless they are slices, maps or interfaces, are
+// pointers so that absent values are not confused with zero values.
+// Required nullable properties are pointers without omitempty, tagged
+// validate:"required". Constraints
+// the Go type cannot express are written as the swaggo struct tags the
+// analyzer reads (format, pattern, minimum, maxLength, enums, ...), and
+// descriptions become doc comments, so that analyzing the generated code
+// yields equivalent schemas.
+//
+// Enum schemas become a defined type, a const block and a String method.
+// oneOf schemas become sealed interfaces, implemented through an unexported
+// marker method by each variant, with an UnmarshalXxx function choosing
+// the first variant the JSON matches; structs holding such interfaces get
+// an UnmarshalJSON method that uses it.
+package modelgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/codegen"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Options configures the generated file.
+type Options struct {
+	// Package is the package clause of the generated file.
+	Package string
+	// Source names the input document in the generated header.
+	Source string
+}
+
+// Generate renders a Go file declaring a type for every schema.
+func Generate(schemas map[string]*model.Schema, opts Options) ([]byte, error) {
+	if !token.IsIdentifier(opts.Package) {
+		return nil, fmt.Errorf("invalid package name %q", opts.Package)
+	}
+	g := &generator{
+		schemas: schemas,
+		opts:    opts,
+		names:   map[string]string{},
+		idents:  map[string]bool{},
+		unions:  map[string]*union{},
+	}
+	for _, reserved := range reservedIdents {
+		g.idents[reserved] = true
+	}
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.names[name] = g.ident(exported(name))
+	}
+	// Structs may hold unions declared after them.
+	for _, name := range names {
+		if len(schemas[name].OneOf) > 0 {
+			g.unions[g.names[name]] = &union{name: g.names[name], marker: "is" + g.names[name]}
+		}
+	}
+	for _, name := range names {
+		g.declare(g.names[name], schemas[name])
+	}
+	return g.file()
+}
+
+type generator struct {
+	schemas map[string]*model.Schema
+	opts    Options
+	// names maps schema names to Go type names.
+	names map[string]string
+	// idents holds the package-level identifiers in use.
+	idents map[string]bool
+	// unions holds the sealed interfaces by Go type name.
+	unions map[string]*union
+	decls  []string
+	// decodeVariant is set once a union needs the decodeVariant helper.
+	decodeVariant bool
+}
+
+// union is a sealed interface generated for a oneOf schema.
+type union struct {
+	name     string
+	marker   string
+	variants []variant
+}
+
+// variant is one type implementing a union.
+type variant struct {
+	goType string
+	// required lists the keys an object variant must contain.
+	required []string
+}
+
+// declare emits the declaration of the named type for s.
+func (g *generator) declare(name string, s *model.Schema) {
+	var b strings.Builder
+	writeDoc(&b, "", s.Description)
+	switch {
+	case len(s.OneOf) > 0:
+		g.declareUnion(&b, name, s)
+	case len(s.Enum) > 0 && isScalar(s.Type):
+		g.declareEnum(&b, name, s)
+	case s.Ref != "":
+		fmt.Fprintf(&b, "type %s = %s\n", name, g.goType(s, name))
+	case isStruct(s):
+		fields := g.fields(s, name)
+		fmt.Fprintf(&b, "type %s struct {\n%s}\n", name, fields.src)
+		g.unmarshalJSON(&b, name, fields.unions)
+	default:
+		fmt.Fprintf(&b, "type %s %s\n", name, g.goType(s, name))
+	}
+	g.decls = append(g.decls, b.String())
+}
+
+// isStruct reports whether s is an object schema declared as a struct
+// rather than a map.
+func isStruct(s *model.Schema) bool {
+	return (s.Type == "object" || s.Type == "") && (len(s.Properties) > 0 || s.Type == "object" && s.AdditionalProperties == nil)
+}
+
+func isScalar(typ string) bool {
+	return typ == "string" || typ == "integer" || typ == "number" || typ == "boolean"
+}
+
+// structFields is the body of a struct and the properties holding unions.
+type structFields struct {
+	src    string
+	unions []unionField
+}
+
+// unionField is a struct field whose value, or element values, are a
+// union.
+type unionField struct {
+	goName, jsonName string
+	union            *union
+	// shape is "", "[]" or "map" for a union, a slice or a map of unions.
+	shape string
+}
+
+// fields renders the fields of an object schema; owner names inline types
+// hoisted out of it.
+func (g *generator) fields(s *model.Schema, owner string) structFields {
+	var out structFields
+	var b strings.Builder
+	used := map[string]bool{}
+	for _, prop := range s.PropertyNames() {
+		ps := s.Properties[prop]
+		goName := fieldName(prop)
+		for i := 2; used[goName]; i++ {
+			goName = fmt.Sprintf("%s%d", fieldName(prop), i)
+		}
+		used[goName] = true
+
+		typ := g.goType(ps, owner+goName)
+		required := s.IsRequired(prop)
+		if pointerable(g.resolve(ps), typ) && (!required || g.nullable(ps)) {
+			typ = "*" + typ
+		}
+		tags := []string{`json:` + strconv.Quote(jsonTag(prop, required))}
+		if ps.Ref == "" && len(ps.OneOf) == 0 {
+			tags = append(tags, constraintTags(ps, typ)...)
+		}
+		if required && strings.HasPrefix(typ, "*") {
+			// Pointers are optional unless a tag says otherwise.
+			tags = append(tags, `validate:"required"`)
+		}
+
+		if ps.Description != "" {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			writeDoc(&b, "\t", ps.Description)
+		}
+		fmt.Fprintf(&b, "\t%s %s `%s`\n", goName, typ, strings.Join(tags, " "))
+
+		if u, shape := g.unionOf(ps); u != nil {
+			out.unions = append(out.unions, unionField{goName: goName, jsonName: prop, union: u, shape: shape})
+		}
+	}
+	out.src = b.String()
+	return out
+}
+
+func jsonTag(name string, required bool) string {
+	if required {
+		return name
+	}
+	return name + ",omitempty"
+}
+
+// pointerable reports whether an optional or nullable value of the Go type
+// is held through a pointer; slices, maps and interfaces are nil already.
+func pointerable(s *model.Schema, goType string) bool {
+	if strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || goType == "any" {
+		return false
+	}
+	return s == nil || len(s.OneOf) == 0
+}
+
+// nullable reports whether s, or the schema it references, allows null.
+func (g *generator) nullable(s *model.Schema) bool {
+	if s.Nullable {
+		return true
+	}
+	if t := g.resolve(s); t != nil {
+		return t.Nullable
+	}
+	return false
+}
+
+// resolve follows references to the schema they name.
+func (g *generator) resolve(s *model.Schema) *model.Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 16; i++ {
+		s = g.schemas[s.RefName()]
+	}
+	return s
+}
+
+// constraintTags returns the swaggo tags recording what the Go type of an
+// inline schema does not.
+func constraintTags(s *model.Schema, goType string) []string {
+	var tags []string
+	add := func(key, value string) {
+		if !strings.Contains(value, "`") {
+			tags = append(tags, key+":"+strconv.Quote(value))
+		}
+	}
+	if s.Format != "" && s.Format != impliedFormat(strings.TrimPrefix(goType, "*")) {
+		add("format", s.Format)
+	}
+	if s.Pattern != "" {
+		add("pattern", s.Pattern)
+	}
+	if s.Minimum != nil {
+		add("minimum", strconv.FormatFloat(*s.Minimum, 'g', -1, 64))
+	}
+	if s.Maximum != nil {
+		add("maximum", strconv.FormatFloat(*s.Maximum, 'g', -1, 64))
+	}
+	if s.MinLength != nil {
+		add("minLength", strconv.Itoa(*s.MinLength))
+	}
+	if s.MaxLength != nil {
+		add("maxLength", strconv.Itoa(*s.MaxLength))
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		if joined := strings.Join(values, ","); len(strings.Split(joined, ",")) == len(values) {
+			add("enums", joined)
+		}
+	}
+	if isScalar(s.Type) && s.Example != nil {
+		switch s.Example.(type) {
+		case map[string]any, []any:
+		default:
+			add("example", fmt.Sprint(s.Example))
+		}
+	}
+	return tags
+}
+
+// impliedFormat returns the format the analyzer derives from a Go type.
+func impliedFormat(goType string) string {
+	switch goType {
+	case "time.Time":
+		return "date-time"
+	case "[]byte":
+		return "byte"
+	case "int32":
+		return "int32"
+	case "int64":
+		return "int64"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	}
+	return ""
+}
+
+// goType returns the Go type for s. Hint names the types hoisted out of
+// inline schemas that need a declaration of their own.
+func (g *generator) goType(s *model.Schema, hint string) string {
+	if s == nil {
+		return "any"
+	}
+	if ref := s.RefName(); ref != "" {
+		if name, ok := g.names[ref]; ok {
+			return name
+		}
+		return "any"
+	}
+	if len(s.OneOf) > 0 {
+		name := g.ident(hint)
+		g.declare(name, s)
+		return name
+	}
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" || s.Format == "int64" {
+			return s.Format
+		}
+		return "int"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		elem := g.goType(s.Items, hint+"Item")
+		if s.Items != nil && g.nullable(s.Items) && pointerable(g.resolve(s.Items), elem) {
+			elem = "*" + elem
+		}
+		return "[]" + elem
+	}
+	if len(s.Properties) > 0 {
+		return "struct {\n" + g.fields(s, hint).src + "}"
+	}
+	if s.AdditionalProperties != nil {
+		return "map[string]" + g.goType(s.AdditionalProperties, hint+"Value")
+	}
+	if s.Type == "object" {
+		return "map[string]any"
+	}
+	return "any"
+}
+
+// declareEnum emits a defined type with one constant per enum value.
+func (g *generator) declareEnum(b *strings.Builder, name string, s *model.Schema) {
+	base := g.goType(&model.Schema{Type: s.Type, Format: s.Format}, name)
+	if base == "time.Time" || base == "[]byte" {
+		base = "string"
+	}
+	fmt.Fprintf(b, "type %s %s\n\n", name, base)
+	fmt.Fprintf(b, "// %s values.\nconst (\n", name)
+	consts := make([]string, len(s.Enum))
+	for i, v := range s.Enum {
+		cname := ""
+		if len(s.EnumVarNames) == len(s.Enum) && token.IsIdentifier(s.EnumVarNames[i]) && ast.IsExported(s.EnumVarNames[i]) {
+			cname = s.EnumVarNames[i]
+		} else {
+			cname = name + valueName(v)
+		}
+		consts[i] = g.ident(cname)
+		if i < len(s.EnumDescriptions) && s.EnumDescriptions[i] != "" {
+			writeDoc(b, "\t", s.EnumDescriptions[i])
+		}
+		fmt.Fprintf(b, "\t%s %s = %s\n", consts[i], name, literal(v, base))
+	}
+	b.WriteString(")\n\n")
+
+	recv := receiver(name)
+	if base == "string" {
+		fmt.Fprintf(b, "// String returns the value of %s.\n", recv)
+		fmt.Fprintf(b, "func (%s %s) String() string {\n\treturn string(%s)\n}\n", recv, name, recv)
+		return
+	}
+	fmt.Fprintf(b, "// String returns the name of the constant %s is equal to.\n", recv)
+	fmt.Fprintf(b, "func (%s %s) String() string {\n\tswitch %s {\n", recv, name, recv)
+	for _, c := range consts {
+		fmt.Fprintf(b, "\tcase %s:\n\t\treturn %q\n", c, c)
+	}
+	fmt.Fprintf(b, "\t}\n\treturn fmt.Sprintf(\"%s(%%v)\", %s(%s))\n}\n", name, base, recv)
+}
+
+// valueName derives a constant name suffix from an enum value.
+func valueName(v any) string {
+	s := fmt.Sprint(v)
+	if strings.HasPrefix(s, "-") {
+		s = "Minus" + s[1:]
+	}
+	if name := exported(strings.ReplaceAll(s, ".", "_")); name != "" {
+		return name
+	}
+	return "Empty"
+}
+
+// literal renders an enum value as a constant of the base type.
+func literal(v any, base string) string {
+	switch base {
+	case "string":
+		return strconv.Quote(fmt.Sprint(v))
+	case "bool":
+		return fmt.Sprint(v == true)
+	}
+	switch n := v.(type) {
+	case int:
+		return strconv.Itoa(n)
+	case int64:
+		return strconv.FormatInt(n, 10)
+	case float64:
+		if strings.HasPrefix(base, "int") {
+			return strconv.FormatInt(int64(n), 10)
+		}
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	}
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+// declareUnion emits a sealed interface, its marker methods and the
+// function decoding it.
+func (g *generator) declareUnion(b *strings.Builder, name string, s *model.Schema) {
+	u := g.unions[name]
+	if u == nil {
+		u = &union{name: name, marker: "is" + name}
+		g.unions[name] = u
+	}
+	seen := map[string]bool{}
+	for i, alt := range g.flatten(s.OneOf, map[string]bool{}) {
+		v := variant{}
+		if ref := alt.RefName(); ref != "" {
+			v.goType = g.names[ref]
+		} else {
+			v.goType = g.ident(name + variantSuffix(alt, i))
+			g.declare(v.goType, alt)
+		}
+		if v.goType == "" || seen[v.goType] {
+			continue
+		}
+		seen[v.goType] = true
+		if t := g.resolve(alt); t != nil && isStruct(t) {
+			v.required = t.Required
+		}
+		u.variants = append(u.variants, v)
+	}
+	g.decodeVariant = true
+
+	fmt.Fprintf(b, "type %s interface {\n\t%s()\n}\n\n", name, u.marker)
+	for _, v := range u.variants {
+		fmt.Fprintf(b, "func (%s) %s() {}\n", v.goType, u.marker)
+	}
+
+	names := make([]string, len(u.variants))
+	for i, v := range u.variants {
+		names[i] = v.goType
+	}
+	list := strings.Join(names, ", ")
+	fmt.Fprintf(b, "\n// Unmarshal%s decodes a %s from JSON, choosing the first of %s\n", name, name, list)
+	b.WriteString("// that data matches. It returns nil for null.\n")
+	fmt.Fprintf(b, "func Unmarshal%s(data []byte) (%s, error) {\n", name, name)
+	b.WriteString("\tif len(data) == 0 || string(data) == \"null\" {\n\t\treturn nil, nil\n\t}\n")
+	for i, v := range u.variants {
+		args := ""
+		for _, r := range v.required {
+			args += ", " + strconv.Quote(r)
+		}
+		fmt.Fprintf(b, "\tvar v%d %s\n", i, v.goType)
+		fmt.Fprintf(b, "\tif decodeVariant(data, &v%d%s) == nil {\n\t\treturn v%d, nil\n\t}\n", i, args, i)
+	}
+	fmt.Fprintf(b, "\treturn nil, errors.New(%q)\n}\n", g.opts.Package+": value matches none of "+list)
+	g.idents["Unmarshal"+name] = true
+}
+
+// flatten returns the alternatives of a oneOf, replacing references to
+// other oneOf schemas by their alternatives.
+func (g *generator) flatten(alts []*model.Schema, seen map[string]bool) []*model.Schema {
+	var out []*model.Schema
+	for _, alt := range alts {
+		ref := alt.RefName()
+		if t := g.schemas[ref]; t != nil && len(t.OneOf) > 0 {
+			if !seen[ref] {
+				seen[ref] = true
+				out = append(out, g.flatten(t.OneOf, seen)...)
+			}
+			continue
+		}
+		out = append(out, alt)
+	}
+	return out
+}
+
+// variantSuffix names an inline union variant after its type.
+func variantSuffix(s *model.Schema, i int) string {
+	switch s.Type {
+	case "string", "integer", "number", "boolean", "array":
+		return exported(s.Type)
+	}
+	return "Variant" + strconv.Itoa(i+1)
+}
+
+// unionOf returns the union held by a property and whether the property
+// holds it directly ("") or as the elements of a slice ("[]") or map
+// ("map").
+func (g *generator) unionOf(s *model.Schema) (*union, string) {
+	u := func(s *model.Schema) *union {
+		if s == nil {
+			return nil
+		}
+		if ref := s.RefName(); ref != "" {
+			if t := g.schemas[ref]; t != nil && len(t.OneOf) > 0 {
+				return g.unions[g.names[ref]]
+			}
+			return nil
+		}
+		return nil
+	}
+	if x := u(s); x != nil {
+		return x, ""
+	}
+	switch {
+	case s.Type == "array":
+		if x := u(s.Items); x != nil {
+			return x, "[]"
+		}
+	case s.AdditionalProperties != nil && len(s.Properties) == 0:
+		if x := u(s.AdditionalProperties); x != nil {
+			return x, "map"
+		}
+	}
+	return nil, ""
+}
+
+// unmarshalJSON emits an UnmarshalJSON method decoding the union fields of
+// a struct with their UnmarshalXxx functions.
+func (g *generator) unmarshalJSON(b *strings.Builder, name string, fields []unionField) {
+	if len(fields) == 0 {
+		return
+	}
+	recv := receiver(name)
+	fmt.Fprintf(b, "\n// UnmarshalJSON decodes %s, choosing the variant of each one-of field.\n", name)
+	fmt.Fprintf(b, "func (%s *%s) UnmarshalJSON(data []byte) error {\n", recv, name)
+	fmt.Fprintf(b, "\ttype plain %s\n\tvar raw struct {\n\t\t*plain\n", name)
+	for _, f := range fields {
+		typ := "json.RawMessage"
+		switch f.shape {
+		case "[]":
+			typ = "[]json.RawMessage"
+		case "map":
+			typ = "map[string]json.RawMessage"
+		}
+		fmt.Fprintf(b, "\t\t%s %s `json:%s`\n", f.goName, typ, strconv.Quote(f.jsonName))
+	}
+	fmt.Fprintf(b, "\t}\n\traw.plain = (*plain)(%s)\n", recv)
+	b.WriteString("\tif err := json.Unmarshal(data, &raw); err != nil {\n\t\treturn err\n\t}\n\tvar err error\n")
+	for _, f := range fields {
+		field, decode := recv+"."+f.goName, "Unmarshal"+f.union.name
+		switch f.shape {
+		case "":
+			fmt.Fprintf(b, "\tif %s, err = %s(raw.%s); err != nil {\n", field, decode, f.goName)
+			fmt.Fprintf(b, "\t\treturn fmt.Errorf(\"%s: %%w\", err)\n\t}\n", f.jsonName)
+		case "[]":
+			fmt.Fprintf(b, "\tif raw.%s != nil {\n\t\t%s = make([]%s, len(raw.%s))\n\t}\n", f.goName, field, f.union.name, f.goName)
+			fmt.Fprintf(b, "\tfor i, item := range raw.%s {\n", f.goName)
+			fmt.Fprintf(b, "\t\tif %s[i], err = %s(item); err != nil {\n", field, decode)
+			fmt.Fprintf(b, "\t\t\treturn fmt.Errorf(\"%s[%%d]: %%w\", i, err)\n\t\t}\n\t}\n", f.jsonName)
+		case "map":
+			fmt.Fprintf(b, "\tif raw.%s != nil {\n\t\t%s = make(map[string]%s, len(raw.%s))\n\t}\n", f.goName, field, f.union.name, f.goName)
+			fmt.Fprintf(b, "\tfor k, item := range raw.%s {\n", f.goName)
+			fmt.Fprintf(b, "\t\tif %s[k], err = %s(item); err != nil {\n", field, decode)
+			fmt.Fprintf(b, "\t\t\treturn fmt.Errorf(\"%s[%%q]: %%w\", k, err)\n\t\t}\n\t}\n", f.jsonName)
+		}
+	}
+	b.WriteString("\treturn nil\n}\n")
+}
+
+// receiver returns the receiver name for methods of the named type.
+func receiver(name string) string {
+	r := strings.ToLower(name[:1])
+	if r == "b" || r == "i" || r == "k" {
+		// Avoid shadowing the loop variables of generated methods.
+		return "x"
+	}
+	return r
+}
+
+// writeDoc writes text as a comment, one comment line per line of text.
+func writeDoc(b *strings.Builder, indent, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			fmt.Fprintf(b, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(b, "%s// %s\n", indent, line)
+	}
+}
+
+// reservedIdents are package-level names used by the generated code.
+var reservedIdents = []string{"decodeVariant", "plain", "raw", "err", "data", "item"}
+
+// ident returns a unique package-level identifier based on name.
+func (g *generator) ident(name string) string {
+	if name == "" {
+		name = "Model"
+	}
+	if !unicode.IsLetter([]rune(name)[0]) {
+		name = "T" + name
+	}
+	id := name
+	for i := 2; g.idents[id]; i++ {
+		id = fmt.Sprintf("%s%d", name, i)
+	}
+	g.idents[id] = true
+	return id
+}
+
+// initialisms are written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "JWT": true, "OS": true, "QPS": true, "RAM": true,
+	"RPC": true, "SKU": true, "SLA": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "URI": true,
+	"URL": true, "UTF8": true, "UUID": true, "VM": true, "XML": true,
+}
+
+// exported converts a schema or property name to an exported identifier.
+func exported(s string) string {
+	var b strings.Builder
+	for _, w := range codegen.Words(s) {
+		if up := strings.ToUpper(w); initialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		r := []rune(w)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	return b.String()
+}
+
+// fieldName returns the struct field name for a JSON property.
+func fieldName(prop string) string {
+	name := exported(prop)
+	if name == "" {
+		return "Field"
+	}
+	if !unicode.IsLetter([]rune(name)[0]) {
+		return "F" + name
+	}
+	return name
+}
+
+// imports are the packages the generated code may use.
+var imports = []string{
+	"bytes",
+	"encoding/json",
+	"errors",
+	"fmt",
+	"time",
+}
+
+// decodeVariantSource decodes a union variant strictly, so that the
+// first matching variant can be chosen.
+const decodeVariantSource = `// decodeVariant decodes data into v, rejecting unknown fields and objects
+// missing one of the required keys.
+func decodeVariant(data []byte, v any, required ...string) error {
+	if len(required) > 0 {
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return err
+		}
+		for _, k := range required {
+			if _, ok := keys[k]; !ok {
+				return fmt.Errorf("missing %q", k)
+			}
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+`
+
+// file assembles and formats the generated source.
+func (g *generator) file() ([]byte, error) {
+	code := strings.Join(g.decls, "\n")
+	if g.decodeVariant {
+		code += "\n" + decodeVariantSource
+	}
+	header := "// Code generated by api-doc-gen-go gen models"
+	if g.opts.Source != "" {
+		header += " from " + g.opts.Source
+	}
+	out, err := codegen.File(header+". DO NOT EDIT.", g.opts.Package, imports, code)
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated models: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,160 @@
+package modelgen
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/openapi"
+)
+
+func readSpec(t *testing.T) map[string]*model.Schema {
+	t.Helper()
+	data, err := os.ReadFile("testdata/petstore.yaml")
+	require.NoError(t, err)
+	schemas, err := openapi.ReadSchemas(data, "petstore.yaml")
+	require.NoError(t, err)
+	return schemas
+}
+
+func TestGenerate(t *testing.T) {
+	out, err := Generate(readSpec(t), Options{Package: "vendorapi", Source: "petstore.yaml"})
+	require.NoError(t, err)
+	src := string(out)
+
+	assert.Contains(t, src, "// Code generated by api-doc-gen-go gen models from petstore.yaml. DO NOT EDIT.")
+	assert.Contains(t, src, "// Unique identifier of the owner.\n\tID ")
+	assert.Regexp(t, "ID +string +`json:\"id\" format:\"uuid\"`", src)
+	assert.Regexp(t, "Email +\\*string +`json:\"email,omitempty\" format:\"email\"`", src)
+	assert.Regexp(t, "Nickname +\\*string +`json:\"nickname\" validate:\"required\"`", src)
+	assert.Regexp(t, "CreatedAt +\\*time.Time +`json:\"createdAt,omitempty\"`", src)
+	assert.Regexp(t, "Tier +\\*string +`json:\"tier,omitempty\" enums:\"free,pro\"`", src)
+	assert.Regexp(t, "Pets +\\[\\]Pet +`json:\"pets,omitempty\"`", src)
+	assert.Contains(t, src, "type Pet interface {\n\tisPet()\n}")
+	assert.Contains(t, src, "func (Cat) isPet() {}")
+	assert.Contains(t, src, `if decodeVariant(data, &v1, "barks") == nil {`)
+	assert.Contains(t, src, "func (o *Owner) UnmarshalJSON(data []byte) error {")
+	assert.Contains(t, src, "\t// Ready for adoption.\n\tStatusAvailable Status = \"available\"")
+	assert.Contains(t, src, "PriorityHigh   Priority = 3")
+	assert.Contains(t, src, `return fmt.Sprintf("Priority(%v)", int(p))`)
+
+	_, err = Generate(readSpec(t), Options{Package: "vendor-api"})
+	assert.Error(t, err)
+}
+
+// comparable drops what the analyzer adds or cannot know, and orders what
+// it may order differently.
+func comparable(t *testing.T, s *model.Schema, keepVarNames bool) any {
+	t.Helper()
+	var walk func(s *model.Schema)
+	walk = func(s *model.Schema) {
+		if s == nil {
+			return
+		}
+		s.GoType, s.Source = "", nil
+		if !keepVarNames {
+			s.EnumVarNames = nil
+		}
+		sort.Strings(s.Required)
+		for _, p := range s.Properties {
+			walk(p)
+		}
+		walk(s.Items)
+		walk(s.AdditionalProperties)
+		for _, alt := range s.OneOf {
+			walk(alt)
+		}
+	}
+	walk(s)
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	var v any
+	require.NoError(t, json.Unmarshal(data, &v))
+	return v
+}
+
+func TestRoundTrip(t *testing.T) {
+	spec := readSpec(t)
+	out, err := Generate(spec, Options{Package: "vendorapi"})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "models.go"), out, 0o644))
+
+	doc, err := analyzer.Analyze(dir, analyzer.Options{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Cat", "Dog", "Owner", "Pet", "Priority", "Status"}, doc.SchemaNames())
+	for name, want := range readSpec(t) {
+		got := doc.Schema(name)
+		require.NotNil(t, got, name)
+		keep := len(want.EnumVarNames) > 0
+		assert.Equal(t, comparable(t, want, keep), comparable(t, got, keep), name)
+	}
+}
+
+const decodeTest = `package vendorapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	var o Owner
+	err := json.Unmarshal([]byte(` + "`" + `{"id":"a","name":"Ada","nickname":null,"pet":{"barks":true},"pets":[{"meows":false,"status":"adopted"},{"barks":false,"weight":3.5}]}` + "`" + `), &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := o.Pet.(Dog); !ok {
+		t.Fatalf("pet is %T, want Dog", o.Pet)
+	}
+	if c, ok := o.Pets[0].(Cat); !ok || *c.Status != StatusAdopted {
+		t.Fatalf("pets[0] is %#v, want an adopted Cat", o.Pets[0])
+	}
+	if d, ok := o.Pets[1].(Dog); !ok || *d.Weight != 3.5 {
+		t.Fatalf("pets[1] is %#v, want Dog", o.Pets[1])
+	}
+	if o.Name != "Ada" || o.Nickname != nil {
+		t.Fatalf("plain fields not decoded: %#v", o)
+	}
+	if err := json.Unmarshal([]byte(` + "`" + `{"pet":{"purrs":true}}` + "`" + `), &o); err == nil {
+		t.Fatal("decoded a pet matching no variant")
+	}
+	if PriorityMedium.String() != "PriorityMedium" || Priority(7).String() != "Priority(7)" {
+		t.Fatal("wrong Priority names")
+	}
+}
+`
+
+func TestGeneratedCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of generated models in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	out, err := Generate(readSpec(t), Options{Package: "vendorapi"})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/vendorapi\n\ngo 1.19\n",
+		"models.go":      string(out),
+		"models_test.go": decodeTest,
+	}
+	for name, src := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644))
+	}
+	cmd := exec.Command(goTool, "test", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOWORK=off")
+	result, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(result))
+}
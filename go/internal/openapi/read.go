@@ -0,0 +1,329 @@
+// Package openapi reads schema definitions from OpenAPI and JSON Schema
+// documents into the model used by the rest of the Go component.
+//
+// OpenAPI 3 components, Swagger 2 definitions, JSON Schema documents with
+// $defs or definitions, and the output of `api-doc-gen-go parse` are
+// understood. Property order is kept from the source document, and the
+// JSON Schema forms that have no counterpart in the model are normalized:
+// type arrays containing "null" become nullable schemas, anyOf is read as
+// oneOf, and allOf is merged into a single object schema.
+package openapi
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// refPrefixes are the JSON pointers under which documents keep named
+// schemas.
+var refPrefixes = []string{
+	"#/components/schemas/",
+	"#/definitions/",
+	"#/$defs/",
+	"#/schemas/",
+}
+
+// ReadSchemas returns the named schemas of a YAML or JSON document. Name is
+// the document's file name; a JSON Schema document whose root is itself a
+// schema is named after its title, or else after the file.
+func ReadSchemas(data []byte, name string) (map[string]*model.Schema, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: not a schema document", name)
+	}
+	r := &reader{file: name, defs: map[string]*yaml.Node{}}
+	doc := root.Content[0]
+
+	var sections []*yaml.Node
+	if c := field(doc, "components"); c != nil {
+		sections = append(sections, field(c, "schemas"))
+	}
+	sections = append(sections, field(doc, "definitions"), field(doc, "$defs"), field(doc, "schemas"))
+	var order []string
+	for _, sec := range sections {
+		if sec == nil || sec.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(sec.Content); i += 2 {
+			key := sec.Content[i].Value
+			if _, dup := r.defs[key]; !dup {
+				order = append(order, key)
+			}
+			r.defs[key] = sec.Content[i+1]
+		}
+	}
+	if field(doc, "openapi") == nil && field(doc, "swagger") == nil && isSchema(doc) {
+		rootName := field(doc, "title")
+		key := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		if rootName != nil && rootName.Value != "" {
+			key = rootName.Value
+		}
+		if _, dup := r.defs[key]; !dup {
+			order = append(order, key)
+		}
+		r.defs[key] = doc
+		r.root = key
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("%s: no schemas found", name)
+	}
+
+	schemas := map[string]*model.Schema{}
+	for _, key := range order {
+		s, err := r.schema(r.defs[key], key)
+		if err != nil {
+			return nil, err
+		}
+		schemas[key] = s
+	}
+	return schemas, nil
+}
+
+// isSchema reports whether a mapping node looks like a schema rather than
+// a document holding schemas.
+func isSchema(n *yaml.Node) bool {
+	for _, key := range []string{"type", "properties", "items", "enum", "oneOf", "anyOf", "allOf", "$ref"} {
+		if field(n, key) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+type reader struct {
+	file string
+	defs map[string]*yaml.Node
+	// root names the document's root schema, the target of "#".
+	root string
+	// merging guards against allOf cycles.
+	merging []string
+}
+
+func (r *reader) errorf(n *yaml.Node, format string, args ...any) error {
+	return fmt.Errorf("%s:%d: %s", r.file, n.Line, fmt.Sprintf(format, args...))
+}
+
+// schema converts a schema node; path names it in error messages.
+func (r *reader) schema(n *yaml.Node, path string) (*model.Schema, error) {
+	if n.Kind == yaml.ScalarNode && (n.Value == "true" || n.Value == "false") {
+		// JSON Schema allows true for "anything".
+		return &model.Schema{}, nil
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil, r.errorf(n, "%s: schema must be an object", path)
+	}
+	s := &model.Schema{}
+	var order []string
+	if ref := field(n, "$ref"); ref != nil {
+		name, err := r.refName(ref)
+		if err != nil {
+			return nil, err
+		}
+		s.Ref = model.SchemaRefPrefix + name
+		if d := field(n, "description"); d != nil {
+			s.Description = d.Value
+		}
+		return s, nil
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, v := n.Content[i].Value, n.Content[i+1]
+		var err error
+		switch key {
+		case "type":
+			err = r.typ(s, v)
+		case "format":
+			s.Format = v.Value
+		case "description":
+			s.Description = v.Value
+		case "pattern":
+			s.Pattern = v.Value
+		case "nullable", "x-nullable":
+			s.Nullable = v.Value == "true"
+		case "properties":
+			s.Properties = map[string]*model.Schema{}
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				prop := v.Content[j].Value
+				if s.Properties[prop], err = r.schema(v.Content[j+1], path+"."+prop); err != nil {
+					return nil, err
+				}
+				s.PropertyOrder = append(s.PropertyOrder, prop)
+			}
+		case "required":
+			err = v.Decode(&s.Required)
+		case "items":
+			s.Items, err = r.schema(v, path+"[]")
+		case "additionalProperties":
+			if v.Value != "false" {
+				s.AdditionalProperties, err = r.schema(v, path+".*")
+			}
+		case "oneOf", "anyOf":
+			for j, alt := range v.Content {
+				var a *model.Schema
+				if a, err = r.schema(alt, fmt.Sprintf("%s|%d", path, j)); err != nil {
+					return nil, err
+				}
+				s.OneOf = append(s.OneOf, a)
+			}
+		case "enum":
+			err = v.Decode(&s.Enum)
+		case "const":
+			var c any
+			err = v.Decode(&c)
+			s.Enum = []any{c}
+		case "x-enum-varnames":
+			err = v.Decode(&s.EnumVarNames)
+		case "x-enum-descriptions":
+			err = v.Decode(&s.EnumDescriptions)
+		case "minimum":
+			s.Minimum, err = decodePtr[float64](v)
+		case "maximum":
+			s.Maximum, err = decodePtr[float64](v)
+		case "minLength":
+			s.MinLength, err = decodePtr[int](v)
+		case "maxLength":
+			s.MaxLength, err = decodePtr[int](v)
+		case "example":
+			err = v.Decode(&s.Example)
+		case "examples":
+			// JSON Schema examples are a list; OpenAPI media examples
+			// are not schema keywords.
+			if v.Kind == yaml.SequenceNode && len(v.Content) > 0 {
+				err = v.Content[0].Decode(&s.Example)
+			}
+		case "x-order":
+			err = v.Decode(&order)
+		}
+		if err != nil {
+			return nil, r.errorf(v, "%s: %s: %v", path, key, err)
+		}
+	}
+	if len(order) == len(s.Properties) {
+		// parse output lists the declaration order separately.
+		s.PropertyOrder = order
+	}
+	if s.Type == "" && len(s.Properties) > 0 {
+		s.Type = "object"
+	}
+	if all := field(n, "allOf"); all != nil {
+		if err := r.allOf(s, all, path); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// typ reads a type keyword, which JSON Schema allows to be a list such as
+// ["string", "null"].
+func (r *reader) typ(s *model.Schema, v *yaml.Node) error {
+	if v.Kind == yaml.ScalarNode {
+		s.Type = v.Value
+		return nil
+	}
+	var types []string
+	if err := v.Decode(&types); err != nil {
+		return err
+	}
+	for _, t := range types {
+		switch {
+		case t == "null":
+			s.Nullable = true
+		case s.Type == "":
+			s.Type = t
+		}
+	}
+	return nil
+}
+
+// allOf merges the properties of every member, following references, into
+// s, which becomes an object schema.
+func (r *reader) allOf(s *model.Schema, all *yaml.Node, path string) error {
+	for j, member := range all.Content {
+		m, err := r.schema(member, fmt.Sprintf("%s&%d", path, j))
+		if err != nil {
+			return err
+		}
+		if name := m.RefName(); name != "" {
+			for _, seen := range r.merging {
+				if seen == name {
+					return r.errorf(member, "%s: allOf cycle through %s", path, name)
+				}
+			}
+			r.merging = append(r.merging, name)
+			m, err = r.schema(r.defs[name], name)
+			r.merging = r.merging[:len(r.merging)-1]
+			if err != nil {
+				return err
+			}
+		}
+		s.Type = "object"
+		if s.Description == "" {
+			s.Description = m.Description
+		}
+		for _, prop := range m.PropertyNames() {
+			if s.Properties == nil {
+				s.Properties = map[string]*model.Schema{}
+			}
+			if _, dup := s.Properties[prop]; !dup {
+				s.PropertyOrder = append(s.PropertyOrder, prop)
+			}
+			s.Properties[prop] = m.Properties[prop]
+		}
+		for _, req := range m.Required {
+			if !s.IsRequired(req) {
+				s.Required = append(s.Required, req)
+			}
+		}
+		if m.AdditionalProperties != nil && s.AdditionalProperties == nil {
+			s.AdditionalProperties = m.AdditionalProperties
+		}
+	}
+	return nil
+}
+
+// refName returns the schema named by a local reference.
+func (r *reader) refName(ref *yaml.Node) (string, error) {
+	for _, prefix := range refPrefixes {
+		if strings.HasPrefix(ref.Value, prefix) {
+			name := strings.TrimPrefix(ref.Value, prefix)
+			name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+			if r.defs[name] == nil {
+				return "", r.errorf(ref, "unresolved reference %s", ref.Value)
+			}
+			return name, nil
+		}
+	}
+	if ref.Value == "#" && r.root != "" {
+		return r.root, nil
+	}
+	return "", r.errorf(ref, "unsupported reference %s (only local schema references are supported)", ref.Value)
+}
+
+func decodePtr[T any](n *yaml.Node) (*T, error) {
+	var v T
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// field returns the value of key in a mapping node, or nil.
+func field(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func TestReadJSONSchema(t *testing.T) {
+	src := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "Order",
+  "type": "object",
+  "required": ["id"],
+  "properties": {
+    "id": {"type": "string", "format": "uuid"},
+    "note": {"type": ["string", "null"], "examples": ["leave at door"]},
+    "customer": {"$ref": "#/$defs/Customer"},
+    "payment": {"anyOf": [{"$ref": "#/$defs/Card"}, {"type": "string"}]},
+    "parent": {"$ref": "#"}
+  },
+  "$defs": {
+    "Named": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}},
+    "Customer": {
+      "allOf": [{"$ref": "#/$defs/Named"}, {"properties": {"vip": {"type": "boolean"}}}],
+      "description": "Customer places orders."
+    },
+    "Card": {"type": "object", "properties": {"last4": {"type": "string"}}, "additionalProperties": false}
+  }
+}`
+	schemas, err := ReadSchemas([]byte(src), "order.schema.json")
+	require.NoError(t, err)
+	assert.Len(t, schemas, 4)
+
+	order := schemas["Order"]
+	require.NotNil(t, order)
+	assert.Equal(t, []string{"id", "note", "customer", "payment", "parent"}, order.PropertyOrder)
+	assert.Equal(t, &model.Schema{Type: "string", Nullable: true, Example: "leave at door"}, order.Properties["note"])
+	assert.Equal(t, "Customer", order.Properties["customer"].RefName())
+	assert.Equal(t, "Order", order.Properties["parent"].RefName())
+	require.Len(t, order.Properties["payment"].OneOf, 2)
+	assert.Equal(t, "Card", order.Properties["payment"].OneOf[0].RefName())
+
+	customer := schemas["Customer"]
+	assert.Equal(t, "object", customer.Type)
+	assert.Equal(t, "Customer places orders.", customer.Description)
+	assert.Equal(t, []string{"name", "vip"}, customer.PropertyOrder)
+	assert.Equal(t, []string{"name"}, customer.Required)
+	assert.Nil(t, schemas["Card"].AdditionalProperties)
+}
+
+func TestReadErrors(t *testing.T) {
+	_, err := ReadSchemas([]byte("openapi: 3.0.0\ncomponents:\n  schemas:\n    A:\n      $ref: 'other.yaml#/B'\n"), "spec.yaml")
+	assert.ErrorContains(t, err, "spec.yaml:5: unsupported reference other.yaml#/B")
+
+	_, err = ReadSchemas([]byte("openapi: 3.0.0\npaths: {}\n"), "spec.yaml")
+	assert.ErrorContains(t, err, "no schemas found")
+}
@@ -0,0 +1,157 @@
+package review
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/site"
+)
+
+// Block classes.
+const (
+	classHeading   = "h"
+	classParagraph = "p"
+	classCode      = "code"
+	classHeader    = "th"
+	classRow       = "tr"
+)
+
+// block is a paragraph, heading, code block or table row of a page. Its
+// key identifies it across revisions, and its cells hold inline Markdown:
+// text with `code` spans.
+type block struct {
+	key   string
+	class string
+	cells []string
+}
+
+func (b *block) equal(o *block) bool {
+	if b.class != o.class || len(b.cells) != len(o.cells) {
+		return false
+	}
+	for i := range b.cells {
+		if b.cells[i] != o.cells[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// page is a rendered endpoint or schema page.
+type page struct {
+	blocks []*block
+}
+
+func (p *page) add(key, class string, cells ...string) {
+	p.blocks = append(p.blocks, &block{key: key, class: class, cells: cells})
+}
+
+// endpointPage renders an endpoint as the endpoint pages of site exports
+// do.
+func endpointPage(doc *model.Document, ep *model.Endpoint) *page {
+	return parse(site.EndpointBody(doc, ep))
+}
+
+// schemaPage renders the named schema as the schema pages of site
+// exports do.
+func schemaPage(doc *model.Document, name string) *page {
+	return parse(site.SchemaBody(doc, name))
+}
+
+// parse splits a Markdown page body into blocks. A block is keyed by the
+// heading it is under and by what it documents: the first cell of a
+// table row, the first word of a list item, the bold lead of a paragraph
+// or else the position of the paragraph among those of its section.
+func parse(body string) *page {
+	p := &page{}
+	section, paragraphs := "", 0
+	var para []string
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		text := strings.Join(para, " ")
+		para = nil
+		if strings.HasPrefix(text, "**") {
+			// A bold lead, as **Deprecated.**, names the paragraph.
+			lead := strings.SplitN(text[2:], "**", 2)[0]
+			p.add(section+" "+lead, classParagraph, strings.ReplaceAll(text, "**", ""))
+			return
+		}
+		p.add(fmt.Sprintf("%s p%d", section, paragraphs), classParagraph, text)
+		paragraphs++
+	}
+	lines := strings.Split(body, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(lines[i], "```"); i++ {
+				code = append(code, lines[i])
+			}
+			p.add(section+" code", classCode, strings.Join(code, "\n"))
+		case strings.HasPrefix(line, "#"):
+			flush()
+			section, paragraphs = strings.TrimSpace(strings.TrimLeft(line, "#")), 0
+			p.add(section, classHeading, section)
+		case strings.HasPrefix(line, "|"):
+			flush()
+			cells := tableCells(line)
+			switch {
+			case separator(cells):
+			case i+1 < len(lines) && separator(tableCells(strings.TrimSpace(lines[i+1]))):
+				p.add(section+" header", classHeader, cells...)
+			default:
+				p.add(section+" row "+cells[0], classRow, cells...)
+			}
+		case listItem.MatchString(line):
+			flush()
+			item := listItem.ReplaceAllString(line, "")
+			p.add(section+" item "+words(item)[0], classParagraph, item)
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+	return p
+}
+
+// listItem matches the marker of a bulleted or numbered list item.
+var listItem = regexp.MustCompile(`^(?:[-*]|\d+\.) `)
+
+// tableCells returns the cells of a table row, unescaping \|.
+func tableCells(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	var cells []string
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			b.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(b.String()))
+}
+
+// separator reports whether table cells are the delimiter row under a
+// header, as | --- | :-: |.
+func separator(cells []string) bool {
+	for _, c := range cells {
+		if strings.Trim(c, ":-") != "" || !strings.Contains(c, "-") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,407 @@
+// Package review renders the documentation pages of two revisions of an
+// API side by side, so that a pull request can be reviewed as its readers
+// will see it rather than as a structural diff.
+//
+// The endpoint and schema pages are laid out as site exports lay them out
+// and compared block by block: paragraphs, headings and table rows are
+// matched by what they document (a parameter, a response status, a
+// field), and the words that changed inside them are highlighted. The
+// report is a single HTML file styled by the built-in theme, without
+// external resources, so that it can be attached to a pull request.
+package review
+
+import (
+	_ "embed"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/markdown"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+//go:embed theme.css
+var theme string
+
+// Page kinds.
+const (
+	KindEndpoint = "endpoint"
+	KindSchema   = "schema"
+)
+
+// Page statuses.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is an endpoint or schema page that differs between revisions.
+type Change struct {
+	Kind string
+	// Title is the "METHOD /path" of an endpoint or the name of a schema.
+	Title  string
+	Status string
+	// Method is the method of an endpoint.
+	Method string
+	rows   []*row
+}
+
+// row pairs a block of the old page with the block of the new page
+// documenting the same thing. Old is nil for added blocks and new for
+// removed ones.
+type row struct {
+	old, new *block
+}
+
+// Compare returns the pages of to that differ from those of from, and the
+// pages of from that to no longer has: endpoints in path and method order,
+// then schemas by name.
+func Compare(from, to *model.Document) []*Change {
+	var changes []*Change
+	oldEps, newEps := endpointsByKey(from), endpointsByKey(to)
+	keys := unionKeys(oldEps, newEps)
+	sort.SliceStable(keys, func(i, j int) bool {
+		mi, pi, _ := strings.Cut(keys[i], " ")
+		mj, pj, _ := strings.Cut(keys[j], " ")
+		if pi != pj {
+			return pi < pj
+		}
+		return mi < mj
+	})
+	for _, key := range keys {
+		o, n := oldEps[key], newEps[key]
+		var op, np *page
+		method := ""
+		if o != nil {
+			op, method = endpointPage(from, o), o.Method
+		}
+		if n != nil {
+			np, method = endpointPage(to, n), n.Method
+		}
+		if c := compare(op, np); c != nil {
+			c.Kind, c.Title, c.Method = KindEndpoint, key, method
+			changes = append(changes, c)
+		}
+	}
+	for _, name := range unionKeys(from.Schemas, to.Schemas) {
+		var op, np *page
+		if from.Schemas[name] != nil {
+			op = schemaPage(from, name)
+		}
+		if to.Schemas[name] != nil {
+			np = schemaPage(to, name)
+		}
+		if c := compare(op, np); c != nil {
+			c.Kind, c.Title = KindSchema, name
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+func endpointsByKey(doc *model.Document) map[string]*model.Endpoint {
+	m := map[string]*model.Endpoint{}
+	for _, ep := range doc.Endpoints {
+		m[ep.Key()] = ep
+	}
+	return m
+}
+
+func unionKeys[A, B any](a map[string]A, b map[string]B) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// compare aligns the blocks of two versions of a page, either of which
+// may be missing, and returns nil when they are the same.
+func compare(op, np *page) *Change {
+	c := &Change{Status: Changed}
+	switch {
+	case op == nil:
+		c.Status, op = Added, &page{}
+	case np == nil:
+		c.Status, np = Removed, &page{}
+	}
+	oldKeys, newKeys := make([]string, len(op.blocks)), make([]string, len(np.blocks))
+	for i, b := range op.blocks {
+		oldKeys[i] = b.key
+	}
+	for i, b := range np.blocks {
+		newKeys[i] = b.key
+	}
+	same := true
+	for _, pair := range align(oldKeys, newKeys) {
+		r := &row{}
+		if pair[0] >= 0 {
+			r.old = op.blocks[pair[0]]
+		}
+		if pair[1] >= 0 {
+			r.new = np.blocks[pair[1]]
+		}
+		same = same && r.old != nil && r.new != nil && r.old.equal(r.new)
+		c.rows = append(c.rows, r)
+	}
+	if same {
+		return nil
+	}
+	return c
+}
+
+// align matches the equal elements of a and b along their longest common
+// subsequence. It returns the pairs of indexes in order, with -1 for the
+// elements of one side that the other lacks; the elements of a come
+// before those of b that replace them.
+func align(a, b []string) [][2]int {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var pairs [][2]int
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i, j = i+1, j+1
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			pairs = append(pairs, [2]int{i, -1})
+			i++
+		default:
+			pairs = append(pairs, [2]int{-1, j})
+			j++
+		}
+	}
+	return pairs
+}
+
+// Options configures HTML.
+type Options struct {
+	// From and To name the compared revisions.
+	From, To string
+	// Title is the title of the report, "API documentation review" by
+	// default.
+	Title string
+}
+
+// HTML renders the changed pages side by side as a self-contained HTML
+// document.
+func HTML(changes []*Change, opts Options) []byte {
+	if opts.Title == "" {
+		opts.Title = "API documentation review"
+	}
+	var b strings.Builder
+	esc := html.EscapeString
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"UTF-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n<div class=\"container\">\n", esc(opts.Title), theme)
+	fmt.Fprintf(&b, "<header class=\"header\">\n<h1>%s</h1>\n<p><code>%s</code> &rarr; <code>%s</code>: %s.</p>\n</header>\n",
+		esc(opts.Title), esc(opts.From), esc(opts.To), esc(summary(changes)))
+
+	ids := map[string]bool{}
+	anchors := make([]string, len(changes))
+	for i, c := range changes {
+		id := c.Kind + "-" + markdown.Slug(c.Title)
+		for n := 2; ids[id]; n++ {
+			id = fmt.Sprintf("%s-%s-%d", c.Kind, markdown.Slug(c.Title), n)
+		}
+		ids[id], anchors[i] = true, id
+	}
+	if len(changes) > 0 {
+		b.WriteString("<nav>\n<ul>\n")
+		for i, c := range changes {
+			fmt.Fprintf(&b, "<li><a href=\"#%s\">%s</a> %s</li>\n", anchors[i], title(c), status(c))
+		}
+		b.WriteString("</ul>\n</nav>\n")
+	}
+	b.WriteString("<main>\n")
+	for i, c := range changes {
+		fmt.Fprintf(&b, "<section class=\"page\" id=\"%s\">\n<h2>%s %s</h2>\n", anchors[i], title(c), status(c))
+		fmt.Fprintf(&b, "<table class=\"side-by-side\">\n<thead><tr><th>%s</th><th>%s</th></tr></thead>\n<tbody>\n", esc(opts.From), esc(opts.To))
+		for _, r := range c.rows {
+			writeRow(&b, r)
+		}
+		b.WriteString("</tbody>\n</table>\n</section>\n")
+	}
+	b.WriteString("</main>\n<footer>Generated by api-doc-gen-go review.</footer>\n</div>\n</body>\n</html>\n")
+	return []byte(b.String())
+}
+
+// summary counts the changed pages.
+func summary(changes []*Change) string {
+	if len(changes) == 0 {
+		return "no endpoint or schema page changed"
+	}
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Kind+" "+c.Status]++
+	}
+	var parts []string
+	for _, kind := range []string{KindEndpoint, KindSchema} {
+		for _, st := range []string{Changed, Added, Removed} {
+			if n := counts[kind+" "+st]; n > 0 {
+				noun := kind + " page"
+				if n > 1 {
+					noun += "s"
+				}
+				parts = append(parts, fmt.Sprintf("%d %s %s", n, noun, st))
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func title(c *Change) string {
+	if c.Kind == KindEndpoint {
+		path := strings.TrimPrefix(c.Title, c.Method+" ")
+		return fmt.Sprintf("<span class=\"method method-%s\">%s</span> <code>%s</code>", strings.ToLower(c.Method), html.EscapeString(c.Method), html.EscapeString(path))
+	}
+	return "<code>" + html.EscapeString(c.Title) + "</code>"
+}
+
+func status(c *Change) string {
+	return fmt.Sprintf("<span class=\"status status-%s\">%s</span>", c.Status, c.Status)
+}
+
+// writeRow writes a pair of blocks, highlighting the words that differ
+// between them.
+func writeRow(b *strings.Builder, r *row) {
+	class := "same"
+	var oldHTML, newHTML string
+	switch {
+	case r.old == nil:
+		class, newHTML = "added", blockHTML(r.new, nil, "")
+	case r.new == nil:
+		class, oldHTML = "removed", blockHTML(r.old, nil, "")
+	case r.old.equal(r.new):
+		oldHTML, newHTML = blockHTML(r.old, nil, ""), blockHTML(r.new, nil, "")
+	default:
+		class = "changed"
+		oldHTML, newHTML = blockHTML(r.old, r.new, "del"), blockHTML(r.new, r.old, "ins")
+	}
+	fmt.Fprintf(b, "<tr class=\"%s\"><td class=\"old\">%s</td><td class=\"new\">%s</td></tr>\n", class, oldHTML, newHTML)
+}
+
+// blockHTML renders a block. When other is set, the words of each cell
+// missing from the same cell of other are wrapped in mark, del or ins.
+func blockHTML(bl, other *block, mark string) string {
+	cells := make([]string, len(bl.cells))
+	for i, cell := range bl.cells {
+		if other == nil {
+			cells[i] = inline(cell)
+			continue
+		}
+		against := ""
+		if other.class == bl.class && i < len(other.cells) {
+			against = other.cells[i]
+		}
+		cells[i] = highlight(cell, against, mark)
+	}
+	switch bl.class {
+	case classHeading:
+		return "<h3>" + cells[0] + "</h3>"
+	case classCode:
+		return "<pre><code>" + cells[0] + "</code></pre>"
+	case classHeader, classRow:
+		var b strings.Builder
+		fmt.Fprintf(&b, "<div class=\"cells %s\" style=\"grid-template-columns: repeat(%d, minmax(0, 1fr))\">", bl.class, len(cells))
+		for _, c := range cells {
+			b.WriteString("<span>" + c + "</span>")
+		}
+		b.WriteString("</div>")
+		return b.String()
+	}
+	return "<p>" + strings.Join(cells, " ") + "</p>"
+}
+
+// words splits inline Markdown at white space outside code spans.
+func words(s string) []string {
+	var out []string
+	start, inCode := -1, false
+	for i, r := range s {
+		switch {
+		case r == '`':
+			inCode = !inCode
+			if start < 0 {
+				start = i
+			}
+		case (r == ' ' || r == '\t' || r == '\n') && !inCode:
+			if start >= 0 {
+				out = append(out, s[start:i])
+				start = -1
+			}
+		case start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		out = append(out, s[start:])
+	}
+	return out
+}
+
+// highlight renders s with the words that against lacks wrapped in mark.
+func highlight(s, against, mark string) string {
+	a, b := words(s), words(against)
+	var out []string
+	var run []string
+	flush := func() {
+		if len(run) > 0 {
+			out = append(out, "<"+mark+">"+inline(strings.Join(run, " "))+"</"+mark+">")
+			run = nil
+		}
+	}
+	for _, pair := range align(a, b) {
+		switch {
+		case pair[0] < 0:
+		case pair[1] < 0:
+			run = append(run, a[pair[0]])
+		default:
+			flush()
+			out = append(out, inline(a[pair[0]]))
+		}
+	}
+	flush()
+	return strings.Join(out, " ")
+}
+
+// inline renders inline Markdown as HTML: `code` spans and escaped text.
+func inline(s string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(s, "`") {
+		switch {
+		case i%2 == 0:
+			b.WriteString(html.EscapeString(part))
+		case strings.Count(s, "`")%2 == 1 && i == strings.Count(s, "`"):
+			// An unclosed span is text.
+			b.WriteString("`" + html.EscapeString(part))
+		default:
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package review
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func reviewDocument(description, fieldDoc string) *model.Document {
+	return &model.Document{
+		Endpoints: []*model.Endpoint{{
+			Method:      "GET",
+			Path:        "/items/{id}",
+			Summary:     "Get an item.",
+			Description: description,
+			Parameters:  []*model.Parameter{{Name: "id", In: "path", Required: true, Schema: &model.Schema{Type: "string"}}},
+			Responses:   []*model.Response{{StatusCode: "200", Description: "OK", ContentType: "application/json", Schema: model.RefTo("Item")}},
+			Handler:     "main.getItem",
+		}},
+		Schemas: map[string]*model.Schema{
+			"Item": {Type: "object", Description: "Item is <sold>.", PropertyOrder: []string{"id"},
+				Properties: map[string]*model.Schema{"id": {Type: "string", Description: fieldDoc}}},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	before := reviewDocument("Returns the item with the given ID.", "ID identifies the item.")
+	after := reviewDocument("Returns the catalog item with the `id` given.", "ID identifies the item.")
+	after.Endpoints = append(after.Endpoints,
+		&model.Endpoint{Method: "DELETE", Path: "/items/{id}", Handler: "main.deleteItem"},
+		&model.Endpoint{Method: "POST", Path: "/baskets", Handler: "main.createBasket"})
+	after.Schemas["Item"].Properties["name"] = &model.Schema{Type: "string"}
+	after.Schemas["Item"].PropertyOrder = []string{"id", "name"}
+	before.Schemas["Unused"] = &model.Schema{Type: "string"}
+
+	changes := Compare(before, after)
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Kind+" "+c.Title+" "+c.Status)
+	}
+	// Endpoints come in path and then method order.
+	assert.Equal(t, []string{
+		"endpoint POST /baskets added",
+		"endpoint DELETE /items/{id} added",
+		"endpoint GET /items/{id} changed",
+		"schema Item changed",
+		"schema Unused removed",
+	}, got)
+	assert.Empty(t, Compare(before, before))
+
+	out := string(HTML(changes, Options{From: "main", To: "HEAD"}))
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.Contains(t, out, "<style>\nbody {")
+	assert.NotContains(t, out, "<link")
+	assert.NotContains(t, out, "<script")
+	assert.Contains(t, out, "<code>main</code> &rarr; <code>HEAD</code>: 1 endpoint page changed, 2 endpoint pages added, 1 schema page changed, 1 schema page removed.")
+	assert.Contains(t, out, `<li><a href="#endpoint-get-items-id"><span class="method method-get">GET</span> <code>/items/{id}</code></a>`)
+	// Descriptions are compared word by word, code spans as one word.
+	assert.Contains(t, out, `<tr class="changed"><td class="old"><p>Returns the item with the <del>given ID.</del></p></td>`+
+		`<td class="new"><p>Returns the <ins>catalog</ins> item with the <ins><code>id</code> given.</ins></p></td></tr>`)
+	assert.Contains(t, out, `<tr class="added"><td class="old"></td><td class="new"><div class="cells tr" style="grid-template-columns: repeat(4, minmax(0, 1fr))">`+
+		`<span><code>name</code></span><span>string</span><span>no</span><span></span></div></td></tr>`)
+	assert.Contains(t, out, "<p>Item is &lt;sold&gt;.</p>")
+
+	empty := string(HTML(nil, Options{From: "v1", To: "working tree", Title: "Shop API"}))
+	assert.Contains(t, empty, "<title>Shop API</title>")
+	assert.Contains(t, empty, "no endpoint or schema page changed.")
+}
+
+func TestPages(t *testing.T) {
+	doc := reviewDocument("Returns the item.", "ID identifies the item.")
+	doc.Endpoints[0].Deprecated = true
+	var got []string
+	for _, p := range []*page{endpointPage(doc, doc.Endpoints[0]), schemaPage(doc, "Item")} {
+		for _, b := range p.blocks {
+			got = append(got, b.key+": "+strings.Join(b.cells, " | "))
+		}
+	}
+	// The blocks are those of the site export pages.
+	assert.Equal(t, []string{
+		" Deprecated.: Deprecated.",
+		" p0: Get an item.",
+		" p1: Returns the item.",
+		" code: GET /items/{id}",
+		"Parameters: Parameters",
+		"Parameters header: Name | In | Type | Required | Description",
+		"Parameters row `id`: `id` | path | string | yes | ",
+		"Responses: Responses",
+		"Responses header: Status | Type | Description",
+		"Responses row 200: 200 | `Item` as `application/json` | OK",
+		"Responses p0: Handled by `main.getItem`.",
+		" p0: Item is <sold>.",
+		" header: Field | Type | Required | Description",
+		" row `id`: `id` | string | no | ID identifies the item.",
+		"Used by: Used by",
+		"Used by item `GET /items/{id}`: `GET /items/{id}`",
+	}, got)
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, []string{"Use", "`a b`,", "not", "`c`."}, words("Use `a b`, not  `c`."))
+	assert.Equal(t, "Returns <del>an item of</del> the catalog.", highlight("Returns an item of the catalog.", "Returns the whole catalog.", "del"))
+	assert.Equal(t, "Returns the <ins>whole</ins> catalog.", highlight("Returns the whole catalog.", "Returns an item of the catalog.", "ins"))
+	assert.Equal(t, "a <code>x &lt; y</code> `b", inline("a `x < y` `b"))
+
+	pairs := align([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+	require.Len(t, pairs, 5)
+	assert.Equal(t, [][2]int{{0, 0}, {1, -1}, {-1, 1}, {2, 2}, {-1, 3}}, pairs)
+}
@@ -0,0 +1,602 @@
+// Package site exports an API document as the docs tree of a static site
+// generator: Docusaurus, MkDocs or Hugo.
+//
+// The tree has an overview page, a section per tag holding one page per
+// endpoint, and a schemas section holding one page per schema. Pages link
+// to each other with relative links, and carry the front matter and
+// navigation files their generator expects, so the tree can be dropped
+// into the docs directory of an existing site.
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/markdown"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// Formats lists the supported site generators.
+var Formats = []string{"docusaurus", "mkdocs", "hugo"}
+
+// IsFormat reports whether format names a supported site generator.
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Options configures Generate.
+type Options struct {
+	// Format is the site generator, one of Formats.
+	Format string
+	// Title is the title of the overview page, by default the service or
+	// module name.
+	Title string
+	// Prefix is the path of the generated tree inside the docs directory
+	// of the site, e.g. "api". Docusaurus sidebars and the MkDocs nav name
+	// pages by their path in the docs directory.
+	Prefix string
+}
+
+// defaultTag groups the endpoints without tags.
+const defaultTag = "default"
+
+type page struct {
+	path   string
+	title  string
+	label  string
+	weight int
+	body   string
+}
+
+// section is a directory of pages: the endpoints of a tag, or the
+// schemas when schemas is set.
+type section struct {
+	label     string
+	index     *page
+	pages     []*page
+	endpoints []*model.Endpoint
+	schemas   bool
+}
+
+type generator struct {
+	doc  *model.Document
+	opts Options
+	esc  func(string) string
+
+	root      *page
+	sections  []*section
+	endpoints map[*model.Endpoint]*page
+	schemas   map[string]*page
+	taken     map[string]bool
+}
+
+// Generate returns the files of the docs tree, keyed by their
+// slash-separated paths relative to the output directory.
+func Generate(doc *model.Document, opts Options) (map[string][]byte, error) {
+	if !IsFormat(opts.Format) {
+		return nil, fmt.Errorf("unknown site format %q (want %s)", opts.Format, strings.Join(Formats, ", "))
+	}
+	if opts.Title == "" {
+		opts.Title = doc.Service
+	}
+	if opts.Title == "" {
+		opts.Title = doc.Module
+	}
+	if opts.Title == "" {
+		opts.Title = "API"
+	}
+	opts.Prefix = strings.Trim(opts.Prefix, "/")
+	g := newGenerator(doc, opts)
+	g.plan()
+	g.render()
+
+	files := map[string][]byte{}
+	for _, p := range g.pages() {
+		files[p.path] = g.file(p)
+	}
+	switch opts.Format {
+	case "docusaurus":
+		files["sidebars.js"] = g.sidebars()
+	case "mkdocs":
+		nav, err := g.nav()
+		if err != nil {
+			return nil, err
+		}
+		files["mkdocs.yml"] = nav
+	}
+	return files, nil
+}
+
+func newGenerator(doc *model.Document, opts Options) *generator {
+	g := &generator{
+		doc:       doc,
+		opts:      opts,
+		endpoints: map[*model.Endpoint]*page{},
+		schemas:   map[string]*page{},
+		taken:     map[string]bool{},
+	}
+	if opts.Format == "docusaurus" {
+		// Docusaurus reads pages as MDX, where braces and angle brackets
+		// start expressions and JSX.
+		g.esc = strings.NewReplacer("{", `\{`, "}", `\}`, "<", "&lt;", ">", "&gt;").Replace
+	}
+	return g
+}
+
+// EndpointBody returns the Markdown body of the page of an endpoint as
+// Generate writes it, without links to other pages.
+func EndpointBody(doc *model.Document, ep *model.Endpoint) string {
+	return newGenerator(doc, Options{}).endpointBody(&page{title: ep.Key()}, ep)
+}
+
+// SchemaBody returns the Markdown body of the page of the named schema as
+// Generate writes it, without links to other pages.
+func SchemaBody(doc *model.Document, name string) string {
+	return newGenerator(doc, Options{}).schemaBody(&page{title: name})
+}
+
+// indexName is the file name of the page introducing a directory.
+func (g *generator) indexName() string {
+	if g.opts.Format == "hugo" {
+		return "_index.md"
+	}
+	return "index.md"
+}
+
+// claim reserves a unique page path in dir for a slug.
+func (g *generator) claim(dir, name string) string {
+	if name == "" {
+		name = "index-page"
+	}
+	p := path.Join(dir, name+".md")
+	for i := 2; g.taken[p]; i++ {
+		p = path.Join(dir, fmt.Sprintf("%s-%d.md", name, i))
+	}
+	g.taken[p] = true
+	return p
+}
+
+// plan lays out the pages: the overview, a section per tag with a page
+// per endpoint, and the schemas section.
+func (g *generator) plan() {
+	g.root = &page{path: g.indexName(), title: g.opts.Title, label: "Overview", weight: 0}
+	g.taken[g.root.path] = true
+	byTag := map[string][]*model.Endpoint{}
+	for _, ep := range g.doc.Endpoints {
+		tag := defaultTag
+		if len(ep.Tags) > 0 {
+			tag = ep.Tags[0]
+		}
+		byTag[tag] = append(byTag[tag], ep)
+	}
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	dirs := map[string]bool{"schemas": true}
+	for i, tag := range tags {
+		base := markdown.Slug(tag)
+		if base == "" {
+			base = defaultTag
+		}
+		dir := base
+		for n := 2; dirs[dir]; n++ {
+			dir = fmt.Sprintf("%s-%d", base, n)
+		}
+		dirs[dir] = true
+		s := &section{label: tag, index: &page{path: path.Join(dir, g.indexName()), title: tag, label: tag, weight: i + 1}}
+		for j, ep := range byTag[tag] {
+			name := ep.OperationID
+			if name == "" {
+				name = ep.Method + " " + ep.Path
+			}
+			p := &page{path: g.claim(dir, markdown.Slug(name)), title: ep.Key(), label: ep.Key(), weight: j + 1}
+			g.endpoints[ep] = p
+			s.pages = append(s.pages, p)
+			s.endpoints = append(s.endpoints, ep)
+		}
+		g.sections = append(g.sections, s)
+	}
+	s := &section{label: "Schemas", schemas: true, index: &page{path: path.Join("schemas", g.indexName()), title: "Schemas", label: "Schemas", weight: len(tags) + 1}}
+	for i, name := range markdown.SortedSchemaNames(g.doc) {
+		p := &page{path: g.claim("schemas", markdown.Slug(name)), title: name, label: name, weight: i + 1}
+		g.schemas[name] = p
+		s.pages = append(s.pages, p)
+	}
+	g.sections = append(g.sections, s)
+}
+
+// pages returns every page, the overview first.
+func (g *generator) pages() []*page {
+	pages := []*page{g.root}
+	for _, s := range g.sections {
+		pages = append(pages, s.index)
+		pages = append(pages, s.pages...)
+	}
+	return pages
+}
+
+// link returns the target of a link from one page to another.
+func (g *generator) link(from, to *page) string {
+	rel := relative(path.Dir(from.path), to.path)
+	if g.opts.Format == "hugo" {
+		// Hugo does not resolve links to Markdown files by itself.
+		return fmt.Sprintf(`{{< relref %q >}}`, rel)
+	}
+	return rel
+}
+
+// ref returns a link labeled label from one page to another, or the bare
+// label when the target has no page.
+func (g *generator) ref(from *page, label string, to *page) string {
+	if to == nil {
+		return label
+	}
+	return "[" + label + "](" + g.link(from, to) + ")"
+}
+
+// relative returns the slash-separated path of target relative to dir.
+func relative(dir, target string) string {
+	var from []string
+	if dir != "." {
+		from = strings.Split(dir, "/")
+	}
+	to := strings.Split(target, "/")
+	for len(from) > 0 && len(to) > 1 && from[0] == to[0] {
+		from, to = from[1:], to[1:]
+	}
+	return strings.Repeat("../", len(from)) + strings.Join(to, "/")
+}
+
+// options returns the rendering options of fragments on page p.
+func (g *generator) options(p *page) markdown.Options {
+	return markdown.Options{
+		SchemaLink: func(name string) string {
+			if target := g.schemas[name]; target != nil {
+				return g.link(p, target)
+			}
+			return ""
+		},
+		EndpointLink: func(ep *model.Endpoint) string {
+			if target := g.endpoints[ep]; target != nil {
+				return g.link(p, target)
+			}
+			return ""
+		},
+		Escape: g.esc,
+	}
+}
+
+// text escapes prose from the document for the site's Markdown dialect.
+func (g *generator) text(s string) string {
+	if g.esc == nil {
+		return s
+	}
+	return g.esc(s)
+}
+
+// render writes the bodies of all pages.
+func (g *generator) render() {
+	g.root.body = g.overviewBody()
+	for _, s := range g.sections {
+		if s.schemas {
+			s.index.body = g.schemasBody(s.index)
+			for _, p := range s.pages {
+				p.body = g.schemaBody(p)
+			}
+			continue
+		}
+		s.index.body = markdown.Endpoints(s.endpoints, g.options(s.index))
+		for _, ep := range s.endpoints {
+			p := g.endpoints[ep]
+			p.body = g.endpointBody(p, ep)
+		}
+	}
+}
+
+func (g *generator) overviewBody() string {
+	var b strings.Builder
+	if g.doc.Module != "" {
+		fmt.Fprintf(&b, "API of the Go module `%s`.\n\n", g.doc.Module)
+	}
+	b.WriteString("| Section | Pages |\n| --- | --- |\n")
+	for _, s := range g.sections {
+		fmt.Fprintf(&b, "| [%s](%s) | %d |\n", markdown.Cell(g.text(s.label)), g.link(g.root, s.index), len(s.pages))
+	}
+	var assets, limits strings.Builder
+	for _, pkg := range g.doc.Packages {
+		for _, a := range pkg.Assets {
+			fmt.Fprintf(&assets, "| `%s` | `%s` | %s | %s |\n", pkg.ImportPath, a.Var, markdown.Cell("`"+strings.Join(a.Patterns, "`, `")+"`"), markdown.Cell(g.text(firstSentence(a.Description))))
+		}
+		if len(pkg.Limitations) > 0 {
+			fmt.Fprintf(&limits, "\n### `%s`\n\n", pkg.ImportPath)
+			for _, l := range pkg.Limitations {
+				fmt.Fprintf(&limits, "- %s\n", g.text(l.Detail))
+			}
+		}
+	}
+	if len(g.doc.Middleware) > 0 {
+		b.WriteString("\n## Middleware\n\n" + markdown.Middleware(g.doc, g.options(g.root)))
+	}
+	if assets.Len() > 0 {
+		b.WriteString("\n## Static assets\n\n| Package | Variable | Files | Description |\n| --- | --- | --- | --- |\n" + assets.String())
+	}
+	if limits.Len() > 0 {
+		b.WriteString("\n## Analysis limitations\n\nThese parts of the sources are not analyzed; what they declare is documented as far as its Go declarations tell.\n" + limits.String())
+	}
+	return b.String()
+}
+
+func (g *generator) schemasBody(p *page) string {
+	if len(g.schemas) == 0 {
+		return "_No schemas._\n"
+	}
+	var b strings.Builder
+	b.WriteString("| Schema | Description |\n| --- | --- |\n")
+	for _, name := range markdown.SortedSchemaNames(g.doc) {
+		fmt.Fprintf(&b, "| [`%s`](%s) | %s |\n", name, g.link(p, g.schemas[name]), markdown.Cell(g.text(firstSentence(g.doc.Schemas[name].Description))))
+	}
+	return b.String()
+}
+
+func (g *generator) schemaBody(p *page) string {
+	name := p.title
+	s := g.doc.Schemas[name]
+	opts := g.options(p)
+	var b strings.Builder
+	if d := strings.TrimSpace(s.Description); d != "" {
+		b.WriteString(g.text(d) + "\n\n")
+	}
+	b.WriteString(markdown.SchemaTable(s, opts))
+	var users []string
+	for _, ep := range g.doc.Endpoints {
+		if refersTo(endpointSchemas(ep), name) {
+			users = append(users, "- "+g.ref(p, "`"+ep.Key()+"`", g.endpoints[ep]))
+		}
+	}
+	for _, other := range markdown.SortedSchemaNames(g.doc) {
+		if other != name && refersTo([]*model.Schema{g.doc.Schemas[other]}, name) {
+			users = append(users, "- "+g.ref(p, "`"+other+"`", g.schemas[other]))
+		}
+	}
+	if len(users) > 0 {
+		b.WriteString("\n## Used by\n\n" + strings.Join(users, "\n") + "\n")
+	}
+	if s.Source != nil {
+		fmt.Fprintf(&b, "\nDeclared at `%s`.\n", s.Source)
+	}
+	return b.String()
+}
+
+func (g *generator) endpointBody(p *page, ep *model.Endpoint) string {
+	opts := g.options(p)
+	var b strings.Builder
+	if ep.Deprecated {
+		b.WriteString("**Deprecated.**")
+		if ep.Sunset != "" {
+			fmt.Fprintf(&b, " Sunset on %s.", ep.Sunset)
+		}
+		b.WriteString("\n\n")
+	}
+	if ep.Summary != "" {
+		b.WriteString(g.text(ep.Summary) + "\n\n")
+	}
+	if d := strings.TrimSpace(ep.Description); d != "" && d != ep.Summary {
+		b.WriteString(g.text(d) + "\n\n")
+	}
+	fmt.Fprintf(&b, "```http\n%s\n```\n", ep.Key())
+	if len(ep.Parameters) > 0 {
+		b.WriteString("\n## Parameters\n\n| Name | In | Type | Required | Description |\n| --- | --- | --- | --- | --- |\n")
+		for _, param := range ep.Parameters {
+			desc := g.text(param.Description)
+			if param.Schema != nil {
+				desc = markdown.Description(&model.Schema{Description: param.Description, Enum: param.Schema.Enum}, opts)
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", markdown.Cell(param.Name), param.In, markdown.Type(param.Schema, opts), markdown.YesNo(param.Required), markdown.Cell(desc))
+		}
+	}
+	if rb := ep.RequestBody; rb != nil {
+		b.WriteString("\n## Request body\n\n")
+		fmt.Fprintf(&b, "%s as `%s`", markdown.Type(rb.Schema, opts), rb.ContentType)
+		if rb.Required {
+			b.WriteString(", required")
+		}
+		b.WriteString(".\n")
+		if d := strings.TrimSpace(rb.Description); d != "" {
+			b.WriteString("\n" + g.text(d) + "\n")
+		}
+	}
+	if len(ep.Responses) > 0 {
+		b.WriteString("\n## Responses\n\n| Status | Type | Description |\n| --- | --- | --- |\n")
+		for _, r := range ep.Responses {
+			typ := ""
+			if r.Schema != nil {
+				typ = markdown.Type(r.Schema, opts)
+				if r.ContentType != "" {
+					typ += " as `" + r.ContentType + "`"
+				}
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", r.StatusCode, typ, markdown.Cell(g.text(r.Description)))
+		}
+	}
+	if lr := ep.LongRunning; lr != nil {
+		b.WriteString("\n## Long-running operation\n\n")
+		b.WriteString(g.pollingFlow(lr, opts) + "\n")
+	}
+	if len(ep.Middleware) > 0 {
+		b.WriteString("\n## Middleware\n\nRequests go through this middleware, in order, before the handler.\n\n" + markdown.Chain(g.doc, ep))
+	}
+	if len(ep.Security) > 0 {
+		b.WriteString("\n## Security\n\n")
+		for _, name := range ep.Security {
+			fmt.Fprintf(&b, "- `%s`", name)
+			if s := g.doc.SecuritySchemes[name]; s != nil && s.Description != "" {
+				b.WriteString(": " + g.text(markdown.OneLine(s.Description)))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if ep.Handler != "" {
+		fmt.Fprintf(&b, "\nHandled by `%s`", ep.Handler)
+		if ep.Source != nil {
+			fmt.Fprintf(&b, ", registered at `%s`", ep.Source)
+		}
+		b.WriteString(".\n")
+	}
+	return b.String()
+}
+
+// pollingFlow describes how a client follows a long-running operation.
+func (g *generator) pollingFlow(lr *model.LongRunning, opts markdown.Options) string {
+	text := fmt.Sprintf("The operation completes asynchronously: the response is `202 Accepted` with a `Location` header of the form `%s`.", lr.Location)
+	if lr.StatusEndpoint == "" {
+		return text
+	}
+	status := "`" + lr.StatusEndpoint + "`"
+	for _, ep := range g.doc.Endpoints {
+		if ep.Key() == lr.StatusEndpoint {
+			if link := opts.EndpointLink(ep); link != "" {
+				status = "[" + status + "](" + link + ")"
+			}
+			break
+		}
+	}
+	text += " Poll " + status
+	if lr.Schema != nil {
+		text += ", which returns " + markdown.Type(lr.Schema, opts) + ","
+	}
+	if lr.StateProperty == "" {
+		return text + " until the operation is done."
+	}
+	states := make([]string, len(lr.States))
+	for i, s := range lr.States {
+		states[i] = "`" + s + "`"
+	}
+	return text + fmt.Sprintf(" until its `%s` (%s) reaches a final state.", lr.StateProperty, strings.Join(states, ", "))
+}
+
+// endpointSchemas returns the schemas of an endpoint's parameters, body
+// and responses.
+func endpointSchemas(ep *model.Endpoint) []*model.Schema {
+	var schemas []*model.Schema
+	for _, p := range ep.Parameters {
+		schemas = append(schemas, p.Schema)
+	}
+	if ep.RequestBody != nil {
+		schemas = append(schemas, ep.RequestBody.Schema)
+	}
+	for _, r := range ep.Responses {
+		schemas = append(schemas, r.Schema)
+	}
+	return schemas
+}
+
+// refersTo reports whether one of schemas references the named schema.
+func refersTo(schemas []*model.Schema, name string) bool {
+	found := false
+	for _, s := range schemas {
+		s.Walk(func(s *model.Schema) {
+			found = found || s.RefName() == name
+		})
+	}
+	return found
+}
+
+// file returns a page with its front matter.
+func (g *generator) file(p *page) []byte {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(p.title))
+	switch g.opts.Format {
+	case "docusaurus":
+		fmt.Fprintf(&b, "sidebar_label: %s\nsidebar_position: %d\n", strconv.Quote(p.label), p.weight)
+	case "hugo":
+		fmt.Fprintf(&b, "linkTitle: %s\nweight: %d\n", strconv.Quote(p.label), p.weight)
+	}
+	b.WriteString("---\n\n")
+	if g.opts.Format == "mkdocs" {
+		// MkDocs themes show the first heading of a page as its title.
+		fmt.Fprintf(&b, "# %s\n\n", p.title)
+	}
+	b.WriteString(p.body)
+	return []byte(b.String())
+}
+
+// docID returns the Docusaurus ID of a page: its path in the docs
+// directory without extension.
+func (g *generator) docID(p *page) string {
+	return path.Join(g.opts.Prefix, strings.TrimSuffix(p.path, ".md"))
+}
+
+// sidebars returns the sidebars.js of a Docusaurus site, defining the
+// "api" sidebar.
+func (g *generator) sidebars() []byte {
+	q := func(s string) string {
+		data, _ := json.Marshal(s)
+		return string(data)
+	}
+	var b strings.Builder
+	b.WriteString("// Code generated by api-doc-gen-go. DO NOT EDIT.\n\n")
+	b.WriteString("/** @type {import('@docusaurus/plugin-content-docs').SidebarsConfig} */\n")
+	b.WriteString("module.exports = {\n  api: [\n")
+	fmt.Fprintf(&b, "    %s,\n", q(g.docID(g.root)))
+	for _, s := range g.sections {
+		b.WriteString("    {\n      type: \"category\",\n")
+		fmt.Fprintf(&b, "      label: %s,\n", q(s.label))
+		fmt.Fprintf(&b, "      link: {type: \"doc\", id: %s},\n", q(g.docID(s.index)))
+		b.WriteString("      items: [\n")
+		for _, p := range s.pages {
+			fmt.Fprintf(&b, "        %s,\n", q(g.docID(p)))
+		}
+		b.WriteString("      ],\n    },\n")
+	}
+	b.WriteString("  ],\n};\n")
+	return []byte(b.String())
+}
+
+// nav returns a mkdocs.yml holding the nav of the tree, to merge into the
+// configuration of the site.
+func (g *generator) nav() ([]byte, error) {
+	docPath := func(p *page) string { return path.Join(g.opts.Prefix, p.path) }
+	entries := []any{map[string]any{"Overview": docPath(g.root)}}
+	for _, s := range g.sections {
+		items := []any{docPath(s.index)}
+		for _, p := range s.pages {
+			items = append(items, map[string]any{p.label: docPath(p)})
+		}
+		entries = append(entries, map[string]any{s.label: items})
+	}
+	var b bytes.Buffer
+	b.WriteString("# Code generated by api-doc-gen-go. DO NOT EDIT.\n# Merge this nav into the nav of the mkdocs.yml of the site.\n")
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]any{"nav": []any{map[string]any{g.opts.Title: entries}}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// firstSentence returns the first sentence of a description.
+func firstSentence(s string) string {
+	s = markdown.OneLine(s)
+	if i := strings.Index(s, ". "); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package site
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func testDoc() *model.Document {
+	return &model.Document{
+		Service: "usersvc",
+		Endpoints: []*model.Endpoint{
+			{Method: "GET", Path: "/healthz", OperationID: "health"},
+			{
+				Method: "GET", Path: "/users/{id}", OperationID: "getUserByID", Summary: "Returns the user <id>.", Tags: []string{"users"},
+				Parameters: []*model.Parameter{{Name: "id", In: "path", Required: true, Schema: &model.Schema{Type: "string"}}},
+				Responses:  []*model.Response{{StatusCode: "200", Description: "OK", ContentType: "application/json", Schema: model.RefTo("User")}},
+			},
+		},
+		Schemas: map[string]*model.Schema{
+			"User": {Type: "object", Description: "A user {account}.", Properties: map[string]*model.Schema{"id": {Type: "string"}}},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	files, err := Generate(testDoc(), Options{Format: "docusaurus", Prefix: "/api/"})
+	require.NoError(t, err)
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"index.md", "sidebars.js",
+		"default/index.md", "default/health.md",
+		"users/index.md", "users/get-user-by-id.md",
+		"schemas/index.md", "schemas/user.md",
+	}, names)
+	ep := string(files["users/get-user-by-id.md"])
+	assert.Contains(t, ep, "---\ntitle: \"GET /users/{id}\"\nsidebar_label: \"GET /users/{id}\"\nsidebar_position: 1\n---\n\nReturns the user &lt;id&gt;.\n")
+	assert.Contains(t, ep, "| 200 | [`User`](../schemas/user.md) as `application/json` | OK |\n")
+	assert.Contains(t, string(files["schemas/user.md"]), "A user \\{account\\}.\n")
+	assert.Contains(t, string(files["schemas/user.md"]), "- [`GET /users/{id}`](../users/get-user-by-id.md)\n")
+	assert.Contains(t, string(files["sidebars.js"]), "      link: {type: \"doc\", id: \"api/users/index\"},\n      items: [\n        \"api/users/get-user-by-id\",\n")
+
+	files, err = Generate(testDoc(), Options{Format: "mkdocs", Title: "Users API"})
+	require.NoError(t, err)
+	assert.Contains(t, string(files["mkdocs.yml"]), "nav:\n  - Users API:\n      - Overview: index.md\n")
+	assert.Contains(t, string(files["users/get-user-by-id.md"]), "---\ntitle: \"GET /users/{id}\"\n---\n\n# GET /users/{id}\n\nReturns the user <id>.\n")
+
+	files, err = Generate(testDoc(), Options{Format: "hugo"})
+	require.NoError(t, err)
+	assert.Contains(t, files, "users/_index.md")
+	assert.Contains(t, string(files["_index.md"]), `| [users]({{< relref "users/_index.md" >}}) | 1 |`)
+	assert.Contains(t, string(files["users/get-user-by-id.md"]), "linkTitle: \"GET /users/{id}\"\nweight: 1\n")
+
+	_, err = Generate(testDoc(), Options{Format: "jekyll"})
+	assert.EqualError(t, err, `unknown site format "jekyll" (want docusaurus, mkdocs, hugo)`)
+}
+
+func TestLongRunning(t *testing.T) {
+	doc := testDoc()
+	doc.Endpoints = append(doc.Endpoints, &model.Endpoint{
+		Method: "POST", Path: "/users/{id}/export", OperationID: "exportUser", Tags: []string{"users"},
+		Responses: []*model.Response{{StatusCode: "202", Description: "Accepted"}},
+		LongRunning: &model.LongRunning{
+			Location: "/users/{id}", StatusEndpoint: "GET /users/{id}", Schema: model.RefTo("User"),
+			StateProperty: "state", States: []string{"pending", "done"},
+		},
+	})
+	files, err := Generate(doc, Options{Format: "mkdocs"})
+	require.NoError(t, err)
+	assert.Contains(t, string(files["users/export-user.md"]), "\n## Long-running operation\n\n"+
+		"The operation completes asynchronously: the response is `202 Accepted` with a `Location` header of the form `/users/{id}`. "+
+		"Poll [`GET /users/{id}`](get-user-by-id.md), which returns [`User`](../schemas/user.md), until its `state` (`pending`, `done`) reaches a final state.\n")
+}
+
+func TestRelative(t *testing.T) {
+	assert.Equal(t, "../schemas/user.md", relative("users", "schemas/user.md"))
+	assert.Equal(t, "schemas/user.md", relative(".", "schemas/user.md"))
+	assert.Equal(t, "user.md", relative("schemas", "schemas/user.md"))
+}
+
+func TestLimitations(t *testing.T) {
+	doc := testDoc()
+	doc.Packages = []*model.Package{{
+		Name: "main", ImportPath: "example.com/usersvc",
+		Assets: []*model.Asset{{Var: "static", Type: "embed.FS", Patterns: []string{"static/*.html", "favicon.ico"}, Description: "Static pages. Served under /static/."}},
+		Limitations: []*model.Limitation{
+			{Construct: model.LimitationCgo, Detail: "main.go uses cgo"},
+			{Construct: model.LimitationAssembly, Detail: "assembly sources add_amd64.s are not analyzed"},
+		},
+	}}
+	files, err := Generate(doc, Options{Format: "mkdocs"})
+	require.NoError(t, err)
+	index := string(files["index.md"])
+	assert.Contains(t, index, "\n## Static assets\n\n| Package | Variable | Files | Description |\n| --- | --- | --- | --- |\n"+
+		"| `example.com/usersvc` | `static` | `static/*.html`, `favicon.ico` | Static pages. |\n")
+	assert.Contains(t, index, "\n### `example.com/usersvc`\n\n- main.go uses cgo\n- assembly sources add_amd64.s are not analyzed\n")
+}
+
+func TestMiddleware(t *testing.T) {
+	doc := testDoc()
+	doc.Endpoints[1].Middleware = []string{"middleware.Logger", "RequireSession"}
+	doc.Middleware = map[string]*model.Middleware{
+		"RequireSession": {Kind: "net/http", Description: "RequireSession rejects requests without a {session}.", Statuses: []int{401}, Security: "cookieAuth"},
+	}
+	files, err := Generate(doc, Options{Format: "docusaurus"})
+	require.NoError(t, err)
+	assert.Contains(t, string(files["users/get-user-by-id.md"]), "\n## Middleware\n\nRequests go through this middleware, in order, before the handler.\n\n"+
+		"1. `middleware.Logger`\n"+
+		"2. `RequireSession`: answers `401`; authenticates with `cookieAuth`\n")
+	assert.Contains(t, string(files["index.md"]), "\n## Middleware\n\n| Middleware | Kind | Effects | Description |\n| --- | --- | --- | --- |\n"+
+		"| `RequireSession` | net/http | answers `401`; authenticates with `cookieAuth` | RequireSession rejects requests without a \\{session\\}. |\n")
+	assert.NotContains(t, string(files["default/health.md"]), "## Middleware")
+}
@@ -0,0 +1,452 @@
+// Package snapshot records the public API surface of a program as sorted,
+// line-oriented text, in the manner of the api/*.txt files of the Go
+// project, so that changes to it show up in code review.
+//
+// The surface has one line per feature: every endpoint with its
+// parameters, bodies, responses and security, every schema property, and
+// the signature of every exported Go declaration outside main and
+// internal packages:
+//
+//	endpoint GET /users/{id}, param path id string, required
+//	schema User, property name string
+//	pkg example.com/app/client, func New(string) (*Client, error)
+//
+// A snapshot directory holds the approved surface in api.txt. Changes not
+// yet recorded there must be approved by listing them in a file of its
+// next/ subdirectory: added lines as they are, removed lines prefixed with
+// "-". Check reports the changes that are not approved.
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+// File is the name of the approved surface in a snapshot directory, and
+// NextDir the subdirectory of approvals.
+const (
+	File    = "api.txt"
+	NextDir = "next"
+)
+
+// Surface returns the sorted lines of the API surface of a program and
+// its document.
+func Surface(prog *analyzer.Program, doc *model.Document) []string {
+	set := map[string]bool{}
+	add := func(format string, args ...any) {
+		set[fmt.Sprintf(format, args...)] = true
+	}
+	for _, ep := range doc.Endpoints {
+		endpointLines(ep, add)
+	}
+	for name, s := range doc.Schemas {
+		schemaLines(name, s, add)
+	}
+	for _, pkg := range prog.Packages {
+		if pkg.Name == "main" || isInternal(pkg.ImportPath) {
+			continue
+		}
+		for _, f := range pkg.Files {
+			for _, decl := range f.AST.Decls {
+				declLines(pkg.ImportPath, decl, add)
+			}
+		}
+	}
+	lines := make([]string, 0, len(set))
+	for line := range set {
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func endpointLines(ep *model.Endpoint, add func(string, ...any)) {
+	key := "endpoint " + ep.Key()
+	add("%s", key)
+	for _, p := range ep.Parameters {
+		line := fmt.Sprintf("%s, param %s %s %s", key, p.In, p.Name, schemaType(p.Schema))
+		if p.Required {
+			line += ", required"
+		}
+		add("%s", line)
+	}
+	if rb := ep.RequestBody; rb != nil {
+		add("%s, body %s %s", key, rb.ContentType, schemaType(rb.Schema))
+	}
+	for _, r := range ep.Responses {
+		if r.Schema == nil {
+			add("%s, response %s", key, r.StatusCode)
+		} else {
+			add("%s, response %s %s %s", key, r.StatusCode, r.ContentType, schemaType(r.Schema))
+		}
+	}
+	for _, s := range ep.Security {
+		add("%s, security %s", key, s)
+	}
+	if ep.Deprecated {
+		add("%s, deprecated", key)
+	}
+}
+
+func schemaLines(name string, s *model.Schema, add func(string, ...any)) {
+	key := "schema " + name
+	add("%s %s", key, schemaType(s))
+	for prop, p := range s.Properties {
+		line := fmt.Sprintf("%s, property %s %s", key, prop, schemaType(p))
+		if s.IsRequired(prop) {
+			line += ", required"
+		}
+		add("%s", line)
+	}
+	for _, v := range s.Enum {
+		add("%s, enum %#v", key, v)
+	}
+	for _, v := range s.OneOf {
+		add("%s, oneOf %s", key, schemaType(v))
+	}
+}
+
+// schemaType renders the type of a schema in Go-like notation.
+func schemaType(s *model.Schema) string {
+	switch {
+	case s == nil:
+		return "any"
+	case s.RefName() != "":
+		return s.RefName()
+	case s.Type == "array":
+		return "[]" + schemaType(s.Items)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "map[string]" + schemaType(s.AdditionalProperties)
+	case len(s.OneOf) > 0:
+		return "oneOf"
+	case s.Type == "":
+		return "any"
+	}
+	t := s.Type
+	if s.Format != "" {
+		t += "(" + s.Format + ")"
+	}
+	if s.Nullable {
+		t = "*" + t
+	}
+	return t
+}
+
+// isInternal reports whether an import path can only be imported from
+// within its module.
+func isInternal(importPath string) bool {
+	for _, elem := range strings.Split(importPath, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+func declLines(pkgPath string, decl ast.Decl, add func(string, ...any)) {
+	prefix := "pkg " + pkgPath + ", "
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if !d.Name.IsExported() {
+			return
+		}
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			add("%sfunc %s%s", prefix, d.Name.Name, signature(d.Type))
+			return
+		}
+		recv := d.Recv.List[0].Type
+		if !ast.IsExported(receiverName(recv)) {
+			return
+		}
+		add("%smethod (%s) %s%s", prefix, expr(recv), d.Name.Name, signature(d.Type))
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				typeLines(prefix, s, add)
+			case *ast.ValueSpec:
+				kind := "var"
+				if d.Tok == token.CONST {
+					kind = "const"
+				}
+				for i, name := range s.Names {
+					if !name.IsExported() {
+						continue
+					}
+					switch {
+					case s.Type != nil:
+						add("%s%s %s %s", prefix, kind, name.Name, expr(s.Type))
+					case d.Tok == token.CONST && i < len(s.Values):
+						add("%s%s %s = %s", prefix, kind, name.Name, expr(s.Values[i]))
+					default:
+						add("%s%s %s", prefix, kind, name.Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func typeLines(prefix string, s *ast.TypeSpec, add func(string, ...any)) {
+	if !s.Name.IsExported() {
+		return
+	}
+	name := s.Name.Name
+	if s.TypeParams != nil {
+		name += "[" + fieldTypes(s.TypeParams, true) + "]"
+	}
+	if s.Assign.IsValid() {
+		add("%stype %s = %s", prefix, name, expr(s.Type))
+		return
+	}
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		add("%stype %s struct", prefix, name)
+		for _, f := range t.Fields.List {
+			if len(f.Names) == 0 {
+				if ast.IsExported(receiverName(f.Type)) {
+					add("%stype %s struct, embedded %s", prefix, name, expr(f.Type))
+				}
+				continue
+			}
+			for _, n := range f.Names {
+				if n.IsExported() {
+					add("%stype %s struct, %s %s", prefix, name, n.Name, expr(f.Type))
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		add("%stype %s interface", prefix, name)
+		for _, m := range t.Methods.List {
+			if len(m.Names) == 0 {
+				add("%stype %s interface, embedded %s", prefix, name, expr(m.Type))
+				continue
+			}
+			if ft, ok := m.Type.(*ast.FuncType); ok && m.Names[0].IsExported() {
+				add("%stype %s interface, %s%s", prefix, name, m.Names[0].Name, signature(ft))
+			} else if !m.Names[0].IsExported() {
+				add("%stype %s interface, unexported methods", prefix, name)
+			}
+		}
+	default:
+		add("%stype %s %s", prefix, name, expr(s.Type))
+	}
+}
+
+// receiverName returns the name of the type of a receiver or embedded
+// field, without pointer, package qualifier and type arguments.
+func receiverName(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// signature renders the parameters and results of a function without
+// their names, which are not part of its API.
+func signature(ft *ast.FuncType) string {
+	s := "(" + fieldTypes(ft.Params, false) + ")"
+	if ft.Results == nil || len(ft.Results.List) == 0 {
+		return s
+	}
+	results := fieldTypes(ft.Results, false)
+	if ft.Results.NumFields() == 1 {
+		return s + " " + results
+	}
+	return s + " (" + results + ")"
+}
+
+// fieldTypes renders the types of a field list, one per name. Type
+// parameter lists keep their names, which instantiations refer to.
+func fieldTypes(fl *ast.FieldList, named bool) string {
+	if fl == nil {
+		return ""
+	}
+	var parts []string
+	for _, f := range fl.List {
+		t := expr(f.Type)
+		if named {
+			for _, n := range f.Names {
+				parts = append(parts, n.Name+" "+t)
+			}
+			continue
+		}
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			parts = append(parts, t)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func expr(e ast.Expr) string {
+	if ft, ok := e.(*ast.FuncType); ok {
+		return "func" + signature(ft)
+	}
+	return types.ExprString(e)
+}
+
+// Read returns the lines of a surface file, without blank lines,
+// comments and trailing "#" annotations such as issue numbers.
+func Read(name string) ([]string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+// Write writes the lines of a surface file.
+func Write(name string, lines []string) error {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line + "\n")
+	}
+	return os.WriteFile(name, []byte(b.String()), 0o644)
+}
+
+// Change is a line added to or removed from the recorded surface.
+type Change struct {
+	Line    string
+	Removed bool
+	// Approval is the next/ file approving the change, or "".
+	Approval string
+}
+
+// String returns the change as it is written in an approvals file.
+func (c *Change) String() string {
+	if c.Removed {
+		return "-" + c.Line
+	}
+	return c.Line
+}
+
+// Result is the outcome of Check.
+type Result struct {
+	// Changes are the differences between the recorded surface and the
+	// current one, in line order.
+	Changes []*Change
+	// Unused lists the approvals, as "file: line", that match no change:
+	// they were recorded in api.txt since, or the change was undone.
+	Unused []string
+}
+
+// Unapproved returns the changes no approvals file lists.
+func (r *Result) Unapproved() []*Change {
+	var out []*Change
+	for _, c := range r.Changes {
+		if c.Approval == "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Check compares a surface with the one recorded in the snapshot
+// directory dir and its approvals.
+func Check(dir string, surface []string) (*Result, error) {
+	recorded, err := Read(filepath.Join(dir, File))
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := readApprovals(dir)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{}
+	old := toSet(recorded)
+	cur := toSet(surface)
+	used := map[string]bool{}
+	for _, line := range surface {
+		if !old[line] {
+			res.Changes = append(res.Changes, &Change{Line: line})
+		}
+	}
+	for _, line := range recorded {
+		if !cur[line] {
+			res.Changes = append(res.Changes, &Change{Line: line, Removed: true})
+		}
+	}
+	sort.SliceStable(res.Changes, func(i, j int) bool { return res.Changes[i].Line < res.Changes[j].Line })
+	for _, c := range res.Changes {
+		if file, ok := approvals[c.String()]; ok {
+			c.Approval = file
+			used[c.String()] = true
+		}
+	}
+	for entry, file := range approvals {
+		if !used[entry] {
+			res.Unused = append(res.Unused, file+": "+entry)
+		}
+	}
+	sort.Strings(res.Unused)
+	return res, nil
+}
+
+// readApprovals returns the entries of the next/*.txt files of dir, each
+// mapped to the first file listing it.
+func readApprovals(dir string) (map[string]string, error) {
+	names, err := filepath.Glob(filepath.Join(dir, NextDir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	approvals := map[string]string{}
+	for _, name := range names {
+		lines, err := Read(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			if _, ok := approvals[line]; !ok {
+				approvals[line] = filepath.ToSlash(name)
+			}
+		}
+	}
+	return approvals, nil
+}
+
+func toSet(lines []string) map[string]bool {
+	set := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		set[line] = true
+	}
+	return set
+}
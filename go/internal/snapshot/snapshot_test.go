@@ -0,0 +1,124 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+)
+
+func write(t *testing.T, dir, name, src string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644))
+}
+
+func surface(t *testing.T, dir string) []string {
+	t.Helper()
+	prog, err := analyzer.Load(dir+"/...", analyzer.Options{})
+	require.NoError(t, err)
+	return Surface(prog, analyzer.AnalyzeProgram(prog))
+}
+
+func TestSurface(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "go.mod", "module example.com/app\n")
+	write(t, dir, "client/client.go", `package client
+
+import "context"
+
+const Version = "1.0"
+
+const (
+	Small Size = iota
+	large
+)
+
+type Size int
+
+var ErrClosed error
+
+type Client struct {
+	Base
+	Name    string
+	timeout int
+}
+
+type Base struct{}
+
+type List[T any] []T
+
+type Doer interface {
+	Do(ctx context.Context, req string) (status int, err error)
+	close()
+}
+
+type Alias = Client
+
+func New(name, addr string, opts ...func(*Client)) (*Client, error) { return nil, nil }
+
+func (c *Client) Get(ctx context.Context) error { return nil }
+
+func (c *Client) reset() {}
+
+func helper() {}
+`)
+	write(t, dir, "internal/secret/secret.go", "package secret\n\nfunc Hidden() {}\n")
+	write(t, dir, "main.go", "package main\n\nfunc Exported() {}\n")
+
+	assert.Equal(t, []string{
+		"pkg example.com/app/client, const Small Size",
+		"pkg example.com/app/client, const Version = \"1.0\"",
+		"pkg example.com/app/client, func New(string, string, ...func(*Client)) (*Client, error)",
+		"pkg example.com/app/client, method (*Client) Get(context.Context) error",
+		"pkg example.com/app/client, type Alias = Client",
+		"pkg example.com/app/client, type Base struct",
+		"pkg example.com/app/client, type Client struct",
+		"pkg example.com/app/client, type Client struct, Name string",
+		"pkg example.com/app/client, type Client struct, embedded Base",
+		"pkg example.com/app/client, type Doer interface",
+		"pkg example.com/app/client, type Doer interface, Do(context.Context, string) (int, error)",
+		"pkg example.com/app/client, type Doer interface, unexported methods",
+		"pkg example.com/app/client, type List[T any] []T",
+		"pkg example.com/app/client, type Size int",
+		"pkg example.com/app/client, var ErrClosed error",
+		"schema Base object",
+		"schema Client object",
+		"schema Client, property Name string, required",
+		"schema Size integer",
+		"schema Size, enum 0",
+		"schema Size, enum 1",
+	}, surface(t, dir))
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Write(filepath.Join(dir, File), []string{"endpoint GET /a", "endpoint GET /b", "endpoint GET /c"}))
+	write(t, dir, "next/42.txt", "# Orders API\nendpoint GET /d #42\n-endpoint GET /b\nendpoint GET /z\n")
+
+	res, err := Check(dir, []string{"endpoint GET /a", "endpoint GET /d", "endpoint GET /e"})
+	require.NoError(t, err)
+	var changes []string
+	for _, c := range res.Changes {
+		changes = append(changes, c.String()+" "+c.Approval)
+	}
+	next := filepath.ToSlash(filepath.Join(dir, "next", "42.txt"))
+	assert.Equal(t, []string{
+		"-endpoint GET /b " + next,
+		"-endpoint GET /c ",
+		"endpoint GET /d " + next,
+		"endpoint GET /e ",
+	}, changes)
+	unapproved := res.Unapproved()
+	require.Len(t, unapproved, 2)
+	assert.True(t, unapproved[0].Removed)
+	assert.Equal(t, "endpoint GET /e", unapproved[1].Line)
+	assert.Equal(t, []string{next + ": endpoint GET /z"}, res.Unused)
+
+	_, err = Check(t.TempDir(), nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,109 @@
+package sunset
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+func testDoc() *model.Document {
+	return &model.Document{Endpoints: []*model.Endpoint{
+		{Method: "GET", Path: "/users", Deprecated: true, Sunset: "2027-03-01"},
+		{Method: "GET", Path: "/users/me"},
+		{Method: "GET", Path: "/users/{id}", Deprecated: true, Sunset: "2026-10-01"},
+		{Method: "DELETE", Path: "/users/{id}", Deprecated: true},
+		{Method: "POST", Path: "/users", Deprecated: true, Sunset: "2026-11-15"},
+		{Method: "GET", Path: "/v2/users"},
+	}}
+}
+
+func TestReport(t *testing.T) {
+	today := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	var got []string
+	for _, e := range Report(testDoc(), today, 30) {
+		got = append(got, e.Endpoint.Key()+" "+e.Status)
+	}
+	assert.Equal(t, []string{
+		"GET /users/{id} past",
+		"POST /users near",
+		"GET /users scheduled",
+		"DELETE /users/{id} undated",
+	}, got)
+
+	entries := Report(testDoc(), today, 30)
+	assert.Equal(t, -15, entries[0].Days)
+	assert.Equal(t, 30, entries[1].Days)
+	assert.True(t, entries[3].Sunset.IsZero())
+}
+
+const middlewareTest = `package sunset
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, c := range []struct{ method, path, deprecation, sunset string }{
+		{"GET", "/users", "true", "Mon, 01 Mar 2027 00:00:00 GMT"},
+		{"HEAD", "/users/42", "true", "Thu, 01 Oct 2026 00:00:00 GMT"},
+		{"GET", "/users/me", "", ""},
+		{"DELETE", "/users/42", "true", ""},
+		{"GET", "/v2/users", "", ""},
+		{"GET", "/unknown", "", ""},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+		if got := w.Header().Get("Deprecation"); got != c.deprecation {
+			t.Errorf("%s %s: Deprecation %q, want %q", c.method, c.path, got, c.deprecation)
+		}
+		if got := w.Header().Get("Sunset"); got != c.sunset {
+			t.Errorf("%s %s: Sunset %q, want %q", c.method, c.path, got, c.sunset)
+		}
+		if got, want := w.Header().Get("Link") != "", c.deprecation != ""; got != want {
+			t.Errorf("%s %s: Link set %t, want %t", c.method, c.path, got, want)
+		}
+	}
+}
+`
+
+func TestMiddleware(t *testing.T) {
+	_, err := Middleware(testDoc(), Options{})
+	assert.EqualError(t, err, "package name is required")
+
+	out, err := Middleware(testDoc(), Options{Package: "sunset", Link: "https://example.com/deprecation"})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "// Code generated by api-doc-gen-go gen sunset. DO NOT EDIT.\n")
+	assert.Contains(t, string(out), `{"GET", "/users/{id}", true, "Thu, 01 Oct 2026 00:00:00 GMT"},`)
+
+	if testing.Short() {
+		t.Skip("skipping go test of generated middleware in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/sunset\n\ngo 1.19\n",
+		"sunset.go":      string(out),
+		"sunset_test.go": middlewareTest,
+	}
+	for name, src := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644))
+	}
+	cmd := exec.Command(goTool, "test", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOWORK=off")
+	result, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(result))
+}
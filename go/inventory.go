@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/cache"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/inventory"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Inventory the endpoints of every Go module under a directory",
+	Long: `Discover the Go modules of the repositories cloned under --root, analyze them
+in parallel (--jobs) through the shared analysis cache, and write one
+inventory with a row per endpoint:
+
+  repo, module, service, method, path   where the endpoint is served
+  auth                                  the security schemes it requires, or none
+  stability                             deprecated, experimental (alpha, beta,
+                                        preview... path segments or tags) or stable
+  owner                                 the CODEOWNERS owners of its registration
+  doc_coverage                          the percentage of its summary, parameters
+                                        and responses that are documented
+  source, last_commit, last_changed     its registration and the commit that
+                                        last changed that file
+
+Modules nested in another are analyzed on their own. A module whose
+analysis fails is reported on standard error, and in the failures of the
+JSON inventory, without stopping the others.`,
+	Example: `  api-doc-gen-go inventory --root /src/repos -o inventory.csv
+  api-doc-gen-go inventory --root /src/repos --jobs 16 --format json -o inventory.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, _ := cmd.Flags().GetString("root")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "csv" && format != "json" {
+			return fmt.Errorf("unknown inventory format %q (want csv or json)", format)
+		}
+		if jobs < 1 {
+			jobs = 1
+		}
+		modules, err := inventory.Discover(root)
+		if err != nil {
+			return err
+		}
+		if len(modules) == 0 {
+			return fmt.Errorf("no Go modules found under %s", root)
+		}
+		c, err := openCache(cmd)
+		if err != nil {
+			return err
+		}
+
+		inv := &inventory.Inventory{Endpoints: []*inventory.Row{}}
+		owners := &repoOwners{owners: map[string]*inventory.Owners{}}
+		history := &inventory.History{}
+		rows := make([][]*inventory.Row, len(modules))
+		failures := make([]*inventory.Failure, len(modules))
+		var wg sync.WaitGroup
+		next := make(chan int)
+		for w := 0; w < jobs; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range next {
+					m := modules[i]
+					doc, err := analyzeModule(c, m)
+					if err == nil {
+						var o *inventory.Owners
+						if o, err = owners.get(m.Repo); err == nil {
+							rows[i] = inventory.Rows(root, m, doc, o, history)
+						}
+					}
+					if err != nil {
+						repo, _ := filepath.Rel(root, m.Repo)
+						failures[i] = &inventory.Failure{Repo: filepath.ToSlash(repo), Module: m.Path, Error: err.Error()}
+					}
+				}
+			}()
+		}
+		for i := range modules {
+			next <- i
+		}
+		close(next)
+		wg.Wait()
+
+		repos := map[string]bool{}
+		for i, m := range modules {
+			repos[m.Repo] = true
+			inv.Endpoints = append(inv.Endpoints, rows[i]...)
+			if f := failures[i]; f != nil {
+				inv.Failures = append(inv.Failures, f)
+				fmt.Fprintf(os.Stderr, "%s: %s: %s\n", f.Repo, f.Module, f.Error)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "inventoried %d endpoints of %d modules in %d repositories", len(inv.Endpoints), len(modules)-len(inv.Failures), len(repos))
+		if len(inv.Failures) > 0 {
+			fmt.Fprintf(os.Stderr, "; %d modules failed", len(inv.Failures))
+		}
+		fmt.Fprintln(os.Stderr)
+
+		output, _ := cmd.Flags().GetString("output")
+		return writeOutput(output, func(w io.Writer) error {
+			if format == "json" {
+				return encode(w, inv, "json")
+			}
+			return inv.WriteCSV(w)
+		})
+	},
+}
+
+// analyzeModule analyzes the packages of a module, leaving out the
+// modules nested in it. A panic fails the module rather than the run.
+func analyzeModule(c *cache.Cache, m *inventory.Module) (doc *model.Document, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("internal error: %v", v)
+		}
+	}()
+	opts := analyzer.Options{Recursive: true, Exclude: m.Nested, Recovered: recordPanic}
+	return cachedAnalysis(c, filepath.Join(m.Dir, "..."), opts)
+}
+
+// repoOwners loads the CODEOWNERS of each repository once.
+type repoOwners struct {
+	mu     sync.Mutex
+	owners map[string]*inventory.Owners
+}
+
+func (r *repoOwners) get(repo string) (*inventory.Owners, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if o, ok := r.owners[repo]; ok {
+		return o, nil
+	}
+	o, err := inventory.LoadOwners(repo)
+	if err != nil {
+		return nil, err
+	}
+	r.owners[repo] = o
+	return o, nil
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+
+	inventoryCmd.Flags().String("root", ".", "Directory holding the repositories to inventory")
+	inventoryCmd.Flags().IntP("jobs", "j", runtime.NumCPU(), "Modules analyzed in parallel")
+	inventoryCmd.Flags().StringP("format", "f", "csv", "Output format (csv, json)")
+	inventoryCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+}
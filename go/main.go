@@ -2,10 +2,25 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/cache"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/compat"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/config"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/examples"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/gitfs"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/graphql"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/site"
 )
 
 var rootCmd = &cobra.Command{
@@ -14,8 +29,11 @@ var rootCmd = &cobra.Command{
 	Long: `A Go-based parser component that extracts documentation from Go source code
 including doc comments, struct tags, and interface definitions.
 This component is part of the multi-runtime API Documentation Generator.`,
+	// main reports errors itself; usage is only shown for --help.
+	SilenceErrors: true,
+	SilenceUsage:  true,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("API Documentation Generator - Go Parser Component v1.0.0")
+		fmt.Println("API Documentation Generator - Go Parser Component v" + version)
 		fmt.Println("Use --help for available commands")
 	},
 }
@@ -24,29 +42,300 @@ var parseCmd = &cobra.Command{
 	Use:   "parse [path]",
 	Short: "Parse Go source files and extract documentation",
 	Long: `Parse Go source files in the specified path and extract documentation
-including doc comments, struct definitions, interface definitions, and method signatures.`,
+including doc comments, struct definitions, interface definitions, and method signatures.
+
+--shard i/n analyzes only shard i of n of the program and writes a partial
+output; merge-shards combines the partials of all shards.
+
+--format docusaurus, mkdocs and hugo write a docs tree for the static site
+generator into the directory given by -o: an overview page, a page per tag
+listing its endpoints, a page per endpoint and per schema, with front
+matter and relative links, and the sidebars.js of Docusaurus or the nav of
+mkdocs.yml. Hugo sections are introduced by _index.md pages. --site-prefix
+is the path of the tree inside the docs directory of the site, which the
+sidebar and nav entries include.
+
+--compat ts-godoc shapes the json or yaml output like the ParseResponse of
+the TypeScript GoDocParser: the same endpoint IDs, schemas listed as data
+models named after their Go types, and bodies keyed by media type, so that
+the Node pipeline can switch to this component service by service.`,
 	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		path := args[0]
-		fmt.Printf("Parsing Go source files in: %s\n", path)
-		// TODO: Implement Go parsing logic
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if shard, _ := cmd.Flags().GetString("shard"); shard != "" {
+			return parseShard(cmd, args[0], shard)
+		}
+		start := time.Now()
+		doc, err := analyze(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if mode, _ := cmd.Flags().GetString("compat"); mode != "" {
+			if mode != compat.TSGoDoc {
+				return fmt.Errorf("unknown compatibility mode %q (want %s)", mode, compat.TSGoDoc)
+			}
+			resp := compat.GoDoc(doc, compat.Options{Source: args[0], Elapsed: time.Since(start)})
+			return writeOutput(output, func(w io.Writer) error {
+				return encode(w, resp, format)
+			})
+		}
+		if site.IsFormat(format) {
+			return writeSite(cmd, doc, format, output)
+		}
+		if format == "graphql-sdl" {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			sdl, err := graphql.Generate(doc, graphql.Options{Naming: cfg.GraphQL.Naming})
+			if err != nil {
+				return err
+			}
+			return writeOutput(output, func(w io.Writer) error {
+				_, err := w.Write(sdl)
+				return err
+			})
+		}
+		return writeOutput(output, func(w io.Writer) error {
+			return encode(w, doc, format)
+		})
 	},
 }
 
+// writeSite writes the docs tree of a static site generator into the
+// directory dir. Files of earlier exports that the tree no longer has are
+// left in place.
+func writeSite(cmd *cobra.Command, doc *model.Document, format, dir string) error {
+	if dir == "" || dir == "-" {
+		return fmt.Errorf("--format %s writes a directory; name it with -o", format)
+	}
+	opts := site.Options{Format: format}
+	opts.Title, _ = cmd.Flags().GetString("site-title")
+	opts.Prefix, _ = cmd.Flags().GetString("site-prefix")
+	files, err := site.Generate(doc, opts)
+	if err != nil {
+		return err
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadConfig loads the file named by --config, or the default
+// configuration file when it exists.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	path, _ := cmd.Flags().GetString("config")
+	return config.Load(path)
+}
+
+// addAnalysisFlags registers the flags selecting which files are analyzed.
+func addAnalysisFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("recursive", "r", false, "Parse directories recursively")
+	cmd.Flags().StringSliceP("include", "i", []string{}, "Include patterns for files")
+	cmd.Flags().StringSliceP("exclude", "e", []string{}, "Exclude patterns for files")
+}
+
+// analysisOptions returns the options set by the flags of addAnalysisFlags
+// and the schema naming of the configuration file.
+func analysisOptions(cmd *cobra.Command) (analyzer.Options, error) {
+	var opts analyzer.Options
+	opts.Recursive, _ = cmd.Flags().GetBool("recursive")
+	opts.Include, _ = cmd.Flags().GetStringSlice("include")
+	opts.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+	opts.Recovered = recordPanic
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return opts, err
+	}
+	opts.Naming = cfg.Schemas
+	return opts, nil
+}
+
+// addRevisionFlag registers --rev, analyzing a git revision instead of
+// the working tree.
+func addRevisionFlag(cmd *cobra.Command) {
+	cmd.Flags().String("rev", "", "Analyze the sources of a git commit or tag, read from the repository without checking it out")
+}
+
+// revisionOptions makes opts read the sources under path from the git
+// revision named by --rev, if any. The returned function releases the
+// revision.
+func revisionOptions(cmd *cobra.Command, path string, opts analyzer.Options) (analyzer.Options, func(), error) {
+	rev, _ := cmd.Flags().GetString("rev")
+	return atRevision(path, rev, opts)
+}
+
+// atRevision makes opts read the sources under path from the git revision
+// rev, or from the working tree when rev is empty. The returned function
+// releases the revision.
+func atRevision(path, rev string, opts analyzer.Options) (analyzer.Options, func(), error) {
+	if rev == "" {
+		return opts, func() {}, nil
+	}
+	dir, _ := analyzer.SplitPattern(path)
+	fsys, err := gitfs.Open(dir, rev)
+	if err != nil {
+		return opts, nil, err
+	}
+	opts.FS = fsys
+	return opts, func() { fsys.Close() }, nil
+}
+
+// addExampleFlags registers the flags filling in missing examples.
+func addExampleFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("examples", false, "Fill in missing examples with generated values")
+	cmd.Flags().Int64("seed", 1, "Seed for generated examples")
+}
+
+// analyze extracts the API document from the Go sources under path, and
+// fills in missing examples when the command was asked to.
+func analyze(cmd *cobra.Command, path string) (*model.Document, error) {
+	doc, err := analyzeCached(cmd, path)
+	if err != nil {
+		return nil, err
+	}
+	if fill, _ := cmd.Flags().GetBool("examples"); fill {
+		seed, _ := cmd.Flags().GetInt64("seed")
+		examples.Fill(doc, examples.Options{Seed: seed})
+	}
+	return doc, nil
+}
+
+// analyzeCached returns the cached analysis of the sources under path,
+// analyzing and caching them when they changed since the last run.
+// Failures to use the cache only cost the analysis.
+func analyzeCached(cmd *cobra.Command, path string) (*model.Document, error) {
+	opts, err := analysisOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	opts, done, err := revisionOptions(cmd, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	c, err := openCache(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return cachedAnalysis(c, path, opts)
+}
+
+// cachedAnalysis returns the analysis of the sources under path from c,
+// analyzing and caching them on a miss. A nil c analyzes without caching.
+func cachedAnalysis(c *cache.Cache, path string, opts analyzer.Options) (*model.Document, error) {
+	if c == nil {
+		return analyzer.Analyze(path, opts)
+	}
+	fingerprint, err := analyzer.Fingerprint(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	key := cache.Key("analysis", fingerprint, cache.Binary())
+	doc := &model.Document{}
+	if c.Get(key, doc) {
+		return doc, nil
+	}
+	doc, err = analyzer.Analyze(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.Put(key, doc)
+	return doc, nil
+}
+
+// openCache returns the cache selected by --cache-dir and bounded by
+// --cache-max-size, or nil when caching is disabled with --no-cache or no
+// cache directory is available. --debug-bundle disables the cache too, so
+// that the analysis whose failures it captures runs.
+func openCache(cmd *cobra.Command) (*cache.Cache, error) {
+	if off, _ := cmd.Flags().GetBool("no-cache"); off || debugBundle(cmd) != "" {
+		return nil, nil
+	}
+	size, _ := cmd.Flags().GetString("cache-max-size")
+	maxSize, err := cache.ParseSize(size)
+	if err != nil {
+		return nil, fmt.Errorf("--cache-max-size: %w", err)
+	}
+	dir, _ := cmd.Flags().GetString("cache-dir")
+	if dir == "" {
+		if dir, err = cache.DefaultDir(); err != nil {
+			return nil, nil
+		}
+	}
+	return cache.New(dir, maxSize), nil
+}
+
+// encode writes v as JSON or YAML.
+func encode(w io.Writer, v any, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(v)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("unknown output format %q (want json or yaml)", format)
+}
+
+// writeOutput runs write against the named file, or standard output when
+// path is empty or "-".
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "" || path == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func init() {
+	rootCmd.PersistentFlags().String("config", "", "Configuration file (default: "+config.DefaultFile+" if present)")
+	rootCmd.PersistentFlags().String("cache-dir", "", "Directory caching analysis results (default: the user cache directory)")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Analyze without reading or writing the cache")
+	rootCmd.PersistentFlags().String("cache-max-size", "512MB", "Size the cache directory is kept under by evicting the least recently used entries (0: unbounded)")
 	rootCmd.AddCommand(parseCmd)
-	
+
 	// Add flags for parse command
-	parseCmd.Flags().BoolP("recursive", "r", false, "Parse directories recursively")
-	parseCmd.Flags().StringSliceP("include", "i", []string{}, "Include patterns for files")
-	parseCmd.Flags().StringSliceP("exclude", "e", []string{}, "Exclude patterns for files")
+	addAnalysisFlags(parseCmd)
+	addExampleFlags(parseCmd)
+	addRevisionFlag(parseCmd)
+	parseCmd.Flags().String("shard", "", "Analyze shard i/n of the program and write a partial output for merge-shards")
 	parseCmd.Flags().StringP("output", "o", "", "Output file for parsed documentation")
-	parseCmd.Flags().StringP("format", "f", "json", "Output format (json, yaml)")
+	parseCmd.Flags().StringP("format", "f", "json", "Output format (json, yaml, graphql-sdl, docusaurus, mkdocs, hugo)")
+	parseCmd.Flags().String("site-title", "", "Title of the overview page of docusaurus, mkdocs and hugo output (default: the service or module name)")
+	parseCmd.Flags().String("site-prefix", "", "Path of the docusaurus or mkdocs output inside the docs directory of the site")
+	parseCmd.Flags().String("compat", "", "Shape json or yaml output like another parser of the generator (ts-godoc: the TypeScript GoDocParser)")
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	err := execute()
+	if berr := writeDebugBundle(err); berr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", berr)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/review"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review [path]",
+	Short: "Render the documentation changes between two git revisions for review",
+	Long: `Analyze the Go sources under path (./... by default) at two git revisions,
+read from the repository without checking them out, render the endpoint and
+schema pages of both as site exports lay them out, and write a side-by-side
+HTML diff of the pages that changed: added and removed pages, and changed
+paragraphs, headings and table rows with the words that changed
+highlighted.
+
+The report is a single HTML file styled by the built-in theme, with no
+external resources, that can be attached to a pull request. --to names the
+working tree when empty.`,
+	Example: `  api-doc-gen-go review --from main --to HEAD --out review.html
+  api-doc-gen-go review ./services/users/... --from v1.4.0 --to "" -o review.html`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "./..."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		if from == "" {
+			return fmt.Errorf("name the base revision with --from")
+		}
+		c, err := openCache(cmd)
+		if err != nil {
+			return err
+		}
+		analyzeAt := func(rev string) (*model.Document, error) {
+			opts, err := analysisOptions(cmd)
+			if err != nil {
+				return nil, err
+			}
+			opts, done, err := atRevision(path, rev, opts)
+			if err != nil {
+				return nil, err
+			}
+			defer done()
+			return cachedAnalysis(c, path, opts)
+		}
+		before, err := analyzeAt(from)
+		if err != nil {
+			return fmt.Errorf("%s: %w", from, err)
+		}
+		after, err := analyzeAt(to)
+		if err != nil {
+			return fmt.Errorf("%s: %w", revisionName(to), err)
+		}
+
+		changes := review.Compare(before, after)
+		opts := review.Options{From: from, To: revisionName(to)}
+		opts.Title, _ = cmd.Flags().GetString("title")
+		out, _ := cmd.Flags().GetString("out")
+		err = writeOutput(out, func(w io.Writer) error {
+			_, err := w.Write(review.HTML(changes, opts))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d pages changed between %s and %s\n", len(changes), from, revisionName(to))
+		return nil
+	},
+}
+
+// revisionName names a revision given to atRevision.
+func revisionName(rev string) string {
+	if rev == "" {
+		return "working tree"
+	}
+	return rev
+}
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+	addAnalysisFlags(reviewCmd)
+	reviewCmd.Flags().String("from", "main", "Base revision: a git branch, tag or commit")
+	reviewCmd.Flags().String("to", "HEAD", "Revision under review (empty: the working tree)")
+	reviewCmd.Flags().StringP("out", "o", "review.html", "Output HTML file (-: standard output)")
+	reviewCmd.Flags().String("title", "", "Title of the report (default: API documentation review)")
+}
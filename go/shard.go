@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/examples"
+)
+
+var mergeShardsCmd = &cobra.Command{
+	Use:   "merge-shards [partial...]",
+	Short: "Combine the partial outputs of sharded parse runs",
+	Long: `Combine the partial outputs written by "parse --shard i/n" for every shard of
+a program into the document an unsharded parse of the program writes.
+
+parse --shard divides the packages of the program deterministically: every
+shard is planned from the same import graph, so the shards can run in
+parallel on different machines over the same sources. A shard analyzes the
+packages some binaries of the program are built from, keeping the packages
+parsed by more than one shard few. The command fails when a shard is
+missing or the partials were written for different sources or options.`,
+	Example: `  api-doc-gen-go parse ./... --shard 1/3 -o part1.json
+  api-doc-gen-go parse ./... --shard 2/3 -o part2.json
+  api-doc-gen-go parse ./... --shard 3/3 -o part3.json
+  api-doc-gen-go merge-shards part1.json part2.json part3.json -o api.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var parts []*analyzer.Partial
+		for _, name := range args {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			part := &analyzer.Partial{}
+			if err := json.Unmarshal(data, part); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			parts = append(parts, part)
+		}
+		doc, err := analyzer.Merge(parts)
+		if err != nil {
+			return err
+		}
+		if fill, _ := cmd.Flags().GetBool("examples"); fill {
+			seed, _ := cmd.Flags().GetInt64("seed")
+			examples.Fill(doc, examples.Options{Seed: seed})
+		}
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		return writeOutput(output, func(w io.Writer) error {
+			return encode(w, doc, format)
+		})
+	},
+}
+
+// parseShard writes the partial analysis of the shard named by --shard of
+// the program under path. Partials are never cached.
+func parseShard(cmd *cobra.Command, path, shard string) error {
+	s, err := analyzer.ParseShard(shard)
+	if err != nil {
+		return fmt.Errorf("--shard: %w", err)
+	}
+	if format, _ := cmd.Flags().GetString("format"); format != "json" {
+		return fmt.Errorf("--shard writes partial output as json, not %s; merge-shards converts it", format)
+	}
+	if fill, _ := cmd.Flags().GetBool("examples"); fill {
+		return fmt.Errorf("--examples applies to the merged document; pass it to merge-shards")
+	}
+	opts, err := analysisOptions(cmd)
+	if err != nil {
+		return err
+	}
+	opts, done, err := revisionOptions(cmd, path, opts)
+	if err != nil {
+		return err
+	}
+	defer done()
+	opts.Shard = s
+	part, err := analyzer.AnalyzeShard(path, opts)
+	if err != nil {
+		return err
+	}
+	output, _ := cmd.Flags().GetString("output")
+	return writeOutput(output, func(w io.Writer) error {
+		return encode(w, part, "json")
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(mergeShardsCmd)
+
+	addExampleFlags(mergeShardsCmd)
+	mergeShardsCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	mergeShardsCmd.Flags().StringP("format", "f", "json", "Output format (json, yaml)")
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/snapshot"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Record the public API surface and check changes to it",
+	Long: `Record the public API surface of the Go sources in committed text files, and
+fail when it changes without approval, so that every API change is
+acknowledged in code review.
+
+The surface has one line per feature, sorted: endpoints with their
+parameters, bodies, responses and security, schema properties, and the
+signatures of the exported declarations of packages other than main and
+internal ones. The snapshot directory (--dir) holds the recorded surface in
+api.txt. Changes since are approved by listing them in files of its next/
+subdirectory: added lines as they are, removed lines prefixed with "-".
+Text after " #", such as an issue number, is ignored.
+
+"snapshot check" fails when the surface differs from api.txt by changes no
+next/ file approves. "snapshot write --next NAME" writes the unapproved
+changes to next/NAME.txt for review; "snapshot write" records the current
+surface in api.txt, after which the approvals can be removed.`,
+	Example: `  api-doc-gen-go snapshot write ./...
+  api-doc-gen-go snapshot check ./...
+  api-doc-gen-go snapshot write ./... --next 1234-add-orders`,
+}
+
+var snapshotWriteCmd = &cobra.Command{
+	Use:   "write [path]",
+	Short: "Record the API surface, or its unapproved changes with --next",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		surface, err := apiSurface(cmd, args)
+		if err != nil {
+			return err
+		}
+		dir, _ := cmd.Flags().GetString("dir")
+		next, _ := cmd.Flags().GetString("next")
+		if next == "" {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return err
+			}
+			name := filepath.Join(dir, snapshot.File)
+			if err := snapshot.Write(name, surface); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "recorded %d lines in %s\n", len(surface), name)
+			return nil
+		}
+
+		res, err := checkSnapshot(dir, surface)
+		if err != nil {
+			return err
+		}
+		changes := res.Unapproved()
+		if len(changes) == 0 {
+			fmt.Fprintln(os.Stderr, "every change is approved")
+			return nil
+		}
+		name := filepath.Join(dir, snapshot.NextDir, next+".txt")
+		var lines []string
+		if _, err := os.Stat(name); err == nil {
+			if lines, err = snapshot.Read(name); err != nil {
+				return err
+			}
+		}
+		for _, c := range changes {
+			lines = append(lines, c.String())
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		if err := snapshot.Write(name, lines); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "wrote %d changes to %s\n", len(changes), name)
+		return nil
+	},
+}
+
+var snapshotCheckCmd = &cobra.Command{
+	Use:   "check [path]",
+	Short: "Fail when the API surface changed without approval",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		surface, err := apiSurface(cmd, args)
+		if err != nil {
+			return err
+		}
+		dir, _ := cmd.Flags().GetString("dir")
+		res, err := checkSnapshot(dir, surface)
+		if err != nil {
+			return err
+		}
+		for _, entry := range res.Unused {
+			fmt.Fprintf(os.Stderr, "note: %s approves no change\n", entry)
+		}
+		changes := res.Unapproved()
+		for _, c := range changes {
+			kind := "addition"
+			if c.Removed {
+				kind = "removal"
+			}
+			fmt.Printf("unapproved %s: %s\n", kind, c.Line)
+		}
+		if len(changes) > 0 {
+			return fmt.Errorf("%d API changes are not approved; list them in a file of %s for review (snapshot write --next NAME writes them)",
+				len(changes), filepath.Join(dir, snapshot.NextDir))
+		}
+		return nil
+	},
+}
+
+// apiSurface returns the API surface of the Go sources named by args,
+// ./... by default.
+func apiSurface(cmd *cobra.Command, args []string) ([]string, error) {
+	path := "./..."
+	if len(args) == 1 {
+		path = args[0]
+	}
+	opts, err := analysisOptions(cmd)
+	if err != nil {
+		return nil, err
+	}
+	opts, done, err := revisionOptions(cmd, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	prog, err := analyzer.Load(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Surface(prog, analyzer.AnalyzeProgram(prog)), nil
+}
+
+func checkSnapshot(dir string, surface []string) (*snapshot.Result, error) {
+	res, err := snapshot.Check(dir, surface)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w; record the surface with snapshot write first", err)
+	}
+	return res, err
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotWriteCmd)
+	snapshotCmd.AddCommand(snapshotCheckCmd)
+
+	for _, cmd := range []*cobra.Command{snapshotWriteCmd, snapshotCheckCmd} {
+		addAnalysisFlags(cmd)
+		addRevisionFlag(cmd)
+		cmd.Flags().String("dir", "api", "Snapshot directory holding api.txt and the next/ approvals")
+	}
+	snapshotWriteCmd.Flags().String("next", "", "Write the unapproved changes to next/NAME.txt instead of recording the surface")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/sunset"
+)
+
+var sunsetCmd = &cobra.Command{
+	Use:   "sunset [path]",
+	Short: "Report deprecated endpoints by sunset date",
+	Long: `List the deprecated endpoints of the Go sources in path by the sunset date of
+their doc comment, given in the Deprecated paragraph:
+
+  // Deprecated: use /v2/users. Sunset: 2027-03-01.
+
+Each endpoint is past its sunset date, near it (within --within days),
+scheduled later, or undated. With --check the command fails when an
+endpoint past its sunset date is still served, so that CI catches removals
+that are overdue.
+
+"gen sunset" generates middleware announcing the schedule to clients.`,
+	Example: `  api-doc-gen-go sunset ./...
+  api-doc-gen-go sunset ./... --within 30 --check`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "./..."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		today := time.Now()
+		if s, _ := cmd.Flags().GetString("today"); s != "" {
+			t, err := time.Parse(model.DateLayout, s)
+			if err != nil {
+				return fmt.Errorf("invalid --today %q (want a date like 2027-03-01)", s)
+			}
+			today = t
+		}
+		within, _ := cmd.Flags().GetInt("within")
+		doc, err := analyze(cmd, path)
+		if err != nil {
+			return err
+		}
+		entries := sunset.Report(doc, today, within)
+		if len(entries) == 0 {
+			fmt.Fprintln(os.Stderr, "no deprecated endpoints")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		past := 0
+		for _, e := range entries {
+			date, when := "-", ""
+			if !e.Sunset.IsZero() {
+				date = e.Sunset.Format(model.DateLayout)
+				switch {
+				case e.Days < 0:
+					when = fmt.Sprintf("%d days ago", -e.Days)
+				case e.Days == 0:
+					when = "today"
+				default:
+					when = fmt.Sprintf("in %d days", e.Days)
+				}
+			}
+			if e.Status == sunset.Past {
+				past++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Status, date, when, e.Endpoint.Key())
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if check, _ := cmd.Flags().GetBool("check"); check && past > 0 {
+			return fmt.Errorf("%d endpoints are still served past their sunset date", past)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sunsetCmd)
+
+	addAnalysisFlags(sunsetCmd)
+	sunsetCmd.Flags().Int("within", 90, "Days before its sunset date from which an endpoint is near it")
+	sunsetCmd.Flags().String("today", "", "Date the report is made for, as 2027-03-01 (default: today)")
+	sunsetCmd.Flags().Bool("check", false, "Fail when an endpoint is served past its sunset date")
+}
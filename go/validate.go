@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/analyzer"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/fix"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/glossary"
+	"github.com/api-documentation-generator/api-document-generator/go/go/internal/model"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "Report problems in the API documentation of Go sources",
+	Long: `Analyze the Go sources in path and report the diagnostics of the analysis.
+
+With a glossary (--glossary, or the "glossary" key of the configuration file),
+the doc comments of schemas, fields, enum constants and handlers and the
+values of example tags are also checked for banned terms and for the
+capitalization of preferred terms. Positions point at the offending word in
+the source.
+
+--fix applies the suggested replacements to the source files and reports the
+problems that remain. The command fails when errors remain.
+
+--rev validates the sources of a git commit or tag instead of the working tree.`,
+	Example: `  api-doc-gen-go validate ./... --glossary glossary.yaml
+  api-doc-gen-go validate ./... --fix
+  api-doc-gen-go validate ./... --rev v1.2.0`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		g, err := loadGlossary(cmd)
+		if err != nil {
+			return err
+		}
+		apply, _ := cmd.Flags().GetBool("fix")
+		if rev, _ := cmd.Flags().GetString("rev"); apply && rev != "" {
+			return fmt.Errorf("--fix cannot edit the sources of --rev %s", rev)
+		}
+		opts, err := analysisOptions(cmd)
+		if err != nil {
+			return err
+		}
+		opts, done, err := revisionOptions(cmd, args[0], opts)
+		if err != nil {
+			return err
+		}
+		defer done()
+		prog, err := analyzer.Load(args[0], opts)
+		if err != nil {
+			return err
+		}
+		doc, texts := analyzer.AnalyzeTexts(prog)
+		diags := doc.Diagnostics
+		if g != nil {
+			diags = append(diags, g.Check(texts)...)
+		}
+
+		if apply {
+			res, err := fix.Apply(prog.Root, diags)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "fixed %d problems in %d files\n", len(res.Fixed), len(res.Files))
+			diags = without(diags, res.Fixed)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		err = writeOutput(output, func(w io.Writer) error {
+			if format != "text" {
+				if diags == nil {
+					diags = []*model.Diagnostic{}
+				}
+				return encode(w, diags, format)
+			}
+			for _, d := range diags {
+				line := d.String()
+				if d.Suggestion != "" {
+					line += fmt.Sprintf(" (suggestion: %q)", d.Suggestion)
+				}
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, d := range diags {
+			if d.Severity == model.SeverityError {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d errors found", failed)
+		}
+		return nil
+	},
+}
+
+// loadGlossary loads the glossary named by --glossary or by the
+// configuration file, or returns nil when there is none.
+func loadGlossary(cmd *cobra.Command) (*glossary.Glossary, error) {
+	path, _ := cmd.Flags().GetString("glossary")
+	if path == "" {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return nil, err
+		}
+		path = cfg.Glossary
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return glossary.Load(path)
+}
+
+func without(diags, drop []*model.Diagnostic) []*model.Diagnostic {
+	dropped := map[*model.Diagnostic]bool{}
+	for _, d := range drop {
+		dropped[d] = true
+	}
+	var kept []*model.Diagnostic
+	for _, d := range diags {
+		if !dropped[d] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	addAnalysisFlags(validateCmd)
+	addRevisionFlag(validateCmd)
+	validateCmd.Flags().String("glossary", "", "Glossary file (default: the glossary key of the configuration file)")
+	validateCmd.Flags().Bool("fix", false, "Apply suggested fixes to the source files")
+	validateCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+	validateCmd.Flags().StringP("format", "f", "text", "Output format (text, json, yaml)")
+}